@@ -1,10 +1,17 @@
+// Command analyzer runs the file analysis service
+// Besides the database and storage settings it is configured by PLAGIARISM_MODE, CONTAINMENT_THRESHOLD,
+// WORDCLOUD_RENDERER, WORDCLOUD_API_URL, SUMMARY_SENTENCES, ANALYSIS_WORKERS, ANALYSIS_JOB_TIMEOUT,
+// INDEX_BACKFILL_INTERVAL, WEBHOOK_SECRET, WEBHOOK_TIMEOUT, WEBHOOK_MAX_ATTEMPTS and WEBHOOK_ALLOW_INTERNAL_ADDRESSES
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"net"
 	"os"
+	"strconv"
+	"time"
 
 	_ "github.com/lib/pq"
 	"google.golang.org/grpc"
@@ -14,7 +21,10 @@ import (
 	"local.dev/doc-analyzer/internal/pkg/analyzer/clients"
 	"local.dev/doc-analyzer/internal/pkg/analyzer/repository/postgres"
 	"local.dev/doc-analyzer/internal/pkg/analyzer/service"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/storage"
 	"local.dev/doc-analyzer/internal/pkg/analyzer/storage/local"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/storage/s3"
+	"local.dev/doc-analyzer/internal/pkg/s3client"
 	pb "local.dev/doc-analyzer/internal/proto/analyzer"
 )
 
@@ -41,7 +51,7 @@ func main() {
 	}
 	log.Println("Connected to the database")
 
-	// Create the analysis_results and similar_files tables if they don't exist
+	// Create the analysis_results, similar_files, matched_passages and LSH index tables if they don't exist
 	_, err = db.Exec(`
 		CREATE TABLE IF NOT EXISTS analysis_results (
 			file_id TEXT PRIMARY KEY,
@@ -50,29 +60,232 @@ func main() {
 			character_count INT NOT NULL,
 			is_plagiarism BOOLEAN NOT NULL,
 			word_cloud_location TEXT,
+			language TEXT NOT NULL DEFAULT '',
+			obfuscation_detected BOOLEAN NOT NULL DEFAULT FALSE,
+			homoglyph_count INT NOT NULL DEFAULT 0,
+			invisible_char_count INT NOT NULL DEFAULT 0,
+			character_count_no_spaces INT NOT NULL DEFAULT 0,
+			sentence_count INT NOT NULL DEFAULT 0,
+			avg_sentence_length DOUBLE PRECISION NOT NULL DEFAULT 0,
+			avg_word_length DOUBLE PRECISION NOT NULL DEFAULT 0,
+			lexical_diversity DOUBLE PRECISION NOT NULL DEFAULT 0,
+			readability_formula TEXT NOT NULL DEFAULT '',
+			reading_ease DOUBLE PRECISION NOT NULL DEFAULT 0,
+			grade_level DOUBLE PRECISION NOT NULL DEFAULT 0,
+			summary TEXT NOT NULL DEFAULT '',
+			word_cloud_format TEXT NOT NULL DEFAULT '',
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		);
+
+		ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS language TEXT NOT NULL DEFAULT '';
+		ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS obfuscation_detected BOOLEAN NOT NULL DEFAULT FALSE;
+		ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS homoglyph_count INT NOT NULL DEFAULT 0;
+		ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS invisible_char_count INT NOT NULL DEFAULT 0;
+		ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS character_count_no_spaces INT NOT NULL DEFAULT 0;
+		ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS sentence_count INT NOT NULL DEFAULT 0;
+		ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS avg_sentence_length DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS avg_word_length DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS lexical_diversity DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS readability_formula TEXT NOT NULL DEFAULT '';
+		ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS reading_ease DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS grade_level DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS summary TEXT NOT NULL DEFAULT '';
+		ALTER TABLE analysis_results ADD COLUMN IF NOT EXISTS word_cloud_format TEXT NOT NULL DEFAULT '';
+		UPDATE analysis_results SET word_cloud_format = 'png'
+			WHERE word_cloud_format = '' AND COALESCE(word_cloud_location, '') <> '';
 		
 		CREATE TABLE IF NOT EXISTS similar_files (
 			file_id TEXT,
 			similar_file_id TEXT,
+			score DOUBLE PRECISION NOT NULL DEFAULT 0,
+			metric TEXT NOT NULL DEFAULT '',
+			ngram_size INT NOT NULL DEFAULT 0,
+			threshold DOUBLE PRECISION NOT NULL DEFAULT 0,
+			algorithm_version INT NOT NULL DEFAULT 0,
+			containment DOUBLE PRECISION NOT NULL DEFAULT 0,
+			similar_containment DOUBLE PRECISION NOT NULL DEFAULT 0,
+			coverage DOUBLE PRECISION NOT NULL DEFAULT 0,
+			matched_words INT NOT NULL DEFAULT 0,
 			PRIMARY KEY (file_id, similar_file_id)
 		);
+
+		ALTER TABLE similar_files ADD COLUMN IF NOT EXISTS score DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE similar_files ADD COLUMN IF NOT EXISTS metric TEXT NOT NULL DEFAULT '';
+		ALTER TABLE similar_files ADD COLUMN IF NOT EXISTS ngram_size INT NOT NULL DEFAULT 0;
+		ALTER TABLE similar_files ADD COLUMN IF NOT EXISTS threshold DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE similar_files ADD COLUMN IF NOT EXISTS algorithm_version INT NOT NULL DEFAULT 0;
+		ALTER TABLE similar_files ADD COLUMN IF NOT EXISTS containment DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE similar_files ADD COLUMN IF NOT EXISTS similar_containment DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE similar_files ADD COLUMN IF NOT EXISTS coverage DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE similar_files ADD COLUMN IF NOT EXISTS matched_words INT NOT NULL DEFAULT 0;
+
+		CREATE TABLE IF NOT EXISTS matched_passages (
+			id SERIAL PRIMARY KEY,
+			file_id TEXT NOT NULL,
+			similar_file_id TEXT NOT NULL,
+			start_offset INT NOT NULL,
+			end_offset INT NOT NULL,
+			similar_start_offset INT NOT NULL,
+			similar_end_offset INT NOT NULL,
+			matched_text TEXT NOT NULL,
+			FOREIGN KEY (file_id, similar_file_id) REFERENCES similar_files (file_id, similar_file_id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS matched_passages_pair_idx ON matched_passages (file_id, similar_file_id);
+
+		CREATE TABLE IF NOT EXISTS minhash_signatures (
+			file_id TEXT PRIMARY KEY,
+			signature BIGINT[] NOT NULL,
+			algorithm_version INT NOT NULL DEFAULT 1,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		ALTER TABLE minhash_signatures ADD COLUMN IF NOT EXISTS algorithm_version INT NOT NULL DEFAULT 1;
+
+		CREATE TABLE IF NOT EXISTS lsh_buckets (
+			band INT NOT NULL,
+			bucket BIGINT NOT NULL,
+			file_id TEXT NOT NULL,
+			PRIMARY KEY (band, bucket, file_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS lsh_buckets_file_id_idx ON lsh_buckets (file_id);
+
+		ALTER TABLE minhash_signatures ADD COLUMN IF NOT EXISTS fingerprint_count INT;
+
+		CREATE TABLE IF NOT EXISTS winnowing_fingerprints (
+			hash BIGINT NOT NULL,
+			file_id TEXT NOT NULL,
+			PRIMARY KEY (hash, file_id)
+		);
+
+		CREATE INDEX IF NOT EXISTS winnowing_fingerprints_file_id_idx ON winnowing_fingerprints (file_id);
+
+		CREATE TABLE IF NOT EXISTS index_failures (
+			file_id TEXT PRIMARY KEY,
+			attempts INT NOT NULL,
+			error TEXT NOT NULL,
+			next_attempt_at TIMESTAMP NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS file_terms (
+			file_id TEXT NOT NULL,
+			term TEXT NOT NULL,
+			word TEXT NOT NULL,
+			count INT NOT NULL,
+			PRIMARY KEY (file_id, term)
+		);
+
+		CREATE TABLE IF NOT EXISTS document_frequencies (
+			term TEXT PRIMARY KEY,
+			document_count INT NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS analysis_jobs (
+			id TEXT PRIMARY KEY,
+			file_id TEXT NOT NULL,
+			generate_word_cloud BOOLEAN NOT NULL DEFAULT FALSE,
+			word_cloud_options JSONB NOT NULL DEFAULT '{}',
+			status TEXT NOT NULL,
+			error TEXT NOT NULL DEFAULT '',
+			attempts INT NOT NULL DEFAULT 0,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			started_at TIMESTAMPTZ,
+			finished_at TIMESTAMPTZ
+		);
+
+		CREATE INDEX IF NOT EXISTS analysis_jobs_status_idx ON analysis_jobs (status, created_at);
+
+		ALTER TABLE analysis_jobs ADD COLUMN IF NOT EXISTS webhook_url TEXT NOT NULL DEFAULT '';
+
+		CREATE TABLE IF NOT EXISTS webhooks (
+			id TEXT PRIMARY KEY,
+			url TEXT NOT NULL,
+			secret TEXT NOT NULL,
+			events TEXT[] NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id TEXT PRIMARY KEY,
+			webhook_id TEXT NOT NULL DEFAULT '',
+			url TEXT NOT NULL,
+			event TEXT NOT NULL,
+			file_id TEXT NOT NULL,
+			payload JSONB NOT NULL,
+			status TEXT NOT NULL,
+			attempts INT NOT NULL DEFAULT 0,
+			response_status INT NOT NULL DEFAULT 0,
+			error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			last_attempt_at TIMESTAMPTZ
+		);
+
+		CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (status, next_attempt_at);
+		CREATE INDEX IF NOT EXISTS webhook_deliveries_file_id_idx ON webhook_deliveries (file_id);
 	`)
 	if err != nil {
 		log.Fatalf("Failed to create tables: %v", err)
 	}
 
-	// Initialize storage
-	storagePath := os.Getenv("STORAGE_PATH")
-	if storagePath == "" {
-		storagePath = "./storage/wordclouds"
-		log.Println("STORAGE_PATH not set, using default:", storagePath)
+	// Initialize storage, the local filesystem or an S3-compatible object storage such as MinIO
+	storageBackend := os.Getenv("STORAGE_BACKEND")
+	if storageBackend == "" {
+		storageBackend = "local"
+		log.Println("STORAGE_BACKEND not set, using default:", storageBackend)
 	}
 
-	storage, err := local.NewLocalStorage(storagePath)
-	if err != nil {
-		log.Fatalf("Failed to initialize storage: %v", err)
+	var wordCloudStorage storage.WordCloudStorage
+	switch storageBackend {
+	case "local":
+		storagePath := os.Getenv("STORAGE_PATH")
+		if storagePath == "" {
+			storagePath = "./storage/wordclouds"
+			log.Println("STORAGE_PATH not set, using default:", storagePath)
+		}
+
+		wordCloudStorage, err = local.NewLocalStorage(storagePath)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+	case "s3":
+		s3Config := s3client.Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		}
+		if value := os.Getenv("S3_PART_SIZE"); value != "" {
+			partSize, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || partSize <= 0 {
+				log.Fatalf("Invalid S3_PART_SIZE: %q", value)
+			}
+			s3Config.PartSize = partSize
+		}
+
+		s3Prefix := os.Getenv("S3_PREFIX")
+		if s3Prefix == "" {
+			s3Prefix = "wordclouds/"
+			log.Println("S3_PREFIX not set, using default:", s3Prefix)
+		}
+
+		s3Client, err := s3client.NewClient(s3Config)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err = s3Client.CheckBucket(ctx)
+		cancel()
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+
+		wordCloudStorage = s3.NewS3Storage(s3Client, s3Prefix)
+	default:
+		log.Fatalf("Invalid STORAGE_BACKEND: %q", storageBackend)
 	}
 
 	// Initialize repository
@@ -94,27 +307,126 @@ func main() {
 	// Initialize analyzers
 	textAnalyzer := analyzer.NewTextAnalyzer()
 	plagiarismChecker := analyzer.NewPlagiarismChecker()
+
+	plagiarismMode, err := analyzer.ParseDetectionMode(os.Getenv("PLAGIARISM_MODE"))
+	if err != nil {
+		log.Fatalf("Invalid PLAGIARISM_MODE: %v", err)
+	}
+	plagiarismChecker.Mode = plagiarismMode
+	log.Println("Plagiarism detection mode:", plagiarismMode)
+
+	if value := os.Getenv("CONTAINMENT_THRESHOLD"); value != "" {
+		containmentThreshold, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			log.Fatalf("Invalid CONTAINMENT_THRESHOLD: %v", err)
+		}
+		plagiarismChecker.ContainmentThreshold = containmentThreshold
+	}
+	log.Println("Containment threshold:", plagiarismChecker.ContainmentThreshold)
 	
+	wordCloudRenderer, err := analyzer.ParseWordCloudRenderer(os.Getenv("WORDCLOUD_RENDERER"))
+	if err != nil {
+		log.Fatalf("Invalid WORDCLOUD_RENDERER: %v", err)
+	}
+	log.Println("Word cloud renderer:", wordCloudRenderer)
+
 	wordCloudAPIURL := os.Getenv("WORDCLOUD_API_URL")
-	if wordCloudAPIURL == "" {
+	if wordCloudAPIURL == "" && wordCloudRenderer == analyzer.HTTPRenderer {
 		wordCloudAPIURL = "https://quickchart.io/wordcloud"
 		log.Println("WORDCLOUD_API_URL not set, using default:", wordCloudAPIURL)
 	}
-	wordCloudGenerator := analyzer.NewWordCloudGenerator(wordCloudAPIURL)
+	wordCloudGenerator := analyzer.NewWordCloudGenerator(wordCloudRenderer, wordCloudAPIURL)
+
+	summarizer := analyzer.NewSummarizer(textAnalyzer)
+	if value := os.Getenv("SUMMARY_SENTENCES"); value != "" {
+		summarySentences, err := strconv.Atoi(value)
+		if err != nil || summarySentences < 1 {
+			log.Fatalf("Invalid SUMMARY_SENTENCES: %q", value)
+		}
+		summarizer.SentenceCount = summarySentences
+	}
+	log.Println("Summary sentences:", summarizer.SentenceCount)
+
+	// Configure the webhook notifications, before the workers finishing analyses start
+	webhookSecret := os.Getenv("WEBHOOK_SECRET")
+	if webhookSecret == "" {
+		log.Println("WEBHOOK_SECRET not set, webhook URLs of analysis requests are disabled")
+	}
+
+	webhookTimeout := service.DefaultWebhookTimeout
+	if value := os.Getenv("WEBHOOK_TIMEOUT"); value != "" {
+		webhookTimeout, err = time.ParseDuration(value)
+		if err != nil || webhookTimeout <= 0 {
+			log.Fatalf("Invalid WEBHOOK_TIMEOUT: %q", value)
+		}
+	}
+
+	webhookService := service.NewWebhookService(postgres.NewWebhookRepo(db), webhookSecret, webhookTimeout)
+	if value := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); value != "" {
+		webhookMaxAttempts, err := strconv.Atoi(value)
+		if err != nil || webhookMaxAttempts < 1 {
+			log.Fatalf("Invalid WEBHOOK_MAX_ATTEMPTS: %q", value)
+		}
+		webhookService.MaxAttempts = int32(webhookMaxAttempts)
+	}
+	if value := os.Getenv("WEBHOOK_ALLOW_INTERNAL_ADDRESSES"); value != "" {
+		webhookService.AllowInternalAddresses, err = strconv.ParseBool(value)
+		if err != nil {
+			log.Fatalf("Invalid WEBHOOK_ALLOW_INTERNAL_ADDRESSES: %q", value)
+		}
+	}
+	if webhookService.AllowInternalAddresses {
+		log.Println("Webhooks may reach internal addresses")
+	}
+	log.Printf("Webhook timeout: %s, max attempts: %d", webhookTimeout, webhookService.MaxAttempts)
 
 	// Initialize service
 	analysisService := service.NewAnalysisService(
 		repo,
-		storage,
+		wordCloudStorage,
 		fileStoringClient,
 		textAnalyzer,
 		plagiarismChecker,
 		wordCloudGenerator,
+		summarizer,
 	)
+	analysisService.SetWebhookService(webhookService)
+	go service.NewWebhookDispatcher(webhookService, 2).Run(context.Background())
+
+	// Start the workers running asynchronous analysis jobs
+	analysisWorkers := 4
+	if value := os.Getenv("ANALYSIS_WORKERS"); value != "" {
+		analysisWorkers, err = strconv.Atoi(value)
+		if err != nil || analysisWorkers < 1 {
+			log.Fatalf("Invalid ANALYSIS_WORKERS: %q", value)
+		}
+	}
+
+	analysisJobTimeout := service.DefaultJobTimeout
+	if value := os.Getenv("ANALYSIS_JOB_TIMEOUT"); value != "" {
+		analysisJobTimeout, err = time.ParseDuration(value)
+		if err != nil || analysisJobTimeout <= 0 {
+			log.Fatalf("Invalid ANALYSIS_JOB_TIMEOUT: %q", value)
+		}
+	}
+	log.Printf("Analysis workers: %d, job timeout: %s", analysisWorkers, analysisJobTimeout)
+
+	jobWorkerPool := service.NewJobWorkerPool(analysisService, analysisWorkers, analysisJobTimeout)
+	go jobWorkerPool.Run(context.Background())
+
+	// Index files analyzed before the LSH and fingerprint indexes existed, in the background
+	indexBackfill := service.NewIndexBackfill(analysisService)
+	if value := os.Getenv("INDEX_BACKFILL_INTERVAL"); value != "" {
+		indexBackfill.Interval, err = time.ParseDuration(value)
+		if err != nil || indexBackfill.Interval <= 0 {
+			log.Fatalf("Invalid INDEX_BACKFILL_INTERVAL: %q", value)
+		}
+	}
+	go indexBackfill.Run(context.Background())
 
 	// Initialize server
 	grpcServer := grpc.NewServer()
-	analysisServer := server.NewServer(analysisService)
+	analysisServer := server.NewServer(analysisService, webhookService)
 	pb.RegisterFileAnalysisServiceServer(grpcServer, analysisServer)
 
 	// Start listening
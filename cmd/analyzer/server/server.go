@@ -2,22 +2,33 @@ package server
 
 import (
 	"context"
+	"errors"
 	"log"
+	"time"
 
-	pb "local.dev/doc-analyzer/internal/proto/analyzer"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/repository"
 	"local.dev/doc-analyzer/internal/pkg/analyzer/service"
+	"local.dev/doc-analyzer/internal/pkg/storageroot"
+	pb "local.dev/doc-analyzer/internal/proto/analyzer"
 )
 
 // Server implements the FileAnalysisServiceServer interface
 type Server struct {
 	pb.UnimplementedFileAnalysisServiceServer
 	analysisService *service.AnalysisService
+	webhookService  *service.WebhookService
 }
 
 // NewServer creates a new Server instance
-func NewServer(analysisService *service.AnalysisService) *Server {
+func NewServer(analysisService *service.AnalysisService, webhookService *service.WebhookService) *Server {
 	return &Server{
 		analysisService: analysisService,
+		webhookService:  webhookService,
 	}
 }
 
@@ -25,10 +36,20 @@ func NewServer(analysisService *service.AnalysisService) *Server {
 func (s *Server) AnalyzeFile(ctx context.Context, req *pb.AnalyzeFileRequest) (*pb.AnalyzeFileResponse, error) {
 	log.Printf("Received analysis request for file ID: %s", req.FileId)
 
-	paragraphCount, wordCount, characterCount, isPlagiarism, similarFileIDs, wordCloudLocation, err := s.analysisService.AnalyzeFile(
+	if req.WebhookUrl != "" {
+		return nil, status.Error(codes.InvalidArgument, "webhook URLs are only supported for asynchronous analyses")
+	}
+
+	wordCloudOptions, err := toWordCloudOptions(req.GetWordCloudOptions())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid word cloud options: %v", err)
+	}
+
+	result, err := s.analysisService.AnalyzeFile(
 		ctx,
 		req.FileId,
 		req.GenerateWordCloud,
+		wordCloudOptions,
 	)
 	if err != nil {
 		log.Printf("Failed to analyze file: %v", err)
@@ -36,13 +57,391 @@ func (s *Server) AnalyzeFile(ctx context.Context, req *pb.AnalyzeFileRequest) (*
 	}
 
 	log.Printf("File analyzed successfully: %s", req.FileId)
+	return toPBAnalyzeFileResponse(result), nil
+}
+
+// toPBAnalyzeFileResponse converts analysis results to their protobuf representation
+func toPBAnalyzeFileResponse(result *models.AnalysisResult) *pb.AnalyzeFileResponse {
 	return &pb.AnalyzeFileResponse{
-		ParagraphCount:    paragraphCount,
-		WordCount:         wordCount,
-		CharacterCount:    characterCount,
-		IsPlagiarism:      isPlagiarism,
-		SimilarFileIds:    similarFileIDs,
-		WordCloudLocation: wordCloudLocation,
+		ParagraphCount:      result.ParagraphCount,
+		WordCount:           result.WordCount,
+		CharacterCount:      result.CharacterCount,
+		IsPlagiarism:        result.IsPlagiarism,
+		SimilarFileIds:      result.SimilarFileIDs(),
+		WordCloudLocation:   result.WordCloudLocation,
+		WordCloudFormat:     result.WordCloudFormat,
+		SimilarFiles:        toPBSimilarFiles(result.SimilarFiles),
+		Language:            result.Language,
+		ObfuscationDetected: result.ObfuscationDetected,
+		HomoglyphCount:      result.HomoglyphCount,
+		InvisibleCharCount:  result.InvisibleCharCount,
+
+		CharacterCountNoSpaces: result.CharacterCountNoSpaces,
+		SentenceCount:          result.SentenceCount,
+		AvgSentenceLength:      result.AvgSentenceLength,
+		AvgWordLength:          result.AvgWordLength,
+		LexicalDiversity:       result.LexicalDiversity,
+
+		ReadabilityFormula: result.ReadabilityFormula,
+		ReadingEase:        result.ReadingEase,
+		GradeLevel:         result.GradeLevel,
+
+		Summary: result.Summary,
+	}
+}
+
+// SubmitAnalysis handles requests for asynchronous file analysis
+func (s *Server) SubmitAnalysis(ctx context.Context, req *pb.AnalyzeFileRequest) (*pb.AnalysisJob, error) {
+	log.Printf("Received asynchronous analysis request for file ID: %s", req.FileId)
+
+	wordCloudOptions, err := toWordCloudOptions(req.GetWordCloudOptions())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid word cloud options: %v", err)
+	}
+
+	if req.WebhookUrl != "" {
+		if err := s.webhookService.ValidateRequestWebhookURL(ctx, req.WebhookUrl); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	job, err := s.analysisService.SubmitAnalysis(ctx, req.FileId, req.GenerateWordCloud, wordCloudOptions, req.WebhookUrl)
+	if err != nil {
+		log.Printf("Failed to submit analysis: %v", err)
+		return nil, err
+	}
+
+	log.Printf("Analysis job %s queued for file ID: %s", job.ID, req.FileId)
+	return toPBAnalysisJob(job), nil
+}
+
+// GetAnalysisJob handles requests for the state of an analysis job
+func (s *Server) GetAnalysisJob(ctx context.Context, req *pb.GetAnalysisJobRequest) (*pb.AnalysisJob, error) {
+	log.Printf("Received analysis job request for job ID: %s", req.JobId)
+
+	job, err := s.analysisService.GetAnalysisJob(ctx, req.JobId)
+	if err != nil {
+		log.Printf("Failed to get analysis job: %v", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, status.Errorf(codes.NotFound, "analysis job %s not found", req.JobId)
+		}
+		return nil, err
+	}
+
+	return toPBAnalysisJob(job), nil
+}
+
+// WatchAnalysis streams the stages of the analysis of a file until it finishes
+func (s *Server) WatchAnalysis(req *pb.WatchAnalysisRequest, stream pb.FileAnalysisService_WatchAnalysisServer) error {
+	log.Printf("Received analysis watch request for file ID: %s", req.FileId)
+
+	if req.FileId == "" {
+		return status.Error(codes.InvalidArgument, "file ID is required")
+	}
+
+	err := s.analysisService.WatchAnalysis(stream.Context(), req.FileId, func(event models.AnalysisProgress) error {
+		return stream.Send(&pb.AnalysisEvent{
+			FileId:  event.FileID,
+			Stage:   string(event.Stage),
+			Current: event.Current,
+			Total:   event.Total,
+			Error:   event.Error,
+			Time:    formatJobTime(event.Time),
+		})
+	})
+	if err != nil {
+		log.Printf("Stopped watching analysis of file %s: %v", req.FileId, err)
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return status.FromContextError(err).Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// CreateWebhook handles requests to register a webhook
+func (s *Server) CreateWebhook(ctx context.Context, req *pb.CreateWebhookRequest) (*pb.Webhook, error) {
+	log.Printf("Received webhook registration request for URL: %s", req.Url)
+
+	events := make([]models.WebhookEvent, 0, len(req.Events))
+	for _, value := range req.Events {
+		event, err := service.ParseWebhookEvent(value)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		events = append(events, event)
+	}
+	if err := s.webhookService.ValidateWebhookURL(ctx, req.Url); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	webhook, err := s.webhookService.CreateWebhook(ctx, req.Url, req.Secret, events)
+	if err != nil {
+		log.Printf("Failed to create webhook: %v", err)
+		return nil, err
+	}
+
+	log.Printf("Webhook %s registered for URL: %s", webhook.ID, webhook.URL)
+	pbWebhook := toPBWebhook(webhook)
+	pbWebhook.Secret = webhook.Secret
+	return pbWebhook, nil
+}
+
+// ListWebhooks handles requests for the registered webhooks
+func (s *Server) ListWebhooks(ctx context.Context, req *pb.ListWebhooksRequest) (*pb.ListWebhooksResponse, error) {
+	log.Printf("Received webhook list request")
+
+	webhooks, err := s.webhookService.ListWebhooks(ctx)
+	if err != nil {
+		log.Printf("Failed to list webhooks: %v", err)
+		return nil, err
+	}
+
+	pbWebhooks := make([]*pb.Webhook, len(webhooks))
+	for i := range webhooks {
+		pbWebhooks[i] = toPBWebhook(&webhooks[i])
+	}
+	return &pb.ListWebhooksResponse{Webhooks: pbWebhooks}, nil
+}
+
+// DeleteWebhook handles requests to delete a webhook
+func (s *Server) DeleteWebhook(ctx context.Context, req *pb.DeleteWebhookRequest) (*pb.DeleteWebhookResponse, error) {
+	log.Printf("Received webhook deletion request for webhook ID: %s", req.WebhookId)
+
+	if err := s.webhookService.DeleteWebhook(ctx, req.WebhookId); err != nil {
+		log.Printf("Failed to delete webhook: %v", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, status.Errorf(codes.NotFound, "webhook %s not found", req.WebhookId)
+		}
+		return nil, err
+	}
+
+	return &pb.DeleteWebhookResponse{}, nil
+}
+
+// ListWebhookDeliveries handles requests for the webhook delivery log
+func (s *Server) ListWebhookDeliveries(ctx context.Context, req *pb.ListWebhookDeliveriesRequest) (*pb.ListWebhookDeliveriesResponse, error) {
+	log.Printf("Received webhook delivery list request")
+
+	deliveries, err := s.webhookService.ListDeliveries(ctx, models.WebhookDeliveryFilter{
+		WebhookID: req.WebhookId,
+		FileID:    req.FileId,
+		Limit:     int(req.Limit),
+	})
+	if err != nil {
+		log.Printf("Failed to list webhook deliveries: %v", err)
+		return nil, err
+	}
+
+	pbDeliveries := make([]*pb.WebhookDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		pbDeliveries[i] = &pb.WebhookDelivery{
+			Id:             delivery.ID,
+			WebhookId:      delivery.WebhookID,
+			Url:            delivery.URL,
+			Event:          string(delivery.Event),
+			FileId:         delivery.FileID,
+			Status:         string(delivery.Status),
+			Attempts:       delivery.Attempts,
+			ResponseStatus: delivery.ResponseStatus,
+			Error:          delivery.Error,
+			CreatedAt:      formatJobTime(delivery.CreatedAt),
+			NextAttemptAt:  formatJobTime(delivery.NextAttemptAt),
+			LastAttemptAt:  formatJobTime(delivery.LastAttemptAt),
+		}
+		// Delivered and failed deliveries have no next attempt
+		if delivery.Status != models.DeliveryPending {
+			pbDeliveries[i].NextAttemptAt = ""
+		}
+	}
+	return &pb.ListWebhookDeliveriesResponse{Deliveries: pbDeliveries}, nil
+}
+
+// toPBWebhook converts a webhook to its protobuf representation without its secret
+func toPBWebhook(webhook *models.Webhook) *pb.Webhook {
+	events := make([]string, len(webhook.Events))
+	for i, event := range webhook.Events {
+		events[i] = string(event)
+	}
+	return &pb.Webhook{
+		Id:        webhook.ID,
+		Url:       webhook.URL,
+		Events:    events,
+		CreatedAt: formatJobTime(webhook.CreatedAt),
+	}
+}
+
+// toPBAnalysisJob converts an analysis job to its protobuf representation
+func toPBAnalysisJob(job *models.AnalysisJob) *pb.AnalysisJob {
+	pbJob := &pb.AnalysisJob{
+		Id:         job.ID,
+		FileId:     job.FileID,
+		Status:     string(job.Status),
+		Error:      job.Error,
+		Attempts:   job.Attempts,
+		CreatedAt:  formatJobTime(job.CreatedAt),
+		StartedAt:  formatJobTime(job.StartedAt),
+		FinishedAt: formatJobTime(job.FinishedAt),
+	}
+	if job.Result != nil {
+		pbJob.Result = toPBAnalyzeFileResponse(job.Result)
+	}
+	return pbJob
+}
+
+// formatJobTime formats the time of a job event, empty if the event has not happened
+func formatJobTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// toWordCloudOptions converts word cloud options from their protobuf representation
+// Unset options are left zero, so that the service can tell whether a request configures the word cloud
+func toWordCloudOptions(pbOptions *pb.WordCloudOptions) (analyzer.WordCloudOptions, error) {
+	options := analyzer.WordCloudOptions{
+		Width:         int(pbOptions.GetWidth()),
+		Height:        int(pbOptions.GetHeight()),
+		MaxWords:      int(pbOptions.GetMaxWords()),
+		KeepStopWords: pbOptions.GetKeepStopWords(),
+	}
+
+	var err error
+	if pbOptions.GetFormat() != "" {
+		if options.Format, err = analyzer.ParseWordCloudFormat(pbOptions.GetFormat()); err != nil {
+			return analyzer.WordCloudOptions{}, err
+		}
+	}
+	if pbOptions.GetColorScheme() != "" {
+		if options.ColorScheme, err = analyzer.ParseColorScheme(pbOptions.GetColorScheme()); err != nil {
+			return analyzer.WordCloudOptions{}, err
+		}
+	}
+	if pbOptions.GetCase() != "" {
+		if options.Case, err = analyzer.ParseWordCase(pbOptions.GetCase()); err != nil {
+			return analyzer.WordCloudOptions{}, err
+		}
+	}
+
+	if err := options.Validate(); err != nil {
+		return analyzer.WordCloudOptions{}, err
+	}
+	return options, nil
+}
+
+// toPBSimilarFiles converts similar files to their protobuf representation
+func toPBSimilarFiles(similarFiles []models.SimilarFile) []*pb.SimilarFile {
+	pbSimilarFiles := make([]*pb.SimilarFile, 0, len(similarFiles))
+	for _, similarFile := range similarFiles {
+		pbSimilarFiles = append(pbSimilarFiles, &pb.SimilarFile{
+			FileId:             similarFile.FileID,
+			Coverage:           similarFile.Coverage,
+			MatchedWords:       similarFile.MatchedWords,
+			Score:              similarFile.Score,
+			Metric:             similarFile.Metric,
+			NgramSize:          similarFile.NGramSize,
+			Threshold:          similarFile.Threshold,
+			AlgorithmVersion:   similarFile.AlgorithmVersion,
+			Containment:        similarFile.Containment,
+			SimilarContainment: similarFile.SimilarContainment,
+		})
+	}
+	return pbSimilarFiles
+}
+
+// GetMatchedPassages handles requests for passages copied from a similar file
+func (s *Server) GetMatchedPassages(ctx context.Context, req *pb.GetMatchedPassagesRequest) (*pb.GetMatchedPassagesResponse, error) {
+	log.Printf("Received matched passages request for file ID: %s, similar file ID: %s", req.FileId, req.SimilarFileId)
+
+	if err := uuid.Validate(req.FileId); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid file ID %q", req.FileId)
+	}
+	if err := uuid.Validate(req.SimilarFileId); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid similar file ID %q", req.SimilarFileId)
+	}
+
+	passages, err := s.analysisService.GetMatchedPassages(ctx, req.FileId, req.SimilarFileId)
+	if err != nil {
+		log.Printf("Failed to get matched passages: %v", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, status.Errorf(codes.NotFound, "no analyzed file %s similar to file %s", req.SimilarFileId, req.FileId)
+		}
+		return nil, err
+	}
+
+	pbPassages := make([]*pb.MatchedPassage, 0, len(passages))
+	for _, passage := range passages {
+		pbPassages = append(pbPassages, &pb.MatchedPassage{
+			Start:        passage.Start,
+			End:          passage.End,
+			SimilarStart: passage.SimilarStart,
+			SimilarEnd:   passage.SimilarEnd,
+			Text:         passage.Text,
+		})
+	}
+
+	return &pb.GetMatchedPassagesResponse{
+		Passages: pbPassages,
+	}, nil
+}
+
+// GetKeywords handles requests for the TF-IDF keywords of a file
+func (s *Server) GetKeywords(ctx context.Context, req *pb.GetKeywordsRequest) (*pb.GetKeywordsResponse, error) {
+	log.Printf("Received keywords request for file ID: %s, limit: %d", req.FileId, req.Limit)
+
+	keywords, err := s.analysisService.GetKeywords(ctx, req.FileId, int(req.Limit))
+	if err != nil {
+		log.Printf("Failed to get keywords: %v", err)
+		return nil, fileError(req.FileId, err)
+	}
+
+	pbKeywords := make([]*pb.Keyword, 0, len(keywords))
+	for _, keyword := range keywords {
+		pbKeywords = append(pbKeywords, &pb.Keyword{
+			Word:              keyword.Word,
+			Term:              keyword.Term,
+			Count:             keyword.Count,
+			DocumentFrequency: keyword.DocumentFrequency,
+			Score:             keyword.Score,
+		})
+	}
+
+	return &pb.GetKeywordsResponse{
+		Keywords: pbKeywords,
+	}, nil
+}
+
+// GetWordFrequencies handles requests for the word counts of a file
+func (s *Server) GetWordFrequencies(ctx context.Context, req *pb.GetWordFrequenciesRequest) (*pb.GetWordFrequenciesResponse, error) {
+	log.Printf("Received word frequencies request for file ID: %s, limit: %d", req.FileId, req.Limit)
+
+	wordCase, err := analyzer.ParseWordCase(req.Case)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid word case: %v", err)
+	}
+
+	words, err := s.analysisService.GetWordFrequencies(ctx, req.FileId, analyzer.WordFrequencyOptions{
+		Limit:         int(req.Limit),
+		Case:          wordCase,
+		KeepStopWords: req.KeepStopWords,
+		SeparateForms: req.SeparateForms,
+	})
+	if err != nil {
+		log.Printf("Failed to get word frequencies: %v", err)
+		return nil, fileError(req.FileId, err)
+	}
+
+	pbWords := make([]*pb.WordItem, 0, len(words))
+	for _, word := range words {
+		pbWords = append(pbWords, &pb.WordItem{
+			Text:  word.Text,
+			Value: int32(word.Value),
+		})
+	}
+
+	return &pb.GetWordFrequenciesResponse{
+		Words: pbWords,
 	}, nil
 }
 
@@ -50,14 +449,30 @@ func (s *Server) AnalyzeFile(ctx context.Context, req *pb.AnalyzeFileRequest) (*
 func (s *Server) GetWordCloud(ctx context.Context, req *pb.GetWordCloudRequest) (*pb.GetWordCloudResponse, error) {
 	log.Printf("Received word cloud request for location: %s", req.Location)
 
-	image, err := s.analysisService.GetWordCloud(ctx, req.Location)
+	image, format, err := s.analysisService.GetWordCloud(ctx, req.Location)
 	if err != nil {
 		log.Printf("Failed to get word cloud: %v", err)
+		switch {
+		case errors.Is(err, storageroot.ErrInvalidLocation):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, storageroot.ErrNotFound):
+			return nil, status.Errorf(codes.NotFound, "word cloud %s not found", req.Location)
+		}
 		return nil, err
 	}
 
 	log.Printf("Word cloud retrieved successfully: %s", req.Location)
 	return &pb.GetWordCloudResponse{
-		Image: image,
+		Image:  image,
+		Format: string(format),
 	}, nil
-}
\ No newline at end of file
+}
+
+// fileError returns a NotFound status for errors of files that were not analyzed or are not stored,
+// other errors are returned as they are
+func fileError(fileID string, err error) error {
+	if errors.Is(err, repository.ErrNotFound) || status.Code(err) == codes.NotFound {
+		return status.Errorf(codes.NotFound, "file %s not found", fileID)
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"local.dev/doc-analyzer/cmd/analyzer/server"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/repository"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/repository/mocks"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/service"
+	pb "local.dev/doc-analyzer/internal/proto/analyzer"
+)
+
+const (
+	fileID        = "0b6f1ad8-34c4-4b8e-9a0e-2f4f0e0d6b1a"
+	similarFileID = "7c2e5f9a-1d3b-4c6e-8f0a-9b8d7c6e5f4a"
+)
+
+func newServer(repo *mocks.MockAnalysisRepository) *server.Server {
+	textAnalyzer := analyzer.NewTextAnalyzer()
+	analysisService := service.NewAnalysisService(
+		repo,
+		nil,
+		nil,
+		textAnalyzer,
+		analyzer.NewPlagiarismChecker(),
+		analyzer.NewLocalWordCloudGenerator(),
+		analyzer.NewSummarizer(textAnalyzer),
+	)
+	return server.NewServer(analysisService, nil)
+}
+
+func TestServer_GetMatchedPassages(t *testing.T) {
+	repo := new(mocks.MockAnalysisRepository)
+	passages := []models.MatchedPassage{
+		{Start: 10, End: 27, SimilarStart: 0, SimilarEnd: 17, Text: "test file content"},
+	}
+	repo.On("GetMatchedPassages", mock.Anything, fileID, similarFileID).Return(passages, nil)
+
+	resp, err := newServer(repo).GetMatchedPassages(context.Background(), &pb.GetMatchedPassagesRequest{
+		FileId:        fileID,
+		SimilarFileId: similarFileID,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Passages, 1)
+	assert.Equal(t, "test file content", resp.Passages[0].Text)
+	repo.AssertExpectations(t)
+}
+
+func TestServer_GetMatchedPassages_NotFound(t *testing.T) {
+	t.Run("file not analyzed", func(t *testing.T) {
+		repo := new(mocks.MockAnalysisRepository)
+		repo.On("GetMatchedPassages", mock.Anything, fileID, similarFileID).Return([]models.MatchedPassage{}, nil)
+		repo.On("GetAnalysisResult", mock.Anything, fileID).Return(
+			nil, fmt.Errorf("analysis result not found for file ID %s: %w", fileID, repository.ErrNotFound),
+		)
+
+		_, err := newServer(repo).GetMatchedPassages(context.Background(), &pb.GetMatchedPassagesRequest{
+			FileId:        fileID,
+			SimilarFileId: similarFileID,
+		})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("files not similar", func(t *testing.T) {
+		repo := new(mocks.MockAnalysisRepository)
+		repo.On("GetMatchedPassages", mock.Anything, fileID, similarFileID).Return([]models.MatchedPassage{}, nil)
+		repo.On("GetAnalysisResult", mock.Anything, fileID).Return(&models.AnalysisResult{FileID: fileID}, nil)
+		repo.On("GetSimilarFiles", mock.Anything, fileID).Return([]models.SimilarFile{}, nil)
+
+		_, err := newServer(repo).GetMatchedPassages(context.Background(), &pb.GetMatchedPassagesRequest{
+			FileId:        fileID,
+			SimilarFileId: similarFileID,
+		})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestServer_GetMatchedPassages_InvalidArgument(t *testing.T) {
+	tests := []struct {
+		name          string
+		fileID        string
+		similarFileID string
+	}{
+		{name: "empty file ID", fileID: "", similarFileID: similarFileID},
+		{name: "malformed file ID", fileID: "../etc/passwd", similarFileID: similarFileID},
+		{name: "malformed similar file ID", fileID: fileID, similarFileID: "not-a-uuid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.MockAnalysisRepository)
+
+			_, err := newServer(repo).GetMatchedPassages(context.Background(), &pb.GetMatchedPassagesRequest{
+				FileId:        tt.fileID,
+				SimilarFileId: tt.similarFileID,
+			})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			repo.AssertNotCalled(t, "GetMatchedPassages", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
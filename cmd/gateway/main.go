@@ -43,17 +43,31 @@ func main() {
 	// Initialize handlers
 	fileHandler := handlers.NewFileHandler(fileStoringClient)
 	analysisHandler := handlers.NewAnalysisHandler(fileAnalysisClient)
+	webhookHandler := handlers.NewWebhookHandler(fileAnalysisClient)
 
 	// Setup API routes
 	v1 := router.Group("/api/v1")
 	{
 		// File routes
 		v1.POST("/files", fileHandler.UploadFile)
+		v1.POST("/files/batch", fileHandler.BatchUploadFile)
 		v1.GET("/files/:file_id", fileHandler.GetFile)
+		v1.GET("/files/:file_id/text", fileHandler.GetFileText)
+		v1.GET("/files/:file_id/keywords", analysisHandler.GetKeywords)
+		v1.GET("/files/:file_id/words", analysisHandler.GetWordFrequencies)
 
 		// Analysis routes
 		v1.POST("/analysis", analysisHandler.AnalyzeFile)
+		v1.GET("/analysis/jobs/:job_id", analysisHandler.GetAnalysisJob)
+		v1.GET("/analysis/:file_id/events", analysisHandler.GetAnalysisEvents)
+		v1.GET("/analysis/:file_id/matches/:similar_file_id", analysisHandler.GetMatchedPassages)
 		v1.GET("/wordcloud/:location", analysisHandler.GetWordCloud)
+
+		// Webhook routes
+		v1.POST("/webhooks", webhookHandler.CreateWebhook)
+		v1.GET("/webhooks", webhookHandler.ListWebhooks)
+		v1.GET("/webhooks/deliveries", webhookHandler.ListWebhookDeliveries)
+		v1.DELETE("/webhooks/:webhook_id", webhookHandler.DeleteWebhook)
 	}
 
 	// Setup Swagger
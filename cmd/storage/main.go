@@ -1,10 +1,15 @@
+// Command storage runs the file storing service
+// Besides the database and storage settings it is configured by MAX_FILE_SIZE, the largest file accepted in bytes
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"net"
 	"os"
+	"strconv"
+	"time"
 
 	_ "github.com/lib/pq"
 	"google.golang.org/grpc"
@@ -12,7 +17,10 @@ import (
 	"local.dev/doc-analyzer/cmd/storage/server"
 	"local.dev/doc-analyzer/internal/pkg/storage/repository/postgres"
 	"local.dev/doc-analyzer/internal/pkg/storage/service"
+	"local.dev/doc-analyzer/internal/pkg/s3client"
+	"local.dev/doc-analyzer/internal/pkg/storage/storage"
 	"local.dev/doc-analyzer/internal/pkg/storage/storage/local"
+	"local.dev/doc-analyzer/internal/pkg/storage/storage/s3"
 	pb "local.dev/doc-analyzer/internal/proto/storage"
 )
 
@@ -46,30 +54,90 @@ func main() {
 			name TEXT NOT NULL,
 			hash TEXT NOT NULL,
 			location TEXT NOT NULL,
+			text_location TEXT NOT NULL DEFAULT '',
+			encoding TEXT NOT NULL DEFAULT '',
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
+		);
+
+		ALTER TABLE files ADD COLUMN IF NOT EXISTS text_location TEXT NOT NULL DEFAULT '';
+		ALTER TABLE files ADD COLUMN IF NOT EXISTS encoding TEXT NOT NULL DEFAULT '';
 	`)
 	if err != nil {
 		log.Fatalf("Failed to create files table: %v", err)
 	}
 
-	// Initialize storage
-	storagePath := os.Getenv("STORAGE_PATH")
-	if storagePath == "" {
-		storagePath = "./storage/files"
-		log.Println("STORAGE_PATH not set, using default:", storagePath)
+	// Initialize storage, the local filesystem or an S3-compatible object storage such as MinIO
+	storageBackend := os.Getenv("STORAGE_BACKEND")
+	if storageBackend == "" {
+		storageBackend = "local"
+		log.Println("STORAGE_BACKEND not set, using default:", storageBackend)
 	}
 
-	storage, err := local.NewLocalStorage(storagePath)
-	if err != nil {
-		log.Fatalf("Failed to initialize storage: %v", err)
+	var fileStorage storage.FileStorage
+	switch storageBackend {
+	case "local":
+		storagePath := os.Getenv("STORAGE_PATH")
+		if storagePath == "" {
+			storagePath = "./storage/files"
+			log.Println("STORAGE_PATH not set, using default:", storagePath)
+		}
+
+		fileStorage, err = local.NewLocalStorage(storagePath)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+	case "s3":
+		s3Config := s3client.Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		}
+		if value := os.Getenv("S3_PART_SIZE"); value != "" {
+			partSize, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || partSize <= 0 {
+				log.Fatalf("Invalid S3_PART_SIZE: %q", value)
+			}
+			s3Config.PartSize = partSize
+		}
+
+		s3Prefix := os.Getenv("S3_PREFIX")
+		if s3Prefix == "" {
+			s3Prefix = "files/"
+			log.Println("S3_PREFIX not set, using default:", s3Prefix)
+		}
+
+		s3Client, err := s3client.NewClient(s3Config)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err = s3Client.CheckBucket(ctx)
+		cancel()
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+
+		fileStorage = s3.NewS3Storage(s3Client, s3Prefix)
+	default:
+		log.Fatalf("Invalid STORAGE_BACKEND: %q", storageBackend)
 	}
 
 	// Initialize repository
 	repo := postgres.NewFileRepo(db)
 
 	// Initialize service
-	fileService := service.NewFileService(repo, storage)
+	fileService := service.NewFileService(repo, fileStorage)
+	if value := os.Getenv("MAX_FILE_SIZE"); value != "" {
+		maxFileSize, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || maxFileSize <= 0 {
+			log.Fatalf("Invalid MAX_FILE_SIZE: %q", value)
+		}
+		fileService.MaxFileSize = maxFileSize
+	}
+	log.Println("Max file size:", fileService.MaxFileSize)
 
 	// Initialize server
 	grpcServer := grpc.NewServer()
@@ -2,12 +2,23 @@ package server
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	pb "local.dev/doc-analyzer/internal/proto/storage"
+	"local.dev/doc-analyzer/internal/pkg/storage/extract"
 	"local.dev/doc-analyzer/internal/pkg/storage/service"
+	"local.dev/doc-analyzer/internal/pkg/storageroot"
 )
 
+// fileChunkSize is the size of the file chunks sent by GetFileStream,
+// well below the default gRPC message size limit of 4 MB
+const fileChunkSize = 64 << 10
+
 // Server implements the FileStoringServiceServer interface
 type Server struct {
 	pb.UnimplementedFileStoringServiceServer
@@ -28,7 +39,7 @@ func (s *Server) UploadFile(ctx context.Context, req *pb.UploadFileRequest) (*pb
 	fileID, err := s.fileService.UploadFile(ctx, req.FileName, req.Content)
 	if err != nil {
 		log.Printf("Failed to upload file: %v", err)
-		return nil, err
+		return nil, uploadError(err)
 	}
 
 	log.Printf("File uploaded successfully with ID: %s", fileID)
@@ -39,12 +50,17 @@ func (s *Server) UploadFile(ctx context.Context, req *pb.UploadFileRequest) (*pb
 
 // GetFile handles file retrieval requests
 func (s *Server) GetFile(ctx context.Context, req *pb.GetFileRequest) (*pb.GetFileResponse, error) {
-	log.Printf("Received get file request for ID: %s", req.FileId)
+	log.Printf("Received get file request for ID: %s (original: %t)", req.FileId, req.Original)
+
+	getFile := s.fileService.GetFile
+	if req.Original {
+		getFile = s.fileService.GetOriginalFile
+	}
 
-	fileName, content, err := s.fileService.GetFile(ctx, req.FileId)
+	fileName, content, err := getFile(ctx, req.FileId)
 	if err != nil {
 		log.Printf("Failed to get file: %v", err)
-		return nil, err
+		return nil, fileError(err)
 	}
 
 	log.Printf("File retrieved successfully: %s", fileName)
@@ -52,4 +68,110 @@ func (s *Server) GetFile(ctx context.Context, req *pb.GetFileRequest) (*pb.GetFi
 		FileName: fileName,
 		Content:  content,
 	}, nil
-}
\ No newline at end of file
+}
+
+// UploadFileStream handles the upload of a file sent in chunks, the first one carries the file name
+func (s *Server) UploadFileStream(stream pb.FileStoringService_UploadFileStreamServer) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "file name is required")
+	}
+	if err != nil {
+		return err
+	}
+	if first.FileName == "" {
+		return status.Error(codes.InvalidArgument, "file name is required")
+	}
+
+	log.Printf("Received streamed upload request for file: %s", first.FileName)
+
+	content := &chunkReader{stream: stream, chunk: first.Content}
+	fileID, err := s.fileService.UploadFileStream(stream.Context(), first.FileName, content)
+	if err != nil {
+		log.Printf("Failed to upload file: %v", err)
+		return uploadError(err)
+	}
+
+	log.Printf("File uploaded successfully with ID: %s", fileID)
+	return stream.SendAndClose(&pb.UploadFileResponse{
+		FileId: fileID,
+	})
+}
+
+// GetFileStream handles file retrieval requests answered in chunks,
+// the first message carries only the file name
+func (s *Server) GetFileStream(req *pb.GetFileRequest, stream pb.FileStoringService_GetFileStreamServer) error {
+	log.Printf("Received streamed get file request for ID: %s (original: %t)", req.FileId, req.Original)
+
+	fileName, content, err := s.fileService.OpenFile(stream.Context(), req.FileId, req.Original)
+	if err != nil {
+		log.Printf("Failed to get file: %v", err)
+		return fileError(err)
+	}
+	defer content.Close()
+
+	if err := stream.Send(&pb.FileChunk{FileName: fileName}); err != nil {
+		return err
+	}
+
+	if _, err := io.CopyBuffer(&chunkWriter{stream: stream}, content, make([]byte, fileChunkSize)); err != nil {
+		log.Printf("Failed to send file: %v", err)
+		return err
+	}
+
+	log.Printf("File retrieved successfully: %s", fileName)
+	return nil
+}
+
+// uploadError maps the errors of a failed upload to gRPC statuses,
+// files without text to extract are invalid arguments, files too large exhaust the resources
+func uploadError(err error) error {
+	if errors.Is(err, extract.ErrUnsupportedFormat) || errors.Is(err, extract.ErrInvalidDocument) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, service.ErrFileTooLarge) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return err
+}
+
+// fileError maps the errors of a failed retrieval to gRPC statuses,
+// files whose content is missing from the storage are not found
+func fileError(err error) error {
+	if errors.Is(err, storageroot.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return err
+}
+
+// chunkReader reads the content of the chunks received from an upload stream
+type chunkReader struct {
+	stream pb.FileStoringService_UploadFileStreamServer
+	chunk  []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		msg, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.chunk = msg.Content
+	}
+
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+// chunkWriter sends the content written to it as chunks of a download stream
+type chunkWriter struct {
+	stream pb.FileStoringService_GetFileStreamServer
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	if err := w.stream.Send(&pb.FileChunk{Content: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
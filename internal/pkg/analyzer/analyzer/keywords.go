@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+	"unicode"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+)
+
+// GetTermCounts counts the terms of the content, sorted by term
+// Terms are the stems of the significant words containing at least one letter,
+// so that inflected forms of a word are counted together
+func (a *TextAnalyzer) GetTermCounts(content string) []models.TermCount {
+	var words []string
+	for _, word := range a.GetSignificantWords(content) {
+		if containsLetter(word) {
+			words = append(words, word)
+		}
+	}
+	representatives := a.representativeForms(words)
+
+	counts := make(map[string]int32, len(representatives))
+	for _, word := range words {
+		counts[a.Stem(word)]++
+	}
+
+	terms := make([]models.TermCount, 0, len(counts))
+	for term, count := range counts {
+		terms = append(terms, models.TermCount{
+			Term:  term,
+			Word:  representatives[term],
+			Count: count,
+		})
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		return terms[i].Term < terms[j].Term
+	})
+	return terms
+}
+
+// containsLetter reports whether a word contains a letter, numbers are not keywords
+func containsLetter(word string) bool {
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// RankKeywords scores the terms of a document by TF-IDF and returns the best limit of them,
+// or all of them if limit is not positive
+// documentFrequencies holds the number of documents containing each term among documentCount
+// documents of the corpus, the document itself included
+//
+// The term frequency is the share of the term among all terms of the document.
+// The inverse document frequency is smoothed, ln((1 + N) / (1 + df)) + 1, so that terms
+// found in every document still count and a corpus of one document ranks terms by frequency.
+// Keywords with equal scores are ordered by count, then alphabetically
+func RankKeywords(terms []models.TermCount, documentFrequencies map[string]int32, documentCount int32, limit int) []models.Keyword {
+	var total int32
+	for _, term := range terms {
+		total += term.Count
+	}
+	if total == 0 {
+		return []models.Keyword{}
+	}
+
+	keywords := make([]models.Keyword, 0, len(terms))
+	for _, term := range terms {
+		documentFrequency := documentFrequencies[term.Term]
+		tf := float64(term.Count) / float64(total)
+		idf := math.Log(float64(1+documentCount)/float64(1+documentFrequency)) + 1
+
+		keywords = append(keywords, models.Keyword{
+			Term:              term.Term,
+			Word:              term.Word,
+			Count:             term.Count,
+			DocumentFrequency: documentFrequency,
+			Score:             tf * idf,
+		})
+	}
+
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Score != keywords[j].Score {
+			return keywords[i].Score > keywords[j].Score
+		}
+		if keywords[i].Count != keywords[j].Count {
+			return keywords[i].Count > keywords[j].Count
+		}
+		return keywords[i].Word < keywords[j].Word
+	})
+
+	if limit > 0 && len(keywords) > limit {
+		keywords = keywords[:limit]
+	}
+	return keywords
+}
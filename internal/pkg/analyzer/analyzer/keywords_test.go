@@ -0,0 +1,52 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+)
+
+func TestTextAnalyzer_GetTermCounts(t *testing.T) {
+	// Create a new text analyzer
+	textAnalyzer := analyzer.NewTextAnalyzer()
+
+	terms := textAnalyzer.GetTermCounts("Интеллект и интеллекта, интеллекта в 2024 году; вагон")
+	assert.Equal(t, []models.TermCount{
+		{Term: "вагон", Word: "вагон", Count: 1},
+		{Term: "год", Word: "году", Count: 1},
+		{Term: "интеллект", Word: "интеллекта", Count: 3},
+	}, terms, "Inflected forms should be counted as one term, numbers and stop words skipped")
+
+	assert.Empty(t, textAnalyzer.GetTermCounts("и в 42"))
+}
+
+func TestRankKeywords(t *testing.T) {
+	terms := []models.TermCount{
+		{Term: "анализ", Word: "анализ", Count: 3},
+		{Term: "плагиат", Word: "плагиата", Count: 2},
+		{Term: "текст", Word: "текста", Count: 2},
+	}
+
+	// "анализ" occurs in every document of the corpus, "плагиат" only in this one
+	documentFrequencies := map[string]int32{"анализ": 10, "плагиат": 1, "текст": 5}
+	keywords := analyzer.RankKeywords(terms, documentFrequencies, 10, 0)
+
+	words := make([]string, len(keywords))
+	for i, keyword := range keywords {
+		words[i] = keyword.Word
+	}
+	assert.Equal(t, []string{"плагиата", "текста", "анализ"}, words, "Rare terms should outrank common ones")
+	assert.InDelta(t, 3.0/7, keywords[2].Score, 1e-9, "Terms found in every document keep a score")
+	assert.Equal(t, int32(1), keywords[0].DocumentFrequency)
+
+	// A single document is ranked by term frequency
+	keywords = analyzer.RankKeywords(terms, map[string]int32{"анализ": 1, "плагиат": 1, "текст": 1}, 1, 2)
+	assert.Len(t, keywords, 2)
+	assert.Equal(t, "анализ", keywords[0].Word)
+	assert.Equal(t, "плагиата", keywords[1].Word, "Ties should be ordered alphabetically")
+
+	assert.Empty(t, analyzer.RankKeywords(nil, nil, 0, 10))
+}
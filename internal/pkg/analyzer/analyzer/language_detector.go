@@ -0,0 +1,183 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// UnknownLanguage is reported when the language of a text cannot be detected
+const UnknownLanguage Language = ""
+
+// LanguageDetector detects the language of a text by comparing its character n-gram profile
+// with the profiles of known languages (Cavnar and Trenkle, "N-Gram-Based Text Categorization")
+//
+// A profile lists the most frequent n-grams of a text by rank. The distance between two profiles
+// is the sum of the rank differences of their n-grams, and the closest language profile wins.
+type LanguageDetector struct {
+	// ProfileSize is the number of most frequent n-grams kept in a profile
+	ProfileSize int
+
+	// MinLetters is the minimum number of letters a text needs for its language to be detected
+	MinLetters int
+
+	profiles map[Language]map[string]int
+}
+
+// NewLanguageDetector creates a new LanguageDetector instance with profiles
+// of the built-in languages
+func NewLanguageDetector() *LanguageDetector {
+	d := &LanguageDetector{
+		ProfileSize: 300,
+		MinLetters:  10,
+		profiles:    make(map[Language]map[string]int),
+	}
+	for language, sample := range languageSamples {
+		d.AddProfile(language, sample)
+	}
+	return d
+}
+
+// AddProfile builds the profile of a language from a sample text, replacing
+// the previous profile of the language
+// Samples of a few thousand characters are enough to tell languages apart
+func (d *LanguageDetector) AddProfile(language Language, sample string) {
+	d.profiles[language] = d.profile(sample)
+}
+
+// Languages returns the languages the detector knows, sorted by code
+func (d *LanguageDetector) Languages() []Language {
+	languages := make([]Language, 0, len(d.profiles))
+	for language := range d.profiles {
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		return languages[i] < languages[j]
+	})
+	return languages
+}
+
+// Detect returns the language of the content, or UnknownLanguage if the content has too few
+// letters or no profiles are known
+func (d *LanguageDetector) Detect(content string) Language {
+	letters := 0
+	for _, r := range content {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	if letters < d.MinLetters {
+		return UnknownLanguage
+	}
+
+	profile := d.profile(content)
+
+	best, bestDistance := UnknownLanguage, -1
+	for _, language := range d.Languages() {
+		distance := d.distance(profile, d.profiles[language])
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = language, distance
+		}
+	}
+	return best
+}
+
+// distance computes the out-of-place measure between a text profile and a language profile
+// N-grams missing from the language profile get the maximum penalty
+func (d *LanguageDetector) distance(profile, languageProfile map[string]int) int {
+	distance := 0
+	for ngram, rank := range profile {
+		languageRank, ok := languageProfile[ngram]
+		if !ok {
+			distance += d.ProfileSize
+			continue
+		}
+		if rank > languageRank {
+			distance += rank - languageRank
+		} else {
+			distance += languageRank - rank
+		}
+	}
+	return distance
+}
+
+// profile ranks the ProfileSize most frequent character n-grams (1 to 3 characters) of the text
+// Words are lowercased and padded with spaces, so that n-grams also capture word boundaries
+func (d *LanguageDetector) profile(text string) map[string]int {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		runes := []rune(" " + word + " ")
+		for n := 1; n <= 3; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				ngram := string(runes[i : i+n])
+				if ngram != " " {
+					counts[ngram]++
+				}
+			}
+		}
+	}
+
+	ngrams := make([]string, 0, len(counts))
+	for ngram := range counts {
+		ngrams = append(ngrams, ngram)
+	}
+	sort.Slice(ngrams, func(i, j int) bool {
+		if counts[ngrams[i]] != counts[ngrams[j]] {
+			return counts[ngrams[i]] > counts[ngrams[j]]
+		}
+		return ngrams[i] < ngrams[j]
+	})
+	if len(ngrams) > d.ProfileSize {
+		ngrams = ngrams[:d.ProfileSize]
+	}
+
+	profile := make(map[string]int, len(ngrams))
+	for rank, ngram := range ngrams {
+		profile[ngram] = rank
+	}
+	return profile
+}
+
+// languageSamples holds the sample texts the built-in language profiles are built from
+var languageSamples = map[Language]string{
+	English: `The report describes the results of the study and the methods that were used to collect
+and analyze the data. Students are expected to write their own text, to cite the sources they used and
+to explain how their conclusions follow from the evidence. A good report has a clear structure: an
+introduction that states the problem, a main part where the arguments are developed, and a conclusion
+that summarizes the findings and suggests directions for further work.
+Artificial intelligence is a field of computer science that focuses on building systems which can perform
+tasks that usually require human intelligence. These systems include machine learning, natural language
+processing and computer vision. The development of such technologies has significant consequences for
+society, for the economy and for science as a whole. Many companies invest in research because they
+believe that new tools will help people work faster and make better decisions.
+The history of the city goes back several hundred years. It was founded on the bank of a wide river, and
+for a long time trade was the main source of its wealth. Today the old town attracts visitors from all over
+the world, who come to see its churches, bridges and narrow streets, and to learn about the people who lived
+there before them. When the weather is good, the parks are full of families with children.
+Climate change is one of the most important problems of our time. Rising temperatures affect agriculture,
+water supply and the health of millions of people. Governments and international organizations are looking
+for ways to reduce emissions while keeping economic growth, and every one of us can help by saving energy
+and thinking about the consequences of our everyday choices.`,
+
+	Russian: `В докладе описываются результаты исследования и методы, которые использовались для сбора
+и анализа данных. Студенты должны писать собственный текст, указывать использованные источники и объяснять,
+как их выводы следуют из имеющихся фактов. Хороший доклад имеет ясную структуру: введение, в котором
+формулируется проблема, основную часть, где развиваются аргументы, и заключение, подводящее итоги работы
+и предлагающее направления для дальнейших исследований.
+Искусственный интеллект представляет собой область компьютерных наук, которая фокусируется на создании
+систем, способных выполнять задачи, требующие человеческого интеллекта. Эти системы включают в себя машинное
+обучение, обработку естественного языка и компьютерное зрение. Развитие таких технологий имеет значительные
+последствия для общества, экономики и науки в целом. Многие компании вкладывают средства в исследования,
+потому что считают, что новые инструменты помогут людям работать быстрее и принимать лучшие решения.
+История города насчитывает несколько сотен лет. Он был основан на берегу широкой реки, и долгое время
+торговля была главным источником его богатства. Сегодня старый город привлекает путешественников со всего
+мира, которые приезжают посмотреть на его церкви, мосты и узкие улицы и узнать о людях, живших здесь
+раньше. В хорошую погоду парки заполнены семьями с детьми.
+Изменение климата является одной из важнейших проблем нашего времени. Рост температуры влияет на сельское
+хозяйство, водоснабжение и здоровье миллионов людей. Правительства и международные организации ищут способы
+сократить выбросы, сохраняя при этом экономический рост, и каждый из нас может помочь, экономя энергию
+и задумываясь о последствиях своего повседневного выбора.`,
+}
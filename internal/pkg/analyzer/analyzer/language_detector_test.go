@@ -0,0 +1,106 @@
+package analyzer_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+)
+
+func TestLanguageDetector_Detect(t *testing.T) {
+	// Create a new language detector
+	detector := analyzer.NewLanguageDetector()
+
+	// Test cases
+	testCases := []struct {
+		name     string
+		content  string
+		expected analyzer.Language
+	}{
+		{
+			name:     "English text",
+			content:  "Machine learning allows computers to learn from examples instead of following explicit rules.",
+			expected: analyzer.English,
+		},
+		{
+			name:     "Russian text",
+			content:  "Машинное обучение позволяет компьютерам учиться на примерах, а не следовать явным правилам.",
+			expected: analyzer.Russian,
+		},
+		{
+			name:     "Russian text with English terms",
+			content:  "Для обучения модели мы использовали библиотеку PyTorch и набор данных ImageNet, а результаты сравнили с работами коллег.",
+			expected: analyzer.Russian,
+		},
+		{
+			name:     "Too short",
+			content:  "Да",
+			expected: analyzer.UnknownLanguage,
+		},
+		{
+			name:     "No letters",
+			content:  "12345 67890 — 2024.",
+			expected: analyzer.UnknownLanguage,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, detector.Detect(tc.content))
+		})
+	}
+}
+
+func TestLanguageDetector_Detect_Corpus(t *testing.T) {
+	// Every sample of the corpus is in Russian
+	detector := analyzer.NewLanguageDetector()
+
+	paths, err := filepath.Glob("../../../../texts/*.txt")
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, analyzer.Russian, detector.Detect(string(content)), path)
+	}
+}
+
+func TestLanguageDetector_AddProfile(t *testing.T) {
+	// Create a new language detector
+	detector := analyzer.NewLanguageDetector()
+
+	const german analyzer.Language = "de"
+	detector.AddProfile(german, "Der schnelle braune Fuchs springt über den faulen Hund. "+
+		"Die Studenten schreiben ihre Berichte und zitieren die Quellen, die sie benutzt haben. "+
+		"Künstliche Intelligenz ist ein Teilgebiet der Informatik, das sich mit der Automatisierung "+
+		"intelligenten Verhaltens und dem maschinellen Lernen befasst.")
+
+	assert.Equal(t, []analyzer.Language{german, analyzer.English, analyzer.Russian}, detector.Languages())
+	assert.Equal(t, german, detector.Detect("Die Studenten haben ihre Berichte über künstliche Intelligenz geschrieben."))
+	assert.Equal(t, analyzer.English, detector.Detect("The students have written their reports about artificial intelligence."))
+}
+
+func TestTextAnalyzer_ForLanguage(t *testing.T) {
+	// Create a new text analyzer
+	textAnalyzer := analyzer.NewTextAnalyzer()
+
+	content := "The system and the systems. Система и системы."
+	language := textAnalyzer.DetectLanguage("Системы машинного обучения используются в медицине и образовании.")
+	assert.Equal(t, analyzer.Russian, language)
+
+	// Only Russian stop words are removed and Russian words are stemmed
+	russian := textAnalyzer.ForLanguage(language)
+	assert.Equal(t, []string{"the", "system", "and", "the", "systems", "систем", "систем"}, russian.GetStemmedWords(content))
+
+	// English text is not stemmed
+	english := textAnalyzer.ForLanguage(analyzer.English)
+	assert.Equal(t, []string{"system", "systems", "система", "и", "системы"}, english.GetStemmedWords(content))
+
+	// Unknown language keeps the analyzer as is
+	assert.Same(t, textAnalyzer, textAnalyzer.ForLanguage(analyzer.UnknownLanguage))
+}
@@ -0,0 +1,135 @@
+package analyzer
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+)
+
+// MinHasher computes MinHash signatures of n-gram sets and splits them into
+// LSH bands, so that documents with similar n-gram sets share at least one
+// band bucket with high probability. Only documents sharing a bucket need to
+// be compared in full.
+//
+// The probability that two documents with Jaccard similarity s become
+// candidates is 1 - (1 - s^r)^b, where b is the number of bands and
+// r = NumHashes / Bands is the number of rows per band.
+type MinHasher struct {
+	// Number of hash functions, i.e. the length of a signature
+	// Default is 128
+	NumHashes int
+
+	// Number of LSH bands, must divide NumHashes
+	// Default is 64 (2 rows per band), which makes documents with 30%
+	// similarity candidates with ~99.8% probability
+	Bands int
+
+	// Seeds of the hash functions, derived deterministically so that
+	// signatures stored in the database stay comparable between restarts
+	seeds []uint64
+}
+
+// NewMinHasher creates a new MinHasher instance
+func NewMinHasher() *MinHasher {
+	numHashes := 128
+	return &MinHasher{
+		NumHashes: numHashes,
+		Bands:     64,
+		seeds:     generateSeeds(numHashes),
+	}
+}
+
+// Signature computes the MinHash signature of a set of n-grams
+// An empty set yields a signature where every value is math.MaxUint64
+func (m *MinHasher) Signature(ngrams map[string]int) []uint64 {
+	if len(m.seeds) != m.NumHashes {
+		m.seeds = generateSeeds(m.NumHashes)
+	}
+
+	signature := make([]uint64, m.NumHashes)
+	for i := range signature {
+		signature[i] = math.MaxUint64
+	}
+
+	for ngram := range ngrams {
+		base := hashString(ngram)
+		for i, seed := range m.seeds {
+			if h := mix64(base ^ seed); h < signature[i] {
+				signature[i] = h
+			}
+		}
+	}
+
+	return signature
+}
+
+// BandKeys splits a signature into bands and hashes each band into a bucket key
+// The key at index i belongs to band i
+func (m *MinHasher) BandKeys(signature []uint64) []int64 {
+	if m.Bands <= 0 || len(signature) == 0 {
+		return []int64{}
+	}
+
+	rows := len(signature) / m.Bands
+	if rows == 0 {
+		rows = 1
+	}
+
+	keys := make([]int64, 0, m.Bands)
+	buf := make([]byte, 8)
+	for start := 0; start+rows <= len(signature) && len(keys) < m.Bands; start += rows {
+		h := fnv.New64a()
+		for _, value := range signature[start : start+rows] {
+			binary.LittleEndian.PutUint64(buf, value)
+			h.Write(buf)
+		}
+		keys = append(keys, int64(h.Sum64()))
+	}
+
+	return keys
+}
+
+// EstimateSimilarity estimates the Jaccard similarity of two sets from their signatures
+func (m *MinHasher) EstimateSimilarity(signature1, signature2 []uint64) float64 {
+	if len(signature1) == 0 || len(signature1) != len(signature2) {
+		return 0
+	}
+
+	matches := 0
+	for i := range signature1 {
+		if signature1[i] == signature2[i] {
+			matches++
+		}
+	}
+
+	return float64(matches) / float64(len(signature1))
+}
+
+// generateSeeds derives n pseudo-random seeds from a fixed starting value
+func generateSeeds(n int) []uint64 {
+	seeds := make([]uint64, n)
+	state := uint64(0x5eed5eed5eed5eed)
+	for i := range seeds {
+		state += 0x9e3779b97f4a7c15
+		seeds[i] = mix64(state)
+	}
+	return seeds
+}
+
+// hashString computes the 64-bit FNV-1a hash of a string
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// mix64 is the splitmix64 finalizer, used to turn one base hash into many
+// independent hash functions
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
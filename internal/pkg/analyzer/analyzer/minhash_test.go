@@ -0,0 +1,80 @@
+package analyzer_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+)
+
+func TestMinHasher_Signature(t *testing.T) {
+	minHasher := analyzer.NewMinHasher()
+
+	ngrams := map[string]int{"first second third": 1, "second third fourth": 1}
+
+	// Signatures are deterministic, including between instances
+	signature := minHasher.Signature(ngrams)
+	assert.Len(t, signature, minHasher.NumHashes, "Signature length should match the number of hashes")
+	assert.Equal(t, signature, analyzer.NewMinHasher().Signature(ngrams), "Signatures should be deterministic")
+
+	// Empty set yields the maximum value in every position
+	empty := minHasher.Signature(map[string]int{})
+	for _, value := range empty {
+		assert.Equal(t, uint64(math.MaxUint64), value, "Empty set signature should be saturated")
+	}
+}
+
+func TestMinHasher_EstimateSimilarity(t *testing.T) {
+	minHasher := analyzer.NewMinHasher()
+
+	// Two sets of 100 elements sharing 60 of them: Jaccard = 60/140 ≈ 0.43
+	set1 := make(map[string]int)
+	set2 := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		set1[fmt.Sprintf("ngram %d", i)] = 1
+		set2[fmt.Sprintf("ngram %d", i+40)] = 1
+	}
+
+	estimate := minHasher.EstimateSimilarity(minHasher.Signature(set1), minHasher.Signature(set2))
+	assert.InDelta(t, 60.0/140.0, estimate, 0.15, "Estimate should be close to the real Jaccard similarity")
+
+	assert.Equal(t, 1.0, minHasher.EstimateSimilarity(minHasher.Signature(set1), minHasher.Signature(set1)))
+	assert.Equal(t, 0.0, minHasher.EstimateSimilarity(nil, nil), "Empty signatures should not be similar")
+}
+
+func TestMinHasher_BandKeys(t *testing.T) {
+	minHasher := analyzer.NewMinHasher()
+
+	set1 := make(map[string]int)
+	set2 := make(map[string]int)
+	set3 := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		set1[fmt.Sprintf("ngram %d", i)] = 1
+		set2[fmt.Sprintf("ngram %d", i+30)] = 1
+		set3[fmt.Sprintf("other %d", i)] = 1
+	}
+
+	keys1 := minHasher.BandKeys(minHasher.Signature(set1))
+	keys2 := minHasher.BandKeys(minHasher.Signature(set2))
+	keys3 := minHasher.BandKeys(minHasher.Signature(set3))
+	assert.Len(t, keys1, minHasher.Bands, "There should be one key per band")
+
+	// Similar sets share at least one bucket in the same band
+	assert.True(t, shareBucket(keys1, keys2), "Similar sets should share a bucket")
+
+	// Unrelated sets share no bucket
+	assert.False(t, shareBucket(keys1, keys3), "Unrelated sets should not share a bucket")
+}
+
+// shareBucket reports whether two band key lists have the same key in some band
+func shareBucket(keys1, keys2 []int64) bool {
+	for i := range keys1 {
+		if i < len(keys2) && keys1[i] == keys2[i] {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"github.com/stretchr/testify/mock"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
 )
 
 // MockPlagiarismChecker is a mock implementation of the PlagiarismChecker
@@ -18,6 +19,24 @@ func (m *MockPlagiarismChecker) CheckPlagiarism(ctx context.Context, content str
 	return args.Bool(0), args.Get(1).([]string)
 }
 
+// FindSimilarFiles mocks the FindSimilarFiles method
+func (m *MockPlagiarismChecker) FindSimilarFiles(ctx context.Context, content string, otherContents map[string]string) []models.SimilarFile {
+	args := m.Called(ctx, content, otherContents)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]models.SimilarFile)
+}
+
+// FindPassages mocks the FindPassages method
+func (m *MockPlagiarismChecker) FindPassages(content, otherContent string) []models.MatchedPassage {
+	args := m.Called(content, otherContent)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]models.MatchedPassage)
+}
+
 // preprocessText mocks the preprocessText method
 func (m *MockPlagiarismChecker) preprocessText(text string) string {
 	args := m.Called(text)
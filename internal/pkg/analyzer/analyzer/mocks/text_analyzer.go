@@ -2,6 +2,8 @@ package mocks
 
 import (
 	"github.com/stretchr/testify/mock"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
 )
 
 // MockTextAnalyzer is a mock implementation of the TextAnalyzer
@@ -16,6 +18,12 @@ func (m *MockTextAnalyzer) AnalyzeText(content string) (paragraphCount, wordCoun
 	return args.Get(0).(int32), args.Get(1).(int32), args.Get(2).(int32)
 }
 
+// GetTextMetrics mocks the GetTextMetrics method
+func (m *MockTextAnalyzer) GetTextMetrics(content string) analyzer.TextMetrics {
+	args := m.Called(content)
+	return args.Get(0).(analyzer.TextMetrics)
+}
+
 // GetWords mocks the GetWords method
 func (m *MockTextAnalyzer) GetWords(content string) []string {
 	args := m.Called(content)
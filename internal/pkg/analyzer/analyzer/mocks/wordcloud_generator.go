@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"github.com/stretchr/testify/mock"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
 )
 
 // MockWordCloudGenerator is a mock implementation of the WordCloudGenerator
@@ -11,9 +12,12 @@ type MockWordCloudGenerator struct {
 	apiURL string
 }
 
+// Ensure MockWordCloudGenerator implements WordCloudGenerator
+var _ analyzer.WordCloudGenerator = (*MockWordCloudGenerator)(nil)
+
 // GenerateWordCloud mocks the GenerateWordCloud method
-func (m *MockWordCloudGenerator) GenerateWordCloud(ctx context.Context, text string) ([]byte, string, error) {
-	args := m.Called(ctx, text)
+func (m *MockWordCloudGenerator) GenerateWordCloud(ctx context.Context, words []analyzer.WordItem, options analyzer.WordCloudOptions) ([]byte, string, error) {
+	args := m.Called(ctx, words, options)
 	if args.Get(0) == nil {
 		return nil, args.String(1), args.Error(2)
 	}
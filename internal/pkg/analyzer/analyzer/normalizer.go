@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ObfuscationReport counts the characters of a text that are typically used to hide copied text
+// from plagiarism checkers
+type ObfuscationReport struct {
+	// Homoglyphs is the number of letters replaced by look-alikes of another script
+	// inside a word, such as a Latin "o" in a Russian word
+	Homoglyphs int
+
+	// InvisibleCharacters is the number of zero-width, bidirectional and invisible operator characters inside words
+	// Soft hyphens and byte order marks are left by text editors and not counted
+	InvisibleCharacters int
+}
+
+// Detected reports whether any obfuscation characters were found
+func (r ObfuscationReport) Detected() bool {
+	return r.Homoglyphs > 0 || r.InvisibleCharacters > 0
+}
+
+// TextNormalizer normalizes words so that obfuscated copies compare equal to the original:
+// 1. Zero-width, soft hyphen and other invisible format characters are removed
+// 2. Compatibility characters are replaced by Unicode NFKC (fullwidth letters, ligatures)
+// 3. Homoglyphs of a word's main script are replaced by the letters they imitate
+// 4. "ё" is replaced by "е"
+type TextNormalizer struct {
+	// Look-alike letters of one script mapped to the letters of the other
+	toCyrillic map[rune]rune
+	toLatin    map[rune]rune
+}
+
+// latinCyrillicConfusables lists Latin letters and the Cyrillic letters that look the same
+var latinCyrillicConfusables = map[rune]rune{
+	'a': 'а', 'c': 'с', 'e': 'е', 'o': 'о', 'p': 'р', 'x': 'х', 'y': 'у', 'k': 'к',
+	'i': 'і', 'j': 'ј', 's': 'ѕ', 'h': 'һ',
+	'A': 'А', 'B': 'В', 'C': 'С', 'E': 'Е', 'H': 'Н', 'K': 'К', 'M': 'М', 'O': 'О',
+	'P': 'Р', 'T': 'Т', 'X': 'Х', 'Y': 'У', 'I': 'І', 'J': 'Ј', 'S': 'Ѕ',
+}
+
+// obfuscationCharacters lists the invisible format characters counted as obfuscation: zero-width spaces and joiners,
+// directional marks and embeddings, the word joiner and invisible operators
+// Other format characters such as soft hyphens and byte order marks are removed without being counted
+var obfuscationCharacters = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x200b, Hi: 0x200f, Stride: 1},
+		{Lo: 0x202a, Hi: 0x202e, Stride: 1},
+		{Lo: 0x2060, Hi: 0x2064, Stride: 1},
+	},
+}
+
+// NewTextNormalizer creates a new TextNormalizer instance
+func NewTextNormalizer() *TextNormalizer {
+	n := &TextNormalizer{
+		toCyrillic: make(map[rune]rune, len(latinCyrillicConfusables)),
+		toLatin:    make(map[rune]rune, len(latinCyrillicConfusables)),
+	}
+	for latin, cyrillic := range latinCyrillicConfusables {
+		n.toCyrillic[latin] = cyrillic
+		n.toLatin[cyrillic] = latin
+	}
+	return n
+}
+
+// NormalizeWord returns the normalized form of a word
+func (n *TextNormalizer) NormalizeWord(word string) string {
+	normalized, _ := n.normalizeWord(word)
+	return normalized
+}
+
+// Inspect counts the obfuscation characters of the content
+func (n *TextNormalizer) Inspect(content string) ObfuscationReport {
+	var report ObfuscationReport
+	words := strings.FieldsFunc(content, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+	for _, word := range words {
+		_, wordReport := n.normalizeWord(word)
+		report.Homoglyphs += wordReport.Homoglyphs
+		report.InvisibleCharacters += wordReport.InvisibleCharacters
+	}
+	return report
+}
+
+// normalizeWord normalizes a word and counts the obfuscation characters found in it
+func (n *TextNormalizer) normalizeWord(word string) (string, ObfuscationReport) {
+	var report ObfuscationReport
+
+	var sb strings.Builder
+	for _, r := range word {
+		if unicode.Is(unicode.Cf, r) {
+			if unicode.Is(obfuscationCharacters, r) {
+				report.InvisibleCharacters++
+			}
+			continue
+		}
+		sb.WriteRune(r)
+	}
+
+	normalized := norm.NFKC.String(sb.String())
+	normalized, report.Homoglyphs = n.replaceHomoglyphs(normalized)
+	normalized = strings.NewReplacer("ё", "е", "Ё", "Е").Replace(normalized)
+
+	return normalized, report
+}
+
+// replaceHomoglyphs replaces look-alike letters in a word mixing Cyrillic and Latin letters
+// by the letters of the word's main script: the script of its letters that have no look-alike
+// in the other script, or the script most of the word is written in, Cyrillic on ties
+// Words written in a single script are returned unchanged
+func (n *TextNormalizer) replaceHomoglyphs(word string) (string, int) {
+	cyrillic, latin := 0, 0
+	distinctCyrillic, distinctLatin := 0, 0
+	for _, r := range word {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+			if _, ok := n.toLatin[r]; !ok {
+				distinctCyrillic++
+			}
+		case unicode.Is(unicode.Latin, r):
+			latin++
+			if _, ok := n.toCyrillic[r]; !ok {
+				distinctLatin++
+			}
+		}
+	}
+	if cyrillic == 0 || latin == 0 {
+		return word, 0
+	}
+
+	toLatin := latin > cyrillic
+	if (distinctCyrillic > 0) != (distinctLatin > 0) {
+		toLatin = distinctLatin > 0
+	}
+	confusables := n.toCyrillic
+	if toLatin {
+		confusables = n.toLatin
+	}
+
+	replaced := 0
+	runes := []rune(word)
+	for i, r := range runes {
+		if target, ok := confusables[r]; ok {
+			runes[i] = target
+			replaced++
+		}
+	}
+	return string(runes), replaced
+}
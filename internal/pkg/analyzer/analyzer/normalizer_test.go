@@ -0,0 +1,96 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+)
+
+func TestTextNormalizer_NormalizeWord(t *testing.T) {
+	// Create a new text normalizer
+	normalizer := analyzer.NewTextNormalizer()
+
+	// Test cases
+	testCases := []struct {
+		name     string
+		word     string
+		expected string
+	}{
+		{
+			name:     "Plain Russian word",
+			word:     "интеллект",
+			expected: "интеллект",
+		},
+		{
+			name:     "Plain English word",
+			word:     "copy",
+			expected: "copy",
+		},
+		{
+			name:     "Latin look-alikes in a Russian word",
+			word:     "искусствeнный", // Latin "e"
+			expected: "искусственный",
+		},
+		{
+			name:     "Several Latin look-alikes",
+			word:     "oбpaзoвaниe", // Latin "o", "p", "a", "e"
+			expected: "образование",
+		},
+		{
+			name:     "Cyrillic look-alike in an English word",
+			word:     "repоrt", // Cyrillic "о"
+			expected: "report",
+		},
+		{
+			name:     "Zero-width characters",
+			word:     "ин\u200bтел\u200dлект\u2060",
+			expected: "интеллект",
+		},
+		{
+			name:     "Soft hyphen",
+			word:     "интел\u00adлект",
+			expected: "интеллект",
+		},
+		{
+			name:     "Fullwidth letters",
+			word:     "ｔｅｘｔ",
+			expected: "text",
+		},
+		{
+			name:     "Yo",
+			word:     "ещё",
+			expected: "еще",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, normalizer.NormalizeWord(tc.word))
+		})
+	}
+}
+
+func TestTextNormalizer_Inspect(t *testing.T) {
+	// Create a new text normalizer
+	normalizer := analyzer.NewTextNormalizer()
+
+	// Clean text
+	report := normalizer.Inspect("\ufeffИскусственный интеллект — это область науки. Machine learning, too.")
+	assert.False(t, report.Detected(), "A leading byte order mark is not obfuscation")
+
+	// Obfuscated text
+	report = normalizer.Inspect("Иcкуccтвенный ин\u200bтел\u200bлект — oбласть науки.")
+	assert.True(t, report.Detected())
+	assert.Equal(t, 4, report.Homoglyphs)
+	assert.Equal(t, 2, report.InvisibleCharacters)
+
+	// Soft hyphens and byte order marks inside the text are removed but not counted
+	report = normalizer.Inspect("Искус\u00adственный интел\u00adлект.\r\n\ufeffМашинное обучение.")
+	assert.False(t, report.Detected())
+
+	// Directional marks and invisible operators are counted
+	report = normalizer.Inspect("интел\u202eлект об\u2062ласть на\u200eуки")
+	assert.Equal(t, 3, report.InvisibleCharacters)
+}
@@ -4,15 +4,72 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
 	"strings"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
 )
 
+// DetectionMode selects which similarity measures flag a file as plagiarism
+type DetectionMode string
+
+const (
+	// JaccardMode flags files whose n-gram sets are similar as a whole,
+	// or where one n-gram set is mostly contained in the other
+	JaccardMode DetectionMode = "jaccard"
+
+	// WinnowingMode flags files sharing copied passages, regardless of document length
+	WinnowingMode DetectionMode = "winnowing"
+
+	// CombinedMode flags files detected by either of the measures
+	CombinedMode DetectionMode = "combined"
+)
+
+// Metrics that flag a pair of files as plagiarism
+const (
+	// ExactMetric flags files whose significant words are identical
+	ExactMetric = "exact"
+
+	// JaccardMetric flags files whose n-gram Jaccard similarity reaches SimilarityThreshold
+	JaccardMetric = "jaccard"
+
+	// ContainmentMetric flags files where the n-grams of either file are contained
+	// in the other one at least to ContainmentThreshold
+	ContainmentMetric = "containment"
+
+	// WinnowingMetric flags files sharing enough passages matched by winnowing fingerprints
+	WinnowingMetric = "winnowing"
+)
+
+// AlgorithmVersion identifies the text preprocessing and scoring used to compare files
+// It is stored with every similar pair and must be incremented whenever a change
+// makes new scores incomparable with the stored ones
+const AlgorithmVersion = 3
+
+// ParseDetectionMode converts a configuration value to a DetectionMode
+// An empty value selects CombinedMode
+func ParseDetectionMode(value string) (DetectionMode, error) {
+	switch mode := DetectionMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return CombinedMode, nil
+	case JaccardMode, WinnowingMode, CombinedMode:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown plagiarism detection mode %q", value)
+	}
+}
+
 // PlagiarismChecker provides methods for checking plagiarism between text documents
 // It uses a combination of techniques including:
-// 1. Exact matching via hash comparison for efficiency
-// 2. N-gram analysis to detect similar text patterns
-// 3. Jaccard similarity coefficient to measure text similarity
-// 4. Text preprocessing to normalize content before comparison
+//  1. Exact matching via hash comparison for efficiency
+//  2. N-gram analysis to detect similar text patterns
+//  3. Jaccard similarity coefficient to measure text similarity
+//     and containment in both directions to catch copies padded with filler
+//  4. Winnowing fingerprints to detect copied passages in otherwise original text
+//  5. Text preprocessing to normalize content before comparison,
+//     undoing homoglyph and invisible-character obfuscation
 type PlagiarismChecker struct {
 	// Threshold for similarity (0.0 to 1.0)
 	// Values closer to 1.0 require higher similarity to be considered plagiarism
@@ -25,6 +82,36 @@ type PlagiarismChecker struct {
 	// Default is 3
 	NGramSize int
 
+	// Threshold for containment (0.0 to 1.0) in either direction, i.e. the share
+	// of one file's n-grams found in the other file
+	// Unlike Jaccard similarity, containment does not drop when a copied text is
+	// padded with original filler
+	// Also selects which files sharing fingerprints are compared, see IsFingerprintCandidate
+	// Values above 1.0 disable containment
+	// Default is 0.8
+	ContainmentThreshold float64
+
+	// Measures used to flag a file as plagiarism
+	// Default is CombinedMode
+	Mode DetectionMode
+
+	// Winnower used to select fingerprints of copied passages
+	Winnower *Winnower
+
+	// Minimal number of significant words covered by matched fingerprints
+	// for a file to be flagged in winnowing mode, regardless of document length
+	// Default is 20 (roughly one paragraph without stop words)
+	MinMatchedWords int
+
+	// Share of the document covered by matched fingerprints (0.0 to 1.0)
+	// for a file to be flagged in winnowing mode, used for short documents
+	// Default is 0.5
+	CoverageThreshold float64
+
+	// MinHasher used to build signatures for the LSH candidate index
+	// Signatures are computed over the same n-grams as the similarity check
+	MinHasher *MinHasher
+
 	// TextAnalyzer instance for word extraction and text processing
 	textAnalyzer *TextAnalyzer
 }
@@ -41,20 +128,34 @@ func NewPlagiarismChecker() *PlagiarismChecker {
 		// Default n-gram size: 3
 		// - Larger values (4-5) are more specific and reduce false positives
 		// - Smaller values (2-3) catch more potential matches but may increase false positives
-		NGramSize:           3,
+		NGramSize: 3,
+
+		// Default containment threshold: 80%
+		// - A file is flagged if 80% of its n-grams are found in the other file,
+		//   or 80% of the other file's n-grams are found in it
+		ContainmentThreshold: 0.8,
+
+		// Default mode: combined
+		// - A file is flagged if its overall similarity is above the threshold
+		//   or if it shares copied passages with the checked content
+		Mode: CombinedMode,
+
+		// Default winnowing: 5-word k-grams, windows of 4 k-grams
+		// - Passages of 8 or more significant words are guaranteed to be matched
+		// - 20 matched words or half of the document flag a file
+		Winnower:          NewWinnower(),
+		MinMatchedWords:   20,
+		CoverageThreshold: 0.5,
+
+		// Default MinHash: 128 hashes in 64 bands
+		// - See MinHasher for the candidate probability curve
+		MinHasher: NewMinHasher(),
 
-		textAnalyzer:        NewTextAnalyzer(),
+		textAnalyzer: NewTextAnalyzer(),
 	}
 }
 
 // CheckPlagiarism checks if the content is plagiarized from any of the provided contents
-// The detection process follows these steps:
-// 1. Preprocess the text (remove stop words, normalize whitespace, etc.)
-// 2. Generate n-grams from the processed text
-// 3. For each comparison text:
-//    a. First check for exact matches using hash comparison (fast path)
-//    b. If not an exact match, calculate Jaccard similarity between n-gram sets
-//    c. If similarity is above the threshold, consider it plagiarism
 //
 // Parameters:
 //   - ctx: Context for the operation
@@ -62,69 +163,284 @@ func NewPlagiarismChecker() *PlagiarismChecker {
 //   - otherContents: Map of file IDs to their text contents for comparison
 //
 // Returns:
-//   - bool: True if plagiarism is detected (similarity above threshold)
+//   - bool: True if plagiarism is detected
 //   - []string: List of file IDs that are similar to the provided content
 func (c *PlagiarismChecker) CheckPlagiarism(ctx context.Context, content string, otherContents map[string]string) (bool, []string) {
 	var similarFileIDs []string
+	for _, similarFile := range c.FindSimilarFiles(ctx, content, otherContents) {
+		similarFileIDs = append(similarFileIDs, similarFile.FileID)
+	}
+
+	return len(similarFileIDs) > 0, similarFileIDs
+}
+
+// FindSimilarFiles compares the content with each of the provided contents and
+// returns the files considered plagiarism, ordered by file ID, together with the
+// passages copied from each of them
+// The detection process follows these steps:
+//  1. Preprocess the text (remove stop words, normalize whitespace, etc.)
+//  2. Generate n-grams and winnowing fingerprints from the processed text
+//  3. For each comparison text:
+//     a. First check for exact matches using hash comparison (fast path)
+//     b. If not an exact match, calculate Jaccard similarity and containment in
+//     both directions between n-gram sets, and the share of the content covered by fingerprints found in the other text
+//     c. Depending on Mode, if similarity is above the threshold or enough of the
+//     content is covered by matched fingerprints, consider it plagiarism
+//     d. Align the matched words of both texts and map them back to character
+//     offsets of the original texts
+func (c *PlagiarismChecker) FindSimilarFiles(ctx context.Context, content string, otherContents map[string]string) []models.SimilarFile {
+	return c.FindSimilarFilesWithProgress(ctx, content, otherContents, nil)
+}
+
+// FindSimilarFilesWithProgress works like FindSimilarFiles and calls progress, if not nil,
+// after each comparison with the number of compared and of all contents
+func (c *PlagiarismChecker) FindSimilarFilesWithProgress(ctx context.Context, content string, otherContents map[string]string, progress func(compared, total int)) []models.SimilarFile {
+	similarFiles := []models.SimilarFile{}
 
 	// Preprocess the current content
 	processedContent := c.preprocessText(content)
 
-	// Generate n-grams for the current content
+	// Generate n-grams and words for the current content
 	currentNGrams := c.generateNGrams(processedContent, c.NGramSize)
+	currentWords := c.textAnalyzer.GetWords(processedContent)
 
 	// Compare with other contents
+	compared := 0
 	for fileID, otherContent := range otherContents {
-		// Preprocess the other content
-		processedOtherContent := c.preprocessText(otherContent)
+		if similarFile, ok := c.compare(content, processedContent, currentNGrams, currentWords, fileID, otherContent); ok {
+			similarFiles = append(similarFiles, similarFile)
+		}
 
-		// First, do a quick hash check for exact matches
-		if c.calculateHash(processedContent) == c.calculateHash(processedOtherContent) {
-			similarFileIDs = append(similarFileIDs, fileID)
-			continue
+		compared++
+		if progress != nil {
+			progress(compared, len(otherContents))
 		}
+	}
+
+	sort.Slice(similarFiles, func(i, j int) bool {
+		return similarFiles[i].FileID < similarFiles[j].FileID
+	})
 
-		// If not an exact match, calculate Jaccard similarity
-		otherNGrams := c.generateNGrams(processedOtherContent, c.NGramSize)
-		similarity := c.calculateJaccardSimilarity(currentNGrams, otherNGrams)
-
-		// Uncomment for debugging
-		/*
-		if ctx.Value("debug") != nil {
-			println("Comparing with", fileID)
-			println("Content 1:", processedContent)
-			println("Content 2:", processedOtherContent)
-			println("Similarity:", similarity)
-			println("Threshold:", c.SimilarityThreshold)
-
-			// Print n-grams for debugging
-			println("N-grams 1:")
-			for ngram := range currentNGrams {
-				println("  -", ngram)
-			}
-			println("N-grams 2:")
-			for ngram := range otherNGrams {
-				println("  -", ngram)
-			}
+	return similarFiles
+}
+
+// compare compares the preprocessed content, its n-grams and words with another content
+// and reports whether the other file is considered plagiarism
+func (c *PlagiarismChecker) compare(content, processedContent string, currentNGrams map[string]int, currentWords []string, fileID, otherContent string) (models.SimilarFile, bool) {
+	// Preprocess the other content
+	processedOtherContent := c.preprocessText(otherContent)
+
+	// First, do a quick hash check for exact matches
+	if c.calculateHash(processedContent) == c.calculateHash(processedOtherContent) {
+		return c.newSimilarFile(models.SimilarFile{
+			FileID:             fileID,
+			Score:              1,
+			Metric:             ExactMetric,
+			Containment:        1,
+			SimilarContainment: 1,
+			Coverage:           1,
+			MatchedWords:       int32(len(currentWords)),
+			Passages:           c.exactPassages(content, otherContent),
+		}), true
+	}
+
+	// If not an exact match, calculate Jaccard similarity
+	otherNGrams := c.generateNGrams(processedOtherContent, c.NGramSize)
+	similarity := c.calculateJaccardSimilarity(currentNGrams, otherNGrams)
+	containment, similarContainment := c.calculateContainment(currentNGrams, otherNGrams)
+
+	// And the share of the content covered by fingerprints found in the other content
+	otherHashes := c.Winnower.Hashes(c.textAnalyzer.GetWords(processedOtherContent))
+	matchedWords, coverage := c.calculateCoverage(currentWords, otherHashes)
+
+	metric := c.flaggingMetric(similarity, math.Max(containment, similarContainment), matchedWords, coverage)
+	if metric == "" {
+		return models.SimilarFile{}, false
+	}
+
+	return c.newSimilarFile(models.SimilarFile{
+		FileID:             fileID,
+		Score:              similarity,
+		Metric:             metric,
+		Containment:        containment,
+		SimilarContainment: similarContainment,
+		Coverage:           coverage,
+		MatchedWords:       int32(matchedWords),
+		Passages:           c.FindPassages(content, otherContent),
+	}), true
+}
+
+// FindPassages returns the passages of the content copied from the other content
+// Passages are found on significant words and reported with character offsets
+// of the original texts, so that punctuation and stop words inside a passage are
+// part of it
+func (c *PlagiarismChecker) FindPassages(content, otherContent string) []models.MatchedPassage {
+	tokens := c.textAnalyzer.GetSignificantTokens(content)
+	otherTokens := c.textAnalyzer.GetSignificantTokens(otherContent)
+
+	aligned := c.Winnower.AlignPassages(c.textAnalyzer.GetStems(tokens), c.textAnalyzer.GetStems(otherTokens))
+	if len(aligned) == 0 {
+		return []models.MatchedPassage{}
+	}
+
+	runes := []rune(content)
+	passages := make([]models.MatchedPassage, 0, len(aligned))
+	for _, passage := range aligned {
+		passages = append(passages, newMatchedPassage(
+			runes,
+			tokens[passage.Source.Start:passage.Source.End],
+			otherTokens[passage.Other.Start:passage.Other.End],
+		))
+	}
+	return passages
+}
+
+// exactPassages returns the single passage of two contents whose stemmed significant words are equal
+// Unlike FindPassages it also covers contents shorter than a winnowing k-gram
+func (c *PlagiarismChecker) exactPassages(content, otherContent string) []models.MatchedPassage {
+	tokens := c.textAnalyzer.GetSignificantTokens(content)
+	otherTokens := c.textAnalyzer.GetSignificantTokens(otherContent)
+	if len(tokens) == 0 || len(tokens) != len(otherTokens) {
+		return []models.MatchedPassage{}
+	}
+
+	return []models.MatchedPassage{newMatchedPassage([]rune(content), tokens, otherTokens)}
+}
+
+// newMatchedPassage builds a passage spanning the given non-empty runs of tokens
+func newMatchedPassage(runes []rune, tokens, otherTokens []Token) models.MatchedPassage {
+	start, end := tokens[0].Start, tokens[len(tokens)-1].End
+	return models.MatchedPassage{
+		Start:        int32(start),
+		End:          int32(end),
+		SimilarStart: int32(otherTokens[0].Start),
+		SimilarEnd:   int32(otherTokens[len(otherTokens)-1].End),
+		Text:         string(runes[start:end]),
+	}
+}
+
+// flaggingMetric decides whether the measured similarity flags a file according to Mode
+// and returns the metric that flagged it, or an empty string if the file is not flagged
+// When several metrics flag a file, the first of Jaccard, containment and winnowing is reported
+func (c *PlagiarismChecker) flaggingMetric(similarity, containment float64, matchedWords int, coverage float64) string {
+	jaccard := similarity >= c.SimilarityThreshold
+	contained := containment > 0 && containment >= c.ContainmentThreshold
+	winnowing := matchedWords > 0 && (matchedWords >= c.MinMatchedWords || coverage >= c.CoverageThreshold)
+
+	if jaccard && c.Mode != WinnowingMode {
+		return JaccardMetric
+	}
+	if contained && c.Mode != WinnowingMode {
+		return ContainmentMetric
+	}
+	if winnowing && c.Mode != JaccardMode {
+		return WinnowingMetric
+	}
+	return ""
+}
+
+// newSimilarFile completes a similar file with the parameters the checker used to find it
+func (c *PlagiarismChecker) newSimilarFile(similarFile models.SimilarFile) models.SimilarFile {
+	similarFile.NGramSize = int32(c.NGramSize)
+	similarFile.Threshold = c.SimilarityThreshold
+	similarFile.AlgorithmVersion = AlgorithmVersion
+	return similarFile
+}
+
+// calculateCoverage computes the number and the share of the document's words
+// covered by passages matched by winnowing fingerprints against the other document
+func (c *PlagiarismChecker) calculateCoverage(words []string, otherHashes map[uint64]bool) (int, float64) {
+	if len(words) == 0 {
+		return 0, 0
+	}
+
+	matchedWords := 0
+	for _, region := range c.Winnower.MatchedRegions(words, otherHashes) {
+		matchedWords += region.Len()
+	}
+	return matchedWords, float64(matchedWords) / float64(len(words))
+}
+
+// Signature computes the MinHash signature of the content's n-grams
+// Two contents with similar signatures are likely to be reported by CheckPlagiarism,
+// so the signature can be used to select comparison candidates in advance
+func (c *PlagiarismChecker) Signature(content string) []uint64 {
+	ngrams := c.generateNGrams(c.preprocessText(content), c.NGramSize)
+	return c.MinHasher.Signature(ngrams)
+}
+
+// Fingerprints computes the distinct winnowing fingerprints of the content, in ascending order
+// Two contents sharing a passage of at least K+Window-1 significant words share a fingerprint,
+// however long the rest of them is, so the fingerprints select the comparison candidates
+// of copied passages that the signature misses
+func (c *PlagiarismChecker) Fingerprints(content string) []uint64 {
+	words := c.textAnalyzer.GetWords(c.preprocessText(content))
+
+	seen := make(map[uint64]bool)
+	fingerprints := []uint64{}
+	for _, fingerprint := range c.Winnower.Fingerprints(words) {
+		if !seen[fingerprint.Hash] {
+			seen[fingerprint.Hash] = true
+			fingerprints = append(fingerprints, fingerprint.Hash)
 		}
-		*/
+	}
+
+	sort.Slice(fingerprints, func(i, j int) bool {
+		return fingerprints[i] < fingerprints[j]
+	})
+	return fingerprints
+}
+
+// IsFingerprintCandidate reports whether a file sharing fingerprints with a content,
+// which has the given number of fingerprints, must be compared with it
+// When winnowing flags files, the shared fingerprints must be able to cover MinMatchedWords
+// or CoverageThreshold of the content, so a few shared boilerplate phrases make no candidate
+// When containment flags files, the shared fingerprints must reach the share containment may flag
+func (c *PlagiarismChecker) IsFingerprintCandidate(match models.FingerprintMatch, fingerprints int) bool {
+	if match.Shared == 0 || fingerprints == 0 {
+		return false
+	}
 
-		// If similarity is above threshold, consider it plagiarism
-		if similarity >= c.SimilarityThreshold {
-			similarFileIDs = append(similarFileIDs, fileID)
+	shared := int(match.Shared)
+	if c.Mode != JaccardMode {
+		if shared >= c.minSharedFingerprints() || float64(shared)/float64(fingerprints) >= c.CoverageThreshold {
+			return true
 		}
 	}
+	if c.Mode == WinnowingMode || c.ContainmentThreshold > 1 {
+		return false // Containment does not flag files
+	}
 
-	return len(similarFileIDs) > 0, similarFileIDs
+	smaller := min(fingerprints, int(match.Fingerprints))
+	if smaller == 0 {
+		return false
+	}
+	return float64(shared)/float64(smaller) >= c.minFingerprintContainment()
+}
+
+// minSharedFingerprints is the lowest number of shared fingerprints covering MinMatchedWords
+// Each distinct fingerprint covers the K words of its k-gram
+func (c *PlagiarismChecker) minSharedFingerprints() int {
+	k := max(c.Winnower.K, 1)
+	return max((c.MinMatchedWords+k-1)/k, 1)
+}
+
+// minFingerprintContainment is the lowest share of fingerprints contained in another file
+// for files whose n-gram containment reaches ContainmentThreshold
+// An n-gram missing from the other file breaks at most K-NGramSize+1 of the longer k-grams
+func (c *PlagiarismChecker) minFingerprintContainment() float64 {
+	brokenPerNGram := float64(max(c.Winnower.K-c.NGramSize+1, 1))
+	return 1 - (1-c.ContainmentThreshold)*brokenPerNGram
 }
 
 // preprocessText prepares text for comparison by normalizing it
 func (c *PlagiarismChecker) preprocessText(text string) string {
-	// Get significant words (removes stop words and punctuation)
-	significantWords := c.textAnalyzer.GetSignificantWords(text)
+	// Get stems of significant words (removes stop words and punctuation,
+	// undoes obfuscation, merges inflected forms)
+	stems := c.textAnalyzer.GetStemmedWords(text)
 
-	// Join the significant words
-	processedText := strings.Join(significantWords, " ")
+	// Join the stems
+	processedText := strings.Join(stems, " ")
 
 	// Normalize whitespace
 	return c.textAnalyzer.RemoveExcessWhitespace(processedText)
@@ -177,6 +493,24 @@ func (c *PlagiarismChecker) calculateJaccardSimilarity(ngrams1, ngrams2 map[stri
 	return float64(intersection) / float64(union)
 }
 
+// calculateContainment computes the containment of two sets of n-grams in each other
+// The first value is the share of ngrams1 found in ngrams2 (|A∩B|/|A|), the second
+// is the share of ngrams2 found in ngrams1 (|A∩B|/|B|)
+func (c *PlagiarismChecker) calculateContainment(ngrams1, ngrams2 map[string]int) (float64, float64) {
+	if len(ngrams1) == 0 || len(ngrams2) == 0 {
+		return 0, 0
+	}
+
+	intersection := 0
+	for ngram := range ngrams1 {
+		if _, ok := ngrams2[ngram]; ok {
+			intersection++
+		}
+	}
+
+	return float64(intersection) / float64(len(ngrams1)), float64(intersection) / float64(len(ngrams2))
+}
+
 // calculateHash calculates a SHA-256 hash of the content
 func (c *PlagiarismChecker) calculateHash(content string) string {
 	hash := sha256.Sum256([]byte(content))
@@ -2,7 +2,13 @@ package analyzer
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
 )
 
 func TestPlagiarismChecker_CheckPlagiarism(t *testing.T) {
@@ -241,3 +247,408 @@ func TestPlagiarismChecker_preprocessText(t *testing.T) {
 		})
 	}
 }
+
+// Test for detection of passages copied into a long original document
+func TestPlagiarismChecker_FindSimilarFilesWithProgress(t *testing.T) {
+	checker := NewPlagiarismChecker()
+	content := "The quick brown fox jumps over the lazy dog near the river bank"
+	otherContents := map[string]string{
+		"copy":      content,
+		"unrelated": "completely unrelated text about something else entirely",
+		"other":     "another document on a different subject with other words",
+	}
+
+	var reported [][2]int
+	similarFiles := checker.FindSimilarFilesWithProgress(context.Background(), content, otherContents, func(compared, total int) {
+		reported = append(reported, [2]int{compared, total})
+	})
+
+	// Progress is reported after each comparison and the results match FindSimilarFiles
+	assert.Equal(t, [][2]int{{1, 3}, {2, 3}, {3, 3}}, reported)
+	assert.Equal(t, checker.FindSimilarFiles(context.Background(), content, otherContents), similarFiles)
+	if assert.Len(t, similarFiles, 1) {
+		assert.Equal(t, "copy", similarFiles[0].FileID)
+	}
+}
+
+func TestPlagiarismChecker_FindSimilarFiles_PartialCopy(t *testing.T) {
+	// 40 copied words inside a document of 1000 original words
+	source := make([]string, 40)
+	for i := range source {
+		source[i] = fmt.Sprintf("source%d", i)
+	}
+	original := make([]string, 1000)
+	for i := range original {
+		original[i] = fmt.Sprintf("original%d", i)
+	}
+	content := strings.Join(original[:500], " ") + "\n\n" + strings.Join(source, " ") + "\n\n" + strings.Join(original[500:], " ")
+	otherContents := map[string]string{
+		"source":    strings.Join(source, " "),
+		"unrelated": "completely unrelated text about something else entirely",
+	}
+
+	t.Run("Jaccard similarity misses the copied passage", func(t *testing.T) {
+		checker := NewPlagiarismChecker()
+		checker.Mode = JaccardMode
+		checker.ContainmentThreshold = 1.01 // Disable containment, which would catch the copy
+
+		assert.Empty(t, checker.FindSimilarFiles(context.Background(), content, otherContents))
+	})
+
+	t.Run("Containment detects the copied source", func(t *testing.T) {
+		checker := NewPlagiarismChecker()
+		checker.Mode = JaccardMode
+
+		similarFiles := checker.FindSimilarFiles(context.Background(), content, otherContents)
+		if assert.Len(t, similarFiles, 1) {
+			assert.Equal(t, "source", similarFiles[0].FileID)
+			assert.Equal(t, ContainmentMetric, similarFiles[0].Metric)
+		}
+	})
+
+	t.Run("Winnowing mode detects the copied passage", func(t *testing.T) {
+		checker := NewPlagiarismChecker()
+		checker.Mode = WinnowingMode
+
+		similarFiles := checker.FindSimilarFiles(context.Background(), content, otherContents)
+		if assert.Len(t, similarFiles, 1) {
+			assert.Equal(t, "source", similarFiles[0].FileID)
+			assert.GreaterOrEqual(t, similarFiles[0].MatchedWords, int32(checker.MinMatchedWords))
+			assert.InDelta(t, 40.0/1040.0, similarFiles[0].Coverage, 0.01, "Coverage should be the share of copied words")
+			assert.Equal(t, WinnowingMetric, similarFiles[0].Metric)
+			assert.Less(t, similarFiles[0].Score, checker.SimilarityThreshold, "Jaccard score of a small copy should stay low")
+		}
+	})
+
+	t.Run("Short document covered by another file", func(t *testing.T) {
+		checker := NewPlagiarismChecker()
+		checker.Mode = WinnowingMode
+
+		// 10 copied words are less than MinMatchedWords but cover the whole document
+		similarFiles := checker.FindSimilarFiles(context.Background(), strings.Join(source[:10], " "), otherContents)
+		if assert.Len(t, similarFiles, 1) {
+			assert.Equal(t, "source", similarFiles[0].FileID)
+			assert.Equal(t, 1.0, similarFiles[0].Coverage)
+		}
+	})
+}
+
+func TestPlagiarismChecker_Fingerprints(t *testing.T) {
+	// 60 words copied between two long documents
+	words := func(prefix string, count int) string {
+		result := make([]string, count)
+		for i := range result {
+			result[i] = fmt.Sprintf("%s%d", prefix, i)
+		}
+		return strings.Join(result, " ")
+	}
+	copied := words("copied", 60)
+	content := words("original", 1500) + "\n\n" + copied + "\n\n" + words("remaining", 1500)
+	other := words("other", 1000) + "\n\n" + copied + "\n\n" + words("rest", 1000)
+
+	checker := NewPlagiarismChecker()
+
+	// The signatures of the documents barely change, no LSH band is shared
+	bandKeys := checker.MinHasher.BandKeys(checker.Signature(content))
+	otherBandKeys := checker.MinHasher.BandKeys(checker.Signature(other))
+	for band := range bandKeys {
+		assert.NotEqual(t, bandKeys[band], otherBandKeys[band], "band %d", band)
+	}
+
+	// The fingerprints of the copied passage are shared
+	fingerprints := checker.Fingerprints(content)
+	otherFingerprints := checker.Fingerprints(other)
+	shared := 0
+	for _, fingerprint := range otherFingerprints {
+		for _, contentFingerprint := range fingerprints {
+			if fingerprint == contentFingerprint {
+				shared++
+			}
+		}
+	}
+	assert.Greater(t, shared, 0)
+	assert.IsIncreasing(t, fingerprints, "Fingerprints should be distinct and sorted")
+	assert.Empty(t, checker.Fingerprints("too short"), "Texts shorter than a k-gram have no fingerprints")
+
+	match := models.FingerprintMatch{FileID: "other", Shared: int32(shared), Fingerprints: int32(len(otherFingerprints))}
+	assert.True(t, checker.IsFingerprintCandidate(match, len(fingerprints)))
+	assert.False(t, checker.IsFingerprintCandidate(models.FingerprintMatch{FileID: "other"}, len(fingerprints)))
+
+	checker.Mode = WinnowingMode
+	assert.True(t, checker.IsFingerprintCandidate(match, len(fingerprints)))
+
+	// Containment alone does not select a small copy inside long documents
+	checker.Mode = JaccardMode
+	assert.False(t, checker.IsFingerprintCandidate(match, len(fingerprints)))
+}
+
+func TestPlagiarismChecker_FindPassages(t *testing.T) {
+	checker := NewPlagiarismChecker()
+
+	// Passages span from the first to the last matched word, surrounding punctuation is left out
+	copied := "Winnowing selects fingerprints, and the fingerprints locate copied passages precisely in both documents"
+	content := "Мой реферат начинается здесь. " + copied + ". Дальше идёт собственный текст."
+	otherContent := "Original introduction of the source. " + strings.ReplaceAll(copied, ", ", " - ") + "!"
+
+	passages := checker.FindPassages(content, otherContent)
+	if assert.Len(t, passages, 1) {
+		passage := passages[0]
+
+		// Offsets are in characters of the original texts
+		assert.Equal(t, copied, passage.Text)
+		assert.Equal(t, copied, string([]rune(content)[passage.Start:passage.End]))
+		assert.Equal(t, int32(len([]rune("Мой реферат начинается здесь. "))), passage.Start)
+
+		similarText := string([]rune(otherContent)[passage.SimilarStart:passage.SimilarEnd])
+		assert.Equal(t, strings.ReplaceAll(copied, ", ", " - "), similarText)
+	}
+
+	// Nothing is found in an unrelated text
+	assert.Empty(t, checker.FindPassages(content, "completely unrelated text about something else entirely"))
+}
+
+func TestPlagiarismChecker_FindSimilarFiles_Passages(t *testing.T) {
+	checker := NewPlagiarismChecker()
+
+	// Exact copies are reported as a single passage, even when they are short
+	similarFiles := checker.FindSimilarFiles(context.Background(), "Short copied note.", map[string]string{
+		"copy": "short, COPIED note",
+	})
+	if assert.Len(t, similarFiles, 1) {
+		assert.Equal(t, []models.MatchedPassage{
+			{Start: 0, End: 17, SimilarStart: 0, SimilarEnd: 18, Text: "Short copied note"},
+		}, similarFiles[0].Passages)
+	}
+}
+
+func TestPlagiarismChecker_FindSimilarFiles_Scores(t *testing.T) {
+	checker := NewPlagiarismChecker()
+
+	content := "The quick brown fox jumps over the lazy dog near the river bank today"
+	similarFiles := checker.FindSimilarFiles(context.Background(), content, map[string]string{
+		"exact":   content,
+		"similar": "The quick brown fox jumps over the lazy dog near the river bank yesterday",
+	})
+
+	if assert.Len(t, similarFiles, 2) {
+		exact, similar := similarFiles[0], similarFiles[1]
+
+		assert.Equal(t, ExactMetric, exact.Metric)
+		assert.Equal(t, 1.0, exact.Score)
+
+		assert.Equal(t, JaccardMetric, similar.Metric)
+		assert.Greater(t, similar.Score, checker.SimilarityThreshold)
+		assert.Less(t, similar.Score, 1.0)
+
+		// Every pair records the parameters it was found with
+		for _, similarFile := range similarFiles {
+			assert.Equal(t, int32(checker.NGramSize), similarFile.NGramSize)
+			assert.Equal(t, checker.SimilarityThreshold, similarFile.Threshold)
+			assert.Equal(t, int32(AlgorithmVersion), similarFile.AlgorithmVersion)
+		}
+	}
+}
+
+func TestPlagiarismChecker_calculateContainment(t *testing.T) {
+	checker := NewPlagiarismChecker()
+
+	ngrams1 := map[string]int{"a b c": 1, "b c d": 1}
+	ngrams2 := map[string]int{"a b c": 1, "b c d": 1, "c d e": 1, "d e f": 1}
+
+	containment, similarContainment := checker.calculateContainment(ngrams1, ngrams2)
+	assert.Equal(t, 1.0, containment, "All n-grams of the first set are in the second one")
+	assert.Equal(t, 0.5, similarContainment, "Half of the n-grams of the second set are in the first one")
+
+	containment, similarContainment = checker.calculateContainment(map[string]int{}, ngrams2)
+	assert.Equal(t, 0.0, containment)
+	assert.Equal(t, 0.0, similarContainment)
+}
+
+func TestPlagiarismChecker_FindSimilarFiles_PaddedCopy(t *testing.T) {
+	// A short source copied and padded with three times as much filler
+	source := make([]string, 30)
+	for i := range source {
+		source[i] = fmt.Sprintf("source%d", i)
+	}
+	filler := make([]string, 90)
+	for i := range filler {
+		filler[i] = fmt.Sprintf("filler%d", i)
+	}
+	content := strings.Join(filler[:45], " ") + " " + strings.Join(source, " ") + " " + strings.Join(filler[45:], " ")
+	otherContents := map[string]string{"source": strings.Join(source, " ")}
+
+	checker := NewPlagiarismChecker()
+	checker.Mode = JaccardMode
+
+	similarFiles := checker.FindSimilarFiles(context.Background(), content, otherContents)
+	if assert.Len(t, similarFiles, 1) {
+		similarFile := similarFiles[0]
+		assert.Equal(t, ContainmentMetric, similarFile.Metric)
+		assert.Less(t, similarFile.Score, checker.SimilarityThreshold, "Padding should lower Jaccard similarity")
+		assert.Equal(t, 1.0, similarFile.SimilarContainment, "The whole source should be contained in the padded copy")
+		assert.Less(t, similarFile.Containment, 0.3, "Most of the padded copy is not in the source")
+	}
+
+	// A higher threshold lets the padded copy pass
+	checker.ContainmentThreshold = 1.01
+	assert.Empty(t, checker.FindSimilarFiles(context.Background(), content, otherContents))
+}
+
+func TestPlagiarismChecker_IsFingerprintCandidate_Containment(t *testing.T) {
+	// The padded copy of a short source, and a long document sharing a few words with the copy
+	words := func(prefix string, count int) []string {
+		result := make([]string, count)
+		for i := range result {
+			result[i] = fmt.Sprintf("%s%d", prefix, i)
+		}
+		return result
+	}
+	source := words("source", 30)
+	filler := words("filler", 90)
+	content := strings.Join(filler[:45], " ") + " " + strings.Join(source, " ") + " " + strings.Join(filler[45:], " ")
+	overlapping := strings.Join(words("other", 200), " ") + " " + strings.Join(source[:12], " ")
+
+	checker := NewPlagiarismChecker()
+	checker.Mode = JaccardMode
+
+	fingerprints := checker.Fingerprints(content)
+	match := func(other string) models.FingerprintMatch {
+		otherFingerprints := checker.Fingerprints(other)
+		shared := 0
+		for _, fingerprint := range otherFingerprints {
+			for _, contentFingerprint := range fingerprints {
+				if fingerprint == contentFingerprint {
+					shared++
+				}
+			}
+		}
+		return models.FingerprintMatch{FileID: "other", Shared: int32(shared), Fingerprints: int32(len(otherFingerprints))}
+	}
+	sourceMatch := match(strings.Join(source, " "))
+	overlappingMatch := match(overlapping)
+	assert.Greater(t, overlappingMatch.Shared, int32(0))
+
+	// The source is contained in the copy, the overlapping document is not
+	assert.True(t, checker.IsFingerprintCandidate(sourceMatch, len(fingerprints)))
+	assert.False(t, checker.IsFingerprintCandidate(overlappingMatch, len(fingerprints)))
+
+	// A lower threshold selects the overlapping document too
+	checker.ContainmentThreshold = 0.5
+	assert.True(t, checker.IsFingerprintCandidate(overlappingMatch, len(fingerprints)))
+
+	// Disabled containment selects no candidates in Jaccard mode
+	checker.ContainmentThreshold = 1.01
+	assert.False(t, checker.IsFingerprintCandidate(sourceMatch, len(fingerprints)))
+}
+
+func TestPlagiarismChecker_IsFingerprintCandidate_Boilerplate(t *testing.T) {
+	// Long documents sharing a boilerplate phrase, and one sharing a copied paragraph too
+	words := func(prefix string, count int) []string {
+		result := make([]string, count)
+		for i := range result {
+			result[i] = fmt.Sprintf("%s%d", prefix, i)
+		}
+		return result
+	}
+	boilerplate := strings.Join(words("boilerplate", 10), " ")
+	paragraph := strings.Join(words("paragraph", 40), " ")
+	content := boilerplate + " " + strings.Join(words("content", 300), " ") + " " + paragraph
+	other := boilerplate + " " + strings.Join(words("other", 300), " ")
+	copied := boilerplate + " " + strings.Join(words("copied", 300), " ") + " " + paragraph
+
+	checker := NewPlagiarismChecker()
+	fingerprints := checker.Fingerprints(content)
+	match := func(other string) models.FingerprintMatch {
+		otherFingerprints := checker.Fingerprints(other)
+		shared := 0
+		for _, fingerprint := range otherFingerprints {
+			for _, contentFingerprint := range fingerprints {
+				if fingerprint == contentFingerprint {
+					shared++
+				}
+			}
+		}
+		return models.FingerprintMatch{FileID: "other", Shared: int32(shared), Fingerprints: int32(len(otherFingerprints))}
+	}
+	otherMatch := match(other)
+	copiedMatch := match(copied)
+	assert.Greater(t, otherMatch.Shared, int32(0))
+
+	for _, mode := range []DetectionMode{CombinedMode, WinnowingMode} {
+		checker.Mode = mode
+		assert.False(t, checker.IsFingerprintCandidate(otherMatch, len(fingerprints)), "Boilerplate should not make a candidate in %s mode", mode)
+		assert.True(t, checker.IsFingerprintCandidate(copiedMatch, len(fingerprints)), "A copied paragraph should make a candidate in %s mode", mode)
+	}
+
+	// The boilerplate is enough for winnowing to flag a file with a lower minimum
+	checker.MinMatchedWords = 5
+	assert.True(t, checker.IsFingerprintCandidate(otherMatch, len(fingerprints)))
+}
+
+func TestParseDetectionMode(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected DetectionMode
+		wantErr  bool
+	}{
+		{value: "", expected: CombinedMode},
+		{value: "jaccard", expected: JaccardMode},
+		{value: " Winnowing ", expected: WinnowingMode},
+		{value: "combined", expected: CombinedMode},
+		{value: "cosine", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDetectionMode(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestPlagiarismChecker_FindSimilarFiles_RussianInflections(t *testing.T) {
+	// The same sentences with most words in other grammatical forms
+	content := "Искусственный интеллект представляет собой область компьютерных наук, " +
+		"которая фокусируется на создании системы, способной выполнять сложные задачи."
+	otherContent := "Искусственного интеллекта представляют собою области компьютерной науки, " +
+		"которые фокусируются на создание системы, способных выполнять сложную задачу."
+	otherContents := map[string]string{"other": otherContent}
+
+	checker := NewPlagiarismChecker()
+	checker.Mode = JaccardMode
+
+	similarFiles := checker.FindSimilarFiles(context.Background(), content, otherContents)
+	if assert.Len(t, similarFiles, 1) {
+		assert.Equal(t, ExactMetric, similarFiles[0].Metric, "Stemmed texts should be equal")
+	}
+
+	// Passages are matched on stems and reported in the original texts
+	passages := checker.FindPassages(content, otherContent)
+	if assert.Len(t, passages, 1) {
+		assert.Equal(t, int32(0), passages[0].Start)
+		assert.Equal(t, int32(len([]rune(content))-1), passages[0].End)
+	}
+}
+
+func TestPlagiarismChecker_FindSimilarFiles_Obfuscated(t *testing.T) {
+	content := "Искусственный интеллект представляет собой область компьютерных наук."
+	// The same text with Latin look-alikes and zero-width spaces
+	otherContent := "Иcкуccтвенный интеллeкт пpедставляет собой об\u200bласть компью\u200bтерных наук."
+	otherContents := map[string]string{"other": otherContent}
+
+	checker := NewPlagiarismChecker()
+
+	similarFiles := checker.FindSimilarFiles(context.Background(), content, otherContents)
+	if assert.Len(t, similarFiles, 1) {
+		assert.Equal(t, ExactMetric, similarFiles[0].Metric, "Normalized texts should be equal")
+		if assert.Len(t, similarFiles[0].Passages, 1) {
+			// Offsets refer to the obfuscated text, including the invisible characters
+			assert.Equal(t, int32(len([]rune(otherContent))-1), similarFiles[0].Passages[0].SimilarEnd)
+		}
+	}
+}
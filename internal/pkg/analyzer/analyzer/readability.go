@@ -0,0 +1,137 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Readability formulas
+const (
+	// FleschKincaidFormula scores English texts with the Flesch reading ease
+	// and the Flesch-Kincaid grade level
+	FleschKincaidFormula = "flesch-kincaid"
+
+	// ObornevaFormula scores Russian texts with Oborneva's adaptation of the Flesch reading ease
+	// and the Flesch-Kincaid grade level with coefficients adapted to Russian
+	ObornevaFormula = "oborneva"
+)
+
+// Readability holds the readability indices of a text
+type Readability struct {
+	// Formula used to compute the indices, empty if the language has no formula
+	Formula string
+
+	// ReadingEase is the Flesch reading ease: about 0 to 100, higher is easier to read
+	ReadingEase float64
+
+	// GradeLevel is the number of years of schooling needed to understand the text
+	GradeLevel float64
+}
+
+// readabilityCoefficients holds the coefficients of the Flesch formulas,
+// applied to the average sentence length in words (ASL) and word length in syllables (ASW)
+type readabilityCoefficients struct {
+	formula string
+
+	// Reading ease = ease - easeASL*ASL - easeASW*ASW
+	ease, easeASL, easeASW float64
+
+	// Grade level = gradeASL*ASL + gradeASW*ASW - grade
+	gradeASL, gradeASW, grade float64
+}
+
+// readabilityFormulas holds the coefficients of the formulas by language
+var readabilityFormulas = map[Language]readabilityCoefficients{
+	English: {
+		formula: FleschKincaidFormula,
+		ease:    206.835, easeASL: 1.015, easeASW: 84.6,
+		gradeASL: 0.39, gradeASW: 11.8, grade: 15.59,
+	},
+	Russian: {
+		formula: ObornevaFormula,
+		ease:    206.835, easeASL: 1.3, easeASW: 60.1,
+		gradeASL: 0.318, gradeASW: 14.2, grade: 15.59,
+	},
+}
+
+// GetReadability computes the readability indices of the content with the formula of its language
+// Languages without a formula and texts without words get an empty Readability
+func (a *TextAnalyzer) GetReadability(content string, language Language) Readability {
+	coefficients, ok := readabilityFormulas[language]
+	if !ok {
+		return Readability{}
+	}
+
+	words, syllables := 0, 0
+	for _, word := range a.GetLexicalWords(content) {
+		if strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue // Numbers have no syllables to count
+		}
+		words++
+		syllables += CountSyllables(word)
+	}
+	sentences := len(a.GetSentences(content))
+	if words == 0 || sentences == 0 {
+		return Readability{Formula: coefficients.formula}
+	}
+
+	asl := float64(words) / float64(sentences)
+	asw := float64(syllables) / float64(words)
+	return Readability{
+		Formula:     coefficients.formula,
+		ReadingEase: coefficients.ease - coefficients.easeASL*asl - coefficients.easeASW*asw,
+		GradeLevel:  coefficients.gradeASL*asl + coefficients.gradeASW*asw - coefficients.grade,
+	}
+}
+
+// CountSyllables estimates the number of syllables of a Russian or English word
+// Every Russian vowel is a syllable, while English syllables are groups of vowels,
+// not counting a silent final "e". A word with letters has at least one syllable
+func CountSyllables(word string) int {
+	runes := []rune(strings.ToLower(word))
+
+	syllables := 0
+	latinVowels := 0
+	inLatinVowelGroup := false
+	hasLetters := false
+	for i, r := range runes {
+		if unicode.IsLetter(r) {
+			hasLetters = true
+		}
+
+		if isRussianVowel(r) || r == 'ё' {
+			syllables++
+			inLatinVowelGroup = false
+			continue
+		}
+
+		if !isLatinVowel(r) {
+			inLatinVowelGroup = false
+			continue
+		}
+		latinVowels++
+		if !inLatinVowelGroup && !isSilentE(runes, i, latinVowels) {
+			syllables++
+		}
+		inLatinVowelGroup = true
+	}
+
+	if syllables == 0 && hasLetters {
+		return 1
+	}
+	return syllables
+}
+
+// isLatinVowel reports whether a lowercase rune is an English vowel
+func isLatinVowel(r rune) bool {
+	return strings.ContainsRune("aeiouy", r)
+}
+
+// isSilentE reports whether the "e" at position i is a silent final "e", as in "make",
+// but not in "table" or in words where it is the only vowel, as in "the"
+func isSilentE(runes []rune, i, latinVowels int) bool {
+	if runes[i] != 'e' || i != len(runes)-1 || latinVowels == 1 || i < 2 {
+		return false
+	}
+	return runes[i-1] != 'l' || isLatinVowel(runes[i-2])
+}
@@ -0,0 +1,63 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+)
+
+func TestCountSyllables(t *testing.T) {
+	// Test cases
+	testCases := []struct {
+		word     string
+		expected int
+	}{
+		{word: "cat", expected: 1},
+		{word: "make", expected: 1},
+		{word: "the", expected: 1},
+		{word: "table", expected: 2},
+		{word: "reading", expected: 2},
+		{word: "Computer", expected: 3},
+		{word: "beautiful", expected: 3},
+		{word: "rhythm", expected: 1},
+		{word: "мама", expected: 2},
+		{word: "интеллект", expected: 3},
+		{word: "ёлка", expected: 2},
+		{word: "взгляд", expected: 1},
+		{word: "2024", expected: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.word, func(t *testing.T) {
+			assert.Equal(t, tc.expected, analyzer.CountSyllables(tc.word))
+		})
+	}
+}
+
+func TestTextAnalyzer_GetReadability(t *testing.T) {
+	// Create a new text analyzer
+	textAnalyzer := analyzer.NewTextAnalyzer()
+
+	// English: 9 words, 2 sentences, 9 syllables
+	readability := textAnalyzer.GetReadability("The cat sat on the mat. The dog ran.", analyzer.English)
+	assert.Equal(t, analyzer.FleschKincaidFormula, readability.Formula)
+	assert.InDelta(t, 206.835-1.015*4.5-84.6*1, readability.ReadingEase, 1e-9)
+	assert.InDelta(t, 0.39*4.5+11.8*1-15.59, readability.GradeLevel, 1e-9)
+
+	// Russian: 6 words, 2 sentences, 12 syllables
+	readability = textAnalyzer.GetReadability("Мама мыла раму. Папа читал книгу.", analyzer.Russian)
+	assert.Equal(t, analyzer.ObornevaFormula, readability.Formula)
+	assert.InDelta(t, 206.835-1.3*3-60.1*2, readability.ReadingEase, 1e-9)
+	assert.InDelta(t, 0.318*3+14.2*2-15.59, readability.GradeLevel, 1e-9)
+
+	// Longer words and sentences are harder to read
+	harder := textAnalyzer.GetReadability("Исследовательская деятельность предполагает систематическое применение количественных методов анализа.", analyzer.Russian)
+	assert.Less(t, harder.ReadingEase, readability.ReadingEase)
+	assert.Greater(t, harder.GradeLevel, readability.GradeLevel)
+
+	// Languages without a formula and empty texts
+	assert.Equal(t, analyzer.Readability{}, textAnalyzer.GetReadability("Der Hund.", "de"))
+	assert.Equal(t, analyzer.Readability{Formula: analyzer.ObornevaFormula}, textAnalyzer.GetReadability("2024.", analyzer.Russian))
+}
@@ -0,0 +1,245 @@
+package analyzer
+
+import (
+	"strings"
+	"sync"
+)
+
+// Stemmer reduces inflected words to a common stem, so that different forms
+// of a word are treated as the same word
+// Words passed to a stemmer are lowercase
+type Stemmer interface {
+	Stem(word string) string
+}
+
+var (
+	stemmersMu sync.RWMutex
+
+	// stemmers holds the registered stemmers by language
+	stemmers = map[Language]Stemmer{
+		Russian: NewRussianStemmer(),
+	}
+)
+
+// RegisterStemmer registers the stemmer of a language, replacing the previously registered one
+func RegisterStemmer(language Language, stemmer Stemmer) {
+	stemmersMu.Lock()
+	defer stemmersMu.Unlock()
+
+	stemmers[language] = stemmer
+}
+
+// StemmerFor returns the stemmer registered for a language, or nil if there is none
+func StemmerFor(language Language) Stemmer {
+	stemmersMu.RLock()
+	defer stemmersMu.RUnlock()
+
+	return stemmers[language]
+}
+
+// RussianStemmer implements the Snowball stemming algorithm for Russian
+// (https://snowballstem.org/algorithms/russian/stemmer.html)
+//
+// Words without Russian vowels, including words in other scripts, are returned
+// unchanged, so the stemmer is safe to use on mixed-language text.
+// The letter "ё" is treated as "е".
+type RussianStemmer struct{}
+
+// NewRussianStemmer creates a new RussianStemmer instance
+func NewRussianStemmer() *RussianStemmer {
+	return &RussianStemmer{}
+}
+
+// Suffix groups of the algorithm
+// Suffixes of the first groups of perfective gerunds, participles and verbs are only
+// removed when they follow "а" or "я", which stays in the stem
+var (
+	russianPerfectiveGerund1 = []string{"в", "вши", "вшись"}
+	russianPerfectiveGerund2 = []string{"ив", "ивши", "ившись", "ыв", "ывши", "ывшись"}
+
+	russianAdjective = []string{
+		"ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом",
+		"его", "ого", "ему", "ому", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею",
+	}
+
+	russianParticiple1 = []string{"ем", "нн", "вш", "ющ", "щ"}
+	russianParticiple2 = []string{"ивш", "ывш", "ующ"}
+
+	russianReflexive = []string{"ся", "сь"}
+
+	russianVerb1 = []string{
+		"ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н", "ло", "но", "ет", "ют", "ны", "ть", "ешь", "нно",
+	}
+	russianVerb2 = []string{
+		"ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ей", "уй", "ил", "ыл", "им", "ым", "ен",
+		"ило", "ыло", "ено", "ят", "ует", "уют", "ит", "ыт", "ены", "ить", "ыть", "ишь", "ую", "ю",
+	}
+
+	russianNoun = []string{
+		"а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами", "еи", "ии", "и", "ией", "ей", "ой", "ий",
+		"й", "иям", "ям", "ием", "ем", "ам", "ом", "о", "у", "ах", "иях", "ях", "ы", "ь", "ию", "ью", "ю",
+		"ия", "ья", "я",
+	}
+
+	russianDerivational = []string{"ост", "ость"}
+	russianSuperlative  = []string{"ейш", "ейше"}
+)
+
+// Stem returns the stem of a lowercase word
+func (s *RussianStemmer) Stem(word string) string {
+	w := &russianWord{runes: []rune(strings.ReplaceAll(word, "ё", "е"))}
+	w.markRegions()
+	if w.rv >= len(w.runes) {
+		return string(w.runes)
+	}
+
+	// Step 1: remove the inflectional ending
+	if !w.removeGrouped(russianPerfectiveGerund1, russianPerfectiveGerund2) {
+		w.removeLongest(russianReflexive, w.rv)
+		if !w.removeAdjectival() && !w.removeGrouped(russianVerb1, russianVerb2) {
+			w.removeLongest(russianNoun, w.rv)
+		}
+	}
+
+	// Step 2: remove a final "и"
+	w.removeLongest([]string{"и"}, w.rv)
+
+	// Step 3: remove a derivational ending in R2
+	w.removeLongest(russianDerivational, w.r2)
+
+	// Step 4: remove a superlative ending and undouble "н", or remove a final "ь"
+	switch suffix := w.longestSuffix(append([]string{"нн", "ь"}, russianSuperlative...), w.rv); suffix {
+	case "":
+	case "нн", "ь":
+		w.trim(1)
+	default:
+		w.trim(len([]rune(suffix)))
+		if w.hasSuffix("нн", w.rv) {
+			w.trim(1)
+		}
+	}
+
+	return string(w.runes)
+}
+
+// russianWord is a word being stemmed, with the start positions of its RV and R2 regions
+type russianWord struct {
+	runes []rune
+	rv    int
+	r2    int
+}
+
+// isRussianVowel reports whether a rune is a Russian vowel
+func isRussianVowel(r rune) bool {
+	return strings.ContainsRune("аеиоуыэюя", r)
+}
+
+// markRegions finds RV, the region after the first vowel, and R2, the region after
+// the first non-vowel following a vowel in R1, where R1 is the same region of the word
+func (w *russianWord) markRegions() {
+	n := len(w.runes)
+	w.rv, w.r2 = n, n
+
+	i := 0
+	for i < n && !isRussianVowel(w.runes[i]) {
+		i++
+	}
+	if i == n {
+		return
+	}
+	w.rv = i + 1
+
+	// R1 starts after the first non-vowel following a vowel
+	i = w.rv
+	for i < n && isRussianVowel(w.runes[i]) {
+		i++
+	}
+	r1 := i + 1
+	if r1 > n {
+		return
+	}
+
+	// R2 is R1 of R1
+	i = r1
+	for i < n && !isRussianVowel(w.runes[i]) {
+		i++
+	}
+	for i < n && isRussianVowel(w.runes[i]) {
+		i++
+	}
+	if i+1 <= n {
+		w.r2 = i + 1
+	}
+}
+
+// hasSuffix reports whether the word ends with suffix starting at or after limit
+func (w *russianWord) hasSuffix(suffix string, limit int) bool {
+	s := []rune(suffix)
+	start := len(w.runes) - len(s)
+	if start < limit {
+		return false
+	}
+	for i, r := range s {
+		if w.runes[start+i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// longestSuffix returns the longest of the suffixes the word ends with at or after limit
+func (w *russianWord) longestSuffix(suffixes []string, limit int) string {
+	longest := ""
+	for _, suffix := range suffixes {
+		if len([]rune(suffix)) > len([]rune(longest)) && w.hasSuffix(suffix, limit) {
+			longest = suffix
+		}
+	}
+	return longest
+}
+
+// removeLongest removes the longest of the suffixes found at or after limit
+func (w *russianWord) removeLongest(suffixes []string, limit int) bool {
+	suffix := w.longestSuffix(suffixes, limit)
+	if suffix == "" {
+		return false
+	}
+	w.trim(len([]rune(suffix)))
+	return true
+}
+
+// removeGrouped removes the longest suffix of two groups found in RV
+// A suffix of the first group is only removed when it follows "а" or "я" in RV.
+// As in Snowball, a shorter suffix is not tried when the longest one cannot be removed
+func (w *russianWord) removeGrouped(group1, group2 []string) bool {
+	suffix1 := w.longestSuffix(group1, w.rv)
+	suffix2 := w.longestSuffix(group2, w.rv)
+
+	if len([]rune(suffix2)) >= len([]rune(suffix1)) {
+		if suffix2 == "" {
+			return false
+		}
+		w.trim(len([]rune(suffix2)))
+		return true
+	}
+
+	if !w.hasSuffix("а"+suffix1, w.rv) && !w.hasSuffix("я"+suffix1, w.rv) {
+		return false
+	}
+	w.trim(len([]rune(suffix1)))
+	return true
+}
+
+// removeAdjectival removes an adjective ending, optionally preceded by a participle suffix
+func (w *russianWord) removeAdjectival() bool {
+	if !w.removeLongest(russianAdjective, w.rv) {
+		return false
+	}
+	w.removeGrouped(russianParticiple1, russianParticiple2)
+	return true
+}
+
+// trim removes the last n runes of the word
+func (w *russianWord) trim(n int) {
+	w.runes = w.runes[:len(w.runes)-n]
+}
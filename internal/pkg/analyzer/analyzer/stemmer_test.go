@@ -0,0 +1,51 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+)
+
+func TestRussianStemmer_Stem(t *testing.T) {
+	// Create a new stemmer
+	stemmer := analyzer.NewRussianStemmer()
+
+	// Test cases from the Snowball reference vocabulary
+	testCases := []struct {
+		word     string
+		expected string
+	}{
+		{word: "интеллект", expected: "интеллект"},
+		{word: "интеллекта", expected: "интеллект"},
+		{word: "интеллектом", expected: "интеллект"},
+		{word: "вагон", expected: "вагон"},
+		{word: "вагона", expected: "вагон"},
+		{word: "вагоне", expected: "вагон"},
+		{word: "вагонов", expected: "вагон"},
+		{word: "важная", expected: "важн"},
+		{word: "важный", expected: "важн"},
+		{word: "красивейший", expected: "красив"},
+		{word: "программирования", expected: "программирован"},
+		{word: "обучение", expected: "обучен"},
+		{word: "обучения", expected: "обучен"},
+		{word: "сделавшись", expected: "сдела"},
+		{word: "прочитав", expected: "прочита"},
+		{word: "бывшая", expected: "бывш"},
+		{word: "ведёт", expected: "ведет"},
+		{word: "ведет", expected: "ведет"},
+		{word: "жизнь", expected: "жизн"},
+		{word: "вдр", expected: "вдр"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.word, func(t *testing.T) {
+			assert.Equal(t, tc.expected, stemmer.Stem(tc.word))
+		})
+	}
+
+	// Words in other scripts are left unchanged
+	assert.Equal(t, "learning", stemmer.Stem("learning"))
+	assert.Equal(t, "2024", stemmer.Stem("2024"))
+}
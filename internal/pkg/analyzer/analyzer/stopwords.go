@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"sort"
+	"sync"
+)
+
+// Language identifies a natural language by its ISO 639-1 code
+type Language string
+
+const (
+	// English language
+	English Language = "en"
+
+	// Russian language
+	Russian Language = "ru"
+)
+
+var (
+	stopWordListsMu sync.RWMutex
+
+	// stopWordLists holds the registered stop-word lists by language
+	stopWordLists = map[Language][]string{
+		English: {
+			"a", "an", "the", "and", "or", "but",
+			"is", "are", "was", "were", "be", "been",
+			"in", "on", "at", "to", "for", "with",
+			"by", "of", "about", "from",
+			"this", "that", "these", "those",
+			"it", "its", "it's", "they", "them", "their",
+		},
+
+		// Snowball stop-word list for Russian, with "ё" spellings of its words
+		Russian: {
+			"и", "в", "во", "не", "что", "он", "на", "я", "с", "со", "как", "а",
+			"то", "все", "она", "так", "его", "но", "да", "ты", "к", "у", "же",
+			"вы", "за", "бы", "по", "только", "ее", "её", "мне", "было", "вот", "от",
+			"меня", "еще", "ещё", "нет", "о", "из", "ему", "теперь", "когда", "даже",
+			"ну", "вдруг", "ли", "если", "уже", "или", "ни", "быть", "был", "него",
+			"до", "вас", "нибудь", "опять", "уж", "вам", "ведь", "там", "потом",
+			"себя", "ничего", "ей", "может", "они", "тут", "где", "есть", "надо",
+			"ней", "для", "мы", "тебя", "их", "чем", "была", "сам", "чтоб", "без",
+			"будто", "чего", "раз", "тоже", "себе", "под", "будет", "ж", "тогда",
+			"кто", "этот", "того", "потому", "этого", "какой", "совсем", "ним",
+			"здесь", "этом", "один", "почти", "мой", "тем", "чтобы", "нее", "неё",
+			"сейчас", "были", "куда", "зачем", "всех", "никогда", "можно", "при",
+			"наконец", "два", "об", "другой", "хоть", "после", "над", "больше",
+			"тот", "через", "эти", "нас", "про", "всего", "них", "какая", "много",
+			"разве", "три", "эту", "моя", "впрочем", "хорошо", "свою", "этой",
+			"перед", "иногда", "лучше", "чуть", "том", "нельзя", "такой", "им",
+			"более", "всегда", "конечно", "всю", "между",
+		},
+	}
+)
+
+// RegisterStopWords registers the stop-word list of a language, replacing
+// the previously registered one
+// Words must be lowercase, as they are compared with lowercased text
+func RegisterStopWords(language Language, words []string) {
+	stopWordListsMu.Lock()
+	defer stopWordListsMu.Unlock()
+
+	stopWordLists[language] = append([]string(nil), words...)
+}
+
+// StopWordList returns a copy of the stop-word list registered for a language,
+// or nil if there is none
+func StopWordList(language Language) []string {
+	stopWordListsMu.RLock()
+	defer stopWordListsMu.RUnlock()
+
+	words, ok := stopWordLists[language]
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), words...)
+}
+
+// StopWordLanguages returns the languages with a registered stop-word list, sorted by code
+func StopWordLanguages() []Language {
+	stopWordListsMu.RLock()
+	defer stopWordListsMu.RUnlock()
+
+	languages := make([]Language, 0, len(stopWordLists))
+	for language := range stopWordLists {
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		return languages[i] < languages[j]
+	})
+	return languages
+}
+
+// NewStopWords builds a stop-word set from the lists of the given languages
+// Languages without a registered list are ignored
+func NewStopWords(languages ...Language) map[string]bool {
+	stopWords := make(map[string]bool)
+	for _, language := range languages {
+		for _, word := range StopWordList(language) {
+			stopWords[word] = true
+		}
+	}
+	return stopWords
+}
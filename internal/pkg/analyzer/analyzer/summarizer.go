@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// Summarizer builds extractive summaries with TextRank
+// (Mihalcea and Tarau, "TextRank: Bringing Order into Texts")
+//
+// Sentences are the vertices of a graph whose edges are weighted by the number of stemmed
+// significant words two sentences share, normalized by their lengths. The sentences ranked
+// highest by weighted PageRank make up the summary, in their original order.
+type Summarizer struct {
+	// SentenceCount is the maximum number of sentences in a summary
+	// Default is 3
+	SentenceCount int
+
+	// Damping factor of PageRank
+	// Default is 0.85
+	Damping float64
+
+	// MaxIterations and Tolerance bound the PageRank iterations, which stop once
+	// no score changes by more than Tolerance
+	MaxIterations int
+	Tolerance     float64
+
+	textAnalyzer *TextAnalyzer
+}
+
+// NewSummarizer creates a new Summarizer instance splitting texts with the given TextAnalyzer
+func NewSummarizer(textAnalyzer *TextAnalyzer) *Summarizer {
+	return &Summarizer{
+		SentenceCount: 3,
+		Damping:       0.85,
+		MaxIterations: 100,
+		Tolerance:     1e-6,
+		textAnalyzer:  textAnalyzer,
+	}
+}
+
+// Summarize returns the SentenceCount most important sentences of the content, in their original order
+// Words are compared with the stop words and the stemmer of the language
+func (s *Summarizer) Summarize(content string, language Language) string {
+	sentences := s.splitSentences(content)
+	if len(sentences) <= s.SentenceCount {
+		return strings.Join(sentences, " ")
+	}
+
+	textAnalyzer := s.textAnalyzer.ForLanguage(language)
+	words := make([]map[string]bool, len(sentences))
+	for i, sentence := range sentences {
+		words[i] = make(map[string]bool)
+		for _, word := range textAnalyzer.GetStemmedWords(sentence) {
+			words[i][word] = true
+		}
+	}
+
+	scores := s.rank(s.similarities(words))
+
+	// Take the best ranked sentences, the earlier one on ties
+	order := make([]int, len(sentences))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]] > scores[order[j]]
+	})
+	selected := order[:s.SentenceCount]
+	sort.Ints(selected)
+
+	summary := make([]string, 0, len(selected))
+	for _, i := range selected {
+		summary = append(summary, sentences[i])
+	}
+	return strings.Join(summary, " ")
+}
+
+// splitSentences splits the content into sentences with normalized whitespace
+// Paragraphs always end a sentence, so that headings without a final period stand alone
+func (s *Summarizer) splitSentences(content string) []string {
+	var sentences []string
+	for _, paragraph := range strings.Split(content, "\n\n") {
+		for _, sentence := range s.textAnalyzer.GetSentences(paragraph) {
+			sentences = append(sentences, s.textAnalyzer.RemoveExcessWhitespace(sentence))
+		}
+	}
+	return sentences
+}
+
+// similarities computes the TextRank similarity of every pair of sentences:
+// the number of shared words divided by the sum of the logarithms of the sentence lengths
+func (s *Summarizer) similarities(words []map[string]bool) [][]float64 {
+	weights := make([][]float64, len(words))
+	for i := range weights {
+		weights[i] = make([]float64, len(words))
+	}
+
+	for i := range words {
+		for j := i + 1; j < len(words); j++ {
+			norm := math.Log(float64(len(words[i]))) + math.Log(float64(len(words[j])))
+			if norm <= 0 {
+				continue // Both sentences have at most one word
+			}
+
+			common := 0
+			for word := range words[i] {
+				if words[j][word] {
+					common++
+				}
+			}
+			weights[i][j] = float64(common) / norm
+			weights[j][i] = weights[i][j]
+		}
+	}
+	return weights
+}
+
+// rank computes the weighted PageRank scores of the sentences
+func (s *Summarizer) rank(weights [][]float64) []float64 {
+	n := len(weights)
+
+	outWeights := make([]float64, n)
+	for i := range weights {
+		for _, weight := range weights[i] {
+			outWeights[i] += weight
+		}
+	}
+
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = 1
+	}
+
+	for iteration := 0; iteration < s.MaxIterations; iteration++ {
+		next := make([]float64, n)
+		maxDelta := 0.0
+		for i := range next {
+			sum := 0.0
+			for j := range weights {
+				if weights[j][i] > 0 {
+					sum += weights[j][i] / outWeights[j] * scores[j]
+				}
+			}
+			next[i] = 1 - s.Damping + s.Damping*sum
+			maxDelta = math.Max(maxDelta, math.Abs(next[i]-scores[i]))
+		}
+		scores = next
+		if maxDelta < s.Tolerance {
+			break
+		}
+	}
+	return scores
+}
@@ -0,0 +1,67 @@
+package analyzer_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+)
+
+func TestSummarizer_Summarize(t *testing.T) {
+	// Create a new summarizer
+	summarizer := analyzer.NewSummarizer(analyzer.NewTextAnalyzer())
+	summarizer.SentenceCount = 2
+
+	content := "Machine learning models learn patterns from data.\n\n" +
+		"The weather was nice yesterday. " +
+		"Deep learning models learn patterns from large data sets. " +
+		"Models that learn from data need careful evaluation. " +
+		"My neighbour bought a red bicycle."
+
+	summary := summarizer.Summarize(content, analyzer.English)
+	assert.Equal(t, "Machine learning models learn patterns from data. "+
+		"Deep learning models learn patterns from large data sets.", summary,
+		"The sentences sharing most words should be selected in their original order")
+}
+
+func TestSummarizer_Summarize_ShortText(t *testing.T) {
+	// Create a new summarizer
+	summarizer := analyzer.NewSummarizer(analyzer.NewTextAnalyzer())
+
+	// Texts with few sentences are returned whole with normalized whitespace
+	assert.Equal(t, "Заголовок Первое предложение. Второе предложение!",
+		summarizer.Summarize("Заголовок\n\nПервое  предложение.\nВторое\tпредложение!", analyzer.Russian))
+	assert.Empty(t, summarizer.Summarize("", analyzer.Russian))
+}
+
+func TestSummarizer_Summarize_Corpus(t *testing.T) {
+	// Create a new summarizer
+	summarizer := analyzer.NewSummarizer(analyzer.NewTextAnalyzer())
+
+	// Several texts of the corpus make a document of a dozen sentences
+	var documents []string
+	for _, name := range []string{"01-01.txt", "02-01.txt", "03-01.txt", "04-01.txt"} {
+		document, err := os.ReadFile(filepath.Join("../../../../texts", name))
+		require.NoError(t, err)
+		documents = append(documents, string(document))
+	}
+	content := strings.Join(documents, "\n\n")
+
+	summary := summarizer.Summarize(content, analyzer.Russian)
+	assert.NotEmpty(t, summary)
+	assert.Less(t, len(summary), len(content))
+
+	// The summary consists of sentences of the text
+	textAnalyzer := analyzer.NewTextAnalyzer()
+	sentences := textAnalyzer.GetSentences(summary)
+	assert.LessOrEqual(t, len(sentences), summarizer.SentenceCount)
+	normalized := textAnalyzer.RemoveExcessWhitespace(content)
+	for _, sentence := range sentences {
+		assert.Contains(t, normalized, sentence)
+	}
+}
@@ -1,35 +1,113 @@
+// Package analyzer implements the analysis of document texts:
+//   - statistics of paragraphs, sentences, words and characters, lexical diversity and readability,
+//     by Flesch-Kincaid for English and by Oborneva's adaptation for Russian
+//   - extractive summaries of the most central sentences by TextRank
+//   - keywords ranked by TF-IDF against the analyzed files, and word frequencies
+//   - language detection by character n-gram profiles, Russian and English stop words and Snowball
+//     stemming, so that the forms of a word are counted and compared as one
+//   - normalization undoing obfuscation by NFKC, Latin and Cyrillic homoglyphs and invisible characters
+//   - plagiarism checks by n-gram similarity and containment and by winnowing fingerprints of copied
+//     passages, with MinHash signatures and fingerprints selecting the candidates, see PlagiarismChecker
+//   - word clouds in PNG or SVG, rendered with an embedded font or by the quickchart.io API
 package analyzer
 
 import (
 	"regexp"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // TextAnalyzer provides methods for analyzing text content
 type TextAnalyzer struct {
 	// Common words to ignore in analysis (stop words)
 	StopWords map[string]bool
+
+	// Stemmer reduces inflected forms of significant words to a common stem
+	// A nil Stemmer leaves words unchanged
+	Stemmer Stemmer
+
+	// Detector detects the language of a text
+	Detector *LanguageDetector
+
+	// Normalizer undoes homoglyph and invisible-character obfuscation of words
+	// A nil Normalizer leaves words unchanged
+	Normalizer *TextNormalizer
 }
 
 // NewTextAnalyzer creates a new TextAnalyzer instance
+// It ignores English and Russian stop words and stems Russian words
 func NewTextAnalyzer() *TextAnalyzer {
-	// Initialize with common English stop words
-	stopWords := map[string]bool{
-		"a": true, "an": true, "the": true, "and": true, "or": true, "but": true,
-		"is": true, "are": true, "was": true, "were": true, "be": true, "been": true,
-		"in": true, "on": true, "at": true, "to": true, "for": true, "with": true,
-		"by": true, "of": true, "about": true, "from": true,
-		"this": true, "that": true, "these": true, "those": true,
-		"it": true, "its": true, "it's": true, "they": true, "them": true, "their": true,
+	return NewTextAnalyzerForLanguages(English, Russian)
+}
+
+// NewTextAnalyzerForLanguages creates a new TextAnalyzer instance ignoring the stop words
+// of the given languages
+func NewTextAnalyzerForLanguages(languages ...Language) *TextAnalyzer {
+	return &TextAnalyzer{
+		StopWords:  NewStopWords(languages...),
+		Stemmer:    NewRussianStemmer(),
+		Detector:   NewLanguageDetector(),
+		Normalizer: NewTextNormalizer(),
 	}
+}
+
+// DetectLanguage returns the language of the content, or UnknownLanguage if it cannot be detected
+func (a *TextAnalyzer) DetectLanguage(content string) Language {
+	if a.Detector == nil {
+		return UnknownLanguage
+	}
+	return a.Detector.Detect(content)
+}
 
+// ForLanguage returns a TextAnalyzer using the stop words and the stemmer registered for the language
+// For UnknownLanguage the analyzer itself is returned
+func (a *TextAnalyzer) ForLanguage(language Language) *TextAnalyzer {
+	if language == UnknownLanguage {
+		return a
+	}
 	return &TextAnalyzer{
-		StopWords: stopWords,
+		StopWords:  NewStopWords(language),
+		Stemmer:    StemmerFor(language),
+		Detector:   a.Detector,
+		Normalizer: a.Normalizer,
+	}
+}
+
+// WithStopWords returns a copy of the analyzer ignoring the given stop words
+// A nil map keeps all words
+func (a *TextAnalyzer) WithStopWords(stopWords map[string]bool) *TextAnalyzer {
+	configured := *a
+	configured.StopWords = stopWords
+	return &configured
+}
+
+// WithStemmer returns a copy of the analyzer reducing words with the given stemmer
+// A nil stemmer leaves words unchanged, so that inflected forms are different words
+func (a *TextAnalyzer) WithStemmer(stemmer Stemmer) *TextAnalyzer {
+	configured := *a
+	configured.Stemmer = stemmer
+	return &configured
+}
+
+// InspectObfuscation counts the characters of the content used to hide copied text
+func (a *TextAnalyzer) InspectObfuscation(content string) ObfuscationReport {
+	if a.Normalizer == nil {
+		return ObfuscationReport{}
 	}
+	return a.Normalizer.Inspect(content)
+}
+
+// normalizeWord undoes obfuscation of a lowercase word
+func (a *TextAnalyzer) normalizeWord(word string) string {
+	if a.Normalizer == nil {
+		return word
+	}
+	return strings.ToLower(a.Normalizer.NormalizeWord(word))
 }
 
 // AnalyzeText analyzes text content and returns statistics
+// Characters are counted as Unicode code points, so that Cyrillic letters count once
 func (a *TextAnalyzer) AnalyzeText(content string) (paragraphCount, wordCount, characterCount int32) {
 	paragraphs := strings.Split(content, "\n\n")
 	var nonEmptyParagraphs []string
@@ -43,7 +121,7 @@ func (a *TextAnalyzer) AnalyzeText(content string) (paragraphCount, wordCount, c
 	words := strings.Fields(content)
 	wordCount = int32(len(words))
 
-	characterCount = int32(len(content))
+	characterCount = int32(utf8.RuneCountInString(content))
 
 	return paragraphCount, wordCount, characterCount
 }
@@ -54,6 +132,7 @@ func (a *TextAnalyzer) GetWords(content string) []string {
 }
 
 // GetSignificantWords returns words after removing stop words and punctuation
+// Words are normalized, so that obfuscated words equal the original ones
 func (a *TextAnalyzer) GetSignificantWords(content string) []string {
 	content = strings.ToLower(content)
 
@@ -71,7 +150,8 @@ func (a *TextAnalyzer) GetSignificantWords(content string) []string {
 
 	var significantWords []string
 	for _, word := range words {
-		if !a.StopWords[word] {
+		word = a.normalizeWord(word)
+		if word != "" && !a.StopWords[word] {
 			significantWords = append(significantWords, word)
 		}
 	}
@@ -79,6 +159,192 @@ func (a *TextAnalyzer) GetSignificantWords(content string) []string {
 	return significantWords
 }
 
+// Stem returns the stem of a lowercase word
+func (a *TextAnalyzer) Stem(word string) string {
+	if a.Stemmer == nil {
+		return word
+	}
+	return a.Stemmer.Stem(word)
+}
+
+// GetStemmedWords returns the stems of the significant words of the content
+// Inflected forms of a word ("интеллекта", "интеллектом") share the same stem
+func (a *TextAnalyzer) GetStemmedWords(content string) []string {
+	words := a.GetSignificantWords(content)
+	for i, word := range words {
+		words[i] = a.Stem(word)
+	}
+	return words
+}
+
+// GetStems returns the stems of the token texts
+func (a *TextAnalyzer) GetStems(tokens []Token) []string {
+	stems := make([]string, len(tokens))
+	for i, token := range tokens {
+		stems[i] = a.Stem(token.Text)
+	}
+	return stems
+}
+
+// WordFrequencyOptions selects how the words of a document are counted
+type WordFrequencyOptions struct {
+	// Limit is the number of most frequent words returned, all of them if not positive
+	Limit int
+
+	Case WordCase
+
+	// KeepStopWords disables removal of the stop words of the document language
+	KeepStopWords bool
+
+	// SeparateForms counts inflected forms of a word as different words
+	SeparateForms bool
+}
+
+// GetWordFrequencies counts the significant words of the content for a word cloud,
+// most frequent first, with inflected forms counted as one word
+// Every word is shown in its most frequent form, the first one on ties, written in the given case
+func (a *TextAnalyzer) GetWordFrequencies(content string, wordCase WordCase) []WordItem {
+	tokens := a.GetSignificantTokens(content)
+	words := make([]string, len(tokens))
+	for i, token := range tokens {
+		words[i] = token.Text
+	}
+	representatives := a.representativeForms(words)
+
+	counts := make(map[string]int)
+	for _, word := range words {
+		counts[representatives[a.Stem(word)]]++
+	}
+
+	var spellings map[string]string
+	if wordCase == OriginalCase {
+		spellings = originalSpellings(content, tokens)
+	}
+
+	items := make([]WordItem, 0, len(counts))
+	for word, count := range counts {
+		text := word
+		switch wordCase {
+		case UpperCase:
+			text = strings.ToUpper(word)
+		case OriginalCase:
+			if spelling, ok := spellings[word]; ok {
+				text = spelling
+			}
+		}
+		items = append(items, WordItem{Text: text, Value: count})
+	}
+	sortWordItems(items)
+	return items
+}
+
+// originalSpellings maps every token text to the way it is most often written in the content,
+// the first one on ties
+// Obfuscated spellings are skipped, so that homoglyphs do not end up on a word cloud
+func originalSpellings(content string, tokens []Token) map[string]string {
+	runes := []rune(content)
+	counts := make(map[string]map[string]int)
+	firstSeen := make(map[string]int)
+	for i, token := range tokens {
+		spelling := string(runes[token.Start:token.End])
+		if strings.ToLower(spelling) != token.Text {
+			continue
+		}
+		if counts[token.Text] == nil {
+			counts[token.Text] = make(map[string]int)
+		}
+		counts[token.Text][spelling]++
+		if _, ok := firstSeen[spelling]; !ok {
+			firstSeen[spelling] = i
+		}
+	}
+
+	spellings := make(map[string]string, len(counts))
+	for word, spellingCounts := range counts {
+		best := ""
+		for spelling, count := range spellingCounts {
+			if best == "" || count > spellingCounts[best] || (count == spellingCounts[best] && firstSeen[spelling] < firstSeen[best]) {
+				best = spelling
+			}
+		}
+		spellings[word] = best
+	}
+	return spellings
+}
+
+// representativeForms maps the stem of every word to the most frequent form sharing it,
+// the first one on ties
+func (a *TextAnalyzer) representativeForms(words []string) map[string]string {
+	formCounts := make(map[string]map[string]int)
+	firstSeen := make(map[string]int)
+	for i, word := range words {
+		stem := a.Stem(word)
+		if formCounts[stem] == nil {
+			formCounts[stem] = make(map[string]int)
+		}
+		formCounts[stem][word]++
+		if _, ok := firstSeen[word]; !ok {
+			firstSeen[word] = i
+		}
+	}
+
+	representatives := make(map[string]string, len(formCounts))
+	for stem, counts := range formCounts {
+		best := ""
+		for form, count := range counts {
+			if best == "" || count > counts[best] || (count == counts[best] && firstSeen[form] < firstSeen[best]) {
+				best = form
+			}
+		}
+		representatives[stem] = best
+	}
+	return representatives
+}
+
+// Token is a significant word together with its position in the original content
+// Start and End are character (rune) offsets, End is exclusive
+type Token struct {
+	Text  string
+	Start int
+	End   int
+}
+
+// GetSignificantTokens returns the same words as GetSignificantWords, each with its
+// position in the content, so that matches found on the processed words can be
+// reported in terms of the original text
+func (a *TextAnalyzer) GetSignificantTokens(content string) []Token {
+	var tokens []Token
+	var sb strings.Builder
+	start := -1
+
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		if word := a.normalizeWord(sb.String()); word != "" && !a.StopWords[word] {
+			tokens = append(tokens, Token{Text: word, Start: start, End: end})
+		}
+		sb.Reset()
+		start = -1
+	}
+
+	position := 0
+	for _, r := range content {
+		if unicode.IsPunct(r) || unicode.IsSpace(r) {
+			flush(position)
+		} else {
+			if start < 0 {
+				start = position
+			}
+			sb.WriteRune(unicode.ToLower(r))
+		}
+		position++
+	}
+	flush(position)
+
+	return tokens
+}
+
 // GetNGrams returns n-grams (sequences of n consecutive words) from the content
 func (a *TextAnalyzer) GetNGrams(content string, n int) []string {
 	words := a.GetWords(content)
@@ -1,6 +1,7 @@
 package analyzer_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -55,6 +56,13 @@ func TestTextAnalyzer_AnalyzeText(t *testing.T) {
 			wordCount:       4,
 			characterCount:  28,
 		},
+		{
+			name:            "Cyrillic text",
+			content:         "Привет, мир!",
+			paragraphCount:  1,
+			wordCount:       2,
+			characterCount:  12,
+		},
 	}
 
 	// Run test cases
@@ -155,3 +163,158 @@ func TestTextAnalyzer_RemoveExcessWhitespace(t *testing.T) {
 		})
 	}
 }
+
+func TestTextAnalyzer_GetSignificantTokens(t *testing.T) {
+	// Create a new text analyzer
+	textAnalyzer := analyzer.NewTextAnalyzer()
+
+	content := "The Quick, brown fox — jumps!\nÜber den Fluss."
+	tokens := textAnalyzer.GetSignificantTokens(content)
+
+	// Token texts match the significant words
+	var words []string
+	for _, token := range tokens {
+		words = append(words, token.Text)
+	}
+	assert.Equal(t, textAnalyzer.GetSignificantWords(content), words, "Token texts should match significant words")
+
+	// Offsets point to the original text in characters, not bytes
+	runes := []rune(content)
+	for _, token := range tokens {
+		assert.Equal(t, token.Text, strings.ToLower(string(runes[token.Start:token.End])), "Offsets should cover the word")
+	}
+	assert.Equal(t, analyzer.Token{Text: "über", Start: 30, End: 34}, tokens[4])
+}
+
+func TestTextAnalyzer_GetSignificantWords_Russian(t *testing.T) {
+	// Create a new text analyzer
+	textAnalyzer := analyzer.NewTextAnalyzer()
+
+	words := textAnalyzer.GetSignificantWords("Системы включают в себя машинное обучение и компьютерное зрение.")
+	assert.Equal(t, []string{"системы", "включают", "машинное", "обучение", "компьютерное", "зрение"}, words, "Russian stop words should be removed")
+
+	// Only the stop words of the requested languages are removed
+	englishOnly := analyzer.NewTextAnalyzerForLanguages(analyzer.English)
+	assert.Contains(t, englishOnly.GetSignificantWords("обучение и зрение"), "и")
+}
+
+func TestTextAnalyzer_GetStemmedWords(t *testing.T) {
+	// Create a new text analyzer
+	textAnalyzer := analyzer.NewTextAnalyzer()
+
+	assert.Equal(t,
+		textAnalyzer.GetStemmedWords("Развитие искусственного интеллекта"),
+		textAnalyzer.GetStemmedWords("развитием искусственным интеллектом"),
+		"Inflected forms should have the same stems")
+
+	// Without a stemmer words are left unchanged
+	textAnalyzer.Stemmer = nil
+	assert.Equal(t, []string{"интеллекта"}, textAnalyzer.GetStemmedWords("интеллекта"))
+}
+
+func TestTextAnalyzer_GetWordFrequencies(t *testing.T) {
+	// Create a new text analyzer
+	textAnalyzer := analyzer.NewTextAnalyzer()
+
+	content := "Интеллект и интеллекта, интеллекта; вагон"
+	assert.Equal(t, []analyzer.WordItem{
+		{Text: "интеллекта", Value: 3},
+		{Text: "вагон", Value: 1},
+	}, textAnalyzer.GetWordFrequencies(content, analyzer.LowerCase), "Inflected forms should be merged into the most frequent one")
+
+	assert.Equal(t, []analyzer.WordItem{
+		{Text: "ИНТЕЛЛЕКТА", Value: 3},
+		{Text: "ВАГОН", Value: 1},
+	}, textAnalyzer.GetWordFrequencies(content, analyzer.UpperCase))
+
+	// Words are written as they are most often written in the content
+	content = "NASA и Москва. Nasa, NASA, москва"
+	assert.Equal(t, []analyzer.WordItem{
+		{Text: "NASA", Value: 3},
+		{Text: "Москва", Value: 2},
+	}, textAnalyzer.GetWordFrequencies(content, analyzer.OriginalCase))
+
+	// Stop words are counted when the analyzer keeps them
+	assert.Equal(t, []analyzer.WordItem{
+		{Text: "nasa", Value: 3},
+		{Text: "москва", Value: 2},
+		{Text: "и", Value: 1},
+	}, textAnalyzer.WithStopWords(nil).GetWordFrequencies(content, analyzer.LowerCase))
+	assert.Empty(t, textAnalyzer.GetWordFrequencies(" и, или ", analyzer.LowerCase))
+}
+
+func TestRegisterStopWords(t *testing.T) {
+	const german analyzer.Language = "de"
+	assert.Nil(t, analyzer.StopWordList(german))
+
+	analyzer.RegisterStopWords(german, []string{"und", "der"})
+	assert.Equal(t, []string{"und", "der"}, analyzer.StopWordList(german))
+	assert.Contains(t, analyzer.StopWordLanguages(), german)
+
+	textAnalyzer := analyzer.NewTextAnalyzerForLanguages(german)
+	assert.Equal(t, []string{"hund", "katze"}, textAnalyzer.GetSignificantWords("der Hund und der Katze"))
+}
+
+func TestTextAnalyzer_GetTextMetrics(t *testing.T) {
+	// Create a new text analyzer
+	textAnalyzer := analyzer.NewTextAnalyzer()
+
+	content := "Текст отчёта. Второе предложение текста!\n\nЧто дальше? Конец…"
+	metrics := textAnalyzer.GetTextMetrics(content)
+
+	assert.Equal(t, int32(2), metrics.ParagraphCount)
+	assert.Equal(t, int32(8), metrics.WordCount)
+	assert.Equal(t, int32(60), metrics.CharacterCount, "Characters should be counted as runes, not bytes")
+	assert.Equal(t, int32(52), metrics.CharacterCountNoSpaces)
+	assert.Equal(t, int32(4), metrics.SentenceCount)
+	assert.InDelta(t, 2.0, metrics.AvgSentenceLength, 1e-9)
+	assert.InDelta(t, 6.0, metrics.AvgWordLength, 1e-9)
+	assert.InDelta(t, 1.0, metrics.LexicalDiversity, 1e-9, "Inflected forms are distinct words")
+
+	// Repeated words lower lexical diversity
+	metrics = textAnalyzer.GetTextMetrics("Да, да, да. Нет")
+	assert.Equal(t, int32(2), metrics.SentenceCount)
+	assert.InDelta(t, 0.5, metrics.LexicalDiversity, 1e-9)
+
+	// Whitespace only
+	assert.Equal(t, analyzer.TextMetrics{CharacterCount: 3}, textAnalyzer.GetTextMetrics(" \n "))
+}
+
+func TestTextAnalyzer_GetSentences(t *testing.T) {
+	// Create a new text analyzer
+	textAnalyzer := analyzer.NewTextAnalyzer()
+
+	// Test cases
+	testCases := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "No terminator",
+			content:  "Just one sentence",
+			expected: []string{"Just one sentence"},
+		},
+		{
+			name:     "Decimal numbers and ellipsis",
+			content:  "Pi is 3.14... Or so they say! Really?!",
+			expected: []string{"Pi is 3.14...", "Or so they say!", "Really?!"},
+		},
+		{
+			name:     "Closing quotes",
+			content:  "Он сказал: «Хватит.» И ушёл.",
+			expected: []string{"Он сказал: «Хватит.»", "И ушёл."},
+		},
+		{
+			name:     "Punctuation only",
+			content:  "... !!! ?",
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, textAnalyzer.GetSentences(tc.content))
+		})
+	}
+}
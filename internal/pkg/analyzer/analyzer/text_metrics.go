@@ -0,0 +1,125 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TextMetrics holds statistics of a text
+// Characters are counted as Unicode code points, not bytes
+type TextMetrics struct {
+	ParagraphCount         int32
+	WordCount              int32
+	CharacterCount         int32
+	CharacterCountNoSpaces int32
+	SentenceCount          int32
+
+	// AvgSentenceLength is the average number of words in a sentence
+	AvgSentenceLength float64
+
+	// AvgWordLength is the average number of letters and digits in a word
+	AvgWordLength float64
+
+	// LexicalDiversity is the type/token ratio: the number of distinct words
+	// divided by the number of words (0.0 to 1.0)
+	LexicalDiversity float64
+}
+
+// GetTextMetrics computes statistics of the content
+// Paragraph and word counts are the ones reported by AnalyzeText, while sentence lengths,
+// word lengths and lexical diversity only consider words containing letters or digits
+func (a *TextAnalyzer) GetTextMetrics(content string) TextMetrics {
+	var metrics TextMetrics
+	metrics.ParagraphCount, metrics.WordCount, metrics.CharacterCount = a.AnalyzeText(content)
+
+	for _, r := range content {
+		if !unicode.IsSpace(r) {
+			metrics.CharacterCountNoSpaces++
+		}
+	}
+
+	words := a.GetLexicalWords(content)
+	metrics.SentenceCount = int32(len(a.GetSentences(content)))
+	if len(words) == 0 {
+		return metrics
+	}
+
+	letters := 0
+	distinctWords := make(map[string]bool)
+	for _, word := range words {
+		for _, r := range word {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				letters++
+			}
+		}
+		distinctWords[a.normalizeWord(strings.ToLower(word))] = true
+	}
+
+	metrics.AvgWordLength = float64(letters) / float64(len(words))
+	metrics.LexicalDiversity = float64(len(distinctWords)) / float64(len(words))
+	if metrics.SentenceCount > 0 {
+		metrics.AvgSentenceLength = float64(len(words)) / float64(metrics.SentenceCount)
+	}
+
+	return metrics
+}
+
+// GetLexicalWords returns the words of the content consisting of letters and digits,
+// with punctuation and other symbols removed
+// Hyphenated words and words with apostrophes are split into their parts, while combining
+// marks and invisible characters stay inside words
+func (a *TextAnalyzer) GetLexicalWords(content string) []string {
+	return strings.FieldsFunc(content, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r) && !unicode.Is(unicode.Cf, r)
+	})
+}
+
+// GetSentences splits the content into sentences
+// A sentence ends with ".", "!", "?" or "…" followed by a space or the end of the text,
+// possibly with closing quotes or brackets in between, and must contain a letter or a digit
+func (a *TextAnalyzer) GetSentences(content string) []string {
+	var sentences []string
+	runes := []rune(content)
+
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		if !isSentenceTerminator(runes[i]) {
+			continue
+		}
+
+		// Include repeated terminators and closing punctuation
+		end := i + 1
+		for end < len(runes) && (isSentenceTerminator(runes[end]) || isClosingPunctuation(runes[end])) {
+			end++
+		}
+		if end < len(runes) && !unicode.IsSpace(runes[end]) {
+			i = end - 1
+			continue
+		}
+
+		sentences = appendSentence(sentences, string(runes[start:end]))
+		start, i = end, end-1
+	}
+	return appendSentence(sentences, string(runes[start:]))
+}
+
+// appendSentence appends a trimmed sentence if it contains a letter or a digit
+func appendSentence(sentences []string, sentence string) []string {
+	sentence = strings.TrimSpace(sentence)
+	if strings.IndexFunc(sentence, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) < 0 {
+		return sentences
+	}
+	return append(sentences, sentence)
+}
+
+// isSentenceTerminator reports whether a rune ends a sentence
+func isSentenceTerminator(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '…'
+}
+
+// isClosingPunctuation reports whether a rune may follow a sentence terminator
+func isClosingPunctuation(r rune) bool {
+	return r == '"' || r == '\'' || r == ')' || r == ']' || r == '»' || r == '”' || r == '’'
+}
@@ -0,0 +1,226 @@
+package analyzer
+
+import (
+	"strings"
+)
+
+// Winnower selects document fingerprints with the winnowing algorithm used by MOSS
+// (Schleimer, Wilkerson, Aiken: "Winnowing: Local Algorithms for Document Fingerprinting")
+//
+// Every k-gram of words is hashed, and from every window of consecutive hashes the
+// minimal one is selected as a fingerprint. Two documents sharing a passage of at
+// least K+Window-1 words are guaranteed to share a fingerprint, no matter how long
+// the rest of the documents is, which makes the algorithm suitable for detecting
+// partial copying.
+type Winnower struct {
+	// Number of consecutive words hashed into one k-gram
+	// Matches shorter than K words are ignored as noise
+	// Default is 5
+	K int
+
+	// Number of consecutive k-gram hashes in a winnowing window
+	// Larger values select fewer fingerprints but raise the guaranteed match length
+	// Default is 4
+	Window int
+}
+
+// Fingerprint is a selected k-gram hash and the position of the k-gram's first word
+type Fingerprint struct {
+	Hash     uint64
+	Position int
+}
+
+// NewWinnower creates a new Winnower instance
+func NewWinnower() *Winnower {
+	return &Winnower{
+		K:      5,
+		Window: 4,
+	}
+}
+
+// Fingerprints selects the fingerprints of a sequence of words
+// A document shorter than K words has no fingerprints
+func (w *Winnower) Fingerprints(words []string) []Fingerprint {
+	if w.K <= 0 || len(words) < w.K {
+		return []Fingerprint{}
+	}
+
+	hashes := w.kGramHashes(words)
+
+	window := w.Window
+	if window <= 0 {
+		window = 1
+	}
+	if window > len(hashes) {
+		// Document is shorter than one window, select its global minimum
+		window = len(hashes)
+	}
+
+	var fingerprints []Fingerprint
+	selected := -1
+	for start := 0; start+window <= len(hashes); start++ {
+		// Select the rightmost minimal hash of the window
+		minimum := start
+		for i := start + 1; i < start+window; i++ {
+			if hashes[i] <= hashes[minimum] {
+				minimum = i
+			}
+		}
+
+		if minimum != selected {
+			fingerprints = append(fingerprints, Fingerprint{Hash: hashes[minimum], Position: minimum})
+			selected = minimum
+		}
+	}
+
+	return fingerprints
+}
+
+// Hashes returns the set of hashes of all k-grams of a sequence of words
+// Matching fingerprints against all k-grams of the other document, rather than
+// against its fingerprints only, keeps coverage accurate near passage boundaries
+func (w *Winnower) Hashes(words []string) map[uint64]bool {
+	set := make(map[uint64]bool)
+	if w.K <= 0 || len(words) < w.K {
+		return set
+	}
+	for _, hash := range w.kGramHashes(words) {
+		set[hash] = true
+	}
+	return set
+}
+
+// kGramHashes hashes every k-gram of a sequence of words, in order
+// The caller ensures that there are at least K words
+func (w *Winnower) kGramHashes(words []string) []uint64 {
+	hashes := make([]uint64, len(words)-w.K+1)
+	for i := range hashes {
+		hashes[i] = hashString(strings.Join(words[i:i+w.K], " "))
+	}
+	return hashes
+}
+
+// MatchedRegions returns the regions of words matched against another document
+// Every fingerprint whose hash occurs in otherHashes seeds a region, which is then
+// extended over the neighbouring k-grams that also occur in otherHashes, so that
+// the whole copied passage is covered and not only the sparse fingerprints.
+// Overlapping regions are merged, and the result is ordered by position
+func (w *Winnower) MatchedRegions(words []string, otherHashes map[uint64]bool) []WordRegion {
+	if w.K <= 0 || len(words) < w.K {
+		return []WordRegion{}
+	}
+
+	hashes := w.kGramHashes(words)
+	regions := []WordRegion{}
+	for _, fingerprint := range w.Fingerprints(words) {
+		if !otherHashes[fingerprint.Hash] {
+			continue
+		}
+
+		// Skip seeds inside the previous region, it was already extended over them
+		if len(regions) > 0 && fingerprint.Position < regions[len(regions)-1].End-w.K+1 {
+			continue
+		}
+
+		first, last := fingerprint.Position, fingerprint.Position
+		for first > 0 && otherHashes[hashes[first-1]] {
+			first--
+		}
+		for last < len(hashes)-1 && otherHashes[hashes[last+1]] {
+			last++
+		}
+
+		region := WordRegion{Start: first, End: last + w.K}
+		if len(regions) > 0 && region.Start <= regions[len(regions)-1].End {
+			if region.End > regions[len(regions)-1].End {
+				regions[len(regions)-1].End = region.End
+			}
+			continue
+		}
+		regions = append(regions, region)
+	}
+
+	return regions
+}
+
+// AlignPassages finds the passages of words copied from another document
+// Each matched region is aligned with the other document k-gram by k-gram, taking
+// the longest run of equal k-grams, so a region assembled from several places of
+// the other document is reported as several passages. Passages do not overlap
+// and are ordered by their position in words
+func (w *Winnower) AlignPassages(words, otherWords []string) []AlignedPassage {
+	if w.K <= 0 || len(words) < w.K || len(otherWords) < w.K {
+		return []AlignedPassage{}
+	}
+
+	hashes := w.kGramHashes(words)
+	otherHashes := w.kGramHashes(otherWords)
+
+	// Positions of every k-gram in the other document
+	otherPositions := make(map[uint64][]int)
+	otherSet := make(map[uint64]bool)
+	for position, hash := range otherHashes {
+		otherPositions[hash] = append(otherPositions[hash], position)
+		otherSet[hash] = true
+	}
+
+	passages := []AlignedPassage{}
+	for _, region := range w.MatchedRegions(words, otherSet) {
+		lastKGram := region.End - w.K
+		for i := region.Start; i <= lastKGram; {
+			// Find the longest run of equal k-grams starting at i
+			bestPosition, bestRun := -1, 0
+			for _, position := range otherPositions[hashes[i]] {
+				run := 0
+				for i+run <= lastKGram && position+run < len(otherHashes) && hashes[i+run] == otherHashes[position+run] {
+					run++
+				}
+				if run > bestRun {
+					bestPosition, bestRun = position, run
+				}
+			}
+			if bestRun == 0 {
+				i++
+				continue
+			}
+
+			passage := AlignedPassage{
+				Source: WordRegion{Start: i, End: i + bestRun - 1 + w.K},
+				Other:  WordRegion{Start: bestPosition, End: bestPosition + bestRun - 1 + w.K},
+			}
+
+			// Consecutive runs share up to K-1 words, trim them from the later passage
+			if len(passages) > 0 {
+				if overlap := passages[len(passages)-1].Source.End - passage.Source.Start; overlap > 0 {
+					passage.Source.Start += overlap
+					passage.Other.Start += overlap
+				}
+			}
+			if passage.Source.Len() > 0 {
+				passages = append(passages, passage)
+			}
+
+			i += bestRun
+		}
+	}
+
+	return passages
+}
+
+// AlignedPassage is a passage of words found in both documents
+// Source and Other are the passage's word regions in the checked and the other document
+type AlignedPassage struct {
+	Source WordRegion
+	Other  WordRegion
+}
+
+// WordRegion is a range of word positions [Start, End) in a document
+type WordRegion struct {
+	Start int
+	End   int
+}
+
+// Len returns the number of words in the region
+func (r WordRegion) Len() int {
+	return r.End - r.Start
+}
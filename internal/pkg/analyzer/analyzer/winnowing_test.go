@@ -0,0 +1,104 @@
+package analyzer_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+)
+
+// generateWords returns count distinct words with the given prefix
+func generateWords(prefix string, count int) []string {
+	words := make([]string, count)
+	for i := range words {
+		words[i] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return words
+}
+
+func TestWinnower_Fingerprints(t *testing.T) {
+	winnower := analyzer.NewWinnower()
+
+	// Documents shorter than K words have no fingerprints
+	assert.Empty(t, winnower.Fingerprints(generateWords("word", winnower.K-1)))
+
+	// A document shorter than one window still gets a fingerprint
+	assert.Len(t, winnower.Fingerprints(generateWords("word", winnower.K)), 1)
+
+	// Fingerprints are ordered by position and select at least one hash per window
+	words := generateWords("word", 100)
+	fingerprints := winnower.Fingerprints(words)
+	assert.NotEmpty(t, fingerprints)
+	for i := 1; i < len(fingerprints); i++ {
+		assert.Greater(t, fingerprints[i].Position, fingerprints[i-1].Position, "Positions should increase")
+		assert.LessOrEqual(t, fingerprints[i].Position-fingerprints[i-1].Position, winnower.Window, "Every window should have a fingerprint")
+	}
+}
+
+func TestWinnower_Hashes(t *testing.T) {
+	winnower := analyzer.NewWinnower()
+
+	assert.Empty(t, winnower.Hashes(generateWords("word", winnower.K-1)))
+	assert.Len(t, winnower.Hashes(generateWords("word", 20)), 20-winnower.K+1, "There should be one hash per k-gram")
+}
+
+func TestWinnower_MatchedRegions(t *testing.T) {
+	winnower := analyzer.NewWinnower()
+
+	// A passage of K+Window-1 words shared by otherwise different documents is always matched
+	passage := generateWords("copied", winnower.K+winnower.Window-1)
+	document := append(append(generateWords("original", 50), passage...), generateWords("original", 100)[50:]...)
+	other := append(generateWords("source", 30), passage...)
+
+	regions := winnower.MatchedRegions(document, winnower.Hashes(other))
+	assert.Equal(t, []analyzer.WordRegion{{Start: 50, End: 50 + len(passage)}}, regions, "The whole shared passage should be matched")
+
+	// Two passages are matched as separate regions
+	document = append(append(append(document, generateWords("filler", 20)...), other[:15]...), generateWords("tail", 5)...)
+	regions = winnower.MatchedRegions(document, winnower.Hashes(other))
+	assert.Len(t, regions, 2, "Both shared passages should be matched")
+
+	// Nothing is matched against an unrelated document
+	assert.Empty(t, winnower.MatchedRegions(document, winnower.Hashes(generateWords("unrelated", 100))))
+}
+
+func TestWinnower_AlignPassages(t *testing.T) {
+	winnower := analyzer.NewWinnower()
+
+	// A passage is aligned with its position in the other document
+	passage := generateWords("copied", 12)
+	document := append(append(generateWords("original", 10), passage...), generateWords("tail", 10)...)
+	other := append(generateWords("source", 30), passage...)
+
+	passages := winnower.AlignPassages(document, other)
+	assert.Equal(t, []analyzer.AlignedPassage{
+		{
+			Source: analyzer.WordRegion{Start: 10, End: 22},
+			Other:  analyzer.WordRegion{Start: 30, End: 42},
+		},
+	}, passages)
+
+	// A region assembled from two places of the other document yields two passages
+	first := generateWords("first", 10)
+	second := generateWords("second", 10)
+	document = append(append([]string{}, second...), first...)
+	other = append(append(append([]string{}, first...), generateWords("gap", 10)...), second...)
+
+	passages = winnower.AlignPassages(document, other)
+	assert.Equal(t, []analyzer.AlignedPassage{
+		{
+			Source: analyzer.WordRegion{Start: 0, End: 10},
+			Other:  analyzer.WordRegion{Start: 20, End: 30},
+		},
+		{
+			Source: analyzer.WordRegion{Start: 10, End: 20},
+			Other:  analyzer.WordRegion{Start: 0, End: 10},
+		},
+	}, passages)
+
+	// Nothing is aligned with an unrelated or a too short document
+	assert.Empty(t, winnower.AlignPassages(document, generateWords("unrelated", 50)))
+	assert.Empty(t, winnower.AlignPassages(document, first[:winnower.K-1]))
+}
@@ -6,21 +6,285 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/google/uuid"
+	"image/color"
 	"io"
 	"net/http"
+	"path"
+	"strings"
 )
 
-// WordCloudGenerator provides methods for generating word clouds
-type WordCloudGenerator struct {
+// WordCloudRenderer selects how word clouds are rendered
+type WordCloudRenderer string
+
+const (
+	// LocalRenderer renders word clouds in process, without network access
+	LocalRenderer WordCloudRenderer = "local"
+
+	// HTTPRenderer renders word clouds with an external API
+	HTTPRenderer WordCloudRenderer = "http"
+)
+
+// ParseWordCloudRenderer converts a configuration value to a WordCloudRenderer
+// An empty value selects LocalRenderer
+func ParseWordCloudRenderer(value string) (WordCloudRenderer, error) {
+	switch renderer := WordCloudRenderer(strings.ToLower(strings.TrimSpace(value))); renderer {
+	case "":
+		return LocalRenderer, nil
+	case LocalRenderer, HTTPRenderer:
+		return renderer, nil
+	default:
+		return "", fmt.Errorf("unknown word cloud renderer %q", value)
+	}
+}
+
+// WordCloudFormat is the image format of a word cloud
+type WordCloudFormat string
+
+const (
+	// PNGFormat is a raster PNG image
+	PNGFormat WordCloudFormat = "png"
+
+	// SVGFormat is a scalable SVG image, suitable for embedding into HTML reports
+	SVGFormat WordCloudFormat = "svg"
+)
+
+// ParseWordCloudFormat converts a request value to a WordCloudFormat
+// An empty value selects PNGFormat
+func ParseWordCloudFormat(value string) (WordCloudFormat, error) {
+	switch format := WordCloudFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case "":
+		return PNGFormat, nil
+	case PNGFormat, SVGFormat:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown word cloud format %q", value)
+	}
+}
+
+// WordCloudFormatOf returns the format of a word cloud stored at the location
+// The format is recorded as the extension of the location, clouds stored without
+// a known extension are PNG images, as they were before SVG support
+func WordCloudFormatOf(location string) WordCloudFormat {
+	if strings.EqualFold(path.Ext(location), "."+string(SVGFormat)) {
+		return SVGFormat
+	}
+	return PNGFormat
+}
+
+// WordCloudLocationAs returns the location of a word cloud stored in another format,
+// which differs from the location only by the extension of the format
+func WordCloudLocationAs(location string, format WordCloudFormat) string {
+	return strings.TrimSuffix(location, path.Ext(location)) + "." + string(format)
+}
+
+// Other returns the other format a word cloud is stored in
+func (f WordCloudFormat) Other() WordCloudFormat {
+	if f == SVGFormat {
+		return PNGFormat
+	}
+	return SVGFormat
+}
+
+// ContentType returns the media type of images in the format
+func (f WordCloudFormat) ContentType() string {
+	if f == SVGFormat {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// ColorScheme is a set of colors a word cloud is drawn with
+type ColorScheme string
+
+const (
+	// DefaultColorScheme draws words in distinct colors on a white background
+	DefaultColorScheme ColorScheme = "default"
+
+	// BluesColorScheme draws words in shades of blue on a white background
+	BluesColorScheme ColorScheme = "blues"
+
+	// WarmColorScheme draws words in red, orange and yellow on a white background
+	WarmColorScheme ColorScheme = "warm"
+
+	// GrayscaleColorScheme draws words in shades of gray on a white background, suitable for printing
+	GrayscaleColorScheme ColorScheme = "grayscale"
+
+	// DarkColorScheme draws words in bright colors on a dark background
+	DarkColorScheme ColorScheme = "dark"
+)
+
+// colorPalette holds the colors of a color scheme
+type colorPalette struct {
+	Background color.Color
+	Words      []color.Color
+}
+
+// colorSchemes holds the palettes of the color schemes, word colors are used in turn
+var colorSchemes = map[ColorScheme]colorPalette{
+	DefaultColorScheme: {
+		Background: color.White,
+		Words: []color.Color{
+			color.RGBA{R: 0x1f, G: 0x77, B: 0xb4, A: 0xff},
+			color.RGBA{R: 0xff, G: 0x7f, B: 0x0e, A: 0xff},
+			color.RGBA{R: 0x2c, G: 0xa0, B: 0x2c, A: 0xff},
+			color.RGBA{R: 0xd6, G: 0x27, B: 0x28, A: 0xff},
+			color.RGBA{R: 0x94, G: 0x67, B: 0xbd, A: 0xff},
+			color.RGBA{R: 0x8c, G: 0x56, B: 0x4b, A: 0xff},
+		},
+	},
+	BluesColorScheme: {
+		Background: color.White,
+		Words: []color.Color{
+			color.RGBA{R: 0x08, G: 0x30, B: 0x6b, A: 0xff},
+			color.RGBA{R: 0x08, G: 0x51, B: 0x9c, A: 0xff},
+			color.RGBA{R: 0x21, G: 0x71, B: 0xb5, A: 0xff},
+			color.RGBA{R: 0x42, G: 0x92, B: 0xc6, A: 0xff},
+			color.RGBA{R: 0x6b, G: 0xae, B: 0xd6, A: 0xff},
+		},
+	},
+	WarmColorScheme: {
+		Background: color.White,
+		Words: []color.Color{
+			color.RGBA{R: 0xbd, G: 0x00, B: 0x26, A: 0xff},
+			color.RGBA{R: 0xe3, G: 0x1a, B: 0x1c, A: 0xff},
+			color.RGBA{R: 0xfc, G: 0x4e, B: 0x2a, A: 0xff},
+			color.RGBA{R: 0xfd, G: 0x8d, B: 0x3c, A: 0xff},
+			color.RGBA{R: 0xe6, G: 0xab, B: 0x02, A: 0xff},
+		},
+	},
+	GrayscaleColorScheme: {
+		Background: color.White,
+		Words: []color.Color{
+			color.RGBA{R: 0x25, G: 0x25, B: 0x25, A: 0xff},
+			color.RGBA{R: 0x52, G: 0x52, B: 0x52, A: 0xff},
+			color.RGBA{R: 0x73, G: 0x73, B: 0x73, A: 0xff},
+			color.RGBA{R: 0x96, G: 0x96, B: 0x96, A: 0xff},
+		},
+	},
+	DarkColorScheme: {
+		Background: color.RGBA{R: 0x1e, G: 0x1e, B: 0x2e, A: 0xff},
+		Words: []color.Color{
+			color.RGBA{R: 0x89, G: 0xb4, B: 0xfa, A: 0xff},
+			color.RGBA{R: 0xa6, G: 0xe3, B: 0xa1, A: 0xff},
+			color.RGBA{R: 0xf9, G: 0xe2, B: 0xaf, A: 0xff},
+			color.RGBA{R: 0xf3, G: 0x8b, B: 0xa8, A: 0xff},
+			color.RGBA{R: 0xcb, G: 0xa6, B: 0xf7, A: 0xff},
+		},
+	},
+}
+
+// ParseColorScheme converts a request value to a ColorScheme
+// An empty value selects DefaultColorScheme
+func ParseColorScheme(value string) (ColorScheme, error) {
+	scheme := ColorScheme(strings.ToLower(strings.TrimSpace(value)))
+	if scheme == "" {
+		return DefaultColorScheme, nil
+	}
+	if _, ok := colorSchemes[scheme]; !ok {
+		return "", fmt.Errorf("unknown color scheme %q", value)
+	}
+	return scheme, nil
+}
+
+// WordCase selects the case words are written in on a word cloud
+type WordCase string
+
+const (
+	// LowerCase writes all words in lowercase
+	LowerCase WordCase = "lower"
+
+	// UpperCase writes all words in uppercase
+	UpperCase WordCase = "upper"
+
+	// OriginalCase writes every word as it is most often written in the document,
+	// so that names and abbreviations keep their capitals
+	OriginalCase WordCase = "original"
+)
+
+// ParseWordCase converts a request value to a WordCase
+// An empty value selects LowerCase
+func ParseWordCase(value string) (WordCase, error) {
+	switch wordCase := WordCase(strings.ToLower(strings.TrimSpace(value))); wordCase {
+	case "":
+		return LowerCase, nil
+	case LowerCase, UpperCase, OriginalCase:
+		return wordCase, nil
+	default:
+		return "", fmt.Errorf("unknown word case %q", value)
+	}
+}
+
+// Limits of the word cloud options
+const (
+	MinWordCloudSize  = 64
+	MaxWordCloudSize  = 4096
+	MaxWordCloudWords = 1000
+)
+
+// WordCloudOptions holds the settings of a word cloud requested with an analysis
+// Zero values select the defaults of the generator
+type WordCloudOptions struct {
+	Format WordCloudFormat `json:"format,omitempty"`
+
+	// Image size in pixels
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+
+	// MaxWords is the number of most frequent words rendered
+	MaxWords int `json:"max_words,omitempty"`
+
+	ColorScheme ColorScheme `json:"color_scheme,omitempty"`
+
+	// Case and KeepStopWords select how the words are counted, they are applied
+	// before the frequencies are passed to a generator
+	Case WordCase `json:"case,omitempty"`
+
+	// KeepStopWords disables removal of the stop words of the document language
+	KeepStopWords bool `json:"keep_stop_words,omitempty"`
+}
+
+// Validate checks that the options are within the limits
+func (o WordCloudOptions) Validate() error {
+	if o.Width != 0 && (o.Width < MinWordCloudSize || o.Width > MaxWordCloudSize) {
+		return fmt.Errorf("width must be between %d and %d pixels", MinWordCloudSize, MaxWordCloudSize)
+	}
+	if o.Height != 0 && (o.Height < MinWordCloudSize || o.Height > MaxWordCloudSize) {
+		return fmt.Errorf("height must be between %d and %d pixels", MinWordCloudSize, MaxWordCloudSize)
+	}
+	if o.MaxWords < 0 || o.MaxWords > MaxWordCloudWords {
+		return fmt.Errorf("max words must be between 1 and %d", MaxWordCloudWords)
+	}
+	return nil
+}
+
+// WordCloudGenerator generates word cloud images
+type WordCloudGenerator interface {
+	// GenerateWordCloud generates a word cloud image from the word frequencies
+	// and returns it together with a unique location to store it at
+	// The extension of the location is the format of the image
+	GenerateWordCloud(ctx context.Context, words []WordItem, options WordCloudOptions) ([]byte, string, error)
+}
+
+// NewWordCloudGenerator creates a word cloud generator using the given renderer
+// apiURL is only used by HTTPRenderer
+func NewWordCloudGenerator(renderer WordCloudRenderer, apiURL string) WordCloudGenerator {
+	if renderer == HTTPRenderer {
+		return NewHTTPWordCloudGenerator(apiURL)
+	}
+	return NewLocalWordCloudGenerator()
+}
+
+// HTTPWordCloudGenerator generates word clouds with an external API compatible with quickchart.io
+type HTTPWordCloudGenerator struct {
 	apiURL string
 }
 
-// NewWordCloudGenerator creates a new WordCloudGenerator instance
-func NewWordCloudGenerator(apiURL string) *WordCloudGenerator {
+// NewHTTPWordCloudGenerator creates a new HTTPWordCloudGenerator instance
+func NewHTTPWordCloudGenerator(apiURL string) *HTTPWordCloudGenerator {
 	if apiURL == "" {
 		apiURL = "https://quickchart.io/wordcloud"
 	}
-	return &WordCloudGenerator{
+	return &HTTPWordCloudGenerator{
 		apiURL: apiURL,
 	}
 }
@@ -32,18 +296,62 @@ type WordItem struct {
 }
 
 // GenerateWordCloud generates a word cloud image from the given words
-func (g *WordCloudGenerator) GenerateWordCloud(ctx context.Context, text string) ([]byte, string, error) {
+// The API splits the text itself, so every word is repeated as many times as it occurs
+func (g *HTTPWordCloudGenerator) GenerateWordCloud(ctx context.Context, words []WordItem, options WordCloudOptions) ([]byte, string, error) {
+	format := options.Format
+	if format == "" {
+		format = PNGFormat
+	}
+
+	width, height := options.Width, options.Height
+	if width == 0 {
+		width = 1024
+	}
+	if height == 0 {
+		height = 1024
+	}
+
+	palette, ok := colorSchemes[options.ColorScheme]
+	if !ok {
+		palette = colorSchemes[DefaultColorScheme]
+	}
+	colors := make([]string, len(palette.Words))
+	for i, c := range palette.Words {
+		colors[i] = hexColor(c)
+	}
+
+	var text strings.Builder
+	for _, word := range words {
+		for i := 0; i < word.Value; i++ {
+			if text.Len() > 0 {
+				text.WriteByte(' ')
+			}
+			text.WriteString(word.Text)
+		}
+	}
+
 	// Prepare the request payload
+	// Words are already counted and cased, so the API must not change them
 	requestData := struct {
-		Width  int    `json:"width"`
-		Height int    `json:"height"`
-		Text   string `json:"text"`
-		Format string `json:"format"`
+		Width           int      `json:"width"`
+		Height          int      `json:"height"`
+		Text            string   `json:"text"`
+		Format          string   `json:"format"`
+		MaxNumWords     int      `json:"maxNumWords,omitempty"`
+		Colors          []string `json:"colors"`
+		BackgroundColor string   `json:"backgroundColor"`
+		Case            string   `json:"case"`
+		RemoveStopwords bool     `json:"removeStopwords"`
 	}{
-		Width:  1024,
-		Height: 1024,
-		Text:   text,
-		Format: "png",
+		Width:           width,
+		Height:          height,
+		Text:            text.String(),
+		Format:          string(format),
+		MaxNumWords:     options.MaxWords,
+		Colors:          colors,
+		BackgroundColor: hexColor(palette.Background),
+		Case:            "none",
+		RemoveStopwords: false,
 	}
 
 	// Convert to JSON
@@ -79,7 +387,7 @@ func (g *WordCloudGenerator) GenerateWordCloud(ctx context.Context, text string)
 	}
 
 	// Generate a unique location for the image
-	location := uuid.New().String() + ".png"
+	location := uuid.New().String() + "." + string(format)
 
 	return imageData, location, nil
 }
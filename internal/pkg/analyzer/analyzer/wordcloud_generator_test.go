@@ -2,6 +2,7 @@ package analyzer_test
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -11,18 +12,149 @@ import (
 	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
 )
 
-func TestWordCloudGenerator_NewWordCloudGenerator(t *testing.T) {
+func TestParseWordCloudRenderer(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected analyzer.WordCloudRenderer
+		wantErr  bool
+	}{
+		{value: "", expected: analyzer.LocalRenderer},
+		{value: "local", expected: analyzer.LocalRenderer},
+		{value: " HTTP ", expected: analyzer.HTTPRenderer},
+		{value: "cairo", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := analyzer.ParseWordCloudRenderer(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestParseWordCloudFormat(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected analyzer.WordCloudFormat
+		wantErr  bool
+	}{
+		{value: "", expected: analyzer.PNGFormat},
+		{value: "png", expected: analyzer.PNGFormat},
+		{value: " SVG ", expected: analyzer.SVGFormat},
+		{value: "jpeg", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := analyzer.ParseWordCloudFormat(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestWordCloudFormatOf(t *testing.T) {
+	assert.Equal(t, analyzer.SVGFormat, analyzer.WordCloudFormatOf("b7a1c9.svg"))
+	assert.Equal(t, analyzer.SVGFormat, analyzer.WordCloudFormatOf("clouds/b7a1c9.SVG"))
+	assert.Equal(t, analyzer.PNGFormat, analyzer.WordCloudFormatOf("b7a1c9.png"))
+	assert.Equal(t, analyzer.PNGFormat, analyzer.WordCloudFormatOf("b7a1c9"), "Clouds without an extension are PNG images")
+
+	assert.Equal(t, "b7a1c9.svg", analyzer.WordCloudLocationAs("b7a1c9.png", analyzer.SVGFormat))
+	assert.Equal(t, "clouds/b7a1c9.png", analyzer.WordCloudLocationAs("clouds/b7a1c9.svg", analyzer.PNGFormat))
+	assert.Equal(t, "b7a1c9.svg", analyzer.WordCloudLocationAs("b7a1c9", analyzer.SVGFormat))
+	assert.Equal(t, analyzer.SVGFormat, analyzer.PNGFormat.Other())
+	assert.Equal(t, analyzer.PNGFormat, analyzer.SVGFormat.Other())
+
+	assert.Equal(t, "image/svg+xml", analyzer.SVGFormat.ContentType())
+	assert.Equal(t, "image/png", analyzer.PNGFormat.ContentType())
+}
+
+func TestParseColorScheme(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected analyzer.ColorScheme
+		wantErr  bool
+	}{
+		{value: "", expected: analyzer.DefaultColorScheme},
+		{value: "blues", expected: analyzer.BluesColorScheme},
+		{value: " Dark ", expected: analyzer.DarkColorScheme},
+		{value: "rainbow", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := analyzer.ParseColorScheme(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestParseWordCase(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected analyzer.WordCase
+		wantErr  bool
+	}{
+		{value: "", expected: analyzer.LowerCase},
+		{value: "upper", expected: analyzer.UpperCase},
+		{value: "Original", expected: analyzer.OriginalCase},
+		{value: "title", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := analyzer.ParseWordCase(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestWordCloudOptions_Validate(t *testing.T) {
+	assert.NoError(t, analyzer.WordCloudOptions{}.Validate(), "Zero options select the defaults")
+	assert.NoError(t, analyzer.WordCloudOptions{Width: 800, Height: 600, MaxWords: 50}.Validate())
+
+	assert.Error(t, analyzer.WordCloudOptions{Width: 10}.Validate())
+	assert.Error(t, analyzer.WordCloudOptions{Height: 10000}.Validate())
+	assert.Error(t, analyzer.WordCloudOptions{MaxWords: -1}.Validate())
+	assert.Error(t, analyzer.WordCloudOptions{MaxWords: analyzer.MaxWordCloudWords + 1}.Validate())
+}
+
+func TestNewWordCloudGenerator(t *testing.T) {
+	assert.IsType(t, &analyzer.LocalWordCloudGenerator{}, analyzer.NewWordCloudGenerator(analyzer.LocalRenderer, ""))
+	assert.IsType(t, &analyzer.HTTPWordCloudGenerator{}, analyzer.NewWordCloudGenerator(analyzer.HTTPRenderer, "https://example.com/wordcloud"))
+}
+
+func TestHTTPWordCloudGenerator_NewHTTPWordCloudGenerator(t *testing.T) {
 	// Test with custom URL
 	customURL := "https://example.com/wordcloud"
-	generator := analyzer.NewWordCloudGenerator(customURL)
+	generator := analyzer.NewHTTPWordCloudGenerator(customURL)
 	assert.NotNil(t, generator, "Generator should not be nil")
 
 	// Test with empty URL (should use default)
-	generator = analyzer.NewWordCloudGenerator("")
+	generator = analyzer.NewHTTPWordCloudGenerator("")
 	assert.NotNil(t, generator, "Generator should not be nil")
 }
 
-func TestWordCloudGenerator_GenerateWordCloud(t *testing.T) {
+func TestHTTPWordCloudGenerator_GenerateWordCloud(t *testing.T) {
 	// Create a mock server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check request method
@@ -39,10 +171,10 @@ func TestWordCloudGenerator_GenerateWordCloud(t *testing.T) {
 	defer server.Close()
 
 	// Create a generator with the mock server URL
-	generator := analyzer.NewWordCloudGenerator(server.URL)
+	generator := analyzer.NewHTTPWordCloudGenerator(server.URL)
 
 	// Test generating a word cloud
-	imageData, location, err := generator.GenerateWordCloud(context.Background(), "This is a test text for word cloud generation")
+	imageData, location, err := generator.GenerateWordCloud(context.Background(), analyzer.CountWords("This is a test text for word cloud generation"), analyzer.WordCloudOptions{})
 	
 	// Assertions
 	assert.NoError(t, err, "Should not return an error")
@@ -51,7 +183,77 @@ func TestWordCloudGenerator_GenerateWordCloud(t *testing.T) {
 	assert.Contains(t, location, ".png", "Location should be a PNG file")
 }
 
-func TestWordCloudGenerator_GenerateWordCloud_ServerError(t *testing.T) {
+func TestHTTPWordCloudGenerator_GenerateWordCloud_SVG(t *testing.T) {
+	// Create a mock server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Check the requested format
+		var request struct {
+			Format string `json:"format"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "svg", request.Format, "SVG format should be requested")
+
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("<svg></svg>"))
+	}))
+	defer server.Close()
+
+	// Create a generator with the mock server URL
+	generator := analyzer.NewHTTPWordCloudGenerator(server.URL)
+
+	// Test generating a word cloud
+	imageData, location, err := generator.GenerateWordCloud(context.Background(), analyzer.CountWords("This is a test text"), analyzer.WordCloudOptions{Format: analyzer.SVGFormat})
+
+	// Assertions
+	assert.NoError(t, err, "Should not return an error")
+	assert.Equal(t, []byte("<svg></svg>"), imageData, "Image data should match")
+	assert.Equal(t, analyzer.SVGFormat, analyzer.WordCloudFormatOf(location), "Location should be an SVG file")
+}
+
+func TestHTTPWordCloudGenerator_GenerateWordCloud_Options(t *testing.T) {
+	// Create a mock server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Check that the options and the counted words are sent
+		var request struct {
+			Width           int      `json:"width"`
+			Height          int      `json:"height"`
+			Text            string   `json:"text"`
+			MaxNumWords     int      `json:"maxNumWords"`
+			Colors          []string `json:"colors"`
+			BackgroundColor string   `json:"backgroundColor"`
+			Case            string   `json:"case"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, 800, request.Width)
+		assert.Equal(t, 600, request.Height)
+		assert.Equal(t, "Москва Москва NASA", request.Text, "Words should be repeated by their counts")
+		assert.Equal(t, 20, request.MaxNumWords)
+		assert.NotEmpty(t, request.Colors)
+		assert.Equal(t, "#1e1e2e", request.BackgroundColor)
+		assert.Equal(t, "none", request.Case, "Words should be sent in their case")
+
+		w.Header().Set("Content-Type", "image/png")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("mock-image-data"))
+	}))
+	defer server.Close()
+
+	// Create a generator with the mock server URL
+	generator := analyzer.NewHTTPWordCloudGenerator(server.URL)
+
+	// Test generating a word cloud
+	words := []analyzer.WordItem{{Text: "Москва", Value: 2}, {Text: "NASA", Value: 1}}
+	imageData, _, err := generator.GenerateWordCloud(context.Background(), words, analyzer.WordCloudOptions{
+		Width: 800, Height: 600, MaxWords: 20, ColorScheme: analyzer.DarkColorScheme,
+	})
+
+	// Assertions
+	assert.NoError(t, err, "Should not return an error")
+	assert.Equal(t, []byte("mock-image-data"), imageData, "Image data should match")
+}
+
+func TestHTTPWordCloudGenerator_GenerateWordCloud_ServerError(t *testing.T) {
 	// Create a mock server that returns an error
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -60,10 +262,10 @@ func TestWordCloudGenerator_GenerateWordCloud_ServerError(t *testing.T) {
 	defer server.Close()
 
 	// Create a generator with the mock server URL
-	generator := analyzer.NewWordCloudGenerator(server.URL)
+	generator := analyzer.NewHTTPWordCloudGenerator(server.URL)
 
 	// Test generating a word cloud with server error
-	imageData, location, err := generator.GenerateWordCloud(context.Background(), "This is a test text")
+	imageData, location, err := generator.GenerateWordCloud(context.Background(), analyzer.CountWords("This is a test text"), analyzer.WordCloudOptions{})
 	
 	// Assertions
 	assert.Error(t, err, "Should return an error")
@@ -71,12 +273,12 @@ func TestWordCloudGenerator_GenerateWordCloud_ServerError(t *testing.T) {
 	assert.Empty(t, location, "Location should be empty")
 }
 
-func TestWordCloudGenerator_GenerateWordCloud_InvalidURL(t *testing.T) {
+func TestHTTPWordCloudGenerator_GenerateWordCloud_InvalidURL(t *testing.T) {
 	// Create a generator with an invalid URL
-	generator := analyzer.NewWordCloudGenerator("http://invalid-url-that-does-not-exist.example")
+	generator := analyzer.NewHTTPWordCloudGenerator("http://invalid-url-that-does-not-exist.example")
 
 	// Test generating a word cloud with an invalid URL
-	imageData, location, err := generator.GenerateWordCloud(context.Background(), "This is a test text")
+	imageData, location, err := generator.GenerateWordCloud(context.Background(), analyzer.CountWords("This is a test text"), analyzer.WordCloudOptions{})
 	
 	// Assertions
 	assert.Error(t, err, "Should return an error")
@@ -84,16 +286,16 @@ func TestWordCloudGenerator_GenerateWordCloud_InvalidURL(t *testing.T) {
 	assert.Empty(t, location, "Location should be empty")
 }
 
-func TestWordCloudGenerator_GenerateWordCloud_CanceledContext(t *testing.T) {
+func TestHTTPWordCloudGenerator_GenerateWordCloud_CanceledContext(t *testing.T) {
 	// Create a generator
-	generator := analyzer.NewWordCloudGenerator("")
+	generator := analyzer.NewHTTPWordCloudGenerator("")
 
 	// Create a canceled context
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel the context immediately
 
 	// Test generating a word cloud with a canceled context
-	imageData, location, err := generator.GenerateWordCloud(ctx, "This is a test text")
+	imageData, location, err := generator.GenerateWordCloud(ctx, analyzer.CountWords("This is a test text"), analyzer.WordCloudOptions{})
 	
 	// Assertions
 	assert.Error(t, err, "Should return an error")
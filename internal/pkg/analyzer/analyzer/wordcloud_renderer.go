@@ -0,0 +1,382 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// ErrNoWords is returned when a word cloud is requested for a text without words
+var ErrNoWords = errors.New("no words to render")
+
+var (
+	wordCloudFontOnce sync.Once
+	wordCloudFont     *opentype.Font
+	wordCloudFontErr  error
+)
+
+// loadWordCloudFont parses the font embedded in the binary
+// Go Bold covers Latin and Cyrillic, so that both English and Russian words are rendered
+func loadWordCloudFont() (*opentype.Font, error) {
+	wordCloudFontOnce.Do(func() {
+		wordCloudFont, wordCloudFontErr = opentype.Parse(gobold.TTF)
+	})
+	return wordCloudFont, wordCloudFontErr
+}
+
+// LocalWordCloudGenerator renders word clouds in process, without network access
+// Words are sized by frequency and placed along an Archimedean spiral from the center
+// of the image, so that the most frequent words are in the middle
+type LocalWordCloudGenerator struct {
+	Width  int
+	Height int
+
+	// MaxWords is the number of most frequent words rendered
+	MaxWords int
+
+	// Font sizes in pixels of the most and the least frequent words
+	// Words that do not fit are shrunk down to MinFontSize and skipped if they still do not fit
+	MaxFontSize float64
+	MinFontSize float64
+
+	// Padding is the minimal distance in pixels between words
+	Padding int
+
+	Background color.Color
+	Palette    []color.Color
+}
+
+// NewLocalWordCloudGenerator creates a new LocalWordCloudGenerator instance
+// with the same image size as the HTTP generator
+func NewLocalWordCloudGenerator() *LocalWordCloudGenerator {
+	palette := colorSchemes[DefaultColorScheme]
+	return &LocalWordCloudGenerator{
+		Width:       1024,
+		Height:      1024,
+		MaxWords:    150,
+		MaxFontSize: 140,
+		MinFontSize: 12,
+		Padding:     2,
+		Background:  palette.Background,
+		Palette:     palette.Words,
+	}
+}
+
+// withOptions returns a copy of the generator configured by the options of a request
+// The largest font size follows the image size, so that clouds of any size look alike
+func (g *LocalWordCloudGenerator) withOptions(options WordCloudOptions) *LocalWordCloudGenerator {
+	configured := *g
+	if options.Width > 0 {
+		configured.Width = options.Width
+	}
+	if options.Height > 0 {
+		configured.Height = options.Height
+	}
+	if g.Width > 0 && g.Height > 0 {
+		configured.MaxFontSize *= math.Min(
+			float64(configured.Width)/float64(g.Width),
+			float64(configured.Height)/float64(g.Height),
+		)
+	}
+	if options.MaxWords > 0 {
+		configured.MaxWords = options.MaxWords
+	}
+	if palette, ok := colorSchemes[options.ColorScheme]; ok {
+		configured.Background = palette.Background
+		configured.Palette = palette.Words
+	}
+	return &configured
+}
+
+// PlacedWord is a word of a word cloud with its size, position and color
+type PlacedWord struct {
+	Text     string
+	Count    int
+	FontSize float64
+
+	// Bounds of the rendered text in the image
+	Bounds image.Rectangle
+
+	// Baseline is the origin of the text, where drawing starts
+	Baseline image.Point
+
+	// Advance is the width of the text in pixels, including the spacing around the glyphs
+	Advance float64
+
+	Color color.Color
+}
+
+// CountWords counts the whitespace-separated words of the text, most frequent first,
+// with words of equal counts in alphabetical order
+func CountWords(text string) []WordItem {
+	counts := make(map[string]int)
+	for _, word := range strings.Fields(text) {
+		counts[word]++
+	}
+
+	items := make([]WordItem, 0, len(counts))
+	for word, count := range counts {
+		items = append(items, WordItem{Text: word, Value: count})
+	}
+	sortWordItems(items)
+	return items
+}
+
+// sortWordItems sorts words by frequency, most frequent first,
+// with words of equal counts in alphabetical order
+func sortWordItems(items []WordItem) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Value != items[j].Value {
+			return items[i].Value > items[j].Value
+		}
+		return items[i].Text < items[j].Text
+	})
+}
+
+// Layout places the words, most frequent first, without overlaps
+// Words that do not fit into the image are left out
+func (g *LocalWordCloudGenerator) Layout(ctx context.Context, words []WordItem) ([]PlacedWord, error) {
+	items := make([]WordItem, 0, len(words))
+	for _, word := range words {
+		if word.Value > 0 && strings.TrimSpace(word.Text) != "" {
+			items = append(items, word)
+		}
+	}
+	sortWordItems(items)
+	if len(items) == 0 {
+		return nil, ErrNoWords
+	}
+	if g.MaxWords > 0 && len(items) > g.MaxWords {
+		items = items[:g.MaxWords]
+	}
+
+	f, err := loadWordCloudFont()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+
+	maxCount := items[0].Value
+	placed := make([]PlacedWord, 0, len(items))
+	occupied := make([]image.Rectangle, 0, len(items))
+	lastHit := 0
+
+	// scale shrinks all words when the most frequent one is too large for the image,
+	// so that larger words still mean more frequent ones
+	scale := 1.0
+
+	for i, item := range items {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// Font size grows with the square root of the frequency, so that
+		// areas of the words are roughly proportional to their counts
+		minSize := math.Max(g.MinFontSize, 1)
+		size := scale * g.MaxFontSize * math.Sqrt(float64(item.Value)/float64(maxCount))
+		size = math.Max(size, minSize)
+
+		for size >= minSize {
+			word, ok, err := g.place(f, item.Text, size, occupied, &lastHit)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				word.Count = item.Value
+				if len(g.Palette) > 0 {
+					word.Color = g.Palette[i%len(g.Palette)]
+				} else {
+					word.Color = color.Black
+				}
+				if i == 0 {
+					scale = size / g.MaxFontSize
+				}
+				placed = append(placed, word)
+				occupied = append(occupied, word.Bounds.Inset(-g.Padding))
+				break
+			}
+			size *= 0.8
+		}
+	}
+
+	return placed, nil
+}
+
+// place looks for a free position for a word of the given size along the spiral
+// lastHit is the index of the last rectangle a word collided with, it is checked first
+// as the next positions on the spiral usually collide with it too
+func (g *LocalWordCloudGenerator) place(f *opentype.Font, text string, size float64, occupied []image.Rectangle, lastHit *int) (PlacedWord, bool, error) {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return PlacedWord{}, false, fmt.Errorf("failed to create font face: %w", err)
+	}
+	defer face.Close()
+
+	textBounds, advance := font.BoundString(face, text)
+	width := (textBounds.Max.X - textBounds.Min.X).Ceil()
+	height := (textBounds.Max.Y - textBounds.Min.Y).Ceil()
+	if width > g.Width || height > g.Height {
+		return PlacedWord{}, false, nil
+	}
+
+	canvas := image.Rect(0, 0, g.Width, g.Height)
+	centerX, centerY := float64(g.Width)/2, float64(g.Height)/2
+
+	// The spiral is stretched along the longer side of the image and ends
+	// when it has passed the corners
+	scaleX := centerX / math.Max(centerX, centerY)
+	scaleY := centerY / math.Max(centerX, centerY)
+	maxRadius := math.Max(centerX/scaleX, centerY/scaleY) * math.Sqrt2
+
+	const (
+		angleStep = 0.1
+		spacing   = 6.0 // distance between the turns of the spiral in pixels
+	)
+	for angle := 0.0; ; angle += angleStep {
+		radius := spacing * angle / (2 * math.Pi)
+		if radius > maxRadius {
+			return PlacedWord{}, false, nil
+		}
+
+		x := int(centerX+radius*math.Cos(angle)*scaleX) - width/2
+		y := int(centerY+radius*math.Sin(angle)*scaleY) - height/2
+		bounds := image.Rect(x, y, x+width, y+height)
+		if !bounds.In(canvas) || collides(bounds, occupied, lastHit) {
+			continue
+		}
+
+		return PlacedWord{
+			Text:     text,
+			FontSize: size,
+			Bounds:   bounds,
+			Baseline: image.Pt(x-textBounds.Min.X.Floor(), y-textBounds.Min.Y.Floor()),
+			Advance:  float64(advance) / 64,
+		}, true, nil
+	}
+}
+
+// collides reports whether the rectangle overlaps any of the occupied ones
+func collides(bounds image.Rectangle, occupied []image.Rectangle, lastHit *int) bool {
+	if *lastHit < len(occupied) && bounds.Overlaps(occupied[*lastHit]) {
+		return true
+	}
+	for i, rect := range occupied {
+		if bounds.Overlaps(rect) {
+			*lastHit = i
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateWordCloud renders a word cloud of the words as configured by the options
+func (g *LocalWordCloudGenerator) GenerateWordCloud(ctx context.Context, words []WordItem, options WordCloudOptions) ([]byte, string, error) {
+	format := options.Format
+	if format == "" {
+		format = PNGFormat
+	}
+	g = g.withOptions(options)
+
+	placed, err := g.Layout(ctx, words)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to lay out words: %w", err)
+	}
+
+	var imageData []byte
+	switch format {
+	case PNGFormat:
+		imageData, err = g.renderPNG(placed)
+	case SVGFormat:
+		imageData, err = g.renderSVG(placed)
+	default:
+		err = fmt.Errorf("unknown word cloud format %q", format)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Generate a unique location for the image
+	location := uuid.New().String() + "." + string(format)
+
+	return imageData, location, nil
+}
+
+// renderPNG draws the placed words with the embedded font and encodes the image as PNG
+func (g *LocalWordCloudGenerator) renderPNG(words []PlacedWord) ([]byte, error) {
+	f, err := loadWordCloudFont()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, g.Width, g.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(g.Background), image.Point{}, draw.Src)
+
+	for _, word := range words {
+		face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: word.FontSize, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create font face: %w", err)
+		}
+
+		drawer := &font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(word.Color),
+			Face: face,
+			Dot:  fixed.P(word.Baseline.X, word.Baseline.Y),
+		}
+		drawer.DrawString(word.Text)
+		face.Close()
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// svgFontFamily lists the font the layout is computed with, followed by similar fallbacks
+const svgFontFamily = "Go, 'DejaVu Sans', Verdana, Arial, sans-serif"
+
+// renderSVG writes the placed words as SVG text elements
+// The font is not embedded, so every word is stretched to the width it has in the layout,
+// which keeps words from overlapping when a viewer substitutes another font
+func (g *LocalWordCloudGenerator) renderSVG(words []PlacedWord) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		g.Width, g.Height, g.Width, g.Height)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hexColor(g.Background))
+	fmt.Fprintf(&buf, `<g font-family="%s" font-weight="bold">`+"\n", svgFontFamily)
+
+	for _, word := range words {
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" font-size="%.1f" fill="%s" textLength="%.1f" lengthAdjust="spacingAndGlyphs">`,
+			word.Baseline.X, word.Baseline.Y, word.FontSize, hexColor(word.Color), word.Advance)
+		if err := xml.EscapeText(&buf, []byte(word.Text)); err != nil {
+			return nil, fmt.Errorf("failed to escape word: %w", err)
+		}
+		buf.WriteString("</text>\n")
+	}
+
+	buf.WriteString("</g>\n</svg>\n")
+	return buf.Bytes(), nil
+}
+
+// svgColor formats a color as an SVG hex color, ignoring transparency
+func hexColor(c color.Color) string {
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	return fmt.Sprintf("#%02x%02x%02x", rgba.R, rgba.G, rgba.B)
+}
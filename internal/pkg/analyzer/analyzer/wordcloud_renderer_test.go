@@ -0,0 +1,173 @@
+package analyzer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+)
+
+func TestCountWords(t *testing.T) {
+	items := analyzer.CountWords("beta alpha beta  gamma\nalpha beta")
+	assert.Equal(t, []analyzer.WordItem{
+		{Text: "beta", Value: 3},
+		{Text: "alpha", Value: 2},
+		{Text: "gamma", Value: 1},
+	}, items)
+
+	assert.Empty(t, analyzer.CountWords(" \n "))
+}
+
+func TestLocalWordCloudGenerator_Layout(t *testing.T) {
+	generator := analyzer.NewLocalWordCloudGenerator()
+	generator.Width, generator.Height = 400, 300
+
+	text := strings.Repeat("интеллект ", 10) + strings.Repeat("обучение ", 5) + "зрение data science"
+	words, err := generator.Layout(context.Background(), analyzer.CountWords(text))
+	require.NoError(t, err)
+	require.Len(t, words, 5)
+
+	// The most frequent word is the largest and the closest to the center
+	assert.Equal(t, "интеллект", words[0].Text)
+	assert.Equal(t, 10, words[0].Count)
+	for _, word := range words[1:] {
+		assert.Less(t, word.FontSize, words[0].FontSize)
+	}
+	assert.True(t, words[0].Bounds.Min.X < 200 && words[0].Bounds.Max.X > 200, "The most frequent word should cross the center")
+
+	// Words stay inside the image and do not overlap
+	canvas := image.Rect(0, 0, 400, 300)
+	for i, word := range words {
+		assert.True(t, word.Bounds.In(canvas), "%s should be inside the image", word.Text)
+		for _, other := range words[i+1:] {
+			assert.False(t, word.Bounds.Overlaps(other.Bounds), "%s and %s should not overlap", word.Text, other.Text)
+		}
+	}
+}
+
+func TestLocalWordCloudGenerator_Layout_LeavesOutWordsThatDoNotFit(t *testing.T) {
+	generator := analyzer.NewLocalWordCloudGenerator()
+	generator.Width, generator.Height = 100, 40
+	generator.MaxFontSize, generator.MinFontSize = 30, 20
+
+	words, err := generator.Layout(context.Background(), analyzer.CountWords("one two three four five six seven eight"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, words)
+	assert.Less(t, len(words), 8)
+
+	generator.MaxWords = 1
+	words, err = generator.Layout(context.Background(), analyzer.CountWords("one two two"))
+	require.NoError(t, err)
+	require.Len(t, words, 1)
+	assert.Equal(t, "two", words[0].Text)
+}
+
+func TestLocalWordCloudGenerator_GenerateWordCloud(t *testing.T) {
+	generator := analyzer.NewLocalWordCloudGenerator()
+	generator.Width, generator.Height = 320, 240
+
+	imageData, location, err := generator.GenerateWordCloud(context.Background(), analyzer.CountWords("облако облако слов cloud of words"), analyzer.WordCloudOptions{})
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(location, ".png"), "Location should be a PNG file")
+
+	img, err := png.Decode(bytes.NewReader(imageData))
+	require.NoError(t, err, "Image should be a valid PNG")
+	assert.Equal(t, image.Rect(0, 0, 320, 240), img.Bounds())
+
+	// Words are drawn over the white background
+	drawn := 0
+	for y := 0; y < 240; y++ {
+		for x := 0; x < 320; x++ {
+			if r, g, b, _ := img.At(x, y).RGBA(); r != 0xffff || g != 0xffff || b != 0xffff {
+				drawn++
+			}
+		}
+	}
+	assert.Greater(t, drawn, 500, "Words should be drawn")
+}
+
+func TestLocalWordCloudGenerator_GenerateWordCloud_SVG(t *testing.T) {
+	generator := analyzer.NewLocalWordCloudGenerator()
+	generator.Width, generator.Height = 320, 240
+
+	text := "облако облако <слов> & cloud"
+	imageData, location, err := generator.GenerateWordCloud(context.Background(), analyzer.CountWords(text), analyzer.WordCloudOptions{Format: analyzer.SVGFormat})
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(location, ".svg"), "Location should be an SVG file")
+
+	// The image is well-formed XML with a text element per placed word
+	var svg struct {
+		XMLName xml.Name `xml:"svg"`
+		Width   int      `xml:"width,attr"`
+		Height  int      `xml:"height,attr"`
+		Texts   []string `xml:"g>text"`
+	}
+	require.NoError(t, xml.Unmarshal(imageData, &svg), "Image should be valid SVG")
+	assert.Equal(t, 320, svg.Width)
+	assert.Equal(t, 240, svg.Height)
+
+	words, err := generator.Layout(context.Background(), analyzer.CountWords(text))
+	require.NoError(t, err)
+	require.Len(t, svg.Texts, len(words))
+	assert.Equal(t, "облако", svg.Texts[0])
+	assert.Contains(t, svg.Texts, "<слов>", "Special characters should be escaped")
+	assert.Contains(t, svg.Texts, "&")
+}
+
+func TestLocalWordCloudGenerator_GenerateWordCloud_Options(t *testing.T) {
+	generator := analyzer.NewLocalWordCloudGenerator()
+
+	words := analyzer.CountWords("облако облако облако слов слов cloud of words")
+	imageData, _, err := generator.GenerateWordCloud(context.Background(), words, analyzer.WordCloudOptions{
+		Width:       300,
+		Height:      200,
+		MaxWords:    2,
+		ColorScheme: analyzer.DarkColorScheme,
+		Format:      analyzer.SVGFormat,
+	})
+	require.NoError(t, err)
+
+	var svg struct {
+		Width  int `xml:"width,attr"`
+		Height int `xml:"height,attr"`
+		Rect   struct {
+			Fill string `xml:"fill,attr"`
+		} `xml:"rect"`
+		Texts []string `xml:"g>text"`
+	}
+	require.NoError(t, xml.Unmarshal(imageData, &svg), "Image should be valid SVG")
+	assert.Equal(t, 300, svg.Width)
+	assert.Equal(t, 200, svg.Height)
+	assert.Equal(t, "#1e1e2e", svg.Rect.Fill, "Background should follow the color scheme")
+	assert.Equal(t, []string{"облако", "слов"}, svg.Texts, "Only the most frequent words should be rendered")
+
+	// The options do not change the generator
+	assert.Equal(t, 1024, generator.Width)
+	assert.Equal(t, 150, generator.MaxWords)
+}
+
+func TestLocalWordCloudGenerator_GenerateWordCloud_Errors(t *testing.T) {
+	generator := analyzer.NewLocalWordCloudGenerator()
+
+	// No words
+	imageData, location, err := generator.GenerateWordCloud(context.Background(), nil, analyzer.WordCloudOptions{})
+	assert.ErrorIs(t, err, analyzer.ErrNoWords)
+	assert.Nil(t, imageData)
+	assert.Empty(t, location)
+
+	// Canceled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	imageData, location, err = generator.GenerateWordCloud(ctx, analyzer.CountWords("This is a test text"), analyzer.WordCloudOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, imageData)
+	assert.Empty(t, location)
+}
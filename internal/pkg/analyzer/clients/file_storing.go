@@ -1,8 +1,10 @@
 package clients
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"local.dev/doc-analyzer/internal/pkg/grpcConn"
 	"time"
 
@@ -12,6 +14,9 @@ import (
 	pb "local.dev/doc-analyzer/internal/proto/storage"
 )
 
+// streamTimeout bounds the retrieval of a file, which is streamed in chunks
+const streamTimeout = 2 * time.Minute
+
 // FileStoringClientInterface defines the interface for the File Storing Client
 type FileStoringClientInterface interface {
 	GetFile(ctx context.Context, fileID string) (string, []byte, error)
@@ -65,19 +70,41 @@ func (c *FileStoringClient) Close() error {
 	return nil
 }
 
-// GetFile retrieves a file from the File Storing Service
+// GetFile retrieves the text of a file from the File Storing Service
+// The text is received in chunks, so that texts above the gRPC message size limit can be analyzed
 func (c *FileStoringClient) GetFile(ctx context.Context, fileID string) (string, []byte, error) {
 	// Set a timeout for the request
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, streamTimeout)
 	defer cancel()
 
 	// Make the request
-	resp, err := c.client.GetFile(ctx, &pb.GetFileRequest{
+	stream, err := c.client.GetFileStream(ctx, &pb.GetFileRequest{
 		FileId: fileID,
 	})
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to get file: %w", err)
 	}
 
-	return resp.FileName, resp.Content, nil
+	// The first chunk carries the file name, the following ones the content
+	var fileName string
+	var content bytes.Buffer
+	for first := true; ; first = false {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			if first {
+				return "", nil, fmt.Errorf("failed to get file: stream closed before the file name")
+			}
+			break
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get file: %w", err)
+		}
+
+		if first {
+			fileName = chunk.FileName
+		}
+		content.Write(chunk.Content)
+	}
+
+	return fileName, content.Bytes(), nil
 }
@@ -3,17 +3,40 @@ package clients_test
 import (
 	"context"
 	"errors"
+	"io"
 	"local.dev/doc-analyzer/internal/pkg/grpcConn"
 	"net"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"local.dev/doc-analyzer/internal/pkg/analyzer/clients"
 	pb "local.dev/doc-analyzer/internal/proto/storage"
 	"local.dev/doc-analyzer/internal/proto/storage/mocks"
 )
 
+// fakeFileChunkStream returns its chunks and then its error, io.EOF if it has none
+type fakeFileChunkStream struct {
+	grpc.ClientStream
+	chunks []*pb.FileChunk
+	err    error
+}
+
+func (s *fakeFileChunkStream) Recv() (*pb.FileChunk, error) {
+	if len(s.chunks) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
+
 func TestFileStoringClientImpl_GetFile(t *testing.T) {
 	// Create mock client
 	mockClient := new(mocks.MockFileStoringServiceClient)
@@ -21,15 +44,16 @@ func TestFileStoringClientImpl_GetFile(t *testing.T) {
 	// Create client with mock
 	client := clients.NewFileStoringClientWithClient(mockClient, nil)
 
-	// Test case: successful get
+	// Test case: successful get, the text is received in chunks
 	t.Run("Successful get", func(t *testing.T) {
 		// Set up mock expectations
-		mockClient.On("GetFile", mock.Anything, &pb.GetFileRequest{
+		mockClient.On("GetFileStream", mock.Anything, &pb.GetFileRequest{
 			FileId: "file123",
-		}).Return(&pb.GetFileResponse{
-			FileName: "test.txt",
-			Content:  []byte("test content"),
-		}, nil).Once()
+		}).Return(&fakeFileChunkStream{chunks: []*pb.FileChunk{
+			{FileName: "test.txt"},
+			{Content: []byte("test ")},
+			{Content: []byte("content")},
+		}}, nil).Once()
 
 		// Call the method
 		fileName, content, err := client.GetFile(context.Background(), "file123")
@@ -45,7 +69,7 @@ func TestFileStoringClientImpl_GetFile(t *testing.T) {
 	// Test case: error from service
 	t.Run("Error from service", func(t *testing.T) {
 		// Set up mock expectations
-		mockClient.On("GetFile", mock.Anything, &pb.GetFileRequest{
+		mockClient.On("GetFileStream", mock.Anything, &pb.GetFileRequest{
 			FileId: "file456",
 		}).Return(nil, errors.New("connection error")).Once()
 
@@ -58,6 +82,26 @@ func TestFileStoringClientImpl_GetFile(t *testing.T) {
 
 		mockClient.AssertExpectations(t)
 	})
+
+	// Test case: the stream breaks after the first chunks
+	t.Run("Stream error", func(t *testing.T) {
+		// Set up mock expectations
+		mockClient.On("GetFileStream", mock.Anything, &pb.GetFileRequest{
+			FileId: "file789",
+		}).Return(&fakeFileChunkStream{
+			chunks: []*pb.FileChunk{{FileName: "test.txt"}, {Content: []byte("test ")}},
+			err:    status.Error(codes.NotFound, "file not found"),
+		}, nil).Once()
+
+		// Call the method
+		_, _, err := client.GetFile(context.Background(), "file789")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		mockClient.AssertExpectations(t)
+	})
 }
 
 func TestNewFileStoringClient(t *testing.T) {
@@ -0,0 +1,134 @@
+package models
+
+// AnalysisResult holds the results of a file analysis
+type AnalysisResult struct {
+	FileID            string
+	ParagraphCount    int32
+	WordCount         int32
+	CharacterCount    int32
+	IsPlagiarism      bool
+	SimilarFiles      []SimilarFile
+	WordCloudLocation string
+
+	// WordCloudFormat is the image format of the word cloud ("png" or "svg"),
+	// empty if no word cloud was generated
+	WordCloudFormat string
+
+	// Language is the ISO 639-1 code of the detected language, empty if it was not detected
+	Language string
+
+	// Text metrics, characters are counted as Unicode code points
+	CharacterCountNoSpaces int32
+	SentenceCount          int32
+	AvgSentenceLength      float64 // words per sentence
+	AvgWordLength          float64 // letters and digits per word
+	LexicalDiversity       float64 // type/token ratio (0.0 to 1.0)
+
+	// Readability indices and the formula used for the text's language,
+	// the formula is empty if the language has none
+	ReadabilityFormula string
+	ReadingEase        float64
+	GradeLevel         float64
+
+	// Summary is an extractive summary made of the text's most important sentences
+	Summary string
+
+	// ObfuscationDetected is set when the text contains homoglyphs or invisible characters,
+	// which are used to hide copied text from plagiarism checks
+	ObfuscationDetected bool
+	HomoglyphCount      int32
+	InvisibleCharCount  int32
+}
+
+// SimilarFileIDs returns the IDs of the similar files in their current order
+func (r *AnalysisResult) SimilarFileIDs() []string {
+	ids := make([]string, 0, len(r.SimilarFiles))
+	for _, similarFile := range r.SimilarFiles {
+		ids = append(ids, similarFile.FileID)
+	}
+	return ids
+}
+
+// SimilarFile describes a previously analyzed file that is similar to the analyzed one
+type SimilarFile struct {
+	FileID string
+
+	// Jaccard similarity of the files' n-gram sets (0.0 to 1.0), 1.0 for exact copies
+	Score float64
+
+	// Metric that flagged the file: "exact", "jaccard", "containment" or "winnowing"
+	Metric string
+
+	// Share of the analyzed file's n-grams found in the similar file (0.0 to 1.0)
+	Containment float64
+
+	// Share of the similar file's n-grams found in the analyzed file (0.0 to 1.0)
+	SimilarContainment float64
+
+	// Size of n-grams and the similarity threshold Score was compared with
+	NGramSize int32
+	Threshold float64
+
+	// Version of the comparison algorithm, scores of different versions are not comparable
+	AlgorithmVersion int32
+
+	// Share of the analyzed document's significant words covered by
+	// winnowing fingerprints that also occur in the similar file (0.0 to 1.0)
+	Coverage float64
+
+	// Number of significant words of the analyzed document covered by matched fingerprints
+	MatchedWords int32
+
+	// Passages of the analyzed document copied from the similar file
+	Passages []MatchedPassage
+}
+
+// MatchedPassage is a passage found in both the analyzed and the similar file
+// Offsets are in characters (runes) of the original texts, ends are exclusive
+type MatchedPassage struct {
+	Start        int32
+	End          int32
+	SimilarStart int32
+	SimilarEnd   int32
+
+	// Passage text as it appears in the analyzed file
+	Text string
+}
+
+// FingerprintMatch is an indexed file sharing winnowing fingerprints with a checked file
+type FingerprintMatch struct {
+	FileID string
+
+	// Number of fingerprints the file shares with the checked file
+	Shared int32
+
+	// Number of distinct fingerprints of the file
+	Fingerprints int32
+}
+
+// TermCount is the number of occurrences of a term in a document
+// Inflected forms of a word are counted as one term
+type TermCount struct {
+	// Term is the stem shared by the inflected forms
+	Term string
+
+	// Word is the most frequent of the inflected forms, shown instead of the stem
+	Word string
+
+	Count int32
+}
+
+// Keyword is a term of a document ranked by TF-IDF against all analyzed documents
+type Keyword struct {
+	Term string
+	Word string
+
+	// Number of occurrences of the term in the document
+	Count int32
+
+	// Number of analyzed documents containing the term
+	DocumentFrequency int32
+
+	// TF-IDF score, higher for terms frequent in the document and rare in the corpus
+	Score float64
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// JobStatus is the state of an analysis job
+type JobStatus string
+
+const (
+	// JobQueued jobs wait for a free worker
+	JobQueued JobStatus = "queued"
+
+	// JobRunning jobs are being analyzed by a worker
+	JobRunning JobStatus = "running"
+
+	// JobDone jobs have finished, the analysis results are saved for the file
+	JobDone JobStatus = "done"
+
+	// JobFailed jobs have finished with an error
+	JobFailed JobStatus = "failed"
+)
+
+// AnalysisJob is a file analysis requested asynchronously and run by a worker
+type AnalysisJob struct {
+	ID     string
+	FileID string
+
+	GenerateWordCloud bool
+
+	// WordCloudOptions are the JSON-encoded word cloud options of the request
+	WordCloudOptions []byte
+
+	// WebhookURL is notified when the job finishes, besides the registered webhooks
+	WebhookURL string
+
+	Status JobStatus
+
+	// Error is the reason a failed job has failed
+	Error string
+
+	// Attempts is the number of times a worker has started the job,
+	// jobs of stopped workers are started again
+	Attempts int32
+
+	// Times of the job events, zero if the event has not happened yet
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	// Result holds the analysis results of a done job, it is not stored with the job
+	Result *AnalysisResult
+}
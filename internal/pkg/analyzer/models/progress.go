@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// AnalysisStage is a stage of a file analysis
+type AnalysisStage string
+
+const (
+	// StageQueued analyses wait for a free worker
+	StageQueued AnalysisStage = "queued"
+
+	// StageFetchingFile analyses fetch the file from the File Storing Service
+	StageFetchingFile AnalysisStage = "fetching_file"
+
+	// StageAnalyzingText analyses compute the text metrics, the language and the summary
+	StageAnalyzingText AnalysisStage = "analyzing_text"
+
+	// StageComparing analyses compare the file with the candidate documents for plagiarism
+	StageComparing AnalysisStage = "comparing"
+
+	// StageGeneratingWordCloud analyses render the word cloud
+	StageGeneratingWordCloud AnalysisStage = "generating_word_cloud"
+
+	// StageSaving analyses save the analysis results
+	StageSaving AnalysisStage = "saving"
+
+	// StageDone analyses have finished, the analysis results are saved for the file
+	StageDone AnalysisStage = "done"
+
+	// StageFailed analyses have finished with an error
+	StageFailed AnalysisStage = "failed"
+)
+
+// Finished reports whether the stage ends an analysis
+func (s AnalysisStage) Finished() bool {
+	return s == StageDone || s == StageFailed
+}
+
+// AnalysisProgress is an event of a running file analysis
+type AnalysisProgress struct {
+	FileID string
+	Stage  AnalysisStage
+
+	// Current and Total count the steps of the stage, the compared documents
+	// for StageComparing, both are zero for stages without steps
+	Current int32
+	Total   int32
+
+	// Error is the reason a failed analysis has failed
+	Error string
+
+	Time time.Time
+}
@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// WebhookEvent is the kind of event a webhook is notified of
+type WebhookEvent string
+
+const (
+	// EventAnalysisCompleted is sent when the analysis of a file finishes
+	EventAnalysisCompleted WebhookEvent = "analysis.completed"
+
+	// EventAnalysisFailed is sent when the analysis of a file fails
+	EventAnalysisFailed WebhookEvent = "analysis.failed"
+
+	// EventPlagiarismDetected is sent, besides EventAnalysisCompleted, when an analyzed file is flagged as plagiarism
+	EventPlagiarismDetected WebhookEvent = "plagiarism.detected"
+)
+
+// WebhookEvents lists all the webhook events
+var WebhookEvents = []WebhookEvent{EventAnalysisCompleted, EventAnalysisFailed, EventPlagiarismDetected}
+
+// Webhook is a URL registered to be notified of analysis events
+type Webhook struct {
+	ID  string
+	URL string
+
+	// Secret is the key of the HMAC signature of the payloads
+	Secret string
+
+	// Events the webhook is notified of
+	Events []WebhookEvent
+
+	CreatedAt time.Time
+}
+
+// DeliveryStatus is the state of a webhook delivery
+type DeliveryStatus string
+
+const (
+	// DeliveryPending deliveries wait for their next attempt
+	DeliveryPending DeliveryStatus = "pending"
+
+	// DeliveryDelivered deliveries were accepted by the receiver
+	DeliveryDelivered DeliveryStatus = "delivered"
+
+	// DeliveryFailed deliveries were not accepted after all attempts
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// WebhookDelivery is an event payload sent, or to be sent, to a webhook URL
+type WebhookDelivery struct {
+	ID string
+
+	// WebhookID is the registered webhook the payload is sent to,
+	// empty for the webhook URL of an analysis request
+	WebhookID string
+	URL       string
+
+	Event  WebhookEvent
+	FileID string
+
+	// Payload is the JSON body sent to the URL
+	Payload []byte
+
+	Status   DeliveryStatus
+	Attempts int32
+
+	// ResponseStatus is the HTTP status of the last attempt, 0 if there was no response
+	ResponseStatus int32
+
+	// Error is the reason the last attempt has failed
+	Error string
+
+	CreatedAt     time.Time
+	NextAttemptAt time.Time
+
+	// LastAttemptAt is zero until the first attempt
+	LastAttemptAt time.Time
+}
+
+// WebhookDeliveryFilter selects the webhook deliveries listed, empty fields match all deliveries
+type WebhookDeliveryFilter struct {
+	WebhookID string
+	FileID    string
+
+	// Limit is the maximum number of deliveries, newest first
+	Limit int
+}
@@ -2,22 +2,119 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
 )
 
+// ErrNotFound is returned, wrapped, when a requested record does not exist
+var ErrNotFound = errors.New("not found")
+
 // AnalysisRepository defines the interface for analysis results operations
 type AnalysisRepository interface {
 	// SaveAnalysisResult saves analysis results to the database
-	SaveAnalysisResult(ctx context.Context, fileID string, paragraphCount, wordCount, characterCount int32, isPlagiarism bool, wordCloudLocation string) error
+	SaveAnalysisResult(ctx context.Context, result *models.AnalysisResult) error
 	
 	// GetAnalysisResult retrieves analysis results by file ID
-	GetAnalysisResult(ctx context.Context, fileID string) (paragraphCount, wordCount, characterCount int32, isPlagiarism bool, wordCloudLocation string, err error)
+	// Similar files are not loaded, use GetSimilarFiles for them
+	GetAnalysisResult(ctx context.Context, fileID string) (*models.AnalysisResult, error)
 	
 	// SaveSimilarFile saves information about a similar file (for plagiarism detection)
-	SaveSimilarFile(ctx context.Context, fileID, similarFileID string) error
+	// together with the passages copied from it
+	SaveSimilarFile(ctx context.Context, fileID string, similarFile models.SimilarFile) error
 	
-	// GetSimilarFiles retrieves IDs of similar files for a given file ID
-	GetSimilarFiles(ctx context.Context, fileID string) ([]string, error)
+	// GetSimilarFiles retrieves similar files for a given file ID
+	GetSimilarFiles(ctx context.Context, fileID string) ([]models.SimilarFile, error)
+
+	// GetMatchedPassages retrieves the passages of a file copied from a similar file
+	GetMatchedPassages(ctx context.Context, fileID, similarFileID string) ([]models.MatchedPassage, error)
 	
 	// GetAllFileIDs retrieves all file IDs in the database
 	GetAllFileIDs(ctx context.Context) ([]string, error)
-}
\ No newline at end of file
+	
+	// SaveSignature saves the MinHash signature of a file, computed by the given algorithm version,
+	// and indexes it in the LSH band buckets and its winnowing fingerprints in the fingerprint index
+	SaveSignature(ctx context.Context, fileID string, signature []uint64, bandKeys []int64, fingerprints []uint64, algorithmVersion int32) error
+	
+	// FindCandidates retrieves IDs of files sharing at least one LSH band bucket with the given keys
+	FindCandidates(ctx context.Context, bandKeys []int64) ([]string, error)
+
+	// FindFingerprintMatches retrieves the files sharing at least one of the winnowing fingerprints,
+	// with the number of shared fingerprints
+	FindFingerprintMatches(ctx context.Context, fingerprints []uint64) ([]models.FingerprintMatch, error)
+	
+	// GetUnindexedFileIDs retrieves IDs of analyzed files that have no MinHash signature
+	// computed by the given algorithm version, or no indexed fingerprints
+	// Files whose indexing has failed are left out until their next attempt is due
+	GetUnindexedFileIDs(ctx context.Context, algorithmVersion int32) ([]string, error)
+
+	// RecordIndexFailure records a failed indexing of a file, its next attempt is due after retryDelay,
+	// doubled for each earlier failed attempt up to maxRetryDelay
+	// The failure is cleared when the signature of the file is saved
+	RecordIndexFailure(ctx context.Context, fileID string, reason string, retryDelay, maxRetryDelay time.Duration) error
+
+	// SaveTermCounts saves the term counts of a file and updates the corpus document frequencies
+	SaveTermCounts(ctx context.Context, fileID string, terms []models.TermCount) error
+
+	// GetTermCounts retrieves the term counts of a file
+	GetTermCounts(ctx context.Context, fileID string) ([]models.TermCount, error)
+
+	// GetDocumentFrequencies retrieves the number of files containing each of the terms,
+	// together with the number of files in the corpus
+	GetDocumentFrequencies(ctx context.Context, terms []string) (map[string]int32, int32, error)
+
+	// CreateAnalysisJob saves a new queued analysis job
+	CreateAnalysisJob(ctx context.Context, job *models.AnalysisJob) error
+
+	// ClaimAnalysisJob marks the oldest queued job as running and returns it,
+	// nil if there are no queued jobs
+	// Concurrent workers never claim the same job
+	ClaimAnalysisJob(ctx context.Context) (*models.AnalysisJob, error)
+
+	// FinishAnalysisJob marks a running job as done or failed with the given error
+	FinishAnalysisJob(ctx context.Context, jobID string, status models.JobStatus, errorMessage string) error
+
+	// GetAnalysisJob retrieves an analysis job by its ID, the error wraps ErrNotFound if there is none
+	GetAnalysisJob(ctx context.Context, jobID string) (*models.AnalysisJob, error)
+
+	// RequeueStaleAnalysisJobs queues again the jobs running for longer than staleAfter, which
+	// were left by stopped workers, and fails those already started maxAttempts times
+	// It returns the number of requeued and failed jobs
+	RequeueStaleAnalysisJobs(ctx context.Context, staleAfter time.Duration, maxAttempts int32) (int64, error)
+}
+
+// WebhookRepository defines the interface for webhook registrations and deliveries
+type WebhookRepository interface {
+	// CreateWebhook saves a new webhook registration
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+
+	// GetWebhook retrieves a webhook by its ID, the error wraps ErrNotFound if there is none
+	GetWebhook(ctx context.Context, webhookID string) (*models.Webhook, error)
+
+	// ListWebhooks retrieves all webhooks, oldest first
+	ListWebhooks(ctx context.Context) ([]models.Webhook, error)
+
+	// GetWebhooksForEvent retrieves the webhooks notified of the event
+	GetWebhooksForEvent(ctx context.Context, event models.WebhookEvent) ([]models.Webhook, error)
+
+	// DeleteWebhook deletes a webhook, the error wraps ErrNotFound if there is none
+	// Its pending deliveries fail on their next attempt
+	DeleteWebhook(ctx context.Context, webhookID string) error
+
+	// CreateWebhookDeliveries saves new pending deliveries, due immediately
+	CreateWebhookDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error
+
+	// ClaimWebhookDelivery returns the pending delivery due the earliest, nil if none is due,
+	// and counts an attempt for it
+	// The delivery is not due again for the lease, so concurrent dispatchers never claim it
+	// while it is sent, and it is retried if its dispatcher stops
+	ClaimWebhookDelivery(ctx context.Context, lease time.Duration) (*models.WebhookDelivery, error)
+
+	// RecordWebhookAttempt saves the outcome of a delivery attempt: the status, response status,
+	// error and next attempt time of the delivery
+	RecordWebhookAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+
+	// ListWebhookDeliveries retrieves the deliveries matching the filter, newest first
+	ListWebhookDeliveries(ctx context.Context, filter models.WebhookDeliveryFilter) ([]models.WebhookDelivery, error)
+}
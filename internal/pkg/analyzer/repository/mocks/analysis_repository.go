@@ -2,7 +2,9 @@ package mocks
 
 import (
 	"context"
+	"time"
 	"github.com/stretchr/testify/mock"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
 	"local.dev/doc-analyzer/internal/pkg/analyzer/repository"
 )
 
@@ -15,30 +17,42 @@ type MockAnalysisRepository struct {
 var _ repository.AnalysisRepository = (*MockAnalysisRepository)(nil)
 
 // SaveAnalysisResult mocks the SaveAnalysisResult method
-func (m *MockAnalysisRepository) SaveAnalysisResult(ctx context.Context, fileID string, paragraphCount, wordCount, characterCount int32, isPlagiarism bool, wordCloudLocation string) error {
-	args := m.Called(ctx, fileID, paragraphCount, wordCount, characterCount, isPlagiarism, wordCloudLocation)
+func (m *MockAnalysisRepository) SaveAnalysisResult(ctx context.Context, result *models.AnalysisResult) error {
+	args := m.Called(ctx, result)
 	return args.Error(0)
 }
 
 // GetAnalysisResult mocks the GetAnalysisResult method
-func (m *MockAnalysisRepository) GetAnalysisResult(ctx context.Context, fileID string) (paragraphCount, wordCount, characterCount int32, isPlagiarism bool, wordCloudLocation string, err error) {
+func (m *MockAnalysisRepository) GetAnalysisResult(ctx context.Context, fileID string) (*models.AnalysisResult, error) {
 	args := m.Called(ctx, fileID)
-	return args.Get(0).(int32), args.Get(1).(int32), args.Get(2).(int32), args.Bool(3), args.String(4), args.Error(5)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AnalysisResult), args.Error(1)
 }
 
 // SaveSimilarFile mocks the SaveSimilarFile method
-func (m *MockAnalysisRepository) SaveSimilarFile(ctx context.Context, fileID, similarFileID string) error {
-	args := m.Called(ctx, fileID, similarFileID)
+func (m *MockAnalysisRepository) SaveSimilarFile(ctx context.Context, fileID string, similarFile models.SimilarFile) error {
+	args := m.Called(ctx, fileID, similarFile)
 	return args.Error(0)
 }
 
 // GetSimilarFiles mocks the GetSimilarFiles method
-func (m *MockAnalysisRepository) GetSimilarFiles(ctx context.Context, fileID string) ([]string, error) {
+func (m *MockAnalysisRepository) GetSimilarFiles(ctx context.Context, fileID string) ([]models.SimilarFile, error) {
 	args := m.Called(ctx, fileID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]string), args.Error(1)
+	return args.Get(0).([]models.SimilarFile), args.Error(1)
+}
+
+// GetMatchedPassages mocks the GetMatchedPassages method
+func (m *MockAnalysisRepository) GetMatchedPassages(ctx context.Context, fileID, similarFileID string) ([]models.MatchedPassage, error) {
+	args := m.Called(ctx, fileID, similarFileID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.MatchedPassage), args.Error(1)
 }
 
 // GetAllFileIDs mocks the GetAllFileIDs method
@@ -49,3 +63,102 @@ func (m *MockAnalysisRepository) GetAllFileIDs(ctx context.Context) ([]string, e
 	}
 	return args.Get(0).([]string), args.Error(1)
 }
+
+// SaveSignature mocks the SaveSignature method
+func (m *MockAnalysisRepository) SaveSignature(ctx context.Context, fileID string, signature []uint64, bandKeys []int64, fingerprints []uint64, algorithmVersion int32) error {
+	args := m.Called(ctx, fileID, signature, bandKeys, fingerprints, algorithmVersion)
+	return args.Error(0)
+}
+
+// FindCandidates mocks the FindCandidates method
+func (m *MockAnalysisRepository) FindCandidates(ctx context.Context, bandKeys []int64) ([]string, error) {
+	args := m.Called(ctx, bandKeys)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// FindFingerprintMatches mocks the FindFingerprintMatches method
+func (m *MockAnalysisRepository) FindFingerprintMatches(ctx context.Context, fingerprints []uint64) ([]models.FingerprintMatch, error) {
+	args := m.Called(ctx, fingerprints)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.FingerprintMatch), args.Error(1)
+}
+
+// GetUnindexedFileIDs mocks the GetUnindexedFileIDs method
+func (m *MockAnalysisRepository) GetUnindexedFileIDs(ctx context.Context, algorithmVersion int32) ([]string, error) {
+	args := m.Called(ctx, algorithmVersion)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// RecordIndexFailure mocks the RecordIndexFailure method
+func (m *MockAnalysisRepository) RecordIndexFailure(ctx context.Context, fileID string, reason string, retryDelay, maxRetryDelay time.Duration) error {
+	args := m.Called(ctx, fileID, reason, retryDelay, maxRetryDelay)
+	return args.Error(0)
+}
+
+// SaveTermCounts mocks the SaveTermCounts method
+func (m *MockAnalysisRepository) SaveTermCounts(ctx context.Context, fileID string, terms []models.TermCount) error {
+	args := m.Called(ctx, fileID, terms)
+	return args.Error(0)
+}
+
+// GetTermCounts mocks the GetTermCounts method
+func (m *MockAnalysisRepository) GetTermCounts(ctx context.Context, fileID string) ([]models.TermCount, error) {
+	args := m.Called(ctx, fileID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TermCount), args.Error(1)
+}
+
+// GetDocumentFrequencies mocks the GetDocumentFrequencies method
+func (m *MockAnalysisRepository) GetDocumentFrequencies(ctx context.Context, terms []string) (map[string]int32, int32, error) {
+	args := m.Called(ctx, terms)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).(map[string]int32), args.Get(1).(int32), args.Error(2)
+}
+
+// CreateAnalysisJob mocks the CreateAnalysisJob method
+func (m *MockAnalysisRepository) CreateAnalysisJob(ctx context.Context, job *models.AnalysisJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+// ClaimAnalysisJob mocks the ClaimAnalysisJob method
+func (m *MockAnalysisRepository) ClaimAnalysisJob(ctx context.Context) (*models.AnalysisJob, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AnalysisJob), args.Error(1)
+}
+
+// FinishAnalysisJob mocks the FinishAnalysisJob method
+func (m *MockAnalysisRepository) FinishAnalysisJob(ctx context.Context, jobID string, status models.JobStatus, errorMessage string) error {
+	args := m.Called(ctx, jobID, status, errorMessage)
+	return args.Error(0)
+}
+
+// GetAnalysisJob mocks the GetAnalysisJob method
+func (m *MockAnalysisRepository) GetAnalysisJob(ctx context.Context, jobID string) (*models.AnalysisJob, error) {
+	args := m.Called(ctx, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AnalysisJob), args.Error(1)
+}
+
+// RequeueStaleAnalysisJobs mocks the RequeueStaleAnalysisJobs method
+func (m *MockAnalysisRepository) RequeueStaleAnalysisJobs(ctx context.Context, staleAfter time.Duration, maxAttempts int32) (int64, error) {
+	args := m.Called(ctx, staleAfter, maxAttempts)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,86 @@
+package mocks
+
+import (
+	"context"
+	"time"
+	"github.com/stretchr/testify/mock"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/repository"
+)
+
+// MockWebhookRepository is a mock implementation of the WebhookRepository interface
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+// Ensure MockWebhookRepository implements WebhookRepository
+var _ repository.WebhookRepository = (*MockWebhookRepository)(nil)
+
+// CreateWebhook mocks the CreateWebhook method
+func (m *MockWebhookRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+// GetWebhook mocks the GetWebhook method
+func (m *MockWebhookRepository) GetWebhook(ctx context.Context, webhookID string) (*models.Webhook, error) {
+	args := m.Called(ctx, webhookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+// ListWebhooks mocks the ListWebhooks method
+func (m *MockWebhookRepository) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+// GetWebhooksForEvent mocks the GetWebhooksForEvent method
+func (m *MockWebhookRepository) GetWebhooksForEvent(ctx context.Context, event models.WebhookEvent) ([]models.Webhook, error) {
+	args := m.Called(ctx, event)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+// DeleteWebhook mocks the DeleteWebhook method
+func (m *MockWebhookRepository) DeleteWebhook(ctx context.Context, webhookID string) error {
+	args := m.Called(ctx, webhookID)
+	return args.Error(0)
+}
+
+// CreateWebhookDeliveries mocks the CreateWebhookDeliveries method
+func (m *MockWebhookRepository) CreateWebhookDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	args := m.Called(ctx, deliveries)
+	return args.Error(0)
+}
+
+// ClaimWebhookDelivery mocks the ClaimWebhookDelivery method
+func (m *MockWebhookRepository) ClaimWebhookDelivery(ctx context.Context, lease time.Duration) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+// RecordWebhookAttempt mocks the RecordWebhookAttempt method
+func (m *MockWebhookRepository) RecordWebhookAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+// ListWebhookDeliveries mocks the ListWebhookDeliveries method
+func (m *MockWebhookRepository) ListWebhookDeliveries(ctx context.Context, filter models.WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
@@ -5,7 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/lib/pq"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
 	"local.dev/doc-analyzer/internal/pkg/analyzer/repository"
 )
 
@@ -20,24 +24,49 @@ func NewAnalysisRepo(db *sql.DB) repository.AnalysisRepository {
 }
 
 // SaveAnalysisResult saves analysis results to the database
-func (r *AnalysisRepo) SaveAnalysisResult(ctx context.Context, fileID string, paragraphCount, wordCount, characterCount int32, isPlagiarism bool, wordCloudLocation string) error {
+func (r *AnalysisRepo) SaveAnalysisResult(ctx context.Context, result *models.AnalysisResult) error {
 	query := `
 		INSERT INTO analysis_results (
 			file_id, paragraph_count, word_count, character_count, 
-			is_plagiarism, word_cloud_location, created_at
+			is_plagiarism, word_cloud_location, language,
+			obfuscation_detected, homoglyph_count, invisible_char_count,
+			character_count_no_spaces, sentence_count, avg_sentence_length, avg_word_length,
+			lexical_diversity, readability_formula, reading_ease, grade_level, summary,
+			word_cloud_format, created_at
+		)
+		VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+			CURRENT_TIMESTAMP
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP)
 		ON CONFLICT (file_id) DO UPDATE SET
 			paragraph_count = $2,
 			word_count = $3,
 			character_count = $4,
 			is_plagiarism = $5,
 			word_cloud_location = $6,
+			language = $7,
+			obfuscation_detected = $8,
+			homoglyph_count = $9,
+			invisible_char_count = $10,
+			character_count_no_spaces = $11,
+			sentence_count = $12,
+			avg_sentence_length = $13,
+			avg_word_length = $14,
+			lexical_diversity = $15,
+			readability_formula = $16,
+			reading_ease = $17,
+			grade_level = $18,
+			summary = $19,
+			word_cloud_format = $20,
 			created_at = CURRENT_TIMESTAMP
 	`
 	_, err := r.db.ExecContext(
-		ctx, query, fileID, paragraphCount, wordCount, characterCount,
-		isPlagiarism, wordCloudLocation,
+		ctx, query, result.FileID, result.ParagraphCount, result.WordCount, result.CharacterCount,
+		result.IsPlagiarism, result.WordCloudLocation, result.Language,
+		result.ObfuscationDetected, result.HomoglyphCount, result.InvisibleCharCount,
+		result.CharacterCountNoSpaces, result.SentenceCount, result.AvgSentenceLength, result.AvgWordLength,
+		result.LexicalDiversity, result.ReadabilityFormula, result.ReadingEase, result.GradeLevel,
+		result.Summary, result.WordCloudFormat,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to save analysis result: %w", err)
@@ -46,52 +75,113 @@ func (r *AnalysisRepo) SaveAnalysisResult(ctx context.Context, fileID string, pa
 }
 
 // GetAnalysisResult retrieves analysis results by file ID
-func (r *AnalysisRepo) GetAnalysisResult(ctx context.Context, fileID string) (int32, int32, int32, bool, string, error) {
+func (r *AnalysisRepo) GetAnalysisResult(ctx context.Context, fileID string) (*models.AnalysisResult, error) {
 	query := `
-		SELECT paragraph_count, word_count, character_count, is_plagiarism, word_cloud_location
+		SELECT paragraph_count, word_count, character_count, is_plagiarism, word_cloud_location, language,
+			obfuscation_detected, homoglyph_count, invisible_char_count,
+			character_count_no_spaces, sentence_count, avg_sentence_length, avg_word_length, lexical_diversity,
+			readability_formula, reading_ease, grade_level, summary, word_cloud_format
 		FROM analysis_results
 		WHERE file_id = $1
 	`
-	var paragraphCount, wordCount, characterCount int32
-	var isPlagiarism bool
+	result := &models.AnalysisResult{FileID: fileID}
 	var wordCloudLocation sql.NullString
 
 	err := r.db.QueryRowContext(ctx, query, fileID).Scan(
-		&paragraphCount, &wordCount, &characterCount, &isPlagiarism, &wordCloudLocation,
+		&result.ParagraphCount, &result.WordCount, &result.CharacterCount, &result.IsPlagiarism, &wordCloudLocation,
+		&result.Language, &result.ObfuscationDetected, &result.HomoglyphCount, &result.InvisibleCharCount,
+		&result.CharacterCountNoSpaces, &result.SentenceCount, &result.AvgSentenceLength, &result.AvgWordLength,
+		&result.LexicalDiversity, &result.ReadabilityFormula, &result.ReadingEase, &result.GradeLevel,
+		&result.Summary, &result.WordCloudFormat,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return 0, 0, 0, false, "", fmt.Errorf("analysis result not found for file ID %s", fileID)
+			return nil, fmt.Errorf("analysis result not found for file ID %s: %w", fileID, repository.ErrNotFound)
 		}
-		return 0, 0, 0, false, "", fmt.Errorf("failed to get analysis result: %w", err)
+		return nil, fmt.Errorf("failed to get analysis result: %w", err)
 	}
 
-	var location string
 	if wordCloudLocation.Valid {
-		location = wordCloudLocation.String
+		result.WordCloudLocation = wordCloudLocation.String
 	}
 
-	return paragraphCount, wordCount, characterCount, isPlagiarism, location, nil
+	return result, nil
 }
 
 // SaveSimilarFile saves information about a similar file (for plagiarism detection)
-func (r *AnalysisRepo) SaveSimilarFile(ctx context.Context, fileID, similarFileID string) error {
+// together with the passages copied from it, replacing previously saved passages
+func (r *AnalysisRepo) SaveSimilarFile(ctx context.Context, fileID string, similarFile models.SimilarFile) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO similar_files (file_id, similar_file_id)
-		VALUES ($1, $2)
-		ON CONFLICT (file_id, similar_file_id) DO NOTHING
+		INSERT INTO similar_files (
+			file_id, similar_file_id, score, metric, ngram_size, threshold,
+			algorithm_version, containment, similar_containment, coverage, matched_words
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (file_id, similar_file_id) DO UPDATE SET
+			score = $3,
+			metric = $4,
+			ngram_size = $5,
+			threshold = $6,
+			algorithm_version = $7,
+			containment = $8,
+			similar_containment = $9,
+			coverage = $10,
+			matched_words = $11
 	`
-	_, err := r.db.ExecContext(ctx, query, fileID, similarFileID)
+	_, err = tx.ExecContext(
+		ctx, query, fileID, similarFile.FileID, similarFile.Score, similarFile.Metric,
+		similarFile.NGramSize, similarFile.Threshold, similarFile.AlgorithmVersion,
+		similarFile.Containment, similarFile.SimilarContainment,
+		similarFile.Coverage, similarFile.MatchedWords,
+	)
 	if err != nil {
 		return fmt.Errorf("failed to save similar file: %w", err)
 	}
+
+	query = `
+		DELETE FROM matched_passages WHERE file_id = $1 AND similar_file_id = $2
+	`
+	if _, err := tx.ExecContext(ctx, query, fileID, similarFile.FileID); err != nil {
+		return fmt.Errorf("failed to clear matched passages: %w", err)
+	}
+
+	query = `
+		INSERT INTO matched_passages (
+			file_id, similar_file_id, start_offset, end_offset,
+			similar_start_offset, similar_end_offset, matched_text
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	for _, passage := range similarFile.Passages {
+		_, err := tx.ExecContext(
+			ctx, query, fileID, similarFile.FileID, passage.Start, passage.End,
+			passage.SimilarStart, passage.SimilarEnd, passage.Text,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save matched passage: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit similar file: %w", err)
+	}
 	return nil
 }
 
-// GetSimilarFiles retrieves IDs of similar files for a given file ID
-func (r *AnalysisRepo) GetSimilarFiles(ctx context.Context, fileID string) ([]string, error) {
+// GetSimilarFiles retrieves similar files for a given file ID
+func (r *AnalysisRepo) GetSimilarFiles(ctx context.Context, fileID string) ([]models.SimilarFile, error) {
 	query := `
-		SELECT similar_file_id FROM similar_files WHERE file_id = $1
+		SELECT similar_file_id, score, metric, ngram_size, threshold, algorithm_version,
+			containment, similar_containment, coverage, matched_words
+		FROM similar_files
+		WHERE file_id = $1
+		ORDER BY similar_file_id
 	`
 	rows, err := r.db.QueryContext(ctx, query, fileID)
 	if err != nil {
@@ -99,20 +189,56 @@ func (r *AnalysisRepo) GetSimilarFiles(ctx context.Context, fileID string) ([]st
 	}
 	defer rows.Close()
 
-	var similarFileIDs []string
+	var similarFiles []models.SimilarFile
 	for rows.Next() {
-		var similarFileID string
-		if err := rows.Scan(&similarFileID); err != nil {
-			return nil, fmt.Errorf("failed to scan similar file ID: %w", err)
+		var similarFile models.SimilarFile
+		err := rows.Scan(
+			&similarFile.FileID, &similarFile.Score, &similarFile.Metric, &similarFile.NGramSize,
+			&similarFile.Threshold, &similarFile.AlgorithmVersion, &similarFile.Containment,
+			&similarFile.SimilarContainment, &similarFile.Coverage, &similarFile.MatchedWords,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan similar file: %w", err)
 		}
-		similarFileIDs = append(similarFileIDs, similarFileID)
+		similarFiles = append(similarFiles, similarFile)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating over similar files: %w", err)
 	}
 
-	return similarFileIDs, nil
+	return similarFiles, nil
+}
+
+// GetMatchedPassages retrieves the passages of a file copied from a similar file
+func (r *AnalysisRepo) GetMatchedPassages(ctx context.Context, fileID, similarFileID string) ([]models.MatchedPassage, error) {
+	query := `
+		SELECT start_offset, end_offset, similar_start_offset, similar_end_offset, matched_text
+		FROM matched_passages
+		WHERE file_id = $1 AND similar_file_id = $2
+		ORDER BY start_offset
+	`
+	rows, err := r.db.QueryContext(ctx, query, fileID, similarFileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query matched passages: %w", err)
+	}
+	defer rows.Close()
+
+	passages := []models.MatchedPassage{}
+	for rows.Next() {
+		var passage models.MatchedPassage
+		err := rows.Scan(&passage.Start, &passage.End, &passage.SimilarStart, &passage.SimilarEnd, &passage.Text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan matched passage: %w", err)
+		}
+		passages = append(passages, passage)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over matched passages: %w", err)
+	}
+
+	return passages, nil
 }
 
 // GetAllFileIDs retrieves all file IDs in the database
@@ -141,3 +267,340 @@ func (r *AnalysisRepo) GetAllFileIDs(ctx context.Context) ([]string, error) {
 
 	return fileIDs, nil
 }
+
+// SaveSignature saves the MinHash signature of a file, computed by the given algorithm version,
+// and indexes it in the LSH band buckets and its winnowing fingerprints in the fingerprint index
+// Previously indexed buckets and fingerprints of the file are replaced
+func (r *AnalysisRepo) SaveSignature(ctx context.Context, fileID string, signature []uint64, bandKeys []int64, fingerprints []uint64, algorithmVersion int32) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Signature values are stored bit-for-bit as BIGINT
+	values := int64Values(signature)
+
+	query := `
+		INSERT INTO minhash_signatures (file_id, signature, algorithm_version, fingerprint_count, created_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (file_id) DO UPDATE SET
+			signature = $2,
+			algorithm_version = $3,
+			fingerprint_count = $4,
+			created_at = CURRENT_TIMESTAMP
+	`
+	if _, err := tx.ExecContext(ctx, query, fileID, pq.Array(values), algorithmVersion, len(fingerprints)); err != nil {
+		return fmt.Errorf("failed to save signature: %w", err)
+	}
+
+	query = `
+		DELETE FROM lsh_buckets WHERE file_id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, fileID); err != nil {
+		return fmt.Errorf("failed to clear LSH buckets: %w", err)
+	}
+
+	query = `
+		INSERT INTO lsh_buckets (band, bucket, file_id)
+		SELECT band - 1, bucket, $1
+		FROM unnest($2::BIGINT[]) WITH ORDINALITY AS keys(bucket, band)
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, query, fileID, pq.Array(bandKeys)); err != nil {
+		return fmt.Errorf("failed to save LSH buckets: %w", err)
+	}
+
+	query = `
+		DELETE FROM index_failures WHERE file_id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, fileID); err != nil {
+		return fmt.Errorf("failed to clear index failure: %w", err)
+	}
+
+	query = `
+		DELETE FROM winnowing_fingerprints WHERE file_id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, fileID); err != nil {
+		return fmt.Errorf("failed to clear fingerprints: %w", err)
+	}
+
+	query = `
+		INSERT INTO winnowing_fingerprints (hash, file_id)
+		SELECT hash, $1
+		FROM unnest($2::BIGINT[]) AS hash
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, query, fileID, pq.Array(int64Values(fingerprints))); err != nil {
+		return fmt.Errorf("failed to save fingerprints: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit signature: %w", err)
+	}
+	return nil
+}
+
+// int64Values converts hashes to BIGINT values bit-for-bit
+func int64Values(hashes []uint64) []int64 {
+	values := make([]int64, len(hashes))
+	for i, hash := range hashes {
+		values[i] = int64(hash)
+	}
+	return values
+}
+
+// FindCandidates retrieves IDs of files sharing at least one LSH band bucket with the given keys
+// The key at index i is looked up in band i
+func (r *AnalysisRepo) FindCandidates(ctx context.Context, bandKeys []int64) ([]string, error) {
+	query := `
+		SELECT DISTINCT b.file_id
+		FROM lsh_buckets b
+		JOIN unnest($1::BIGINT[]) WITH ORDINALITY AS keys(bucket, band)
+			ON b.band = keys.band - 1 AND b.bucket = keys.bucket
+	`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(bandKeys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var fileIDs []string
+	for rows.Next() {
+		var fileID string
+		if err := rows.Scan(&fileID); err != nil {
+			return nil, fmt.Errorf("failed to scan candidate file ID: %w", err)
+		}
+		fileIDs = append(fileIDs, fileID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over candidates: %w", err)
+	}
+
+	return fileIDs, nil
+}
+
+// FindFingerprintMatches retrieves the files sharing at least one of the winnowing fingerprints,
+// with the number of shared fingerprints and the number of fingerprints of each file
+func (r *AnalysisRepo) FindFingerprintMatches(ctx context.Context, fingerprints []uint64) ([]models.FingerprintMatch, error) {
+	query := `
+		SELECT f.file_id, COUNT(*), COALESCE(s.fingerprint_count, 0)
+		FROM winnowing_fingerprints f
+		JOIN minhash_signatures s ON s.file_id = f.file_id
+		WHERE f.hash = ANY($1::BIGINT[])
+		GROUP BY f.file_id, s.fingerprint_count
+	`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(int64Values(fingerprints)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fingerprint matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.FingerprintMatch
+	for rows.Next() {
+		var match models.FingerprintMatch
+		if err := rows.Scan(&match.FileID, &match.Shared, &match.Fingerprints); err != nil {
+			return nil, fmt.Errorf("failed to scan fingerprint match: %w", err)
+		}
+		matches = append(matches, match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over fingerprint matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+// GetUnindexedFileIDs retrieves IDs of analyzed files that have no MinHash signature
+// computed by the given algorithm version, or no indexed fingerprints
+// These are files analyzed before the LSH or the fingerprint index existed, whose indexing failed
+// or whose signature was computed by an older algorithm
+// Files whose indexing has failed are left out until their next attempt is due
+func (r *AnalysisRepo) GetUnindexedFileIDs(ctx context.Context, algorithmVersion int32) ([]string, error) {
+	query := `
+		SELECT a.file_id
+		FROM analysis_results a
+		LEFT JOIN minhash_signatures s ON s.file_id = a.file_id
+		LEFT JOIN index_failures f ON f.file_id = a.file_id
+		WHERE (s.file_id IS NULL OR s.algorithm_version <> $1 OR s.fingerprint_count IS NULL)
+			AND (f.file_id IS NULL OR f.next_attempt_at <= CURRENT_TIMESTAMP)
+	`
+	rows, err := r.db.QueryContext(ctx, query, algorithmVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unindexed file IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var fileIDs []string
+	for rows.Next() {
+		var fileID string
+		if err := rows.Scan(&fileID); err != nil {
+			return nil, fmt.Errorf("failed to scan file ID: %w", err)
+		}
+		fileIDs = append(fileIDs, fileID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over unindexed file IDs: %w", err)
+	}
+
+	return fileIDs, nil
+}
+
+// RecordIndexFailure records a failed indexing of a file, its next attempt is due after retryDelay,
+// doubled for each earlier failed attempt up to maxRetryDelay
+func (r *AnalysisRepo) RecordIndexFailure(ctx context.Context, fileID string, reason string, retryDelay, maxRetryDelay time.Duration) error {
+	query := `
+		INSERT INTO index_failures (file_id, attempts, error, next_attempt_at)
+		VALUES ($1, 1, $2, CURRENT_TIMESTAMP + make_interval(secs => LEAST($3, $4)))
+		ON CONFLICT (file_id) DO UPDATE SET
+			attempts = index_failures.attempts + 1,
+			error = $2,
+			next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => LEAST($3 * POWER(2, index_failures.attempts), $4))
+	`
+	_, err := r.db.ExecContext(ctx, query, fileID, reason, retryDelay.Seconds(), maxRetryDelay.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to record index failure: %w", err)
+	}
+	return nil
+}
+
+// SaveTermCounts saves the term counts of a file and updates the corpus document frequencies
+// Previously saved terms of the file are replaced and no longer counted in the document frequencies
+// Terms are expected sorted, so that concurrent saves lock document frequencies in the same order
+func (r *AnalysisRepo) SaveTermCounts(ctx context.Context, fileID string, terms []models.TermCount) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE document_frequencies d
+		SET document_count = d.document_count - 1
+		FROM file_terms f
+		WHERE f.file_id = $1 AND f.term = d.term
+	`
+	if _, err := tx.ExecContext(ctx, query, fileID); err != nil {
+		return fmt.Errorf("failed to update document frequencies: %w", err)
+	}
+
+	query = `
+		DELETE FROM document_frequencies WHERE document_count <= 0
+	`
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to clear document frequencies: %w", err)
+	}
+
+	query = `
+		DELETE FROM file_terms WHERE file_id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, fileID); err != nil {
+		return fmt.Errorf("failed to clear term counts: %w", err)
+	}
+
+	termValues := make([]string, len(terms))
+	wordValues := make([]string, len(terms))
+	countValues := make([]int64, len(terms))
+	for i, term := range terms {
+		termValues[i] = term.Term
+		wordValues[i] = term.Word
+		countValues[i] = int64(term.Count)
+	}
+
+	query = `
+		INSERT INTO file_terms (file_id, term, word, count)
+		SELECT $1, t.term, t.word, t.count
+		FROM unnest($2::TEXT[], $3::TEXT[], $4::INT[]) AS t(term, word, count)
+	`
+	if _, err := tx.ExecContext(ctx, query, fileID, pq.Array(termValues), pq.Array(wordValues), pq.Array(countValues)); err != nil {
+		return fmt.Errorf("failed to save term counts: %w", err)
+	}
+
+	query = `
+		INSERT INTO document_frequencies (term, document_count)
+		SELECT term, 1 FROM unnest($1::TEXT[]) AS t(term)
+		ON CONFLICT (term) DO UPDATE SET
+			document_count = document_frequencies.document_count + 1
+	`
+	if _, err := tx.ExecContext(ctx, query, pq.Array(termValues)); err != nil {
+		return fmt.Errorf("failed to save document frequencies: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit term counts: %w", err)
+	}
+	return nil
+}
+
+// GetTermCounts retrieves the term counts of a file, sorted by term
+func (r *AnalysisRepo) GetTermCounts(ctx context.Context, fileID string) ([]models.TermCount, error) {
+	query := `
+		SELECT term, word, count
+		FROM file_terms
+		WHERE file_id = $1
+		ORDER BY term
+	`
+	rows, err := r.db.QueryContext(ctx, query, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query term counts: %w", err)
+	}
+	defer rows.Close()
+
+	var terms []models.TermCount
+	for rows.Next() {
+		var term models.TermCount
+		if err := rows.Scan(&term.Term, &term.Word, &term.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan term count: %w", err)
+		}
+		terms = append(terms, term)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over term counts: %w", err)
+	}
+
+	return terms, nil
+}
+
+// GetDocumentFrequencies retrieves the number of files containing each of the terms,
+// together with the number of files in the corpus
+// Terms found in no file are missing from the map
+func (r *AnalysisRepo) GetDocumentFrequencies(ctx context.Context, terms []string) (map[string]int32, int32, error) {
+	query := `
+		SELECT term, document_count
+		FROM document_frequencies
+		WHERE term = ANY($1)
+	`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(terms))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query document frequencies: %w", err)
+	}
+	defer rows.Close()
+
+	documentFrequencies := make(map[string]int32, len(terms))
+	for rows.Next() {
+		var term string
+		var documentCount int32
+		if err := rows.Scan(&term, &documentCount); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan document frequency: %w", err)
+		}
+		documentFrequencies[term] = documentCount
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating over document frequencies: %w", err)
+	}
+
+	query = `
+		SELECT COUNT(DISTINCT file_id) FROM file_terms
+	`
+	var documentCount int32
+	if err := r.db.QueryRowContext(ctx, query).Scan(&documentCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to count corpus documents: %w", err)
+	}
+
+	return documentFrequencies, documentCount, nil
+}
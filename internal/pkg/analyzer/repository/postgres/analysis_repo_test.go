@@ -5,11 +5,15 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/repository"
 	"local.dev/doc-analyzer/internal/pkg/analyzer/repository/postgres"
 )
 
@@ -26,19 +30,39 @@ func TestSaveAnalysisResult(t *testing.T) {
 	t.Run("Successful save", func(t *testing.T) {
 		// Set up mock expectations
 		mock.ExpectExec("INSERT INTO analysis_results").
-			WithArgs("file123", int32(5), int32(100), int32(500), true, "wordclouds/file123.png").
+			WithArgs("file123", int32(5), int32(100), int32(500), true, "wordclouds/file123.png", "ru", true, int32(3), int32(2),
+				int32(420), int32(8), 12.5, 5.2, 0.65, "oborneva", 45.3, 11.2,
+				"Краткое содержание.", "png").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		// Call the method
-		err := repo.SaveAnalysisResult(
-			context.Background(),
-			"file123",
-			int32(5),
-			int32(100),
-			int32(500),
-			true,
-			"wordclouds/file123.png",
-		)
+		err := repo.SaveAnalysisResult(context.Background(), &models.AnalysisResult{
+			FileID:            "file123",
+			ParagraphCount:    5,
+			WordCount:         100,
+			CharacterCount:    500,
+			IsPlagiarism:      true,
+			WordCloudLocation: "wordclouds/file123.png",
+			Language:          "ru",
+
+			ObfuscationDetected: true,
+			HomoglyphCount:      3,
+			InvisibleCharCount:  2,
+
+			CharacterCountNoSpaces: 420,
+			SentenceCount:          8,
+			AvgSentenceLength:      12.5,
+			AvgWordLength:          5.2,
+			LexicalDiversity:       0.65,
+
+			ReadabilityFormula: "oborneva",
+			ReadingEase:        45.3,
+			GradeLevel:         11.2,
+
+			Summary: "Краткое содержание.",
+
+			WordCloudFormat: "png",
+		})
 
 		// Assert
 		assert.NoError(t, err)
@@ -49,19 +73,39 @@ func TestSaveAnalysisResult(t *testing.T) {
 	t.Run("Database error", func(t *testing.T) {
 		// Set up mock expectations
 		mock.ExpectExec("INSERT INTO analysis_results").
-			WithArgs("file123", int32(5), int32(100), int32(500), true, "wordclouds/file123.png").
+			WithArgs("file123", int32(5), int32(100), int32(500), true, "wordclouds/file123.png", "ru", true, int32(3), int32(2),
+				int32(420), int32(8), 12.5, 5.2, 0.65, "oborneva", 45.3, 11.2,
+				"Краткое содержание.", "png").
 			WillReturnError(errors.New("database error"))
 
 		// Call the method
-		err := repo.SaveAnalysisResult(
-			context.Background(),
-			"file123",
-			int32(5),
-			int32(100),
-			int32(500),
-			true,
-			"wordclouds/file123.png",
-		)
+		err := repo.SaveAnalysisResult(context.Background(), &models.AnalysisResult{
+			FileID:            "file123",
+			ParagraphCount:    5,
+			WordCount:         100,
+			CharacterCount:    500,
+			IsPlagiarism:      true,
+			WordCloudLocation: "wordclouds/file123.png",
+			Language:          "ru",
+
+			ObfuscationDetected: true,
+			HomoglyphCount:      3,
+			InvisibleCharCount:  2,
+
+			CharacterCountNoSpaces: 420,
+			SentenceCount:          8,
+			AvgSentenceLength:      12.5,
+			AvgWordLength:          5.2,
+			LexicalDiversity:       0.65,
+
+			ReadabilityFormula: "oborneva",
+			ReadingEase:        45.3,
+			GradeLevel:         11.2,
+
+			Summary: "Краткое содержание.",
+
+			WordCloudFormat: "png",
+		})
 
 		// Assert
 		assert.Error(t, err)
@@ -83,26 +127,47 @@ func TestGetAnalysisResult(t *testing.T) {
 	t.Run("Successful get", func(t *testing.T) {
 		// Set up mock expectations
 		rows := sqlmock.NewRows([]string{
-			"paragraph_count", "word_count", "character_count", "is_plagiarism", "word_cloud_location",
-		}).AddRow(5, 100, 500, true, "wordclouds/file123.png")
+			"paragraph_count", "word_count", "character_count", "is_plagiarism", "word_cloud_location", "language",
+			"obfuscation_detected", "homoglyph_count", "invisible_char_count",
+			"character_count_no_spaces", "sentence_count", "avg_sentence_length", "avg_word_length", "lexical_diversity",
+			"readability_formula", "reading_ease", "grade_level", "summary", "word_cloud_format",
+		}).AddRow(
+			5, 100, 500, true, "wordclouds/file123.png", "ru", true, 3, 2, 420, 8, 12.5, 5.2, 0.65, "oborneva", 45.3, 11.2,
+			"Краткое содержание.", "png",
+		)
 
 		mock.ExpectQuery("SELECT paragraph_count, word_count, character_count, is_plagiarism, word_cloud_location").
 			WithArgs("file123").
 			WillReturnRows(rows)
 
 		// Call the method
-		paragraphCount, wordCount, characterCount, isPlagiarism, wordCloudLocation, err := repo.GetAnalysisResult(
+		result, err := repo.GetAnalysisResult(
 			context.Background(),
 			"file123",
 		)
 
 		// Assert
 		assert.NoError(t, err)
-		assert.Equal(t, int32(5), paragraphCount)
-		assert.Equal(t, int32(100), wordCount)
-		assert.Equal(t, int32(500), characterCount)
-		assert.True(t, isPlagiarism)
-		assert.Equal(t, "wordclouds/file123.png", wordCloudLocation)
+		assert.Equal(t, "file123", result.FileID)
+		assert.Equal(t, int32(5), result.ParagraphCount)
+		assert.Equal(t, int32(100), result.WordCount)
+		assert.Equal(t, int32(500), result.CharacterCount)
+		assert.True(t, result.IsPlagiarism)
+		assert.Equal(t, "wordclouds/file123.png", result.WordCloudLocation)
+		assert.Equal(t, "ru", result.Language)
+		assert.True(t, result.ObfuscationDetected)
+		assert.Equal(t, int32(3), result.HomoglyphCount)
+		assert.Equal(t, int32(2), result.InvisibleCharCount)
+		assert.Equal(t, int32(420), result.CharacterCountNoSpaces)
+		assert.Equal(t, int32(8), result.SentenceCount)
+		assert.Equal(t, 12.5, result.AvgSentenceLength)
+		assert.Equal(t, 5.2, result.AvgWordLength)
+		assert.Equal(t, 0.65, result.LexicalDiversity)
+		assert.Equal(t, "oborneva", result.ReadabilityFormula)
+		assert.Equal(t, 45.3, result.ReadingEase)
+		assert.Equal(t, 11.2, result.GradeLevel)
+		assert.Equal(t, "Краткое содержание.", result.Summary)
+		assert.Equal(t, "png", result.WordCloudFormat)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -114,13 +179,13 @@ func TestGetAnalysisResult(t *testing.T) {
 			WillReturnError(sql.ErrNoRows)
 
 		// Call the method
-		_, _, _, _, _, err := repo.GetAnalysisResult(
+		_, err := repo.GetAnalysisResult(
 			context.Background(),
 			"file123",
 		)
 
 		// Assert
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
 		assert.Contains(t, err.Error(), "analysis result not found")
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -133,7 +198,7 @@ func TestGetAnalysisResult(t *testing.T) {
 			WillReturnError(errors.New("database error"))
 
 		// Call the method
-		_, _, _, _, _, err := repo.GetAnalysisResult(
+		_, err := repo.GetAnalysisResult(
 			context.Background(),
 			"file123",
 		)
@@ -154,18 +219,42 @@ func TestSaveSimilarFile(t *testing.T) {
 	// Create a new repository with the mock database
 	repo := postgres.NewAnalysisRepo(db)
 
+	similarFile := models.SimilarFile{
+		FileID:             "file456",
+		Score:              0.42,
+		Metric:             "jaccard",
+		NGramSize:          3,
+		Threshold:          0.3,
+		AlgorithmVersion:   1,
+		Containment:        0.5,
+		SimilarContainment: 0.9,
+		Coverage:           0.75,
+		MatchedWords:       30,
+		Passages: []models.MatchedPassage{
+			{Start: 10, End: 50, SimilarStart: 0, SimilarEnd: 40, Text: "copied passage"},
+		},
+	}
+
 	// Test case: successful save
 	t.Run("Successful save", func(t *testing.T) {
 		// Set up mock expectations
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO similar_files").
+			WithArgs("file123", "file456", 0.42, "jaccard", int32(3), 0.3, int32(1), 0.5, 0.9, 0.75, int32(30)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM matched_passages").
 			WithArgs("file123", "file456").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO matched_passages").
+			WithArgs("file123", "file456", int32(10), int32(50), int32(0), int32(40), "copied passage").
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
 		// Call the method
 		err := repo.SaveSimilarFile(
 			context.Background(),
 			"file123",
-			"file456",
+			similarFile,
 		)
 
 		// Assert
@@ -176,15 +265,17 @@ func TestSaveSimilarFile(t *testing.T) {
 	// Test case: database error
 	t.Run("Database error", func(t *testing.T) {
 		// Set up mock expectations
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO similar_files").
-			WithArgs("file123", "file456").
+			WithArgs("file123", "file456", 0.42, "jaccard", int32(3), 0.3, int32(1), 0.5, 0.9, 0.75, int32(30)).
 			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
 
 		// Call the method
 		err := repo.SaveSimilarFile(
 			context.Background(),
 			"file123",
-			"file456",
+			similarFile,
 		)
 
 		// Assert
@@ -192,6 +283,84 @@ func TestSaveSimilarFile(t *testing.T) {
 		assert.Contains(t, err.Error(), "failed to save similar file")
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	// Test case: passage error rolls back the transaction
+	t.Run("Passage error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO similar_files").
+			WithArgs("file123", "file456", 0.42, "jaccard", int32(3), 0.3, int32(1), 0.5, 0.9, 0.75, int32(30)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM matched_passages").
+			WithArgs("file123", "file456").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO matched_passages").
+			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		// Call the method
+		err := repo.SaveSimilarFile(
+			context.Background(),
+			"file123",
+			similarFile,
+		)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to save matched passage")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetMatchedPassages(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewAnalysisRepo(db)
+
+	// Test case: successful get
+	t.Run("Successful get", func(t *testing.T) {
+		// Set up mock expectations
+		rows := sqlmock.NewRows([]string{
+			"start_offset", "end_offset", "similar_start_offset", "similar_end_offset", "matched_text",
+		}).
+			AddRow(10, 50, 0, 40, "first passage").
+			AddRow(80, 120, 60, 100, "second passage")
+
+		mock.ExpectQuery("SELECT start_offset, end_offset, similar_start_offset, similar_end_offset, matched_text").
+			WithArgs("file123", "file456").
+			WillReturnRows(rows)
+
+		// Call the method
+		passages, err := repo.GetMatchedPassages(context.Background(), "file123", "file456")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []models.MatchedPassage{
+			{Start: 10, End: 50, SimilarStart: 0, SimilarEnd: 40, Text: "first passage"},
+			{Start: 80, End: 120, SimilarStart: 60, SimilarEnd: 100, Text: "second passage"},
+		}, passages)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectQuery("SELECT start_offset, end_offset, similar_start_offset, similar_end_offset, matched_text").
+			WithArgs("file123", "file456").
+			WillReturnError(errors.New("database error"))
+
+		// Call the method
+		_, err := repo.GetMatchedPassages(context.Background(), "file123", "file456")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to query matched passages")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestGetSimilarFiles(t *testing.T) {
@@ -206,30 +375,42 @@ func TestGetSimilarFiles(t *testing.T) {
 	// Test case: successful get
 	t.Run("Successful get", func(t *testing.T) {
 		// Set up mock expectations
-		rows := sqlmock.NewRows([]string{"similar_file_id"}).
-			AddRow("file456").
-			AddRow("file789")
+		rows := sqlmock.NewRows([]string{
+			"similar_file_id", "score", "metric", "ngram_size", "threshold", "algorithm_version",
+			"containment", "similar_containment", "coverage", "matched_words",
+		}).
+			AddRow("file456", 0.42, "jaccard", 3, 0.3, 1, 0.5, 0.9, 0.75, 30).
+			AddRow("file789", 1.0, "exact", 3, 0.3, 1, 1.0, 1.0, 1.0, 120)
 
-		mock.ExpectQuery("SELECT similar_file_id FROM similar_files").
+		mock.ExpectQuery("SELECT similar_file_id, score, metric, ngram_size, threshold, algorithm_version").
 			WithArgs("file123").
 			WillReturnRows(rows)
 
 		// Call the method
-		similarFileIDs, err := repo.GetSimilarFiles(
+		similarFiles, err := repo.GetSimilarFiles(
 			context.Background(),
 			"file123",
 		)
 
 		// Assert
 		assert.NoError(t, err)
-		assert.Equal(t, []string{"file456", "file789"}, similarFileIDs)
+		assert.Equal(t, []models.SimilarFile{
+			{
+				FileID: "file456", Score: 0.42, Metric: "jaccard", NGramSize: 3, Threshold: 0.3,
+				AlgorithmVersion: 1, Containment: 0.5, SimilarContainment: 0.9, Coverage: 0.75, MatchedWords: 30,
+			},
+			{
+				FileID: "file789", Score: 1.0, Metric: "exact", NGramSize: 3, Threshold: 0.3,
+				AlgorithmVersion: 1, Containment: 1.0, SimilarContainment: 1.0, Coverage: 1.0, MatchedWords: 120,
+			},
+		}, similarFiles)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	// Test case: database error
 	t.Run("Database error", func(t *testing.T) {
 		// Set up mock expectations
-		mock.ExpectQuery("SELECT similar_file_id FROM similar_files").
+		mock.ExpectQuery("SELECT similar_file_id, score, metric, ngram_size, threshold, algorithm_version").
 			WithArgs("file123").
 			WillReturnError(errors.New("database error"))
 
@@ -300,3 +481,410 @@ func TestGetAllFileIDs(t *testing.T) {
 	// Skip the scan error test as it's not working correctly with the mock
 	// The actual implementation handles scan errors correctly
 }
+
+func TestSaveSignature(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewAnalysisRepo(db)
+
+	signature := []uint64{1, 2, 18446744073709551615}
+	bandKeys := []int64{10, 20}
+	fingerprints := []uint64{5, 18446744073709551614}
+
+	// Test case: successful save
+	t.Run("Successful save", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO minhash_signatures").
+			WithArgs("file123", pq.Array([]int64{1, 2, -1}), int32(2), 2).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM lsh_buckets").
+			WithArgs("file123").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO lsh_buckets").
+			WithArgs("file123", pq.Array(bandKeys)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("DELETE FROM index_failures").
+			WithArgs("file123").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM winnowing_fingerprints").
+			WithArgs("file123").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO winnowing_fingerprints").
+			WithArgs("file123", pq.Array([]int64{5, -2})).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		// Call the method
+		err := repo.SaveSignature(context.Background(), "file123", signature, bandKeys, fingerprints, 2)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error rolls back the transaction
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO minhash_signatures").
+			WithArgs("file123", pq.Array([]int64{1, 2, -1}), int32(2), 2).
+			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		// Call the method
+		err := repo.SaveSignature(context.Background(), "file123", signature, bandKeys, fingerprints, 2)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to save signature")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFindCandidates(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewAnalysisRepo(db)
+
+	bandKeys := []int64{10, 20}
+
+	// Test case: successful get
+	t.Run("Successful get", func(t *testing.T) {
+		// Set up mock expectations
+		rows := sqlmock.NewRows([]string{"file_id"}).
+			AddRow("file456").
+			AddRow("file789")
+
+		mock.ExpectQuery("SELECT DISTINCT b.file_id FROM lsh_buckets").
+			WithArgs(pq.Array(bandKeys)).
+			WillReturnRows(rows)
+
+		// Call the method
+		fileIDs, err := repo.FindCandidates(context.Background(), bandKeys)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"file456", "file789"}, fileIDs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectQuery("SELECT DISTINCT b.file_id FROM lsh_buckets").
+			WithArgs(pq.Array(bandKeys)).
+			WillReturnError(errors.New("database error"))
+
+		// Call the method
+		_, err := repo.FindCandidates(context.Background(), bandKeys)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to query candidates")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFindFingerprintMatches(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewAnalysisRepo(db)
+
+	fingerprints := []uint64{5, 18446744073709551614}
+
+	// Test case: successful get
+	t.Run("Successful get", func(t *testing.T) {
+		// Set up mock expectations
+		rows := sqlmock.NewRows([]string{"file_id", "shared", "fingerprint_count"}).
+			AddRow("file456", 2, 40).
+			AddRow("file789", 1, 3)
+
+		mock.ExpectQuery("SELECT f.file_id, COUNT\\(\\*\\), COALESCE\\(s.fingerprint_count, 0\\) FROM winnowing_fingerprints").
+			WithArgs(pq.Array([]int64{5, -2})).
+			WillReturnRows(rows)
+
+		// Call the method
+		matches, err := repo.FindFingerprintMatches(context.Background(), fingerprints)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []models.FingerprintMatch{
+			{FileID: "file456", Shared: 2, Fingerprints: 40},
+			{FileID: "file789", Shared: 1, Fingerprints: 3},
+		}, matches)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectQuery("SELECT f.file_id").
+			WithArgs(pq.Array([]int64{5, -2})).
+			WillReturnError(errors.New("database error"))
+
+		// Call the method
+		_, err := repo.FindFingerprintMatches(context.Background(), fingerprints)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to query fingerprint matches")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetUnindexedFileIDs(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewAnalysisRepo(db)
+
+	// Test case: successful get
+	t.Run("Successful get", func(t *testing.T) {
+		// Set up mock expectations
+		rows := sqlmock.NewRows([]string{"file_id"}).
+			AddRow("file456")
+
+		mock.ExpectQuery("SELECT a.file_id FROM analysis_results a LEFT JOIN minhash_signatures").
+			WithArgs(int32(2)).
+			WillReturnRows(rows)
+
+		// Call the method
+		fileIDs, err := repo.GetUnindexedFileIDs(context.Background(), 2)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"file456"}, fileIDs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectQuery("SELECT a.file_id FROM analysis_results a LEFT JOIN minhash_signatures").
+			WithArgs(int32(2)).
+			WillReturnError(errors.New("database error"))
+
+		// Call the method
+		_, err := repo.GetUnindexedFileIDs(context.Background(), 2)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to query unindexed file IDs")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRecordIndexFailure(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewAnalysisRepo(db)
+
+	// Test case: successful record
+	t.Run("Successful record", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectExec("INSERT INTO index_failures .* ON CONFLICT \\(file_id\\) DO UPDATE").
+			WithArgs("file456", "file not found", float64(60), float64(3600)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Call the method
+		err := repo.RecordIndexFailure(context.Background(), "file456", "file not found", time.Minute, time.Hour)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectExec("INSERT INTO index_failures").
+			WithArgs("file456", "file not found", float64(60), float64(3600)).
+			WillReturnError(errors.New("database error"))
+
+		// Call the method
+		err := repo.RecordIndexFailure(context.Background(), "file456", "file not found", time.Minute, time.Hour)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to record index failure")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSaveTermCounts(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewAnalysisRepo(db)
+
+	terms := []models.TermCount{
+		{Term: "интеллект", Word: "интеллекта", Count: 3},
+		{Term: "систем", Word: "системы", Count: 1},
+	}
+
+	// Test case: successful save
+	t.Run("Successful save", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE document_frequencies").
+			WithArgs("file123").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM document_frequencies").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM file_terms").
+			WithArgs("file123").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO file_terms").
+			WithArgs("file123", pq.Array([]string{"интеллект", "систем"}), pq.Array([]string{"интеллекта", "системы"}), pq.Array([]int64{3, 1})).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("INSERT INTO document_frequencies").
+			WithArgs(pq.Array([]string{"интеллект", "систем"})).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		// Call the method
+		err := repo.SaveTermCounts(context.Background(), "file123", terms)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error rolls back the transaction
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE document_frequencies").
+			WithArgs("file123").
+			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		// Call the method
+		err := repo.SaveTermCounts(context.Background(), "file123", terms)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to update document frequencies")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetTermCounts(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewAnalysisRepo(db)
+
+	// Test case: successful get
+	t.Run("Successful get", func(t *testing.T) {
+		// Set up mock expectations
+		rows := sqlmock.NewRows([]string{"term", "word", "count"}).
+			AddRow("интеллект", "интеллекта", 3).
+			AddRow("систем", "системы", 1)
+		mock.ExpectQuery("SELECT (.+) FROM file_terms").
+			WithArgs("file123").
+			WillReturnRows(rows)
+
+		// Call the method
+		terms, err := repo.GetTermCounts(context.Background(), "file123")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []models.TermCount{
+			{Term: "интеллект", Word: "интеллекта", Count: 3},
+			{Term: "систем", Word: "системы", Count: 1},
+		}, terms)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectQuery("SELECT (.+) FROM file_terms").
+			WithArgs("file123").
+			WillReturnError(errors.New("database error"))
+
+		// Call the method
+		_, err := repo.GetTermCounts(context.Background(), "file123")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to query term counts")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetDocumentFrequencies(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewAnalysisRepo(db)
+
+	terms := []string{"интеллект", "систем"}
+
+	// Test case: successful get
+	t.Run("Successful get", func(t *testing.T) {
+		// Set up mock expectations
+		rows := sqlmock.NewRows([]string{"term", "document_count"}).
+			AddRow("интеллект", 4)
+		mock.ExpectQuery("SELECT (.+) FROM document_frequencies").
+			WithArgs(pq.Array(terms)).
+			WillReturnRows(rows)
+		mock.ExpectQuery("SELECT COUNT\\(DISTINCT file_id\\) FROM file_terms").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
+
+		// Call the method
+		documentFrequencies, documentCount, err := repo.GetDocumentFrequencies(context.Background(), terms)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int32{"интеллект": 4}, documentFrequencies)
+		assert.Equal(t, int32(10), documentCount)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectQuery("SELECT (.+) FROM document_frequencies").
+			WithArgs(pq.Array(terms)).
+			WillReturnError(errors.New("database error"))
+
+		// Call the method
+		_, _, err := repo.GetDocumentFrequencies(context.Background(), terms)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to query document frequencies")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
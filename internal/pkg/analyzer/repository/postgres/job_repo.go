@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/repository"
+)
+
+// CreateAnalysisJob saves a new queued analysis job
+func (r *AnalysisRepo) CreateAnalysisJob(ctx context.Context, job *models.AnalysisJob) error {
+	query := `
+		INSERT INTO analysis_jobs (id, file_id, generate_word_cloud, word_cloud_options, webhook_url, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.ExecContext(
+		ctx, query, job.ID, job.FileID, job.GenerateWordCloud, string(job.WordCloudOptions), job.WebhookURL,
+		string(models.JobQueued), job.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create analysis job: %w", err)
+	}
+	return nil
+}
+
+// ClaimAnalysisJob marks the oldest queued job as running and returns it, nil if there are no queued jobs
+// SKIP LOCKED lets concurrent workers claim different jobs instead of waiting for each other
+func (r *AnalysisRepo) ClaimAnalysisJob(ctx context.Context) (*models.AnalysisJob, error) {
+	query := `
+		UPDATE analysis_jobs
+		SET status = $1, attempts = attempts + 1, started_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM analysis_jobs
+			WHERE status = $2
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, file_id, generate_word_cloud, word_cloud_options, webhook_url, attempts, created_at, started_at
+	`
+	job := &models.AnalysisJob{Status: models.JobRunning}
+	var wordCloudOptions string
+	err := r.db.QueryRowContext(ctx, query, string(models.JobRunning), string(models.JobQueued)).Scan(
+		&job.ID, &job.FileID, &job.GenerateWordCloud, &wordCloudOptions, &job.WebhookURL, &job.Attempts,
+		&job.CreatedAt, &job.StartedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim analysis job: %w", err)
+	}
+	job.WordCloudOptions = []byte(wordCloudOptions)
+	return job, nil
+}
+
+// FinishAnalysisJob marks a running job as done or failed with the given error
+func (r *AnalysisRepo) FinishAnalysisJob(ctx context.Context, jobID string, status models.JobStatus, errorMessage string) error {
+	query := `
+		UPDATE analysis_jobs
+		SET status = $2, error = $3, finished_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, jobID, string(status), errorMessage)
+	if err != nil {
+		return fmt.Errorf("failed to finish analysis job: %w", err)
+	}
+	return nil
+}
+
+// GetAnalysisJob retrieves an analysis job by its ID
+func (r *AnalysisRepo) GetAnalysisJob(ctx context.Context, jobID string) (*models.AnalysisJob, error) {
+	query := `
+		SELECT file_id, generate_word_cloud, word_cloud_options, webhook_url, status, error, attempts,
+			created_at, started_at, finished_at
+		FROM analysis_jobs
+		WHERE id = $1
+	`
+	job := &models.AnalysisJob{ID: jobID}
+	var wordCloudOptions, status string
+	var startedAt, finishedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, jobID).Scan(
+		&job.FileID, &job.GenerateWordCloud, &wordCloudOptions, &job.WebhookURL, &status, &job.Error, &job.Attempts,
+		&job.CreatedAt, &startedAt, &finishedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("analysis job not found for ID %s: %w", jobID, repository.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get analysis job: %w", err)
+	}
+
+	job.WordCloudOptions = []byte(wordCloudOptions)
+	job.Status = models.JobStatus(status)
+	if startedAt.Valid {
+		job.StartedAt = startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = finishedAt.Time
+	}
+	return job, nil
+}
+
+// RequeueStaleAnalysisJobs queues again the jobs running for longer than staleAfter
+// and fails those already started maxAttempts times
+func (r *AnalysisRepo) RequeueStaleAnalysisJobs(ctx context.Context, staleAfter time.Duration, maxAttempts int32) (int64, error) {
+	query := `
+		UPDATE analysis_jobs
+		SET status = CASE WHEN attempts >= $2 THEN $3 ELSE $4 END,
+			error = CASE WHEN attempts >= $2 THEN 'the job did not finish in time' ELSE error END,
+			finished_at = CASE WHEN attempts >= $2 THEN CURRENT_TIMESTAMP ELSE NULL END
+		WHERE status = $5 AND started_at < CURRENT_TIMESTAMP - $1 * INTERVAL '1 second'
+	`
+	result, err := r.db.ExecContext(
+		ctx, query, staleAfter.Seconds(), maxAttempts,
+		string(models.JobFailed), string(models.JobQueued), string(models.JobRunning),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue stale analysis jobs: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count requeued analysis jobs: %w", err)
+	}
+	return count, nil
+}
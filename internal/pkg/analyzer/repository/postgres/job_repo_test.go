@@ -0,0 +1,317 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/repository"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/repository/postgres"
+)
+
+func TestCreateAnalysisJob(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewAnalysisRepo(db)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	job := &models.AnalysisJob{
+		ID:                "job123",
+		FileID:            "file123",
+		GenerateWordCloud: true,
+		WordCloudOptions:  []byte(`{"format":"svg"}`),
+		WebhookURL:        "https://lms.example.com/hooks",
+		CreatedAt:         createdAt,
+	}
+
+	// Test case: successful create
+	t.Run("Successful create", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectExec("INSERT INTO analysis_jobs").
+			WithArgs("job123", "file123", true, `{"format":"svg"}`, "https://lms.example.com/hooks", "queued", createdAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		// Call the method
+		err := repo.CreateAnalysisJob(context.Background(), job)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectExec("INSERT INTO analysis_jobs").
+			WithArgs("job123", "file123", true, `{"format":"svg"}`, "https://lms.example.com/hooks", "queued", createdAt).
+			WillReturnError(errors.New("database error"))
+
+		// Call the method
+		err := repo.CreateAnalysisJob(context.Background(), job)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create analysis job")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestClaimAnalysisJob(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewAnalysisRepo(db)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	startedAt := createdAt.Add(time.Second)
+
+	// Test case: queued job
+	t.Run("Queued job", func(t *testing.T) {
+		// Set up mock expectations
+		rows := sqlmock.NewRows([]string{
+			"id", "file_id", "generate_word_cloud", "word_cloud_options", "webhook_url", "attempts", "created_at", "started_at",
+		}).AddRow("job123", "file123", true, `{}`, "https://lms.example.com/hooks", int32(1), createdAt, startedAt)
+		mock.ExpectQuery("UPDATE analysis_jobs").
+			WithArgs("running", "queued").
+			WillReturnRows(rows)
+
+		// Call the method
+		job, err := repo.ClaimAnalysisJob(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		require.NotNil(t, job)
+		assert.Equal(t, "job123", job.ID)
+		assert.Equal(t, "file123", job.FileID)
+		assert.True(t, job.GenerateWordCloud)
+		assert.Equal(t, []byte(`{}`), job.WordCloudOptions)
+		assert.Equal(t, "https://lms.example.com/hooks", job.WebhookURL)
+		assert.Equal(t, models.JobRunning, job.Status)
+		assert.Equal(t, int32(1), job.Attempts)
+		assert.Equal(t, startedAt, job.StartedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: empty queue
+	t.Run("Empty queue", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectQuery("UPDATE analysis_jobs").
+			WithArgs("running", "queued").
+			WillReturnError(sql.ErrNoRows)
+
+		// Call the method
+		job, err := repo.ClaimAnalysisJob(context.Background())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, job)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectQuery("UPDATE analysis_jobs").
+			WithArgs("running", "queued").
+			WillReturnError(errors.New("database error"))
+
+		// Call the method
+		_, err := repo.ClaimAnalysisJob(context.Background())
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to claim analysis job")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFinishAnalysisJob(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewAnalysisRepo(db)
+
+	// Test case: failed job
+	t.Run("Failed job", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectExec("UPDATE analysis_jobs").
+			WithArgs("job123", "failed", "file not found").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Call the method
+		err := repo.FinishAnalysisJob(context.Background(), "job123", models.JobFailed, "file not found")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectExec("UPDATE analysis_jobs").
+			WithArgs("job123", "done", "").
+			WillReturnError(errors.New("database error"))
+
+		// Call the method
+		err := repo.FinishAnalysisJob(context.Background(), "job123", models.JobDone, "")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to finish analysis job")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetAnalysisJob(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewAnalysisRepo(db)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	startedAt := createdAt.Add(time.Second)
+	columns := []string{
+		"file_id", "generate_word_cloud", "word_cloud_options", "webhook_url", "status", "error", "attempts",
+		"created_at", "started_at", "finished_at",
+	}
+
+	// Test case: queued job
+	t.Run("Queued job", func(t *testing.T) {
+		// Set up mock expectations
+		rows := sqlmock.NewRows(columns).
+			AddRow("file123", false, `{}`, "", "queued", "", int32(0), createdAt, nil, nil)
+		mock.ExpectQuery("SELECT file_id, generate_word_cloud, word_cloud_options, webhook_url, status").
+			WithArgs("job123").
+			WillReturnRows(rows)
+
+		// Call the method
+		job, err := repo.GetAnalysisJob(context.Background(), "job123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "job123", job.ID)
+		assert.Equal(t, models.JobQueued, job.Status)
+		assert.Equal(t, createdAt, job.CreatedAt)
+		assert.True(t, job.StartedAt.IsZero())
+		assert.True(t, job.FinishedAt.IsZero())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: failed job
+	t.Run("Failed job", func(t *testing.T) {
+		// Set up mock expectations
+		finishedAt := startedAt.Add(time.Second)
+		rows := sqlmock.NewRows(columns).
+			AddRow("file123", true, `{}`, "", "failed", "file not found", int32(1), createdAt, startedAt, finishedAt)
+		mock.ExpectQuery("SELECT file_id, generate_word_cloud, word_cloud_options, webhook_url, status").
+			WithArgs("job123").
+			WillReturnRows(rows)
+
+		// Call the method
+		job, err := repo.GetAnalysisJob(context.Background(), "job123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, models.JobFailed, job.Status)
+		assert.Equal(t, "file not found", job.Error)
+		assert.Equal(t, int32(1), job.Attempts)
+		assert.Equal(t, startedAt, job.StartedAt)
+		assert.Equal(t, finishedAt, job.FinishedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: not found
+	t.Run("Not found", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectQuery("SELECT file_id, generate_word_cloud, word_cloud_options, webhook_url, status").
+			WithArgs("job123").
+			WillReturnError(sql.ErrNoRows)
+
+		// Call the method
+		_, err := repo.GetAnalysisJob(context.Background(), "job123")
+
+		// Assert
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectQuery("SELECT file_id, generate_word_cloud, word_cloud_options, webhook_url, status").
+			WithArgs("job123").
+			WillReturnError(errors.New("database error"))
+
+		// Call the method
+		_, err := repo.GetAnalysisJob(context.Background(), "job123")
+
+		// Assert
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, repository.ErrNotFound)
+		assert.Contains(t, err.Error(), "failed to get analysis job")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRequeueStaleAnalysisJobs(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewAnalysisRepo(db)
+
+	// Test case: stale jobs
+	t.Run("Stale jobs", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectExec("UPDATE analysis_jobs").
+			WithArgs(float64(600), int32(3), "failed", "queued", "running").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		// Call the method
+		count, err := repo.RequeueStaleAnalysisJobs(context.Background(), 10*time.Minute, 3)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectExec("UPDATE analysis_jobs").
+			WithArgs(float64(600), int32(3), "failed", "queued", "running").
+			WillReturnError(errors.New("database error"))
+
+		// Call the method
+		_, err := repo.RequeueStaleAnalysisJobs(context.Background(), 10*time.Minute, 3)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to requeue stale analysis jobs")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,292 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/repository"
+)
+
+// WebhookRepo implements the WebhookRepository interface using PostgreSQL
+type WebhookRepo struct {
+	db *sql.DB
+}
+
+// NewWebhookRepo creates a new WebhookRepo instance
+func NewWebhookRepo(db *sql.DB) repository.WebhookRepository {
+	return &WebhookRepo{db: db}
+}
+
+// CreateWebhook saves a new webhook registration
+func (r *WebhookRepo) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	query := `
+		INSERT INTO webhooks (id, url, secret, events, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.db.ExecContext(
+		ctx, query, webhook.ID, webhook.URL, webhook.Secret, pq.Array(eventStrings(webhook.Events)), webhook.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+// GetWebhook retrieves a webhook by its ID
+func (r *WebhookRepo) GetWebhook(ctx context.Context, webhookID string) (*models.Webhook, error) {
+	query := `
+		SELECT id, url, secret, events, created_at
+		FROM webhooks
+		WHERE id = $1
+	`
+	webhook, err := scanWebhook(r.db.QueryRowContext(ctx, query, webhookID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("webhook not found for ID %s: %w", webhookID, repository.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// ListWebhooks retrieves all webhooks, oldest first
+func (r *WebhookRepo) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	query := `
+		SELECT id, url, secret, events, created_at
+		FROM webhooks
+		ORDER BY created_at, id
+	`
+	return r.queryWebhooks(ctx, query)
+}
+
+// GetWebhooksForEvent retrieves the webhooks notified of the event
+func (r *WebhookRepo) GetWebhooksForEvent(ctx context.Context, event models.WebhookEvent) ([]models.Webhook, error) {
+	query := `
+		SELECT id, url, secret, events, created_at
+		FROM webhooks
+		WHERE $1 = ANY(events)
+		ORDER BY created_at, id
+	`
+	return r.queryWebhooks(ctx, query, string(event))
+}
+
+// queryWebhooks retrieves the webhooks selected by the query
+func (r *WebhookRepo) queryWebhooks(ctx context.Context, query string, args ...interface{}) ([]models.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []models.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanWebhook scans a webhook selected as id, url, secret, events, created_at
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	webhook := &models.Webhook{}
+	var events []string
+	if err := row.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, pq.Array(&events), &webhook.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	webhook.Events = make([]models.WebhookEvent, len(events))
+	for i, event := range events {
+		webhook.Events[i] = models.WebhookEvent(event)
+	}
+	return webhook, nil
+}
+
+// eventStrings converts webhook events to strings for a text array
+func eventStrings(events []models.WebhookEvent) []string {
+	values := make([]string, len(events))
+	for i, event := range events {
+		values[i] = string(event)
+	}
+	return values
+}
+
+// DeleteWebhook deletes a webhook
+func (r *WebhookRepo) DeleteWebhook(ctx context.Context, webhookID string) error {
+	query := `
+		DELETE FROM webhooks WHERE id = $1
+	`
+	result, err := r.db.ExecContext(ctx, query, webhookID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to count deleted webhooks: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("webhook not found for ID %s: %w", webhookID, repository.ErrNotFound)
+	}
+	return nil
+}
+
+// CreateWebhookDeliveries saves new pending deliveries, due immediately
+func (r *WebhookRepo) CreateWebhookDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO webhook_deliveries (
+			id, webhook_id, url, event, file_id, payload, status, created_at, next_attempt_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+	`
+	for _, delivery := range deliveries {
+		_, err := tx.ExecContext(
+			ctx, query, delivery.ID, delivery.WebhookID, delivery.URL, string(delivery.Event), delivery.FileID,
+			string(delivery.Payload), string(models.DeliveryPending), delivery.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create webhook delivery: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// deliveryColumns are the columns scanned by scanWebhookDelivery
+const deliveryColumns = `id, webhook_id, url, event, file_id, payload, status, attempts,
+	response_status, error, created_at, next_attempt_at, last_attempt_at`
+
+// ClaimWebhookDelivery returns the pending delivery due the earliest and postpones it for the lease
+func (r *WebhookRepo) ClaimWebhookDelivery(ctx context.Context, lease time.Duration) (*models.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1,
+			last_attempt_at = CURRENT_TIMESTAMP,
+			next_attempt_at = CURRENT_TIMESTAMP + $1 * INTERVAL '1 second'
+		WHERE id = (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $2 AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY next_attempt_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, query, lease.Seconds(), string(models.DeliveryPending)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// RecordWebhookAttempt saves the outcome of a delivery attempt
+func (r *WebhookRepo) RecordWebhookAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, response_status = $3, error = $4, next_attempt_at = $5
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(
+		ctx, query, delivery.ID, string(delivery.Status), delivery.ResponseStatus, delivery.Error, delivery.NextAttemptAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries retrieves the deliveries matching the filter, newest first
+func (r *WebhookRepo) ListWebhookDeliveries(ctx context.Context, filter models.WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.WebhookID != "" {
+		args = append(args, filter.WebhookID)
+		conditions = append(conditions, fmt.Sprintf("webhook_id = $%d", len(args)))
+	}
+	if filter.FileID != "" {
+		args = append(args, filter.FileID)
+		conditions = append(conditions, fmt.Sprintf("file_id = $%d", len(args)))
+	}
+
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY created_at DESC, id`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// scanWebhookDelivery scans a delivery selected as deliveryColumns
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	var event, payload, status string
+	var lastAttemptAt sql.NullTime
+	err := row.Scan(
+		&delivery.ID, &delivery.WebhookID, &delivery.URL, &event, &delivery.FileID, &payload, &status,
+		&delivery.Attempts, &delivery.ResponseStatus, &delivery.Error,
+		&delivery.CreatedAt, &delivery.NextAttemptAt, &lastAttemptAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Event = models.WebhookEvent(event)
+	delivery.Payload = []byte(payload)
+	delivery.Status = models.DeliveryStatus(status)
+	if lastAttemptAt.Valid {
+		delivery.LastAttemptAt = lastAttemptAt.Time
+	}
+	return delivery, nil
+}
@@ -0,0 +1,428 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/repository"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/repository/postgres"
+)
+
+var deliveryColumns = []string{
+	"id", "webhook_id", "url", "event", "file_id", "payload", "status", "attempts",
+	"response_status", "error", "created_at", "next_attempt_at", "last_attempt_at",
+}
+
+func TestCreateWebhook(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewWebhookRepo(db)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	webhook := &models.Webhook{
+		ID:        "hook123",
+		URL:       "https://lms.example.com/hooks",
+		Secret:    "secret",
+		Events:    []models.WebhookEvent{models.EventPlagiarismDetected},
+		CreatedAt: createdAt,
+	}
+
+	// Test case: successful create
+	t.Run("Successful create", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectExec("INSERT INTO webhooks").
+			WithArgs("hook123", "https://lms.example.com/hooks", "secret", pq.Array([]string{"plagiarism.detected"}), createdAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		// Call the method
+		err := repo.CreateWebhook(context.Background(), webhook)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectExec("INSERT INTO webhooks").
+			WillReturnError(errors.New("database error"))
+
+		// Call the method
+		err := repo.CreateWebhook(context.Background(), webhook)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create webhook")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetWebhook(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewWebhookRepo(db)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Test case: successful get
+	t.Run("Successful get", func(t *testing.T) {
+		// Set up mock expectations
+		rows := sqlmock.NewRows([]string{"id", "url", "secret", "events", "created_at"}).
+			AddRow("hook123", "https://lms.example.com/hooks", "secret", "{analysis.completed,plagiarism.detected}", createdAt)
+		mock.ExpectQuery("SELECT id, url, secret, events, created_at").
+			WithArgs("hook123").
+			WillReturnRows(rows)
+
+		// Call the method
+		webhook, err := repo.GetWebhook(context.Background(), "hook123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, &models.Webhook{
+			ID:        "hook123",
+			URL:       "https://lms.example.com/hooks",
+			Secret:    "secret",
+			Events:    []models.WebhookEvent{models.EventAnalysisCompleted, models.EventPlagiarismDetected},
+			CreatedAt: createdAt,
+		}, webhook)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: not found
+	t.Run("Not found", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectQuery("SELECT id, url, secret, events, created_at").
+			WithArgs("hook123").
+			WillReturnError(sql.ErrNoRows)
+
+		// Call the method
+		_, err := repo.GetWebhook(context.Background(), "hook123")
+
+		// Assert
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetWebhooksForEvent(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewWebhookRepo(db)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Test case: matching webhooks
+	t.Run("Matching webhooks", func(t *testing.T) {
+		// Set up mock expectations
+		rows := sqlmock.NewRows([]string{"id", "url", "secret", "events", "created_at"}).
+			AddRow("hook123", "https://lms.example.com/hooks", "secret", "{plagiarism.detected}", createdAt).
+			AddRow("hook456", "https://audit.example.com/hooks", "other", "{analysis.completed,plagiarism.detected}", createdAt)
+		mock.ExpectQuery("SELECT id, url, secret, events, created_at FROM webhooks WHERE").
+			WithArgs("plagiarism.detected").
+			WillReturnRows(rows)
+
+		// Call the method
+		webhooks, err := repo.GetWebhooksForEvent(context.Background(), models.EventPlagiarismDetected)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, webhooks, 2)
+		assert.Equal(t, "hook123", webhooks[0].ID)
+		assert.Equal(t, "https://audit.example.com/hooks", webhooks[1].URL)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectQuery("SELECT id, url, secret, events, created_at FROM webhooks WHERE").
+			WithArgs("analysis.completed").
+			WillReturnError(errors.New("database error"))
+
+		// Call the method
+		_, err := repo.GetWebhooksForEvent(context.Background(), models.EventAnalysisCompleted)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to get webhooks")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewWebhookRepo(db)
+
+	// Test case: successful delete
+	t.Run("Successful delete", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectExec("DELETE FROM webhooks").
+			WithArgs("hook123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Call the method
+		err := repo.DeleteWebhook(context.Background(), "hook123")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: not found
+	t.Run("Not found", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectExec("DELETE FROM webhooks").
+			WithArgs("hook123").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Call the method
+		err := repo.DeleteWebhook(context.Background(), "hook123")
+
+		// Assert
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCreateWebhookDeliveries(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewWebhookRepo(db)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	deliveries := []models.WebhookDelivery{
+		{
+			ID: "delivery1", WebhookID: "hook123", URL: "https://lms.example.com/hooks",
+			Event: models.EventAnalysisCompleted, FileID: "file123", Payload: []byte(`{}`), CreatedAt: createdAt,
+		},
+		{
+			ID: "delivery2", URL: "https://request.example.com/hook",
+			Event: models.EventAnalysisCompleted, FileID: "file123", Payload: []byte(`{}`), CreatedAt: createdAt,
+		},
+	}
+
+	// Test case: successful create
+	t.Run("Successful create", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WithArgs("delivery1", "hook123", "https://lms.example.com/hooks", "analysis.completed", "file123", `{}`, "pending", createdAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WithArgs("delivery2", "", "https://request.example.com/hook", "analysis.completed", "file123", `{}`, "pending", createdAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		// Call the method
+		err := repo.CreateWebhookDeliveries(context.Background(), deliveries)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		// Call the method
+		err := repo.CreateWebhookDeliveries(context.Background(), deliveries)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create webhook delivery")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestClaimWebhookDelivery(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewWebhookRepo(db)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	nextAttemptAt := createdAt.Add(time.Minute)
+
+	// Test case: due delivery
+	t.Run("Due delivery", func(t *testing.T) {
+		// Set up mock expectations
+		rows := sqlmock.NewRows(deliveryColumns).AddRow(
+			"delivery1", "hook123", "https://lms.example.com/hooks", "analysis.completed", "file123", `{"event":"analysis.completed"}`,
+			"pending", int32(2), int32(500), "unexpected status 500", createdAt, nextAttemptAt, createdAt,
+		)
+		mock.ExpectQuery("UPDATE webhook_deliveries").
+			WithArgs(float64(60), "pending").
+			WillReturnRows(rows)
+
+		// Call the method
+		delivery, err := repo.ClaimWebhookDelivery(context.Background(), time.Minute)
+
+		// Assert
+		require.NoError(t, err)
+		require.NotNil(t, delivery)
+		assert.Equal(t, "delivery1", delivery.ID)
+		assert.Equal(t, models.EventAnalysisCompleted, delivery.Event)
+		assert.Equal(t, []byte(`{"event":"analysis.completed"}`), delivery.Payload)
+		assert.Equal(t, models.DeliveryPending, delivery.Status)
+		assert.Equal(t, int32(2), delivery.Attempts)
+		assert.Equal(t, createdAt, delivery.LastAttemptAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: nothing due
+	t.Run("Nothing due", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectQuery("UPDATE webhook_deliveries").
+			WithArgs(float64(60), "pending").
+			WillReturnError(sql.ErrNoRows)
+
+		// Call the method
+		delivery, err := repo.ClaimWebhookDelivery(context.Background(), time.Minute)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Nil(t, delivery)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRecordWebhookAttempt(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewWebhookRepo(db)
+
+	nextAttemptAt := time.Date(2024, 5, 1, 12, 1, 0, 0, time.UTC)
+
+	// Test case: failed attempt
+	t.Run("Failed attempt", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectExec("UPDATE webhook_deliveries").
+			WithArgs("delivery1", "pending", int32(503), "unexpected status 503", nextAttemptAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Call the method
+		err := repo.RecordWebhookAttempt(context.Background(), &models.WebhookDelivery{
+			ID:             "delivery1",
+			Status:         models.DeliveryPending,
+			ResponseStatus: 503,
+			Error:          "unexpected status 503",
+			NextAttemptAt:  nextAttemptAt,
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: database error
+	t.Run("Database error", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectExec("UPDATE webhook_deliveries").
+			WillReturnError(errors.New("database error"))
+
+		// Call the method
+		err := repo.RecordWebhookAttempt(context.Background(), &models.WebhookDelivery{ID: "delivery1"})
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to record webhook attempt")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestListWebhookDeliveries(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Create a new repository with the mock database
+	repo := postgres.NewWebhookRepo(db)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Test case: filtered deliveries
+	t.Run("Filtered deliveries", func(t *testing.T) {
+		// Set up mock expectations
+		rows := sqlmock.NewRows(deliveryColumns).AddRow(
+			"delivery1", "hook123", "https://lms.example.com/hooks", "analysis.completed", "file123", `{}`,
+			"delivered", int32(1), int32(204), "", createdAt, createdAt, nil,
+		)
+		mock.ExpectQuery(`SELECT .+ FROM webhook_deliveries WHERE webhook_id = \$1 AND file_id = \$2 ORDER BY created_at DESC, id LIMIT \$3`).
+			WithArgs("hook123", "file123", 10).
+			WillReturnRows(rows)
+
+		// Call the method
+		deliveries, err := repo.ListWebhookDeliveries(context.Background(), models.WebhookDeliveryFilter{
+			WebhookID: "hook123",
+			FileID:    "file123",
+			Limit:     10,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, models.DeliveryDelivered, deliveries[0].Status)
+		assert.Equal(t, int32(204), deliveries[0].ResponseStatus)
+		assert.True(t, deliveries[0].LastAttemptAt.IsZero())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: all deliveries
+	t.Run("All deliveries", func(t *testing.T) {
+		// Set up mock expectations
+		mock.ExpectQuery(`SELECT .+ FROM webhook_deliveries ORDER BY created_at DESC, id$`).
+			WillReturnRows(sqlmock.NewRows(deliveryColumns))
+
+		// Call the method
+		deliveries, err := repo.ListWebhookDeliveries(context.Background(), models.WebhookDeliveryFilter{})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, deliveries)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -1,11 +1,26 @@
+// Package service implements the analyses of the analyzer service and the notifications about them:
+//   - analyses run at once or are queued in PostgreSQL for a pool of workers, see JobWorkerPool,
+//     so that queued jobs survive restarts and the jobs of stopped workers are run again
+//   - the stages of running analyses are published to their subscribers, see ProgressBroker
+//   - files analyzed before the candidate index existed are indexed in the background,
+//     failing files are retried with exponential backoff, see IndexBackfill
+//   - webhooks receive HMAC-signed notifications of finished analyses and detected plagiarism,
+//     failed deliveries are retried with exponential backoff and internal addresses are refused,
+//     see WebhookService
 package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
 
 	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
 	"local.dev/doc-analyzer/internal/pkg/analyzer/clients"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
 	"local.dev/doc-analyzer/internal/pkg/analyzer/repository"
 	"local.dev/doc-analyzer/internal/pkg/analyzer/storage"
 )
@@ -17,7 +32,17 @@ type AnalysisService struct {
 	fileStoringClient  clients.FileStoringClientInterface
 	textAnalyzer       *analyzer.TextAnalyzer
 	plagiarismChecker  *analyzer.PlagiarismChecker
-	wordCloudGenerator *analyzer.WordCloudGenerator
+	wordCloudGenerator analyzer.WordCloudGenerator
+	summarizer         *analyzer.Summarizer
+
+	// jobSubmitted wakes a waiting worker when an analysis job is queued
+	jobSubmitted chan struct{}
+
+	// webhooks are notified of finished analyses, nil if there are none
+	webhooks *WebhookService
+
+	// progress relays the progress of the running analyses to their watchers
+	progress *ProgressBroker
 }
 
 // NewAnalysisService creates a new AnalysisService instance
@@ -27,7 +52,8 @@ func NewAnalysisService(
 	fileStoringClient clients.FileStoringClientInterface,
 	textAnalyzer *analyzer.TextAnalyzer,
 	plagiarismChecker *analyzer.PlagiarismChecker,
-	wordCloudGenerator *analyzer.WordCloudGenerator,
+	wordCloudGenerator analyzer.WordCloudGenerator,
+	summarizer *analyzer.Summarizer,
 ) *AnalysisService {
 	return &AnalysisService{
 		repo:               repo,
@@ -36,55 +62,210 @@ func NewAnalysisService(
 		textAnalyzer:       textAnalyzer,
 		plagiarismChecker:  plagiarismChecker,
 		wordCloudGenerator: wordCloudGenerator,
+		summarizer:         summarizer,
+		jobSubmitted:       make(chan struct{}, 1),
+		progress:           NewProgressBroker(),
+	}
+}
+
+// SetWebhookService sets the service notifying webhooks of finished analyses
+// It must be called before the workers running analysis jobs are started
+func (s *AnalysisService) SetWebhookService(webhooks *WebhookService) {
+	s.webhooks = webhooks
+}
+
+// notify creates the webhook deliveries of a finished analysis
+// Failures are logged, they do not fail the analysis
+func (s *AnalysisService) notify(ctx context.Context, notification AnalysisNotification) {
+	if s.webhooks == nil {
+		return
+	}
+	if err := s.webhooks.Notify(ctx, notification); err != nil {
+		fmt.Printf("Failed to notify webhooks of file %s: %v\n", notification.FileID, err)
+	}
+}
+
+// reportProgress publishes the stage of a running analysis to its watchers
+func (s *AnalysisService) reportProgress(fileID string, stage models.AnalysisStage, current, total int) {
+	s.progress.Publish(models.AnalysisProgress{
+		FileID:  fileID,
+		Stage:   stage,
+		Current: int32(current),
+		Total:   int32(total),
+	})
+}
+
+// reportFinished publishes the end of an analysis to its watchers
+func (s *AnalysisService) reportFinished(fileID string, err error) {
+	if err != nil {
+		s.progress.Publish(models.AnalysisProgress{FileID: fileID, Stage: models.StageFailed, Error: err.Error()})
+		return
+	}
+	s.progress.Publish(models.AnalysisProgress{FileID: fileID, Stage: models.StageDone})
+}
+
+// WatchAnalysis sends the progress of the analyses of a file until one finishes or ctx is done
+// Only the analyses run by this instance are watched
+// For an analyzed file without a running analysis a single done event is sent,
+// otherwise the events of the next analysis are waited for
+func (s *AnalysisService) WatchAnalysis(ctx context.Context, fileID string, send func(models.AnalysisProgress) error) error {
+	events, running, unsubscribe := s.progress.Subscribe(fileID)
+	defer unsubscribe()
+
+	if !running {
+		if _, err := s.repo.GetAnalysisResult(ctx, fileID); err == nil {
+			return send(models.AnalysisProgress{FileID: fileID, Stage: models.StageDone, Time: time.Now().UTC()})
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event := <-events:
+			if err := send(event); err != nil {
+				return err
+			}
+			if event.Stage.Finished() {
+				return nil
+			}
+		}
 	}
 }
 
 // AnalyzeFile analyzes a file and returns the analysis results
-func (s *AnalysisService) AnalyzeFile(ctx context.Context, fileID string, generateWordCloud bool) (
-	paragraphCount, wordCount, characterCount int32,
-	isPlagiarism bool,
-	similarFileIDs []string,
-	wordCloudLocation string,
-	err error,
-) {
+// Word cloud options are only used when a word cloud is generated
+// For an analyzed file a new word cloud is generated if it has none yet or the options are set
+// The registered webhooks are notified when the file is analyzed for the first time
+func (s *AnalysisService) AnalyzeFile(ctx context.Context, fileID string, generateWordCloud bool, wordCloudOptions analyzer.WordCloudOptions) (*models.AnalysisResult, error) {
+	result, analyzed, err := s.analyzeFile(ctx, fileID, generateWordCloud, wordCloudOptions)
+	s.reportFinished(fileID, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if analyzed {
+		s.notify(ctx, AnalysisNotification{FileID: fileID, Result: result})
+	}
+	return result, nil
+}
+
+// analyzeFile analyzes a file and returns the analysis results,
+// reporting whether the file was analyzed rather than its existing results returned
+func (s *AnalysisService) analyzeFile(ctx context.Context, fileID string, generateWordCloud bool, wordCloudOptions analyzer.WordCloudOptions) (*models.AnalysisResult, bool, error) {
 	// Try to get existing analysis results
-	paragraphCount, wordCount, characterCount, isPlagiarism, wordCloudLocation, err = s.repo.GetAnalysisResult(ctx, fileID)
+	result, err := s.repo.GetAnalysisResult(ctx, fileID)
 	if err == nil {
-		// Analysis results exist, get similar file IDs if it's plagiarism
-		if isPlagiarism {
-			similarFileIDs, err = s.repo.GetSimilarFiles(ctx, fileID)
+		// Analysis results exist, get similar files if it's plagiarism
+		if result.IsPlagiarism {
+			result.SimilarFiles, err = s.repo.GetSimilarFiles(ctx, fileID)
 			if err != nil {
-				return 0, 0, 0, false, nil, "", fmt.Errorf("failed to get similar files: %w", err)
+				return nil, false, fmt.Errorf("failed to get similar files: %w", err)
 			}
 		}
-		return paragraphCount, wordCount, characterCount, isPlagiarism, similarFileIDs, wordCloudLocation, nil
+
+		if generateWordCloud && (result.WordCloudLocation == "" || wordCloudOptions != (analyzer.WordCloudOptions{})) {
+			s.reportProgress(fileID, models.StageFetchingFile, 0, 0)
+			_, text, err := s.fileStoringClient.GetFile(ctx, fileID)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to get file content: %w", err)
+			}
+
+			s.reportProgress(fileID, models.StageGeneratingWordCloud, 0, 0)
+			location, err := s.createWordCloud(ctx, string(text), analyzer.Language(result.Language), wordCloudOptions)
+			if err != nil {
+				// Log the error but keep the previous word cloud
+				fmt.Printf("Failed to create word cloud: %v\n", err)
+				return result, false, nil
+			}
+
+			result.WordCloudLocation = location
+			result.WordCloudFormat = string(analyzer.WordCloudFormatOf(location))
+			s.reportProgress(fileID, models.StageSaving, 0, 0)
+			if err := s.repo.SaveAnalysisResult(ctx, result); err != nil {
+				return nil, false, fmt.Errorf("failed to save analysis results: %w", err)
+			}
+		}
+		return result, false, nil
 	}
 
 	// Get file content from File Storing Service
+	s.reportProgress(fileID, models.StageFetchingFile, 0, 0)
 	_, content, err := s.fileStoringClient.GetFile(ctx, fileID)
 	if err != nil {
-		return 0, 0, 0, false, nil, "", fmt.Errorf("failed to get file content: %w", err)
+		return nil, false, fmt.Errorf("failed to get file content: %w", err)
 	}
 
 	// Convert content to string
 	contentStr := string(content)
+	s.reportProgress(fileID, models.StageAnalyzingText, 0, 0)
 
 	// Analyze text
-	paragraphCount, wordCount, characterCount = s.textAnalyzer.AnalyzeText(contentStr)
+	result = &models.AnalysisResult{FileID: fileID}
+	metrics := s.textAnalyzer.GetTextMetrics(contentStr)
+	result.ParagraphCount = metrics.ParagraphCount
+	result.WordCount = metrics.WordCount
+	result.CharacterCount = metrics.CharacterCount
+	result.CharacterCountNoSpaces = metrics.CharacterCountNoSpaces
+	result.SentenceCount = metrics.SentenceCount
+	result.AvgSentenceLength = metrics.AvgSentenceLength
+	result.AvgWordLength = metrics.AvgWordLength
+	result.LexicalDiversity = metrics.LexicalDiversity
+
+	// Detect language to pick the stop words and the stemmer for the word cloud
+	// and the readability formula
+	language := s.textAnalyzer.DetectLanguage(contentStr)
+	result.Language = string(language)
+
+	readability := s.textAnalyzer.GetReadability(contentStr, language)
+	result.ReadabilityFormula = readability.Formula
+	result.ReadingEase = readability.ReadingEase
+	result.GradeLevel = readability.GradeLevel
+
+	// Summarize text
+	result.Summary = s.summarizer.Summarize(contentStr, language)
+
+	// Record obfuscation attempts, they are a red flag by themselves
+	obfuscation := s.textAnalyzer.InspectObfuscation(contentStr)
+	result.ObfuscationDetected = obfuscation.Detected()
+	result.HomoglyphCount = int32(obfuscation.Homoglyphs)
+	result.InvisibleCharCount = int32(obfuscation.InvisibleCharacters)
 
 	// Check for plagiarism
-	// First, get all other file IDs
-	otherFileIDs, err := s.repo.GetAllFileIDs(ctx)
+	// First, look up candidate files sharing an LSH bucket with the current file,
+	// so that only they are fetched and compared in full
+	signature := s.plagiarismChecker.Signature(contentStr)
+	bandKeys := s.plagiarismChecker.MinHasher.BandKeys(signature)
+
+	candidateIDs, err := s.repo.FindCandidates(ctx, bandKeys)
 	if err != nil {
-		return 0, 0, 0, false, nil, "", fmt.Errorf("failed to get all file IDs: %w", err)
+		return nil, false, fmt.Errorf("failed to find plagiarism candidates: %w", err)
 	}
 
-	// Get content of all other files
+	// Then add the files sharing winnowing fingerprints, the signature of a long document
+	// barely changes when a short passage of it is copied
+	fingerprints := s.plagiarismChecker.Fingerprints(contentStr)
+	if len(fingerprints) > 0 {
+		matches, err := s.repo.FindFingerprintMatches(ctx, fingerprints)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to find fingerprint matches: %w", err)
+		}
+		for _, match := range matches {
+			if s.plagiarismChecker.IsFingerprintCandidate(match, len(fingerprints)) {
+				candidateIDs = append(candidateIDs, match.FileID)
+			}
+		}
+	}
+
+	// Get content of the candidate files
 	otherContents := make(map[string]string)
-	for _, otherFileID := range otherFileIDs {
+	for _, otherFileID := range candidateIDs {
 		if otherFileID == fileID {
 			continue // Skip the current file
 		}
+		if _, ok := otherContents[otherFileID]; ok {
+			continue // Found by both the LSH index and the fingerprints
+		}
 
 		_, otherContent, err := s.fileStoringClient.GetFile(ctx, otherFileID)
 		if err != nil {
@@ -97,54 +278,323 @@ func (s *AnalysisService) AnalyzeFile(ctx context.Context, fileID string, genera
 	}
 
 	// Check for plagiarism
-	isPlagiarism, similarFileIDs = s.plagiarismChecker.CheckPlagiarism(ctx, contentStr, otherContents)
+	s.reportProgress(fileID, models.StageComparing, 0, len(otherContents))
+	result.SimilarFiles = s.plagiarismChecker.FindSimilarFilesWithProgress(ctx, contentStr, otherContents, func(compared, total int) {
+		s.reportProgress(fileID, models.StageComparing, compared, total)
+	})
+	result.IsPlagiarism = len(result.SimilarFiles) > 0
 
 	// Generate word cloud if requested
 	if generateWordCloud {
+		s.reportProgress(fileID, models.StageGeneratingWordCloud, 0, 0)
 		var text []byte
 		_, text, err = s.fileStoringClient.GetFile(ctx, fileID)
 
 		if err != nil {
-			return 0, 0, 0, false, nil, "", fmt.Errorf("failed to get file content: %w", err)
+			return nil, false, fmt.Errorf("failed to get file content: %w", err)
 		}
 
-		// Generate word cloud
-		wordCloudImage, location, err := s.wordCloudGenerator.GenerateWordCloud(ctx, string(text))
+		location, err := s.createWordCloud(ctx, string(text), language, wordCloudOptions)
 		if err != nil {
 			// Log the error but continue without word cloud
-			fmt.Printf("Failed to generate word cloud: %v\n", err)
+			fmt.Printf("Failed to create word cloud: %v\n", err)
 		} else {
-			// Save word cloud image
-			err = s.storage.SaveWordCloud(ctx, location, wordCloudImage)
-			if err != nil {
-				fmt.Printf("Failed to save word cloud: %v\n", err)
-			} else {
-				wordCloudLocation = location
-			}
+			result.WordCloudLocation = location
+			result.WordCloudFormat = string(analyzer.WordCloudFormatOf(location))
 		}
 	}
 
 	// Save analysis results
-	err = s.repo.SaveAnalysisResult(ctx, fileID, paragraphCount, wordCount, characterCount, isPlagiarism, wordCloudLocation)
+	s.reportProgress(fileID, models.StageSaving, 0, 0)
+	err = s.repo.SaveAnalysisResult(ctx, result)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to save analysis results: %w", err)
+	}
+
+	// Add the file to the keyword corpus
+	err = s.repo.SaveTermCounts(ctx, fileID, s.textAnalyzer.GetTermCounts(contentStr))
 	if err != nil {
-		return 0, 0, 0, false, nil, "", fmt.Errorf("failed to save analysis results: %w", err)
+		// Log the error but continue, the terms are counted again when keywords are requested
+		fmt.Printf("Failed to save term counts for file %s: %v\n", fileID, err)
+	}
+
+	// Add the file to the LSH index so that later analyses can find it
+	err = s.repo.SaveSignature(ctx, fileID, signature, bandKeys, fingerprints, analyzer.AlgorithmVersion)
+	if err != nil {
+		// Log the error but continue, the file is indexed again on a later analysis
+		fmt.Printf("Failed to save signature for file %s: %v\n", fileID, err)
 	}
 
 	// Save similar files if plagiarism is detected
-	if isPlagiarism {
-		for _, similarFileID := range similarFileIDs {
-			err = s.repo.SaveSimilarFile(ctx, fileID, similarFileID)
-			if err != nil {
-				// Log the error but continue with other similar files
-				fmt.Printf("Failed to save similar file %s: %v\n", similarFileID, err)
-			}
+	for _, similarFile := range result.SimilarFiles {
+		err = s.repo.SaveSimilarFile(ctx, fileID, similarFile)
+		if err != nil {
+			// Log the error but continue with other similar files
+			fmt.Printf("Failed to save similar file %s: %v\n", similarFile.FileID, err)
 		}
 	}
 
-	return paragraphCount, wordCount, characterCount, isPlagiarism, similarFileIDs, wordCloudLocation, nil
+	return result, true, nil
+}
+
+// SubmitAnalysis queues an analysis of a file to be run by a worker and returns the queued job
+// The webhook URL, if set, is notified when the job finishes, besides the registered webhooks
+func (s *AnalysisService) SubmitAnalysis(ctx context.Context, fileID string, generateWordCloud bool, wordCloudOptions analyzer.WordCloudOptions, webhookURL string) (*models.AnalysisJob, error) {
+	options, err := json.Marshal(wordCloudOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode word cloud options: %w", err)
+	}
+
+	job := &models.AnalysisJob{
+		ID:                uuid.New().String(),
+		FileID:            fileID,
+		GenerateWordCloud: generateWordCloud,
+		WordCloudOptions:  options,
+		WebhookURL:        webhookURL,
+		Status:            models.JobQueued,
+		CreatedAt:         time.Now().UTC(),
+	}
+	if err := s.repo.CreateAnalysisJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create analysis job: %w", err)
+	}
+	s.reportProgress(fileID, models.StageQueued, 0, 0)
+
+	// Wake a waiting worker, the others find the job when they poll the queue
+	select {
+	case s.jobSubmitted <- struct{}{}:
+	default:
+	}
+	return job, nil
 }
 
-// GetWordCloud retrieves a word cloud image by its location
-func (s *AnalysisService) GetWordCloud(ctx context.Context, location string) ([]byte, error) {
-	return s.storage.GetWordCloud(ctx, location)
+// GetAnalysisJob retrieves an analysis job, with the analysis results once it is done
+func (s *AnalysisService) GetAnalysisJob(ctx context.Context, jobID string) (*models.AnalysisJob, error) {
+	job, err := s.repo.GetAnalysisJob(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get analysis job: %w", err)
+	}
+	if job.Status != models.JobDone {
+		return job, nil
+	}
+
+	job.Result, err = s.repo.GetAnalysisResult(ctx, job.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get analysis results: %w", err)
+	}
+	if job.Result.IsPlagiarism {
+		job.Result.SimilarFiles, err = s.repo.GetSimilarFiles(ctx, job.FileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get similar files: %w", err)
+		}
+	}
+	return job, nil
+}
+
+// RunNextJob claims the oldest queued analysis job and runs it for at most timeout, if it is positive
+// It reports whether a job was claimed
+// A job interrupted by the cancellation of ctx is left running, to be requeued as stale
+// The webhooks are notified of finished jobs, even if the file was analyzed before
+func (s *AnalysisService) RunNextJob(ctx context.Context, timeout time.Duration) (bool, error) {
+	job, err := s.repo.ClaimAnalysisJob(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim analysis job: %w", err)
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	jobCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		jobCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	result, err := s.runJob(jobCtx, job)
+	if err != nil && ctx.Err() != nil {
+		return true, fmt.Errorf("analysis job %s interrupted: %w", job.ID, err)
+	}
+
+	status, errorMessage := models.JobDone, ""
+	if err != nil {
+		status, errorMessage = models.JobFailed, err.Error()
+	}
+	if err := s.repo.FinishAnalysisJob(ctx, job.ID, status, errorMessage); err != nil {
+		return true, fmt.Errorf("failed to finish analysis job: %w", err)
+	}
+	s.reportFinished(job.FileID, err)
+
+	s.notify(ctx, AnalysisNotification{
+		FileID:     job.FileID,
+		JobID:      job.ID,
+		Result:     result,
+		Error:      errorMessage,
+		WebhookURL: job.WebhookURL,
+	})
+	return true, nil
+}
+
+// runJob analyzes the file of a job with the options of the request
+func (s *AnalysisService) runJob(ctx context.Context, job *models.AnalysisJob) (*models.AnalysisResult, error) {
+	var wordCloudOptions analyzer.WordCloudOptions
+	if len(job.WordCloudOptions) > 0 {
+		if err := json.Unmarshal(job.WordCloudOptions, &wordCloudOptions); err != nil {
+			return nil, fmt.Errorf("failed to decode word cloud options: %w", err)
+		}
+	}
+
+	result, _, err := s.analyzeFile(ctx, job.FileID, job.GenerateWordCloud, wordCloudOptions)
+	return result, err
+}
+
+// RequeueStaleJobs queues again the analysis jobs running for longer than staleAfter
+// and fails those already started maxAttempts times, returning their number
+func (s *AnalysisService) RequeueStaleJobs(ctx context.Context, staleAfter time.Duration, maxAttempts int32) (int64, error) {
+	count, err := s.repo.RequeueStaleAnalysisJobs(ctx, staleAfter, maxAttempts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue stale analysis jobs: %w", err)
+	}
+	return count, nil
+}
+
+// GetMatchedPassages retrieves the passages of a file copied from a similar file
+// repository.ErrNotFound is returned when the file was not analyzed or the other file was not found similar to it
+func (s *AnalysisService) GetMatchedPassages(ctx context.Context, fileID, similarFileID string) ([]models.MatchedPassage, error) {
+	passages, err := s.repo.GetMatchedPassages(ctx, fileID, similarFileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get matched passages: %w", err)
+	}
+	if len(passages) > 0 {
+		return passages, nil
+	}
+
+	// No passages are stored for pairs that were never compared, tell them apart from similar files without passages
+	if _, err := s.repo.GetAnalysisResult(ctx, fileID); err != nil {
+		return nil, fmt.Errorf("failed to get analysis results: %w", err)
+	}
+	similarFiles, err := s.repo.GetSimilarFiles(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get similar files: %w", err)
+	}
+	if !slices.ContainsFunc(similarFiles, func(similarFile models.SimilarFile) bool {
+		return similarFile.FileID == similarFileID
+	}) {
+		return nil, fmt.Errorf("file %s not found similar to file %s: %w", similarFileID, fileID, repository.ErrNotFound)
+	}
+	return passages, nil
+}
+
+// GetKeywords retrieves the keywords of an analyzed file ranked by TF-IDF against all analyzed files
+// At most limit keywords are returned, all of them if limit is not positive
+// Terms of files analyzed before keywords were introduced are counted on the first request
+func (s *AnalysisService) GetKeywords(ctx context.Context, fileID string, limit int) ([]models.Keyword, error) {
+	terms, err := s.repo.GetTermCounts(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get term counts: %w", err)
+	}
+
+	if len(terms) == 0 {
+		if _, err := s.repo.GetAnalysisResult(ctx, fileID); err != nil {
+			return nil, fmt.Errorf("failed to get analysis results: %w", err)
+		}
+
+		_, content, err := s.fileStoringClient.GetFile(ctx, fileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file content: %w", err)
+		}
+
+		terms = s.textAnalyzer.GetTermCounts(string(content))
+		if err := s.repo.SaveTermCounts(ctx, fileID, terms); err != nil {
+			return nil, fmt.Errorf("failed to save term counts: %w", err)
+		}
+	}
+
+	termValues := make([]string, len(terms))
+	for i, term := range terms {
+		termValues[i] = term.Term
+	}
+
+	documentFrequencies, documentCount, err := s.repo.GetDocumentFrequencies(ctx, termValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document frequencies: %w", err)
+	}
+
+	return analyzer.RankKeywords(terms, documentFrequencies, documentCount, limit), nil
+}
+
+// wordCounter returns the text analyzer counting the words of a document in the language
+func (s *AnalysisService) wordCounter(language analyzer.Language, keepStopWords, separateForms bool) *analyzer.TextAnalyzer {
+	counter := s.textAnalyzer.ForLanguage(language)
+	if keepStopWords {
+		counter = counter.WithStopWords(nil)
+	}
+	if separateForms {
+		counter = counter.WithStemmer(nil)
+	}
+	return counter
+}
+
+// GetWordFrequencies counts the words of a file, most frequent first
+// Stop words of the file language are removed and inflected forms are counted as one word
+// unless the options keep them apart
+func (s *AnalysisService) GetWordFrequencies(ctx context.Context, fileID string, options analyzer.WordFrequencyOptions) ([]analyzer.WordItem, error) {
+	_, content, err := s.fileStoringClient.GetFile(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	contentStr := string(content)
+	language := s.textAnalyzer.DetectLanguage(contentStr)
+	words := s.wordCounter(language, options.KeepStopWords, options.SeparateForms).GetWordFrequencies(contentStr, options.Case)
+
+	if options.Limit > 0 && len(words) > options.Limit {
+		words = words[:options.Limit]
+	}
+	return words, nil
+}
+
+// createWordCloud generates a word cloud of the text and saves it, returning its location
+// Words are counted with inflected forms merged and, unless the options keep them,
+// without the stop words of the language
+// The cloud is also saved in the other format next to it, so that it can be served in either format
+func (s *AnalysisService) createWordCloud(ctx context.Context, text string, language analyzer.Language, options analyzer.WordCloudOptions) (string, error) {
+	words := s.wordCounter(language, options.KeepStopWords, false).GetWordFrequencies(text, options.Case)
+
+	image, location, err := s.wordCloudGenerator.GenerateWordCloud(ctx, words, options)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate word cloud: %w", err)
+	}
+
+	if err := s.storage.SaveWordCloud(ctx, location, image); err != nil {
+		return "", fmt.Errorf("failed to save word cloud: %w", err)
+	}
+
+	// Without the other format the cloud is still served in its own format
+	options.Format = analyzer.WordCloudFormatOf(location).Other()
+	if err := s.saveWordCloudAs(ctx, words, options, analyzer.WordCloudLocationAs(location, options.Format)); err != nil {
+		fmt.Printf("Failed to save word cloud %s as %s: %v\n", location, options.Format, err)
+	}
+	return location, nil
+}
+
+// saveWordCloudAs generates a word cloud of the words and saves it at the given location
+func (s *AnalysisService) saveWordCloudAs(ctx context.Context, words []analyzer.WordItem, options analyzer.WordCloudOptions, location string) error {
+	image, _, err := s.wordCloudGenerator.GenerateWordCloud(ctx, words, options)
+	if err != nil {
+		return fmt.Errorf("failed to generate word cloud: %w", err)
+	}
+
+	if err := s.storage.SaveWordCloud(ctx, location, image); err != nil {
+		return fmt.Errorf("failed to save word cloud: %w", err)
+	}
+	return nil
+}
+
+// GetWordCloud retrieves a word cloud image by its location, together with its format
+func (s *AnalysisService) GetWordCloud(ctx context.Context, location string) ([]byte, analyzer.WordCloudFormat, error) {
+	image, err := s.storage.GetWordCloud(ctx, location)
+	if err != nil {
+		return nil, "", err
+	}
+	return image, analyzer.WordCloudFormatOf(location), nil
 }
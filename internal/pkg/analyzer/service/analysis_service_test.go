@@ -3,13 +3,19 @@ package service_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"local.dev/doc-analyzer/internal/pkg/analyzer/clients"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+	analyzermocks "local.dev/doc-analyzer/internal/pkg/analyzer/analyzer/mocks"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/repository"
 	"local.dev/doc-analyzer/internal/pkg/analyzer/service"
 )
 
@@ -18,24 +24,32 @@ type MockAnalysisRepository struct {
 	mock.Mock
 }
 
-func (m *MockAnalysisRepository) SaveAnalysisResult(ctx context.Context, fileID string, paragraphCount, wordCount, characterCount int32, isPlagiarism bool, wordCloudLocation string) error {
-	args := m.Called(ctx, fileID, paragraphCount, wordCount, characterCount, isPlagiarism, wordCloudLocation)
+func (m *MockAnalysisRepository) SaveAnalysisResult(ctx context.Context, result *models.AnalysisResult) error {
+	args := m.Called(ctx, result)
 	return args.Error(0)
 }
 
-func (m *MockAnalysisRepository) GetAnalysisResult(ctx context.Context, fileID string) (int32, int32, int32, bool, string, error) {
+func (m *MockAnalysisRepository) GetAnalysisResult(ctx context.Context, fileID string) (*models.AnalysisResult, error) {
 	args := m.Called(ctx, fileID)
-	return args.Get(0).(int32), args.Get(1).(int32), args.Get(2).(int32), args.Bool(3), args.String(4), args.Error(5)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AnalysisResult), args.Error(1)
 }
 
-func (m *MockAnalysisRepository) SaveSimilarFile(ctx context.Context, fileID, similarFileID string) error {
-	args := m.Called(ctx, fileID, similarFileID)
+func (m *MockAnalysisRepository) SaveSimilarFile(ctx context.Context, fileID string, similarFile models.SimilarFile) error {
+	args := m.Called(ctx, fileID, similarFile)
 	return args.Error(0)
 }
 
-func (m *MockAnalysisRepository) GetSimilarFiles(ctx context.Context, fileID string) ([]string, error) {
+func (m *MockAnalysisRepository) GetSimilarFiles(ctx context.Context, fileID string) ([]models.SimilarFile, error) {
 	args := m.Called(ctx, fileID)
-	return args.Get(0).([]string), args.Error(1)
+	return args.Get(0).([]models.SimilarFile), args.Error(1)
+}
+
+func (m *MockAnalysisRepository) GetMatchedPassages(ctx context.Context, fileID, similarFileID string) ([]models.MatchedPassage, error) {
+	args := m.Called(ctx, fileID, similarFileID)
+	return args.Get(0).([]models.MatchedPassage), args.Error(1)
 }
 
 func (m *MockAnalysisRepository) GetAllFileIDs(ctx context.Context) ([]string, error) {
@@ -43,6 +57,77 @@ func (m *MockAnalysisRepository) GetAllFileIDs(ctx context.Context) ([]string, e
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockAnalysisRepository) SaveSignature(ctx context.Context, fileID string, signature []uint64, bandKeys []int64, fingerprints []uint64, algorithmVersion int32) error {
+	args := m.Called(ctx, fileID, signature, bandKeys, fingerprints, algorithmVersion)
+	return args.Error(0)
+}
+
+func (m *MockAnalysisRepository) FindCandidates(ctx context.Context, bandKeys []int64) ([]string, error) {
+	args := m.Called(ctx, bandKeys)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAnalysisRepository) FindFingerprintMatches(ctx context.Context, fingerprints []uint64) ([]models.FingerprintMatch, error) {
+	args := m.Called(ctx, fingerprints)
+	return args.Get(0).([]models.FingerprintMatch), args.Error(1)
+}
+
+func (m *MockAnalysisRepository) GetUnindexedFileIDs(ctx context.Context, algorithmVersion int32) ([]string, error) {
+	args := m.Called(ctx, algorithmVersion)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAnalysisRepository) RecordIndexFailure(ctx context.Context, fileID string, reason string, retryDelay, maxRetryDelay time.Duration) error {
+	args := m.Called(ctx, fileID, reason, retryDelay, maxRetryDelay)
+	return args.Error(0)
+}
+
+func (m *MockAnalysisRepository) SaveTermCounts(ctx context.Context, fileID string, terms []models.TermCount) error {
+	args := m.Called(ctx, fileID, terms)
+	return args.Error(0)
+}
+
+func (m *MockAnalysisRepository) GetTermCounts(ctx context.Context, fileID string) ([]models.TermCount, error) {
+	args := m.Called(ctx, fileID)
+	return args.Get(0).([]models.TermCount), args.Error(1)
+}
+
+func (m *MockAnalysisRepository) GetDocumentFrequencies(ctx context.Context, terms []string) (map[string]int32, int32, error) {
+	args := m.Called(ctx, terms)
+	return args.Get(0).(map[string]int32), args.Get(1).(int32), args.Error(2)
+}
+
+func (m *MockAnalysisRepository) CreateAnalysisJob(ctx context.Context, job *models.AnalysisJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockAnalysisRepository) ClaimAnalysisJob(ctx context.Context) (*models.AnalysisJob, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AnalysisJob), args.Error(1)
+}
+
+func (m *MockAnalysisRepository) FinishAnalysisJob(ctx context.Context, jobID string, status models.JobStatus, errorMessage string) error {
+	args := m.Called(ctx, jobID, status, errorMessage)
+	return args.Error(0)
+}
+
+func (m *MockAnalysisRepository) GetAnalysisJob(ctx context.Context, jobID string) (*models.AnalysisJob, error) {
+	args := m.Called(ctx, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AnalysisJob), args.Error(1)
+}
+
+func (m *MockAnalysisRepository) RequeueStaleAnalysisJobs(ctx context.Context, staleAfter time.Duration, maxAttempts int32) (int64, error) {
+	args := m.Called(ctx, staleAfter, maxAttempts)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // Mock storage
 type MockWordCloudStorage struct {
 	mock.Mock
@@ -85,7 +170,7 @@ func TestAnalysisService_AnalyzeFile_ExistingAnalysis(t *testing.T) {
 	mockFileStoringClient := new(MockFileStoringClient)
 	textAnalyzer := analyzer.NewTextAnalyzer()
 	plagiarismChecker := analyzer.NewPlagiarismChecker()
-	wordCloudGenerator := analyzer.NewWordCloudGenerator("")
+	wordCloudGenerator := analyzer.NewLocalWordCloudGenerator()
 
 	// Create service
 	svc := service.NewAnalysisService(
@@ -95,26 +180,30 @@ func TestAnalysisService_AnalyzeFile_ExistingAnalysis(t *testing.T) {
 		textAnalyzer,
 		plagiarismChecker,
 		wordCloudGenerator,
+		analyzer.NewSummarizer(textAnalyzer),
 	)
 
 	// Set up mock expectations for existing analysis
 	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
-		int32(5), int32(100), int32(500), false, "wordcloud123.png", nil,
+		&models.AnalysisResult{
+			FileID: "file123", ParagraphCount: 5, WordCount: 100, CharacterCount: 500,
+			IsPlagiarism: false, WordCloudLocation: "wordcloud123.png",
+		}, nil,
 	)
 
 	// Call the method
-	paragraphCount, wordCount, characterCount, isPlagiarism, similarFileIDs, wordCloudLocation, err := svc.AnalyzeFile(
-		context.Background(), "file123", true,
+	result, err := svc.AnalyzeFile(
+		context.Background(), "file123", true, analyzer.WordCloudOptions{},
 	)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.Equal(t, int32(5), paragraphCount)
-	assert.Equal(t, int32(100), wordCount)
-	assert.Equal(t, int32(500), characterCount)
-	assert.False(t, isPlagiarism)
-	assert.Empty(t, similarFileIDs)
-	assert.Equal(t, "wordcloud123.png", wordCloudLocation)
+	assert.Equal(t, int32(5), result.ParagraphCount)
+	assert.Equal(t, int32(100), result.WordCount)
+	assert.Equal(t, int32(500), result.CharacterCount)
+	assert.False(t, result.IsPlagiarism)
+	assert.Empty(t, result.SimilarFileIDs())
+	assert.Equal(t, "wordcloud123.png", result.WordCloudLocation)
 
 	mockRepo.AssertExpectations(t)
 	mockStorage.AssertNotCalled(t, "SaveWordCloud")
@@ -128,7 +217,7 @@ func TestAnalysisService_AnalyzeFile_ExistingAnalysisWithPlagiarism(t *testing.T
 	mockFileStoringClient := new(MockFileStoringClient)
 	textAnalyzer := analyzer.NewTextAnalyzer()
 	plagiarismChecker := analyzer.NewPlagiarismChecker()
-	wordCloudGenerator := analyzer.NewWordCloudGenerator("")
+	wordCloudGenerator := analyzer.NewLocalWordCloudGenerator()
 
 	// Create service
 	svc := service.NewAnalysisService(
@@ -138,29 +227,36 @@ func TestAnalysisService_AnalyzeFile_ExistingAnalysisWithPlagiarism(t *testing.T
 		textAnalyzer,
 		plagiarismChecker,
 		wordCloudGenerator,
+		analyzer.NewSummarizer(textAnalyzer),
 	)
 
 	// Set up mock expectations for existing analysis with plagiarism
 	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
-		int32(5), int32(100), int32(500), true, "wordcloud123.png", nil,
+		&models.AnalysisResult{
+			FileID: "file123", ParagraphCount: 5, WordCount: 100, CharacterCount: 500,
+			IsPlagiarism: true, WordCloudLocation: "wordcloud123.png",
+		}, nil,
 	)
 	mockRepo.On("GetSimilarFiles", mock.Anything, "file123").Return(
-		[]string{"file456", "file789"}, nil,
+		[]models.SimilarFile{
+			{FileID: "file456", Coverage: 1, MatchedWords: 40},
+			{FileID: "file789", Coverage: 0.6, MatchedWords: 24},
+		}, nil,
 	)
 
 	// Call the method
-	paragraphCount, wordCount, characterCount, isPlagiarism, similarFileIDs, wordCloudLocation, err := svc.AnalyzeFile(
-		context.Background(), "file123", true,
+	result, err := svc.AnalyzeFile(
+		context.Background(), "file123", true, analyzer.WordCloudOptions{},
 	)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.Equal(t, int32(5), paragraphCount)
-	assert.Equal(t, int32(100), wordCount)
-	assert.Equal(t, int32(500), characterCount)
-	assert.True(t, isPlagiarism)
-	assert.Equal(t, []string{"file456", "file789"}, similarFileIDs)
-	assert.Equal(t, "wordcloud123.png", wordCloudLocation)
+	assert.Equal(t, int32(5), result.ParagraphCount)
+	assert.Equal(t, int32(100), result.WordCount)
+	assert.Equal(t, int32(500), result.CharacterCount)
+	assert.True(t, result.IsPlagiarism)
+	assert.Equal(t, []string{"file456", "file789"}, result.SimilarFileIDs())
+	assert.Equal(t, "wordcloud123.png", result.WordCloudLocation)
 
 	mockRepo.AssertExpectations(t)
 	mockStorage.AssertNotCalled(t, "SaveWordCloud")
@@ -174,7 +270,7 @@ func TestAnalysisService_AnalyzeFile_NewAnalysis(t *testing.T) {
 	mockFileStoringClient := new(MockFileStoringClient)
 	textAnalyzer := analyzer.NewTextAnalyzer()
 	plagiarismChecker := analyzer.NewPlagiarismChecker()
-	wordCloudGenerator := analyzer.NewWordCloudGenerator("")
+	wordCloudGenerator := analyzer.NewLocalWordCloudGenerator()
 
 	// Create service
 	svc := service.NewAnalysisService(
@@ -184,16 +280,17 @@ func TestAnalysisService_AnalyzeFile_NewAnalysis(t *testing.T) {
 		textAnalyzer,
 		plagiarismChecker,
 		wordCloudGenerator,
+		analyzer.NewSummarizer(textAnalyzer),
 	)
 
 	// Set up mock expectations for new analysis
 	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
-		int32(0), int32(0), int32(0), false, "", errors.New("not found"),
+		nil, errors.New("not found"),
 	)
 	mockFileStoringClient.On("GetFile", mock.Anything, "file123").Return(
 		"test.txt", []byte("This is a test file content."), nil,
 	)
-	mockRepo.On("GetAllFileIDs", mock.Anything).Return(
+	mockRepo.On("FindCandidates", mock.Anything, mock.Anything).Return(
 		[]string{"file456", "file789"}, nil,
 	)
 	mockFileStoringClient.On("GetFile", mock.Anything, "file456").Return(
@@ -202,21 +299,23 @@ func TestAnalysisService_AnalyzeFile_NewAnalysis(t *testing.T) {
 	mockFileStoringClient.On("GetFile", mock.Anything, "file789").Return(
 		"test789.txt", []byte("This is another file content."), nil,
 	)
-	mockRepo.On("SaveAnalysisResult", mock.Anything, "file123", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("SaveAnalysisResult", mock.Anything, mock.AnythingOfType("*models.AnalysisResult")).Return(nil)
+	mockRepo.On("SaveTermCounts", mock.Anything, "file123", mock.Anything).Return(nil)
+	mockRepo.On("SaveSignature", mock.Anything, "file123", mock.Anything, mock.Anything, mock.Anything, int32(analyzer.AlgorithmVersion)).Return(nil)
 
 	// Call the method
-	paragraphCount, wordCount, characterCount, isPlagiarism, similarFileIDs, wordCloudLocation, err := svc.AnalyzeFile(
-		context.Background(), "file123", false,
+	result, err := svc.AnalyzeFile(
+		context.Background(), "file123", false, analyzer.WordCloudOptions{},
 	)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.Equal(t, int32(1), paragraphCount)
-	assert.Equal(t, int32(6), wordCount)
-	assert.Equal(t, int32(28), characterCount)
-	assert.False(t, isPlagiarism)
-	assert.Empty(t, similarFileIDs)
-	assert.Empty(t, wordCloudLocation)
+	assert.Equal(t, int32(1), result.ParagraphCount)
+	assert.Equal(t, int32(6), result.WordCount)
+	assert.Equal(t, int32(28), result.CharacterCount)
+	assert.False(t, result.IsPlagiarism)
+	assert.Empty(t, result.SimilarFileIDs())
+	assert.Empty(t, result.WordCloudLocation)
 
 	mockRepo.AssertExpectations(t)
 	mockStorage.AssertNotCalled(t, "SaveWordCloud")
@@ -230,7 +329,7 @@ func TestAnalysisService_AnalyzeFile_NewAnalysisWithWordCloud(t *testing.T) {
 	mockFileStoringClient := new(MockFileStoringClient)
 	textAnalyzer := analyzer.NewTextAnalyzer()
 	plagiarismChecker := analyzer.NewPlagiarismChecker()
-	wordCloudGenerator := analyzer.NewWordCloudGenerator("")
+	wordCloudGenerator := analyzer.NewLocalWordCloudGenerator()
 
 	// Create service
 	svc := service.NewAnalysisService(
@@ -240,16 +339,17 @@ func TestAnalysisService_AnalyzeFile_NewAnalysisWithWordCloud(t *testing.T) {
 		textAnalyzer,
 		plagiarismChecker,
 		wordCloudGenerator,
+		analyzer.NewSummarizer(textAnalyzer),
 	)
 
 	// Set up mock expectations for new analysis with word cloud
 	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
-		int32(0), int32(0), int32(0), false, "", errors.New("not found"),
+		nil, errors.New("not found"),
 	)
 	mockFileStoringClient.On("GetFile", mock.Anything, "file123").Return(
 		"test.txt", []byte("This is a test file content."), nil,
 	).Times(2) // Called twice: once for analysis and once for word cloud
-	mockRepo.On("GetAllFileIDs", mock.Anything).Return(
+	mockRepo.On("FindCandidates", mock.Anything, mock.Anything).Return(
 		[]string{"file456", "file789"}, nil,
 	)
 	mockFileStoringClient.On("GetFile", mock.Anything, "file456").Return(
@@ -260,27 +360,143 @@ func TestAnalysisService_AnalyzeFile_NewAnalysisWithWordCloud(t *testing.T) {
 	)
 	// Mock the word cloud generator to return a test image and location
 	mockStorage.On("SaveWordCloud", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(nil)
-	mockRepo.On("SaveAnalysisResult", mock.Anything, "file123", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("SaveAnalysisResult", mock.Anything, mock.AnythingOfType("*models.AnalysisResult")).Return(nil)
+	mockRepo.On("SaveTermCounts", mock.Anything, "file123", mock.Anything).Return(nil)
+	mockRepo.On("SaveSignature", mock.Anything, "file123", mock.Anything, mock.Anything, mock.Anything, int32(analyzer.AlgorithmVersion)).Return(nil)
 
 	// Call the method
-	paragraphCount, wordCount, characterCount, isPlagiarism, similarFileIDs, wordCloudLocation, err := svc.AnalyzeFile(
-		context.Background(), "file123", true,
+	result, err := svc.AnalyzeFile(
+		context.Background(), "file123", true, analyzer.WordCloudOptions{},
 	)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.Equal(t, int32(1), paragraphCount)
-	assert.Equal(t, int32(6), wordCount)
-	assert.Equal(t, int32(28), characterCount)
-	assert.False(t, isPlagiarism)
-	assert.Empty(t, similarFileIDs)
-	assert.NotEmpty(t, wordCloudLocation)
+	assert.Equal(t, int32(1), result.ParagraphCount)
+	assert.Equal(t, int32(6), result.WordCount)
+	assert.Equal(t, int32(28), result.CharacterCount)
+	assert.False(t, result.IsPlagiarism)
+	assert.Empty(t, result.SimilarFileIDs())
+	assert.NotEmpty(t, result.WordCloudLocation)
+	assert.Equal(t, "png", result.WordCloudFormat)
+	mockStorage.AssertCalled(t, "SaveWordCloud", mock.Anything, result.WordCloudLocation, mock.Anything)
+	mockStorage.AssertCalled(t, "SaveWordCloud", mock.Anything, strings.TrimSuffix(result.WordCloudLocation, ".png")+".svg", mock.Anything)
 
 	mockRepo.AssertExpectations(t)
 	mockStorage.AssertExpectations(t)
 	mockFileStoringClient.AssertExpectations(t)
 }
 
+func TestAnalysisService_AnalyzeFile_ExistingAnalysisWithWordCloudOptions(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	mockStorage := new(MockWordCloudStorage)
+	mockFileStoringClient := new(MockFileStoringClient)
+	mockGenerator := new(analyzermocks.MockWordCloudGenerator)
+	textAnalyzer := analyzer.NewTextAnalyzer()
+
+	// Create service
+	svc := service.NewAnalysisService(
+		mockRepo,
+		mockStorage,
+		mockFileStoringClient,
+		textAnalyzer,
+		analyzer.NewPlagiarismChecker(),
+		mockGenerator,
+		analyzer.NewSummarizer(textAnalyzer),
+	)
+
+	options := analyzer.WordCloudOptions{
+		Format:   analyzer.SVGFormat,
+		Width:    800,
+		Height:   600,
+		MaxWords: 50,
+		Case:     analyzer.OriginalCase,
+	}
+
+	// The file is analyzed already, a new word cloud is generated with the options
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
+		&models.AnalysisResult{
+			FileID: "file123", ParagraphCount: 1, WordCount: 7, CharacterCount: 40,
+			Language: "ru", WordCloudLocation: "wordcloud123.png", WordCloudFormat: "png",
+		}, nil,
+	)
+	mockFileStoringClient.On("GetFile", mock.Anything, "file123").Return(
+		"test.txt", []byte("Москва и москва, и Москва"), nil,
+	)
+
+	// Russian stop words are removed and the words keep their capitals
+	mockGenerator.On("GenerateWordCloud", mock.Anything, []analyzer.WordItem{{Text: "Москва", Value: 3}}, options).Return(
+		[]byte("<svg></svg>"), "wordcloud456.svg", nil,
+	)
+	mockStorage.On("SaveWordCloud", mock.Anything, "wordcloud456.svg", []byte("<svg></svg>")).Return(nil)
+
+	// The word cloud is also saved as PNG next to the SVG image
+	pngOptions := options
+	pngOptions.Format = analyzer.PNGFormat
+	mockGenerator.On("GenerateWordCloud", mock.Anything, []analyzer.WordItem{{Text: "Москва", Value: 3}}, pngOptions).Return(
+		[]byte("png"), "wordcloud789.png", nil,
+	)
+	mockStorage.On("SaveWordCloud", mock.Anything, "wordcloud456.png", []byte("png")).Return(nil)
+	mockRepo.On("SaveAnalysisResult", mock.Anything, mock.MatchedBy(func(result *models.AnalysisResult) bool {
+		return result.WordCloudLocation == "wordcloud456.svg" && result.WordCloudFormat == "svg"
+	})).Return(nil)
+
+	// Call the method
+	result, err := svc.AnalyzeFile(context.Background(), "file123", true, options)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "wordcloud456.svg", result.WordCloudLocation)
+	assert.Equal(t, "svg", result.WordCloudFormat)
+
+	mockRepo.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+	mockGenerator.AssertExpectations(t)
+}
+
+func TestAnalysisService_AnalyzeFile_ExistingAnalysisWithoutWordCloud(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	mockStorage := new(MockWordCloudStorage)
+	mockFileStoringClient := new(MockFileStoringClient)
+	mockGenerator := new(analyzermocks.MockWordCloudGenerator)
+	textAnalyzer := analyzer.NewTextAnalyzer()
+
+	// Create service
+	svc := service.NewAnalysisService(
+		mockRepo,
+		mockStorage,
+		mockFileStoringClient,
+		textAnalyzer,
+		analyzer.NewPlagiarismChecker(),
+		mockGenerator,
+		analyzer.NewSummarizer(textAnalyzer),
+	)
+
+	// The file was analyzed without a word cloud
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
+		&models.AnalysisResult{FileID: "file123", ParagraphCount: 1, WordCount: 3, CharacterCount: 17}, nil,
+	)
+	mockFileStoringClient.On("GetFile", mock.Anything, "file123").Return(
+		"test.txt", []byte("cloud of clouds"), nil,
+	)
+	mockGenerator.On("GenerateWordCloud", mock.Anything, mock.Anything, analyzer.WordCloudOptions{}).Return(
+		nil, "", errors.New("generator error"),
+	)
+
+	// Call the method
+	result, err := svc.AnalyzeFile(context.Background(), "file123", true, analyzer.WordCloudOptions{})
+
+	// Assert: the analysis is returned without a word cloud
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), result.WordCount)
+	assert.Empty(t, result.WordCloudLocation)
+
+	mockGenerator.AssertExpectations(t)
+	mockStorage.AssertNotCalled(t, "SaveWordCloud")
+	mockRepo.AssertNotCalled(t, "SaveAnalysisResult")
+}
+
 func TestAnalysisService_AnalyzeFile_ErrorGettingFile(t *testing.T) {
 	// Create mocks
 	mockRepo := new(MockAnalysisRepository)
@@ -288,7 +504,7 @@ func TestAnalysisService_AnalyzeFile_ErrorGettingFile(t *testing.T) {
 	mockFileStoringClient := new(MockFileStoringClient)
 	textAnalyzer := analyzer.NewTextAnalyzer()
 	plagiarismChecker := analyzer.NewPlagiarismChecker()
-	wordCloudGenerator := analyzer.NewWordCloudGenerator("")
+	wordCloudGenerator := analyzer.NewLocalWordCloudGenerator()
 
 	// Create service
 	svc := service.NewAnalysisService(
@@ -298,19 +514,20 @@ func TestAnalysisService_AnalyzeFile_ErrorGettingFile(t *testing.T) {
 		textAnalyzer,
 		plagiarismChecker,
 		wordCloudGenerator,
+		analyzer.NewSummarizer(textAnalyzer),
 	)
 
 	// Set up mock expectations
 	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
-		int32(0), int32(0), int32(0), false, "", errors.New("not found"),
+		nil, errors.New("not found"),
 	)
 	mockFileStoringClient.On("GetFile", mock.Anything, "file123").Return(
 		"", []byte(nil), errors.New("file not found"),
 	)
 
 	// Call the method
-	_, _, _, _, _, _, err := svc.AnalyzeFile(
-		context.Background(), "file123", false,
+	_, err := svc.AnalyzeFile(
+		context.Background(), "file123", false, analyzer.WordCloudOptions{},
 	)
 
 	// Assert
@@ -321,14 +538,14 @@ func TestAnalysisService_AnalyzeFile_ErrorGettingFile(t *testing.T) {
 	mockFileStoringClient.AssertExpectations(t)
 }
 
-func TestAnalysisService_AnalyzeFile_ErrorGettingAllFileIDs(t *testing.T) {
+func TestAnalysisService_AnalyzeFile_ErrorFindingCandidates(t *testing.T) {
 	// Create mocks
 	mockRepo := new(MockAnalysisRepository)
 	mockStorage := new(MockWordCloudStorage)
 	mockFileStoringClient := new(MockFileStoringClient)
 	textAnalyzer := analyzer.NewTextAnalyzer()
 	plagiarismChecker := analyzer.NewPlagiarismChecker()
-	wordCloudGenerator := analyzer.NewWordCloudGenerator("")
+	wordCloudGenerator := analyzer.NewLocalWordCloudGenerator()
 
 	// Create service
 	svc := service.NewAnalysisService(
@@ -338,27 +555,28 @@ func TestAnalysisService_AnalyzeFile_ErrorGettingAllFileIDs(t *testing.T) {
 		textAnalyzer,
 		plagiarismChecker,
 		wordCloudGenerator,
+		analyzer.NewSummarizer(textAnalyzer),
 	)
 
 	// Set up mock expectations
 	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
-		int32(0), int32(0), int32(0), false, "", errors.New("not found"),
+		nil, errors.New("not found"),
 	)
 	mockFileStoringClient.On("GetFile", mock.Anything, "file123").Return(
 		"test.txt", []byte("This is a test file content."), nil,
 	)
-	mockRepo.On("GetAllFileIDs", mock.Anything).Return(
+	mockRepo.On("FindCandidates", mock.Anything, mock.Anything).Return(
 		[]string{}, errors.New("database error"),
 	)
 
 	// Call the method
-	_, _, _, _, _, _, err := svc.AnalyzeFile(
-		context.Background(), "file123", false,
+	_, err := svc.AnalyzeFile(
+		context.Background(), "file123", false, analyzer.WordCloudOptions{},
 	)
 
 	// Assert
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to get all file IDs")
+	assert.Contains(t, err.Error(), "failed to find plagiarism candidates")
 
 	mockRepo.AssertExpectations(t)
 	mockFileStoringClient.AssertExpectations(t)
@@ -371,7 +589,7 @@ func TestAnalysisService_AnalyzeFile_ErrorSavingAnalysisResult(t *testing.T) {
 	mockFileStoringClient := new(MockFileStoringClient)
 	textAnalyzer := analyzer.NewTextAnalyzer()
 	plagiarismChecker := analyzer.NewPlagiarismChecker()
-	wordCloudGenerator := analyzer.NewWordCloudGenerator("")
+	wordCloudGenerator := analyzer.NewLocalWordCloudGenerator()
 
 	// Create service
 	svc := service.NewAnalysisService(
@@ -381,16 +599,17 @@ func TestAnalysisService_AnalyzeFile_ErrorSavingAnalysisResult(t *testing.T) {
 		textAnalyzer,
 		plagiarismChecker,
 		wordCloudGenerator,
+		analyzer.NewSummarizer(textAnalyzer),
 	)
 
 	// Set up mock expectations
 	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
-		int32(0), int32(0), int32(0), false, "", errors.New("not found"),
+		nil, errors.New("not found"),
 	)
 	mockFileStoringClient.On("GetFile", mock.Anything, "file123").Return(
 		"test.txt", []byte("This is a test file content."), nil,
 	)
-	mockRepo.On("GetAllFileIDs", mock.Anything).Return(
+	mockRepo.On("FindCandidates", mock.Anything, mock.Anything).Return(
 		[]string{"file456", "file789"}, nil,
 	)
 	mockFileStoringClient.On("GetFile", mock.Anything, "file456").Return(
@@ -399,11 +618,11 @@ func TestAnalysisService_AnalyzeFile_ErrorSavingAnalysisResult(t *testing.T) {
 	mockFileStoringClient.On("GetFile", mock.Anything, "file789").Return(
 		"test789.txt", []byte("This is another file content."), nil,
 	)
-	mockRepo.On("SaveAnalysisResult", mock.Anything, "file123", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("database error"))
+	mockRepo.On("SaveAnalysisResult", mock.Anything, mock.AnythingOfType("*models.AnalysisResult")).Return(errors.New("database error"))
 
 	// Call the method
-	_, _, _, _, _, _, err := svc.AnalyzeFile(
-		context.Background(), "file123", false,
+	_, err := svc.AnalyzeFile(
+		context.Background(), "file123", false, analyzer.WordCloudOptions{},
 	)
 
 	// Assert
@@ -421,7 +640,7 @@ func TestAnalysisService_AnalyzeFile_ErrorGettingSimilarFiles(t *testing.T) {
 	mockFileStoringClient := new(MockFileStoringClient)
 	textAnalyzer := analyzer.NewTextAnalyzer()
 	plagiarismChecker := analyzer.NewPlagiarismChecker()
-	wordCloudGenerator := analyzer.NewWordCloudGenerator("")
+	wordCloudGenerator := analyzer.NewLocalWordCloudGenerator()
 
 	// Create service
 	svc := service.NewAnalysisService(
@@ -431,19 +650,23 @@ func TestAnalysisService_AnalyzeFile_ErrorGettingSimilarFiles(t *testing.T) {
 		textAnalyzer,
 		plagiarismChecker,
 		wordCloudGenerator,
+		analyzer.NewSummarizer(textAnalyzer),
 	)
 
 	// Set up mock expectations for existing analysis with plagiarism but error getting similar files
 	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
-		int32(5), int32(100), int32(500), true, "wordcloud123.png", nil,
+		&models.AnalysisResult{
+			FileID: "file123", ParagraphCount: 5, WordCount: 100, CharacterCount: 500,
+			IsPlagiarism: true, WordCloudLocation: "wordcloud123.png",
+		}, nil,
 	)
 	mockRepo.On("GetSimilarFiles", mock.Anything, "file123").Return(
-		[]string{}, errors.New("database error"),
+		[]models.SimilarFile{}, errors.New("database error"),
 	)
 
 	// Call the method
-	_, _, _, _, _, _, err := svc.AnalyzeFile(
-		context.Background(), "file123", true,
+	_, err := svc.AnalyzeFile(
+		context.Background(), "file123", true, analyzer.WordCloudOptions{},
 	)
 
 	// Assert
@@ -460,7 +683,7 @@ func TestAnalysisService_AnalyzeFile_WithPlagiarism(t *testing.T) {
 	mockFileStoringClient := new(MockFileStoringClient)
 	textAnalyzer := analyzer.NewTextAnalyzer()
 	plagiarismChecker := analyzer.NewPlagiarismChecker()
-	wordCloudGenerator := analyzer.NewWordCloudGenerator("")
+	wordCloudGenerator := analyzer.NewLocalWordCloudGenerator()
 
 	// Create service
 	svc := service.NewAnalysisService(
@@ -470,38 +693,374 @@ func TestAnalysisService_AnalyzeFile_WithPlagiarism(t *testing.T) {
 		textAnalyzer,
 		plagiarismChecker,
 		wordCloudGenerator,
+		analyzer.NewSummarizer(textAnalyzer),
 	)
 
 	// Set up mock expectations for new analysis with plagiarism
 	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
-		int32(0), int32(0), int32(0), false, "", errors.New("not found"),
+		nil, errors.New("not found"),
 	)
 	mockFileStoringClient.On("GetFile", mock.Anything, "file123").Return(
 		"test.txt", []byte("This is a test file content."), nil,
 	)
-	mockRepo.On("GetAllFileIDs", mock.Anything).Return(
+	mockRepo.On("FindCandidates", mock.Anything, mock.Anything).Return(
 		[]string{"file456"}, nil,
 	)
 	mockFileStoringClient.On("GetFile", mock.Anything, "file456").Return(
 		"test456.txt", []byte("This is a test file content."), nil, // Same content to trigger plagiarism
 	)
-	mockRepo.On("SaveAnalysisResult", mock.Anything, "file123", mock.Anything, mock.Anything, mock.Anything, true, mock.Anything).Return(nil)
-	mockRepo.On("SaveSimilarFile", mock.Anything, "file123", "file456").Return(nil)
+	mockRepo.On("SaveAnalysisResult", mock.Anything, mock.MatchedBy(func(result *models.AnalysisResult) bool {
+		return result.FileID == "file123" && result.IsPlagiarism && result.Language == "en"
+	})).Return(nil)
+	mockRepo.On("SaveTermCounts", mock.Anything, "file123", []models.TermCount{
+		{Term: "content", Word: "content", Count: 1},
+		{Term: "file", Word: "file", Count: 1},
+		{Term: "test", Word: "test", Count: 1},
+	}).Return(nil)
+	mockRepo.On("SaveSignature", mock.Anything, "file123", mock.Anything, mock.Anything, mock.Anything, int32(analyzer.AlgorithmVersion)).Return(nil)
+	mockRepo.On("SaveSimilarFile", mock.Anything, "file123", models.SimilarFile{
+		FileID: "file456", Score: 1, Metric: analyzer.ExactMetric, NGramSize: 3, Threshold: 0.3,
+		AlgorithmVersion: analyzer.AlgorithmVersion, Containment: 1, SimilarContainment: 1, Coverage: 1, MatchedWords: 3,
+		Passages: []models.MatchedPassage{
+			{Start: 10, End: 27, SimilarStart: 10, SimilarEnd: 27, Text: "test file content"},
+		},
+	}).Return(nil)
+
+	// Call the method
+	result, err := svc.AnalyzeFile(
+		context.Background(), "file123", false, analyzer.WordCloudOptions{},
+	)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, result.IsPlagiarism)
+	assert.Equal(t, "en", result.Language)
+	assert.False(t, result.ObfuscationDetected)
+	assert.Equal(t, int32(28), result.CharacterCount)
+	assert.Equal(t, int32(23), result.CharacterCountNoSpaces)
+	assert.Equal(t, int32(1), result.SentenceCount)
+	assert.Equal(t, 6.0, result.AvgSentenceLength)
+	assert.Equal(t, analyzer.FleschKincaidFormula, result.ReadabilityFormula)
+	assert.NotZero(t, result.ReadingEase)
+	assert.Equal(t, "This is a test file content.", result.Summary)
+	assert.Contains(t, result.SimilarFileIDs(), "file456")
+
+	mockRepo.AssertExpectations(t)
+	mockFileStoringClient.AssertExpectations(t)
+}
+
+func TestAnalysisService_AnalyzeFile_FingerprintCandidates(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	mockStorage := new(MockWordCloudStorage)
+	mockFileStoringClient := new(MockFileStoringClient)
+	textAnalyzer := analyzer.NewTextAnalyzer()
+	plagiarismChecker := analyzer.NewPlagiarismChecker()
+	wordCloudGenerator := analyzer.NewLocalWordCloudGenerator()
+
+	// Create service
+	svc := service.NewAnalysisService(
+		mockRepo,
+		mockStorage,
+		mockFileStoringClient,
+		textAnalyzer,
+		plagiarismChecker,
+		wordCloudGenerator,
+		analyzer.NewSummarizer(textAnalyzer),
+	)
+
+	// A passage copied from file456 into a long document
+	words := func(prefix string, count int) string {
+		result := make([]string, count)
+		for i := range result {
+			result[i] = fmt.Sprintf("%s%d", prefix, i)
+		}
+		return strings.Join(result, " ")
+	}
+	copied := words("copied", 40)
+	content := words("original", 1000) + "\n\n" + copied + "\n\n" + words("remaining", 1000)
+
+	// Set up mock expectations: file456 shares no LSH bucket, only fingerprints
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
+		nil, errors.New("not found"),
+	)
+	mockFileStoringClient.On("GetFile", mock.Anything, "file123").Return(
+		"test.txt", []byte(content), nil,
+	)
+	mockRepo.On("FindCandidates", mock.Anything, mock.Anything).Return(
+		[]string{}, nil,
+	)
+	mockRepo.On("FindFingerprintMatches", mock.Anything, plagiarismChecker.Fingerprints(content)).Return(
+		[]models.FingerprintMatch{{FileID: "file456", Shared: 10, Fingerprints: 10}}, nil,
+	)
+	mockFileStoringClient.On("GetFile", mock.Anything, "file456").Return(
+		"test456.txt", []byte(copied), nil,
+	)
+	mockRepo.On("SaveAnalysisResult", mock.Anything, mock.MatchedBy(func(result *models.AnalysisResult) bool {
+		return result.FileID == "file123" && result.IsPlagiarism
+	})).Return(nil)
+	mockRepo.On("SaveTermCounts", mock.Anything, "file123", mock.Anything).Return(nil)
+	mockRepo.On("SaveSignature", mock.Anything, "file123", mock.Anything, mock.Anything, plagiarismChecker.Fingerprints(content), int32(analyzer.AlgorithmVersion)).Return(nil)
+	mockRepo.On("SaveSimilarFile", mock.Anything, "file123", mock.MatchedBy(func(similarFile models.SimilarFile) bool {
+		return similarFile.FileID == "file456"
+	})).Return(nil)
 
 	// Call the method
-	_, _, _, isPlagiarism, similarFileIDs, _, err := svc.AnalyzeFile(
-		context.Background(), "file123", false,
+	result, err := svc.AnalyzeFile(
+		context.Background(), "file123", false, analyzer.WordCloudOptions{},
 	)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.True(t, isPlagiarism)
-	assert.Contains(t, similarFileIDs, "file456")
+	assert.True(t, result.IsPlagiarism)
+	assert.Equal(t, []string{"file456"}, result.SimilarFileIDs())
 
 	mockRepo.AssertExpectations(t)
 	mockFileStoringClient.AssertExpectations(t)
 }
 
+func TestAnalysisService_GetMatchedPassages(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	mockStorage := new(MockWordCloudStorage)
+	mockFileStoringClient := new(MockFileStoringClient)
+
+	// Create service
+	svc := service.NewAnalysisService(
+		mockRepo,
+		mockStorage,
+		mockFileStoringClient,
+		analyzer.NewTextAnalyzer(),
+		analyzer.NewPlagiarismChecker(),
+		analyzer.NewLocalWordCloudGenerator(),
+		analyzer.NewSummarizer(analyzer.NewTextAnalyzer()),
+	)
+
+	passages := []models.MatchedPassage{
+		{Start: 10, End: 27, SimilarStart: 0, SimilarEnd: 17, Text: "test file content"},
+	}
+
+	// Test case: successful get
+	mockRepo.On("GetMatchedPassages", mock.Anything, "file123", "file456").Return(passages, nil)
+
+	result, err := svc.GetMatchedPassages(context.Background(), "file123", "file456")
+	assert.NoError(t, err)
+	assert.Equal(t, passages, result)
+
+	// Test case: database error
+	mockRepo.On("GetMatchedPassages", mock.Anything, "file123", "file789").Return(
+		[]models.MatchedPassage{}, errors.New("database error"),
+	)
+
+	_, err = svc.GetMatchedPassages(context.Background(), "file123", "file789")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get matched passages")
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAnalysisService_GetMatchedPassages_NoPassages(t *testing.T) {
+	newService := func(mockRepo *MockAnalysisRepository) *service.AnalysisService {
+		return service.NewAnalysisService(
+			mockRepo,
+			new(MockWordCloudStorage),
+			new(MockFileStoringClient),
+			analyzer.NewTextAnalyzer(),
+			analyzer.NewPlagiarismChecker(),
+			analyzer.NewLocalWordCloudGenerator(),
+			analyzer.NewSummarizer(analyzer.NewTextAnalyzer()),
+		)
+	}
+
+	// Test case: similar file without passages
+	mockRepo := new(MockAnalysisRepository)
+	mockRepo.On("GetMatchedPassages", mock.Anything, "file123", "file456").Return([]models.MatchedPassage{}, nil)
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(&models.AnalysisResult{FileID: "file123"}, nil)
+	mockRepo.On("GetSimilarFiles", mock.Anything, "file123").Return([]models.SimilarFile{{FileID: "file456"}}, nil)
+
+	result, err := newService(mockRepo).GetMatchedPassages(context.Background(), "file123", "file456")
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+	mockRepo.AssertExpectations(t)
+
+	// Test case: file not similar
+	mockRepo = new(MockAnalysisRepository)
+	mockRepo.On("GetMatchedPassages", mock.Anything, "file123", "file789").Return([]models.MatchedPassage{}, nil)
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(&models.AnalysisResult{FileID: "file123"}, nil)
+	mockRepo.On("GetSimilarFiles", mock.Anything, "file123").Return([]models.SimilarFile{{FileID: "file456"}}, nil)
+
+	_, err = newService(mockRepo).GetMatchedPassages(context.Background(), "file123", "file789")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	mockRepo.AssertExpectations(t)
+
+	// Test case: file not analyzed
+	mockRepo = new(MockAnalysisRepository)
+	mockRepo.On("GetMatchedPassages", mock.Anything, "file000", "file456").Return([]models.MatchedPassage{}, nil)
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file000").Return(
+		nil, fmt.Errorf("analysis result not found for file ID file000: %w", repository.ErrNotFound),
+	)
+
+	_, err = newService(mockRepo).GetMatchedPassages(context.Background(), "file000", "file456")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAnalysisService_GetKeywords(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	mockStorage := new(MockWordCloudStorage)
+	mockFileStoringClient := new(MockFileStoringClient)
+
+	// Create service
+	svc := service.NewAnalysisService(
+		mockRepo,
+		mockStorage,
+		mockFileStoringClient,
+		analyzer.NewTextAnalyzer(),
+		analyzer.NewPlagiarismChecker(),
+		analyzer.NewLocalWordCloudGenerator(),
+		analyzer.NewSummarizer(analyzer.NewTextAnalyzer()),
+	)
+
+	// Set up mock expectations
+	mockRepo.On("GetTermCounts", mock.Anything, "file123").Return(
+		[]models.TermCount{
+			{Term: "плагиат", Word: "плагиата", Count: 2},
+			{Term: "текст", Word: "текста", Count: 2},
+		}, nil,
+	)
+	mockRepo.On("GetDocumentFrequencies", mock.Anything, []string{"плагиат", "текст"}).Return(
+		map[string]int32{"плагиат": 1, "текст": 3}, int32(3), nil,
+	)
+
+	// Call the method
+	keywords, err := svc.GetKeywords(context.Background(), "file123", 1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, keywords, 1)
+	assert.Equal(t, "плагиата", keywords[0].Word, "The rarer term should come first")
+	assert.Equal(t, int32(1), keywords[0].DocumentFrequency)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAnalysisService_GetKeywords_CountsTermsOfOlderFiles(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	mockStorage := new(MockWordCloudStorage)
+	mockFileStoringClient := new(MockFileStoringClient)
+
+	// Create service
+	svc := service.NewAnalysisService(
+		mockRepo,
+		mockStorage,
+		mockFileStoringClient,
+		analyzer.NewTextAnalyzer(),
+		analyzer.NewPlagiarismChecker(),
+		analyzer.NewLocalWordCloudGenerator(),
+		analyzer.NewSummarizer(analyzer.NewTextAnalyzer()),
+	)
+
+	// The file was analyzed before keywords were introduced
+	terms := []models.TermCount{
+		{Term: "content", Word: "content", Count: 1},
+		{Term: "file", Word: "file", Count: 1},
+		{Term: "test", Word: "test", Count: 1},
+	}
+	mockRepo.On("GetTermCounts", mock.Anything, "file123").Return([]models.TermCount{}, nil)
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
+		&models.AnalysisResult{FileID: "file123"}, nil,
+	)
+	mockFileStoringClient.On("GetFile", mock.Anything, "file123").Return(
+		"test.txt", []byte("This is a test file content."), nil,
+	)
+	mockRepo.On("SaveTermCounts", mock.Anything, "file123", terms).Return(nil)
+	mockRepo.On("GetDocumentFrequencies", mock.Anything, []string{"content", "file", "test"}).Return(
+		map[string]int32{"content": 1, "file": 1, "test": 1}, int32(1), nil,
+	)
+
+	// Call the method
+	keywords, err := svc.GetKeywords(context.Background(), "file123", 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, keywords, 3)
+
+	// Files that were never analyzed are not added to the corpus
+	mockRepo.On("GetTermCounts", mock.Anything, "file456").Return([]models.TermCount{}, nil)
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file456").Return(
+		nil, fmt.Errorf("analysis result not found for file ID file456: %w", repository.ErrNotFound),
+	)
+
+	_, err = svc.GetKeywords(context.Background(), "file456", 0)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.Contains(t, err.Error(), "failed to get analysis results")
+
+	mockRepo.AssertExpectations(t)
+	mockFileStoringClient.AssertExpectations(t)
+}
+
+func TestAnalysisService_GetWordFrequencies(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	mockStorage := new(MockWordCloudStorage)
+	mockFileStoringClient := new(MockFileStoringClient)
+	textAnalyzer := analyzer.NewTextAnalyzer()
+
+	// Create service
+	svc := service.NewAnalysisService(
+		mockRepo,
+		mockStorage,
+		mockFileStoringClient,
+		textAnalyzer,
+		analyzer.NewPlagiarismChecker(),
+		analyzer.NewLocalWordCloudGenerator(),
+		analyzer.NewSummarizer(textAnalyzer),
+	)
+
+	// Set up mock expectations
+	mockFileStoringClient.On("GetFile", mock.Anything, "file123").Return(
+		"test.txt", []byte("Плагиат и плагиата, плагиат. Текст текста и ещё текст"), nil,
+	)
+	mockFileStoringClient.On("GetFile", mock.Anything, "missing").Return(
+		"", []byte(nil), errors.New("file not found"),
+	)
+
+	// Inflected forms are counted as one word, stop words are removed
+	words, err := svc.GetWordFrequencies(context.Background(), "file123", analyzer.WordFrequencyOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []analyzer.WordItem{
+		{Text: "плагиат", Value: 3},
+		{Text: "текст", Value: 3},
+	}, words)
+
+	// Limit
+	words, err = svc.GetWordFrequencies(context.Background(), "file123", analyzer.WordFrequencyOptions{Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []analyzer.WordItem{{Text: "плагиат", Value: 3}}, words)
+
+	// Stop words kept and forms counted separately
+	words, err = svc.GetWordFrequencies(context.Background(), "file123", analyzer.WordFrequencyOptions{
+		KeepStopWords: true,
+		SeparateForms: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []analyzer.WordItem{
+		{Text: "и", Value: 2},
+		{Text: "плагиат", Value: 2},
+		{Text: "текст", Value: 2},
+		{Text: "еще", Value: 1},
+		{Text: "плагиата", Value: 1},
+		{Text: "текста", Value: 1},
+	}, words)
+
+	// Missing file
+	_, err = svc.GetWordFrequencies(context.Background(), "missing", analyzer.WordFrequencyOptions{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get file content")
+}
+
 func TestAnalysisService_GetWordCloud(t *testing.T) {
 	// Create mocks
 	mockRepo := new(MockAnalysisRepository)
@@ -509,7 +1068,7 @@ func TestAnalysisService_GetWordCloud(t *testing.T) {
 	mockFileStoringClient := new(MockFileStoringClient)
 	textAnalyzer := analyzer.NewTextAnalyzer()
 	plagiarismChecker := analyzer.NewPlagiarismChecker()
-	wordCloudGenerator := analyzer.NewWordCloudGenerator("")
+	wordCloudGenerator := analyzer.NewLocalWordCloudGenerator()
 
 	// Create service
 	svc := service.NewAnalysisService(
@@ -519,6 +1078,7 @@ func TestAnalysisService_GetWordCloud(t *testing.T) {
 		textAnalyzer,
 		plagiarismChecker,
 		wordCloudGenerator,
+		analyzer.NewSummarizer(textAnalyzer),
 	)
 
 	// Set up mock expectations
@@ -526,12 +1086,23 @@ func TestAnalysisService_GetWordCloud(t *testing.T) {
 		[]byte("mock-image-data"), nil,
 	)
 
+	mockStorage.On("GetWordCloud", mock.Anything, "wordcloud123.svg").Return(
+		[]byte("<svg></svg>"), nil,
+	)
+
 	// Call the method
-	image, err := svc.GetWordCloud(context.Background(), "wordcloud123.png")
+	image, format, err := svc.GetWordCloud(context.Background(), "wordcloud123.png")
 
 	// Assert
 	assert.NoError(t, err)
 	assert.Equal(t, []byte("mock-image-data"), image)
+	assert.Equal(t, analyzer.PNGFormat, format)
+
+	// The format is recorded as the extension of the location
+	image, format, err = svc.GetWordCloud(context.Background(), "wordcloud123.svg")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("<svg></svg>"), image)
+	assert.Equal(t, analyzer.SVGFormat, format)
 
 	mockStorage.AssertExpectations(t)
 }
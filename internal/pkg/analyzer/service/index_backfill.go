@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+)
+
+const (
+	// DefaultIndexBackfillInterval is how often the backfill checks for unindexed files
+	DefaultIndexBackfillInterval = 10 * time.Minute
+
+	// DefaultIndexRetryDelay is how long a file whose indexing failed waits before its first retry
+	DefaultIndexRetryDelay = time.Minute
+
+	// DefaultIndexMaxRetryDelay caps the delay between retries of a file whose indexing keeps failing
+	DefaultIndexMaxRetryDelay = 24 * time.Hour
+)
+
+// IndexBackfill indexes analyzed files missing from the LSH and fingerprint indexes
+// Files analyzed before the indexes existed or by an older algorithm are indexed at startup and then periodically,
+// a file whose indexing failed is retried with an exponential backoff
+type IndexBackfill struct {
+	analysis *AnalysisService
+
+	// Interval is how often unindexed files are checked for
+	Interval time.Duration
+
+	// RetryDelay is the delay before the first retry of a failed file, doubled on every further failure
+	RetryDelay time.Duration
+
+	// MaxRetryDelay caps the delay between retries of a failed file
+	MaxRetryDelay time.Duration
+}
+
+// NewIndexBackfill creates a backfill indexing the files of the analysis service
+func NewIndexBackfill(analysis *AnalysisService) *IndexBackfill {
+	return &IndexBackfill{
+		analysis:      analysis,
+		Interval:      DefaultIndexBackfillInterval,
+		RetryDelay:    DefaultIndexRetryDelay,
+		MaxRetryDelay: DefaultIndexMaxRetryDelay,
+	}
+}
+
+// Run indexes the missing files right away and then every Interval until ctx is cancelled
+func (b *IndexBackfill) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.Interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := b.IndexMissing(ctx)
+		if err != nil {
+			log.Printf("Failed to backfill the index: %v", err)
+		}
+		if count > 0 {
+			log.Printf("Indexed %d previously analyzed files", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// IndexMissing indexes the analyzed files missing from the index and returns how many were indexed
+// Failures are recorded so the file is skipped until its next attempt is due
+func (b *IndexBackfill) IndexMissing(ctx context.Context) (int, error) {
+	fileIDs, err := b.analysis.repo.GetUnindexedFileIDs(ctx, analyzer.AlgorithmVersion)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get unindexed file IDs: %w", err)
+	}
+
+	count := 0
+	for _, fileID := range fileIDs {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		if err := b.analysis.indexFile(ctx, fileID); err != nil {
+			log.Printf("Failed to index file %s: %v", fileID, err)
+			if err := b.analysis.repo.RecordIndexFailure(ctx, fileID, err.Error(), b.RetryDelay, b.MaxRetryDelay); err != nil {
+				return count, err
+			}
+			continue
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// indexFile computes and saves the signature and fingerprints of an analyzed file
+func (s *AnalysisService) indexFile(ctx context.Context, fileID string) error {
+	_, content, err := s.fileStoringClient.GetFile(ctx, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	signature := s.plagiarismChecker.Signature(string(content))
+	bandKeys := s.plagiarismChecker.MinHasher.BandKeys(signature)
+	fingerprints := s.plagiarismChecker.Fingerprints(string(content))
+	if err := s.repo.SaveSignature(ctx, fileID, signature, bandKeys, fingerprints, analyzer.AlgorithmVersion); err != nil {
+		return fmt.Errorf("failed to save signature: %w", err)
+	}
+
+	return nil
+}
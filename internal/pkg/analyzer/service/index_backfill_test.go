@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/service"
+)
+
+func TestIndexBackfill_IndexMissing(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	mockFileStoringClient := new(MockFileStoringClient)
+	backfill := service.NewIndexBackfill(newJobTestService(mockRepo, mockFileStoringClient))
+
+	// Set up mock expectations: file456 was analyzed before the LSH index existed
+	mockRepo.On("GetUnindexedFileIDs", mock.Anything, int32(analyzer.AlgorithmVersion)).Return(
+		[]string{"file456"}, nil,
+	)
+	mockFileStoringClient.On("GetFile", mock.Anything, "file456").Return(
+		"test456.txt", []byte("This is a test file content."), nil,
+	)
+	mockRepo.On("SaveSignature", mock.Anything, "file456", mock.Anything, mock.Anything, mock.Anything, int32(analyzer.AlgorithmVersion)).Return(nil)
+
+	// Call the method
+	count, err := backfill.IndexMissing(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	mockRepo.AssertExpectations(t)
+	mockFileStoringClient.AssertExpectations(t)
+}
+
+func TestIndexBackfill_IndexMissing_RecordsFailure(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	mockFileStoringClient := new(MockFileStoringClient)
+	backfill := service.NewIndexBackfill(newJobTestService(mockRepo, mockFileStoringClient))
+	backfill.RetryDelay = time.Minute
+	backfill.MaxRetryDelay = time.Hour
+
+	// Set up mock expectations: file456 is missing from the storage, file789 is still indexed
+	mockRepo.On("GetUnindexedFileIDs", mock.Anything, int32(analyzer.AlgorithmVersion)).Return(
+		[]string{"file456", "file789"}, nil,
+	)
+	mockFileStoringClient.On("GetFile", mock.Anything, "file456").Return(
+		"", []byte(nil), errors.New("file not found"),
+	)
+	mockRepo.On("RecordIndexFailure", mock.Anything, "file456", mock.MatchedBy(func(reason string) bool {
+		return reason == "failed to get file content: file not found"
+	}), time.Minute, time.Hour).Return(nil)
+	mockFileStoringClient.On("GetFile", mock.Anything, "file789").Return(
+		"test789.txt", []byte("This is a test file content."), nil,
+	)
+	mockRepo.On("SaveSignature", mock.Anything, "file789", mock.Anything, mock.Anything, mock.Anything, int32(analyzer.AlgorithmVersion)).Return(nil)
+
+	// Call the method
+	count, err := backfill.IndexMissing(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	mockRepo.AssertExpectations(t)
+	mockFileStoringClient.AssertExpectations(t)
+}
+
+func TestIndexBackfill_IndexMissing_ErrorGettingFileIDs(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	backfill := service.NewIndexBackfill(newJobTestService(mockRepo, new(MockFileStoringClient)))
+
+	// Set up mock expectations
+	mockRepo.On("GetUnindexedFileIDs", mock.Anything, int32(analyzer.AlgorithmVersion)).Return(
+		[]string(nil), errors.New("database error"),
+	)
+
+	// Call the method
+	count, err := backfill.IndexMissing(context.Background())
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, 0, count)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Defaults of the job worker pool
+const (
+	DefaultJobPollInterval = 5 * time.Second
+	DefaultJobTimeout      = 10 * time.Minute
+	DefaultJobMaxAttempts  = 3
+)
+
+// JobWorkerPool runs queued analysis jobs on a fixed number of workers
+// Jobs are stored in the repository, so they survive restarts: the jobs of stopped workers
+// are requeued once they run for longer than StaleAfter
+type JobWorkerPool struct {
+	service *AnalysisService
+
+	// Workers is the number of jobs run concurrently
+	Workers int
+
+	// PollInterval is how often idle workers check the queue for jobs submitted
+	// by other instances of the service
+	PollInterval time.Duration
+
+	// JobTimeout limits the time a job runs, jobs exceeding it fail
+	JobTimeout time.Duration
+
+	// StaleAfter is the time after which a running job is considered abandoned by its worker
+	StaleAfter time.Duration
+
+	// MaxAttempts is the number of times an abandoned job is started before it fails
+	MaxAttempts int32
+}
+
+// NewJobWorkerPool creates a pool of workers running the analysis jobs of the service
+func NewJobWorkerPool(service *AnalysisService, workers int, jobTimeout time.Duration) *JobWorkerPool {
+	return &JobWorkerPool{
+		service:      service,
+		Workers:      workers,
+		PollInterval: DefaultJobPollInterval,
+		JobTimeout:   jobTimeout,
+		StaleAfter:   2 * jobTimeout,
+		MaxAttempts:  DefaultJobMaxAttempts,
+	}
+}
+
+// Run runs the workers until ctx is cancelled and waits for them to stop
+func (p *JobWorkerPool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < p.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+
+	if p.StaleAfter > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.requeueStaleJobs(ctx)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// work runs queued jobs until the queue is empty, then waits for a new job
+func (p *JobWorkerPool) work(ctx context.Context) {
+	ticker := time.NewTicker(p.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			ran, err := p.service.RunNextJob(ctx, p.JobTimeout)
+			if err != nil {
+				log.Printf("Failed to run analysis job: %v", err)
+			}
+			if !ran {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.service.jobSubmitted:
+		case <-ticker.C:
+		}
+	}
+}
+
+// requeueStaleJobs periodically requeues the jobs abandoned by stopped workers
+func (p *JobWorkerPool) requeueStaleJobs(ctx context.Context) {
+	interval := p.StaleAfter / 2
+	if interval < p.PollInterval {
+		interval = p.PollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := p.service.RequeueStaleJobs(ctx, p.StaleAfter, p.MaxAttempts)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to requeue stale analysis jobs: %v", err)
+		}
+		if count > 0 {
+			log.Printf("Requeued %d stale analysis jobs", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/repository"
+	repomocks "local.dev/doc-analyzer/internal/pkg/analyzer/repository/mocks"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/service"
+)
+
+func newJobTestService(mockRepo *MockAnalysisRepository, mockFileStoringClient *MockFileStoringClient) *service.AnalysisService {
+	textAnalyzer := analyzer.NewTextAnalyzer()
+	return service.NewAnalysisService(
+		mockRepo,
+		new(MockWordCloudStorage),
+		mockFileStoringClient,
+		textAnalyzer,
+		analyzer.NewPlagiarismChecker(),
+		analyzer.NewLocalWordCloudGenerator(),
+		analyzer.NewSummarizer(textAnalyzer),
+	)
+}
+
+func TestAnalysisService_SubmitAnalysis(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	svc := newJobTestService(mockRepo, new(MockFileStoringClient))
+
+	// Set up mock expectations
+	var created *models.AnalysisJob
+	mockRepo.On("CreateAnalysisJob", mock.Anything, mock.AnythingOfType("*models.AnalysisJob")).
+		Run(func(args mock.Arguments) { created = args.Get(1).(*models.AnalysisJob) }).
+		Return(nil)
+
+	// Call the method
+	job, err := svc.SubmitAnalysis(context.Background(), "file123", true, analyzer.WordCloudOptions{Format: analyzer.SVGFormat}, "https://lms.example.com/hooks")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Same(t, created, job)
+	assert.NotEmpty(t, job.ID)
+	assert.Equal(t, "file123", job.FileID)
+	assert.True(t, job.GenerateWordCloud)
+	assert.Equal(t, "https://lms.example.com/hooks", job.WebhookURL)
+	assert.Equal(t, models.JobQueued, job.Status)
+	assert.False(t, job.CreatedAt.IsZero())
+
+	var options analyzer.WordCloudOptions
+	require.NoError(t, json.Unmarshal(job.WordCloudOptions, &options))
+	assert.Equal(t, analyzer.WordCloudOptions{Format: analyzer.SVGFormat}, options)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAnalysisService_SubmitAnalysis_Error(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	svc := newJobTestService(mockRepo, new(MockFileStoringClient))
+
+	// Set up mock expectations
+	mockRepo.On("CreateAnalysisJob", mock.Anything, mock.Anything).Return(errors.New("database error"))
+
+	// Call the method
+	job, err := svc.SubmitAnalysis(context.Background(), "file123", false, analyzer.WordCloudOptions{}, "")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, job)
+	assert.Contains(t, err.Error(), "failed to create analysis job")
+}
+
+func TestAnalysisService_GetAnalysisJob(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	svc := newJobTestService(mockRepo, new(MockFileStoringClient))
+
+	// Set up mock expectations
+	mockRepo.On("GetAnalysisJob", mock.Anything, "queued123").Return(
+		&models.AnalysisJob{ID: "queued123", FileID: "file123", Status: models.JobQueued}, nil,
+	)
+	mockRepo.On("GetAnalysisJob", mock.Anything, "done123").Return(
+		&models.AnalysisJob{ID: "done123", FileID: "file123", Status: models.JobDone}, nil,
+	)
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
+		&models.AnalysisResult{FileID: "file123", WordCount: 100, IsPlagiarism: true}, nil,
+	)
+	mockRepo.On("GetSimilarFiles", mock.Anything, "file123").Return(
+		[]models.SimilarFile{{FileID: "file456", Score: 0.9}}, nil,
+	)
+	mockRepo.On("GetAnalysisJob", mock.Anything, "missing").Return(
+		nil, repository.ErrNotFound,
+	)
+
+	// A queued job has no results yet
+	job, err := svc.GetAnalysisJob(context.Background(), "queued123")
+	require.NoError(t, err)
+	assert.Equal(t, models.JobQueued, job.Status)
+	assert.Nil(t, job.Result)
+
+	// A done job comes with the results of its file
+	job, err = svc.GetAnalysisJob(context.Background(), "done123")
+	require.NoError(t, err)
+	require.NotNil(t, job.Result)
+	assert.Equal(t, int32(100), job.Result.WordCount)
+	assert.Equal(t, []string{"file456"}, job.Result.SimilarFileIDs())
+
+	// A missing job keeps the not found error
+	_, err = svc.GetAnalysisJob(context.Background(), "missing")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAnalysisService_RunNextJob(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	svc := newJobTestService(mockRepo, new(MockFileStoringClient))
+
+	// Set up mock expectations
+	mockRepo.On("ClaimAnalysisJob", mock.Anything).Return(
+		&models.AnalysisJob{ID: "job123", FileID: "file123", Status: models.JobRunning, WordCloudOptions: []byte(`{}`)}, nil,
+	).Once()
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
+		&models.AnalysisResult{FileID: "file123", WordCloudLocation: "wordcloud123.png"}, nil,
+	)
+	mockRepo.On("FinishAnalysisJob", mock.Anything, "job123", models.JobDone, "").Return(nil)
+
+	// Call the method
+	ran, err := svc.RunNextJob(context.Background(), time.Minute)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, ran)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAnalysisService_RunNextJob_Failed(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	mockFileStoringClient := new(MockFileStoringClient)
+	svc := newJobTestService(mockRepo, mockFileStoringClient)
+
+	// Set up mock expectations
+	mockRepo.On("ClaimAnalysisJob", mock.Anything).Return(
+		&models.AnalysisJob{ID: "job123", FileID: "file123", Status: models.JobRunning}, nil,
+	).Once()
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(nil, errors.New("not found"))
+	mockFileStoringClient.On("GetFile", mock.Anything, "file123").Return("", []byte{}, errors.New("file not found"))
+	mockRepo.On("FinishAnalysisJob", mock.Anything, "job123", models.JobFailed, mock.MatchedBy(func(message string) bool {
+		return assert.Contains(t, message, "file not found")
+	})).Return(nil)
+
+	// Call the method
+	ran, err := svc.RunNextJob(context.Background(), time.Minute)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, ran)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAnalysisService_RunNextJob_NotifiesWebhooks(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	mockFileStoringClient := new(MockFileStoringClient)
+	mockWebhookRepo := new(repomocks.MockWebhookRepository)
+	svc := newJobTestService(mockRepo, mockFileStoringClient)
+	svc.SetWebhookService(service.NewWebhookService(mockWebhookRepo, "secret", time.Second))
+
+	// Set up mock expectations
+	mockRepo.On("ClaimAnalysisJob", mock.Anything).Return(
+		&models.AnalysisJob{ID: "job123", FileID: "file123", Status: models.JobRunning, WebhookURL: "https://lms.example.com/hooks"}, nil,
+	).Once()
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(nil, errors.New("not found"))
+	mockFileStoringClient.On("GetFile", mock.Anything, "file123").Return("", []byte{}, errors.New("file not found"))
+	mockRepo.On("FinishAnalysisJob", mock.Anything, "job123", models.JobFailed, mock.Anything).Return(nil)
+	mockWebhookRepo.On("GetWebhooksForEvent", mock.Anything, models.EventAnalysisFailed).Return([]models.Webhook{}, nil)
+	mockWebhookRepo.On("CreateWebhookDeliveries", mock.Anything, mock.MatchedBy(func(deliveries []models.WebhookDelivery) bool {
+		return len(deliveries) == 1 &&
+			deliveries[0].URL == "https://lms.example.com/hooks" &&
+			deliveries[0].Event == models.EventAnalysisFailed &&
+			deliveries[0].FileID == "file123"
+	})).Return(nil)
+
+	// Call the method
+	ran, err := svc.RunNextJob(context.Background(), time.Minute)
+
+	// Assert: the webhook URL of the job is notified of the failure
+	assert.NoError(t, err)
+	assert.True(t, ran)
+	mockRepo.AssertExpectations(t)
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestAnalysisService_RunNextJob_EmptyQueue(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	svc := newJobTestService(mockRepo, new(MockFileStoringClient))
+
+	// Set up mock expectations
+	mockRepo.On("ClaimAnalysisJob", mock.Anything).Return(nil, nil)
+
+	// Call the method
+	ran, err := svc.RunNextJob(context.Background(), time.Minute)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, ran)
+	mockRepo.AssertNotCalled(t, "FinishAnalysisJob")
+}
+
+func TestJobWorkerPool_Run(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	svc := newJobTestService(mockRepo, new(MockFileStoringClient))
+
+	// Set up mock expectations, the submitted job is claimed by one of the workers
+	finished := make(chan string, 1)
+	mockRepo.On("CreateAnalysisJob", mock.Anything, mock.Anything).Return(nil)
+	idle := make(chan struct{}, 2)
+	mockRepo.On("ClaimAnalysisJob", mock.Anything).
+		Run(func(args mock.Arguments) { idle <- struct{}{} }).
+		Return(nil, nil).Twice()
+	mockRepo.On("ClaimAnalysisJob", mock.Anything).Return(
+		&models.AnalysisJob{ID: "job123", FileID: "file123", Status: models.JobRunning}, nil,
+	).Once()
+	mockRepo.On("ClaimAnalysisJob", mock.Anything).Return(nil, nil)
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(
+		&models.AnalysisResult{FileID: "file123"}, nil,
+	)
+	mockRepo.On("FinishAnalysisJob", mock.Anything, "job123", models.JobDone, "").
+		Run(func(args mock.Arguments) { finished <- args.String(1) }).
+		Return(nil)
+	mockRepo.On("RequeueStaleAnalysisJobs", mock.Anything, 2*time.Minute, int32(service.DefaultJobMaxAttempts)).
+		Return(int64(0), nil)
+
+	// Run the pool
+	pool := service.NewJobWorkerPool(svc, 2, time.Minute)
+	pool.PollInterval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		pool.Run(ctx)
+		close(stopped)
+	}()
+
+	// Wait for both workers to find the queue empty before submitting the job
+	for i := 0; i < 2; i++ {
+		select {
+		case <-idle:
+		case <-time.After(time.Second):
+			t.Fatal("the workers did not check the queue")
+		}
+	}
+
+	_, err := svc.SubmitAnalysis(context.Background(), "file123", false, analyzer.WordCloudOptions{}, "")
+	require.NoError(t, err)
+
+	// Assert
+	select {
+	case jobID := <-finished:
+		assert.Equal(t, "job123", jobID)
+	case <-time.After(time.Second):
+		t.Fatal("the submitted job was not run")
+	}
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("the pool did not stop")
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+)
+
+// progressBufferSize is the number of events buffered for a slow subscriber,
+// older events are dropped when it is full
+const progressBufferSize = 16
+
+// ProgressBroker relays the progress of the analyses run by this instance to their subscribers
+// The last event of each running analysis is kept, so that late subscribers start with it
+type ProgressBroker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan models.AnalysisProgress]struct{}
+	last        map[string]models.AnalysisProgress
+}
+
+// NewProgressBroker creates a new ProgressBroker instance
+func NewProgressBroker() *ProgressBroker {
+	return &ProgressBroker{
+		subscribers: make(map[string]map[chan models.AnalysisProgress]struct{}),
+		last:        make(map[string]models.AnalysisProgress),
+	}
+}
+
+// Publish sends an event to the subscribers of its file
+// Subscribers too slow to receive the events lose the oldest ones, never the last one
+func (b *ProgressBroker) Publish(event models.AnalysisProgress) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if event.Stage.Finished() {
+		delete(b.last, event.FileID)
+	} else {
+		b.last[event.FileID] = event
+	}
+
+	for events := range b.subscribers[event.FileID] {
+		select {
+		case events <- event:
+		default:
+			// Drop the oldest event, publishers hold the lock so there is room afterwards
+			select {
+			case <-events:
+			default:
+			}
+			events <- event
+		}
+	}
+}
+
+// Subscribe returns the events of the analyses of a file, starting with the last event
+// of a running analysis, and reports whether an analysis is running
+// The returned function unsubscribes, it must be called once the events are no longer read
+func (b *ProgressBroker) Subscribe(fileID string) (<-chan models.AnalysisProgress, bool, func()) {
+	events := make(chan models.AnalysisProgress, progressBufferSize)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subscribers[fileID] == nil {
+		b.subscribers[fileID] = make(map[chan models.AnalysisProgress]struct{})
+	}
+	b.subscribers[fileID][events] = struct{}{}
+
+	last, running := b.last[fileID]
+	if running {
+		events <- last
+	}
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.subscribers[fileID], events)
+		if len(b.subscribers[fileID]) == 0 {
+			delete(b.subscribers, fileID)
+		}
+	}
+	return events, running, unsubscribe
+}
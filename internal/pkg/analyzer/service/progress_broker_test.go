@@ -0,0 +1,162 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/analyzer"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/service"
+)
+
+func TestProgressBroker(t *testing.T) {
+	broker := service.NewProgressBroker()
+
+	// Subscribers of other files get nothing
+	events, running, unsubscribe := broker.Subscribe("file123")
+	defer unsubscribe()
+	assert.False(t, running)
+	otherEvents, _, unsubscribeOther := broker.Subscribe("file456")
+	defer unsubscribeOther()
+
+	broker.Publish(models.AnalysisProgress{FileID: "file123", Stage: models.StageFetchingFile})
+	event := <-events
+	assert.Equal(t, models.StageFetchingFile, event.Stage)
+	assert.False(t, event.Time.IsZero())
+	assert.Empty(t, otherEvents)
+
+	// Late subscribers start with the last event of a running analysis
+	broker.Publish(models.AnalysisProgress{FileID: "file123", Stage: models.StageComparing, Current: 1, Total: 2})
+	lateEvents, running, unsubscribeLate := broker.Subscribe("file123")
+	assert.True(t, running)
+	event = <-lateEvents
+	assert.Equal(t, models.StageComparing, event.Stage)
+	assert.Equal(t, int32(1), event.Current)
+	unsubscribeLate()
+
+	// Finished analyses are not replayed
+	broker.Publish(models.AnalysisProgress{FileID: "file123", Stage: models.StageDone})
+	_, running, unsubscribeLate = broker.Subscribe("file123")
+	assert.False(t, running)
+	unsubscribeLate()
+}
+
+func TestProgressBroker_SlowSubscriber(t *testing.T) {
+	broker := service.NewProgressBroker()
+	events, _, unsubscribe := broker.Subscribe("file123")
+	defer unsubscribe()
+
+	// Publishing never blocks, slow subscribers lose the oldest events but get the last one
+	for i := 1; i <= 100; i++ {
+		broker.Publish(models.AnalysisProgress{FileID: "file123", Stage: models.StageComparing, Current: int32(i), Total: 100})
+	}
+	broker.Publish(models.AnalysisProgress{FileID: "file123", Stage: models.StageDone})
+
+	var last models.AnalysisProgress
+	for len(events) > 0 {
+		last = <-events
+	}
+	assert.Equal(t, models.StageDone, last.Stage)
+}
+
+func TestAnalysisService_WatchAnalysis(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	mockFileStoringClient := new(MockFileStoringClient)
+	svc := newJobTestService(mockRepo, mockFileStoringClient)
+
+	// Set up mock expectations, the first lookup of the results is the one of the watcher
+	watching := make(chan struct{})
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").
+		Run(func(args mock.Arguments) { close(watching) }).
+		Return(nil, errors.New("not found")).Once()
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(nil, errors.New("not found"))
+	mockFileStoringClient.On("GetFile", mock.Anything, "file123").Return("test.txt", []byte("This is a test file content."), nil)
+	mockFileStoringClient.On("GetFile", mock.Anything, "file456").Return("test456.txt", []byte("Another file."), nil)
+	mockRepo.On("FindCandidates", mock.Anything, mock.Anything).Return([]string{"file456"}, nil)
+	mockRepo.On("GetUnindexedFileIDs", mock.Anything, int32(analyzer.AlgorithmVersion)).Return([]string{}, nil)
+	mockRepo.On("SaveAnalysisResult", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("SaveTermCounts", mock.Anything, "file123", mock.Anything).Return(nil)
+	mockRepo.On("SaveSignature", mock.Anything, "file123", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	// Watch the file before it is analyzed
+	var stages []models.AnalysisStage
+	var comparing []int32
+	watched := make(chan error)
+	go func() {
+		watched <- svc.WatchAnalysis(context.Background(), "file123", func(event models.AnalysisProgress) error {
+			stages = append(stages, event.Stage)
+			if event.Stage == models.StageComparing {
+				comparing = append(comparing, event.Current, event.Total)
+			}
+			return nil
+		})
+	}()
+	<-watching
+
+	_, err := svc.AnalyzeFile(context.Background(), "file123", false, analyzer.WordCloudOptions{})
+	require.NoError(t, err)
+
+	// Assert: the watch ends with the analysis
+	select {
+	case err := <-watched:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("the watch did not end")
+	}
+	assert.Equal(t, []models.AnalysisStage{
+		models.StageFetchingFile,
+		models.StageAnalyzingText,
+		models.StageComparing,
+		models.StageComparing,
+		models.StageSaving,
+		models.StageDone,
+	}, stages)
+	assert.Equal(t, []int32{0, 1, 1, 1}, comparing)
+}
+
+func TestAnalysisService_WatchAnalysis_Analyzed(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	svc := newJobTestService(mockRepo, new(MockFileStoringClient))
+
+	// Set up mock expectations
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(&models.AnalysisResult{FileID: "file123"}, nil)
+
+	// Call the method
+	var events []models.AnalysisProgress
+	err := svc.WatchAnalysis(context.Background(), "file123", func(event models.AnalysisProgress) error {
+		events = append(events, event)
+		return nil
+	})
+
+	// Assert: an analyzed file is reported as done at once
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.StageDone, events[0].Stage)
+}
+
+func TestAnalysisService_WatchAnalysis_Cancelled(t *testing.T) {
+	// Create mocks
+	mockRepo := new(MockAnalysisRepository)
+	svc := newJobTestService(mockRepo, new(MockFileStoringClient))
+
+	// Set up mock expectations
+	mockRepo.On("GetAnalysisResult", mock.Anything, "file123").Return(nil, errors.New("not found"))
+
+	// Call the method
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := svc.WatchAnalysis(ctx, "file123", func(event models.AnalysisProgress) error {
+		return nil
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
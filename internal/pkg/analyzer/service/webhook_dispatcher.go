@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultWebhookPollInterval is how often idle dispatchers check for due deliveries
+const DefaultWebhookPollInterval = 5 * time.Second
+
+// WebhookDispatcher sends the webhook deliveries on a fixed number of workers
+// Retries are due later, idle workers find them when they poll the deliveries
+type WebhookDispatcher struct {
+	webhooks *WebhookService
+
+	// Workers is the number of deliveries sent concurrently
+	Workers int
+
+	// PollInterval is how often idle workers check for due deliveries
+	PollInterval time.Duration
+}
+
+// NewWebhookDispatcher creates a dispatcher sending the deliveries of the webhook service
+func NewWebhookDispatcher(webhooks *WebhookService, workers int) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhooks:     webhooks,
+		Workers:      workers,
+		PollInterval: DefaultWebhookPollInterval,
+	}
+}
+
+// Run sends deliveries until ctx is cancelled and waits for the workers to stop
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < d.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// work sends due deliveries until none is due, then waits for new ones
+func (d *WebhookDispatcher) work(ctx context.Context) {
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			sent, err := d.webhooks.DeliverNext(ctx)
+			if err != nil {
+				log.Printf("Failed to deliver webhook: %v", err)
+			}
+			if !sent {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.webhooks.deliveryCreated:
+		case <-ticker.C:
+		}
+	}
+}
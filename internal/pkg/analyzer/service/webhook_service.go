@@ -0,0 +1,467 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/repository"
+)
+
+// Headers of the webhook requests
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// Defaults of the webhook deliveries
+const (
+	DefaultWebhookTimeout     = 10 * time.Second
+	DefaultWebhookMaxAttempts = 5
+	DefaultWebhookRetryDelay  = 30 * time.Second
+	DefaultWebhookMaxDelay    = time.Hour
+
+	// DefaultWebhookDeliveryLimit is the number of deliveries listed if no limit is given
+	DefaultWebhookDeliveryLimit = 100
+)
+
+// ErrInternalWebhookAddress is returned for webhooks reaching an internal address
+var ErrInternalWebhookAddress = errors.New("webhook URL must not reach an internal address")
+
+// HostResolver resolves host names of webhook URLs, implemented by *net.Resolver
+type HostResolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// WebhookPayload is the JSON body of a webhook request
+type WebhookPayload struct {
+	Event  models.WebhookEvent `json:"event"`
+	FileID string              `json:"file_id"`
+
+	// JobID is the analysis job that has finished, empty for synchronous analyses
+	JobID string `json:"job_id,omitempty"`
+
+	OccurredAt time.Time `json:"occurred_at"`
+
+	// Error is the reason a failed analysis has failed
+	Error string `json:"error,omitempty"`
+
+	// Result summarizes the results of a completed analysis
+	Result *WebhookResult `json:"result,omitempty"`
+}
+
+// WebhookResult summarizes analysis results in a webhook payload,
+// the full results are returned by the analysis API
+type WebhookResult struct {
+	IsPlagiarism      bool     `json:"is_plagiarism"`
+	SimilarFileIDs    []string `json:"similar_file_ids"`
+	WordCount         int32    `json:"word_count"`
+	Language          string   `json:"language"`
+	WordCloudLocation string   `json:"word_cloud_location,omitempty"`
+}
+
+// AnalysisNotification describes a finished analysis to notify the webhooks of
+type AnalysisNotification struct {
+	FileID string
+	JobID  string
+
+	// Result holds the results of a completed analysis, nil if it has failed
+	Result *models.AnalysisResult
+
+	// Error is the reason a failed analysis has failed
+	Error string
+
+	// WebhookURL of the analysis request, notified besides the registered webhooks
+	WebhookURL string
+}
+
+// WebhookService registers webhooks and delivers analysis events to them
+// Deliveries are stored in the repository and retried with exponential backoff
+// until the receiver answers with a 2xx status or MaxAttempts attempts have failed
+type WebhookService struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+
+	// secret signs the payloads sent to the webhook URLs of analysis requests,
+	// registered webhooks have their own secrets
+	secret string
+
+	// MaxAttempts is the number of attempts of a delivery before it fails
+	MaxAttempts int32
+
+	// RetryDelay is the delay after the first failed attempt, doubled after each next one up to MaxRetryDelay
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+
+	// AllowInternalAddresses lets webhooks reach loopback, private and link-local addresses,
+	// which are refused by default so that webhook URLs cannot probe the internal network
+	AllowInternalAddresses bool
+
+	// Resolver resolves the hosts of webhook URLs when they are validated
+	// The addresses are checked again on each connection, the host may resolve differently by then
+	Resolver HostResolver
+
+	// deliveryCreated wakes a waiting dispatcher when deliveries are created
+	deliveryCreated chan struct{}
+}
+
+// NewWebhookService creates a new WebhookService instance
+// Webhook URLs of analysis requests are only accepted if secret is set
+func NewWebhookService(repo repository.WebhookRepository, secret string, timeout time.Duration) *WebhookService {
+	w := &WebhookService{
+		repo:            repo,
+		secret:          secret,
+		MaxAttempts:     DefaultWebhookMaxAttempts,
+		RetryDelay:      DefaultWebhookRetryDelay,
+		MaxRetryDelay:   DefaultWebhookMaxDelay,
+		Resolver:        net.DefaultResolver,
+		deliveryCreated: make(chan struct{}, 1),
+	}
+
+	// The address is checked when connecting, after the host is resolved,
+	// so that a host resolving to an internal address after validation is refused too
+	dialer := &net.Dialer{Timeout: timeout, Control: w.checkDialAddress}
+	w.client = &http.Client{
+		Timeout: timeout,
+		// No proxy is used, the checked address must be the address of the receiver
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		// Redirects are not followed, they could lead to an internal address
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return w
+}
+
+// SignWebhookPayload returns the signature header value of a payload: the hex-encoded
+// HMAC-SHA256 of the body with the secret, prefixed with "sha256="
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseWebhookEvent parses the name of a webhook event
+func ParseWebhookEvent(value string) (models.WebhookEvent, error) {
+	for _, event := range models.WebhookEvents {
+		if string(event) == value {
+			return event, nil
+		}
+	}
+	return "", fmt.Errorf("unknown webhook event %q", value)
+}
+
+// ValidateWebhookURL checks that a webhook URL is an absolute HTTP or HTTPS URL
+// whose host resolves to public addresses only, unless internal addresses are allowed
+func (w *WebhookService) ValidateWebhookURL(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return fmt.Errorf("webhook URL must be an absolute http or https URL")
+	}
+	if w.AllowInternalAddresses {
+		return nil
+	}
+
+	addresses := []net.IPAddr{{IP: net.ParseIP(parsed.Hostname())}}
+	if addresses[0].IP == nil {
+		addresses, err = w.Resolver.LookupIPAddr(ctx, parsed.Hostname())
+		if err != nil {
+			return fmt.Errorf("failed to resolve webhook host: %w", err)
+		}
+	}
+	for _, address := range addresses {
+		if isInternalAddress(address.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrInternalWebhookAddress, parsed.Hostname(), address.IP)
+		}
+	}
+	return nil
+}
+
+// ValidateRequestWebhookURL checks the webhook URL of an analysis request,
+// which can only be signed if the service has a secret
+func (w *WebhookService) ValidateRequestWebhookURL(ctx context.Context, rawURL string) error {
+	if w.secret == "" {
+		return errors.New("webhook URLs of requests are disabled, no signing secret is configured")
+	}
+	return w.ValidateWebhookURL(ctx, rawURL)
+}
+
+// checkDialAddress refuses connections to internal addresses unless they are allowed
+func (w *WebhookService) checkDialAddress(network, address string, conn syscall.RawConn) error {
+	if w.AllowInternalAddresses {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid webhook address %q: %w", address, err)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isInternalAddress(ip) {
+		return fmt.Errorf("%w: %s", ErrInternalWebhookAddress, host)
+	}
+	return nil
+}
+
+// isInternalAddress reports whether an IP address is a loopback, private, link-local
+// or unspecified address, including the 169.254.169.254 metadata endpoint of cloud providers
+func isInternalAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// CreateWebhook registers a webhook notified of the events, of all events if none are given
+// A random secret is generated if none is given, it is only returned on creation
+func (w *WebhookService) CreateWebhook(ctx context.Context, webhookURL, secret string, events []models.WebhookEvent) (*models.Webhook, error) {
+	if err := w.ValidateWebhookURL(ctx, webhookURL); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		events = models.WebhookEvents
+	}
+
+	if secret == "" {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = hex.EncodeToString(key)
+	}
+
+	webhook := &models.Webhook{
+		ID:        uuid.New().String(),
+		URL:       webhookURL,
+		Secret:    secret,
+		Events:    events,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := w.repo.CreateWebhook(ctx, webhook); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// ListWebhooks retrieves all registered webhooks
+func (w *WebhookService) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	webhooks, err := w.repo.ListWebhooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook deletes a registered webhook, its pending deliveries are not sent
+func (w *WebhookService) DeleteWebhook(ctx context.Context, webhookID string) error {
+	if err := w.repo.DeleteWebhook(ctx, webhookID); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries retrieves the delivery log, newest first
+func (w *WebhookService) ListDeliveries(ctx context.Context, filter models.WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultWebhookDeliveryLimit
+	}
+	deliveries, err := w.repo.ListWebhookDeliveries(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Notify creates the deliveries of the events of a finished analysis: analysis.completed,
+// plagiarism.detected for files flagged as plagiarism, and analysis.failed
+// The deliveries are sent by a dispatcher
+func (w *WebhookService) Notify(ctx context.Context, notification AnalysisNotification) error {
+	events := []models.WebhookEvent{models.EventAnalysisFailed}
+	if notification.Result != nil {
+		events = []models.WebhookEvent{models.EventAnalysisCompleted}
+		if notification.Result.IsPlagiarism {
+			events = append(events, models.EventPlagiarismDetected)
+		}
+	}
+
+	now := time.Now().UTC()
+	var deliveries []models.WebhookDelivery
+	for _, event := range events {
+		payload, err := json.Marshal(newWebhookPayload(event, notification, now))
+		if err != nil {
+			return fmt.Errorf("failed to encode webhook payload: %w", err)
+		}
+
+		webhooks, err := w.repo.GetWebhooksForEvent(ctx, event)
+		if err != nil {
+			return fmt.Errorf("failed to get webhooks: %w", err)
+		}
+		for _, webhook := range webhooks {
+			deliveries = append(deliveries, newWebhookDelivery(webhook.ID, webhook.URL, event, notification.FileID, payload, now))
+		}
+		if notification.WebhookURL != "" {
+			deliveries = append(deliveries, newWebhookDelivery("", notification.WebhookURL, event, notification.FileID, payload, now))
+		}
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := w.repo.CreateWebhookDeliveries(ctx, deliveries); err != nil {
+		return fmt.Errorf("failed to create webhook deliveries: %w", err)
+	}
+
+	select {
+	case w.deliveryCreated <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// newWebhookPayload builds the payload of an event of a finished analysis
+func newWebhookPayload(event models.WebhookEvent, notification AnalysisNotification, occurredAt time.Time) WebhookPayload {
+	payload := WebhookPayload{
+		Event:      event,
+		FileID:     notification.FileID,
+		JobID:      notification.JobID,
+		OccurredAt: occurredAt,
+		Error:      notification.Error,
+	}
+	if result := notification.Result; result != nil {
+		payload.Result = &WebhookResult{
+			IsPlagiarism:      result.IsPlagiarism,
+			SimilarFileIDs:    result.SimilarFileIDs(),
+			WordCount:         result.WordCount,
+			Language:          result.Language,
+			WordCloudLocation: result.WordCloudLocation,
+		}
+	}
+	return payload
+}
+
+// newWebhookDelivery creates a pending delivery of a payload
+func newWebhookDelivery(webhookID, webhookURL string, event models.WebhookEvent, fileID string, payload []byte, createdAt time.Time) models.WebhookDelivery {
+	return models.WebhookDelivery{
+		ID:        uuid.New().String(),
+		WebhookID: webhookID,
+		URL:       webhookURL,
+		Event:     event,
+		FileID:    fileID,
+		Payload:   payload,
+		Status:    models.DeliveryPending,
+		CreatedAt: createdAt,
+	}
+}
+
+// DeliverNext sends the pending delivery due the earliest and records the outcome
+// It reports whether a delivery was due
+// A delivery interrupted by the cancellation of ctx is retried once its lease expires
+func (w *WebhookService) DeliverNext(ctx context.Context) (bool, error) {
+	// The lease outlasts the request timeout, so the delivery is not claimed again while it is sent
+	delivery, err := w.repo.ClaimWebhookDelivery(ctx, 2*w.client.Timeout+time.Minute)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+	if delivery == nil {
+		return false, nil
+	}
+
+	secret := w.secret
+	if delivery.WebhookID != "" {
+		webhook, err := w.repo.GetWebhook(ctx, delivery.WebhookID)
+		if errors.Is(err, repository.ErrNotFound) {
+			delivery.Status, delivery.Error = models.DeliveryFailed, "the webhook was deleted"
+			return true, w.recordAttempt(ctx, delivery)
+		}
+		if err != nil {
+			return true, fmt.Errorf("failed to get webhook: %w", err)
+		}
+		secret = webhook.Secret
+	}
+
+	delivery.ResponseStatus, err = w.send(ctx, delivery, secret)
+	if err != nil && ctx.Err() != nil {
+		return true, fmt.Errorf("webhook delivery %s interrupted: %w", delivery.ID, err)
+	}
+
+	switch {
+	case err == nil:
+		delivery.Status, delivery.Error = models.DeliveryDelivered, ""
+	case delivery.Attempts >= w.MaxAttempts:
+		delivery.Status, delivery.Error = models.DeliveryFailed, err.Error()
+	default:
+		delivery.Status, delivery.Error = models.DeliveryPending, err.Error()
+		delivery.NextAttemptAt = time.Now().UTC().Add(w.retryDelay(delivery.Attempts))
+	}
+	return true, w.recordAttempt(ctx, delivery)
+}
+
+// recordAttempt saves the outcome of a delivery attempt
+func (w *WebhookService) recordAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if err := w.repo.RecordWebhookAttempt(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// send posts the signed payload of a delivery and returns the response status
+func (w *WebhookService) send(ctx context.Context, delivery *models.WebhookDelivery, secret string) (int32, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "doc-analyzer-webhooks")
+	req.Header.Set(WebhookEventHeader, string(delivery.Event))
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(secret, delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Drain a bit of the body so that the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return int32(resp.StatusCode), fmt.Errorf("unexpected status %s", strings.TrimSpace(resp.Status))
+	}
+	return int32(resp.StatusCode), nil
+}
+
+// retryDelay returns the delay before the next attempt after the given number of failed attempts
+func (w *WebhookService) retryDelay(attempts int32) time.Duration {
+	delay := w.RetryDelay
+	for i := int32(1); i < attempts && delay < w.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > w.MaxRetryDelay {
+		delay = w.MaxRetryDelay
+	}
+	return delay
+}
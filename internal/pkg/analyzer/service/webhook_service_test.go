@@ -0,0 +1,469 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/models"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/repository"
+	repomocks "local.dev/doc-analyzer/internal/pkg/analyzer/repository/mocks"
+	"local.dev/doc-analyzer/internal/pkg/analyzer/service"
+)
+
+// webhookRequest is a request received by a test webhook receiver
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newWebhookReceiver starts a receiver answering with the given statuses in turn, the last one repeated
+func newWebhookReceiver(t *testing.T, statuses ...int) (*httptest.Server, <-chan webhookRequest) {
+	requests := make(chan webhookRequest, 10)
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- webhookRequest{header: r.Header.Clone(), body: body}
+
+		status := statuses[len(statuses)-1]
+		if calls < len(statuses) {
+			status = statuses[calls]
+		}
+		calls++
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	// Known HMAC-SHA256 test vector (RFC 4231, test case 2)
+	signature := service.SignWebhookPayload("Jefe", []byte("what do ya want for nothing?"))
+	assert.Equal(t, "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", signature)
+}
+
+// staticResolver resolves host names from a map
+type staticResolver map[string][]string
+
+func (r staticResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	values, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	addresses := make([]net.IPAddr, len(values))
+	for i, value := range values {
+		addresses[i] = net.IPAddr{IP: net.ParseIP(value)}
+	}
+	return addresses, nil
+}
+
+// testResolver resolves the hosts used by the webhook tests
+var testResolver = staticResolver{
+	"lms.example.com":      {"93.184.215.14"},
+	"request.example.com":  {"93.184.215.14", "2606:2800:21f:cb07:6820:80da:af6b:8b2c"},
+	"localhost":            {"127.0.0.1", "::1"},
+	"internal.example.com": {"93.184.215.14", "10.0.0.5"},
+}
+
+// newTestWebhookService creates a webhook service resolving hosts with testResolver
+func newTestWebhookService(repo repository.WebhookRepository, secret string) *service.WebhookService {
+	webhooks := service.NewWebhookService(repo, secret, time.Second)
+	webhooks.Resolver = testResolver
+	return webhooks
+}
+
+func TestWebhookService_ValidateWebhookURL(t *testing.T) {
+	webhooks := newTestWebhookService(new(repomocks.MockWebhookRepository), "")
+	ctx := context.Background()
+
+	assert.NoError(t, webhooks.ValidateWebhookURL(ctx, "https://lms.example.com/hooks"))
+	assert.NoError(t, webhooks.ValidateWebhookURL(ctx, "http://request.example.com:8080/hooks"))
+	assert.NoError(t, webhooks.ValidateWebhookURL(ctx, "https://93.184.215.14/hooks"))
+	assert.Error(t, webhooks.ValidateWebhookURL(ctx, "ftp://lms.example.com/hooks"))
+	assert.Error(t, webhooks.ValidateWebhookURL(ctx, "/hooks"))
+	assert.Error(t, webhooks.ValidateWebhookURL(ctx, "://"))
+	assert.Error(t, webhooks.ValidateWebhookURL(ctx, "https://missing.example.com/hooks"))
+
+	// Internal addresses are refused, even if only one of the addresses of the host is internal
+	for _, internal := range []string{
+		"http://localhost:8080/hooks", "http://127.0.0.1/hooks", "http://[::1]/hooks", "http://10.1.2.3/hooks",
+		"http://172.16.0.1/hooks", "http://192.168.1.1/hooks", "http://169.254.169.254/latest/meta-data",
+		"http://[fe80::1]/hooks", "http://0.0.0.0/hooks", "http://[::ffff:127.0.0.1]/hooks",
+		"https://internal.example.com/hooks",
+	} {
+		assert.ErrorIs(t, webhooks.ValidateWebhookURL(ctx, internal), service.ErrInternalWebhookAddress, internal)
+	}
+
+	// Unless internal addresses are allowed
+	webhooks.AllowInternalAddresses = true
+	assert.NoError(t, webhooks.ValidateWebhookURL(ctx, "http://localhost:8080/hooks"))
+}
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	// Create mocks
+	mockRepo := new(repomocks.MockWebhookRepository)
+	webhooks := newTestWebhookService(mockRepo, "")
+
+	// Set up mock expectations
+	mockRepo.On("CreateWebhook", mock.Anything, mock.AnythingOfType("*models.Webhook")).Return(nil)
+
+	// A secret is generated and all events are selected by default
+	webhook, err := webhooks.CreateWebhook(context.Background(), "https://lms.example.com/hooks", "", nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, webhook.ID)
+	assert.Len(t, webhook.Secret, 64)
+	assert.Equal(t, models.WebhookEvents, webhook.Events)
+
+	// Given secrets and events are kept
+	webhook, err = webhooks.CreateWebhook(
+		context.Background(), "https://lms.example.com/hooks", "secret",
+		[]models.WebhookEvent{models.EventPlagiarismDetected},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", webhook.Secret)
+	assert.Equal(t, []models.WebhookEvent{models.EventPlagiarismDetected}, webhook.Events)
+
+	// Invalid URLs and URLs of internal addresses are rejected
+	_, err = webhooks.CreateWebhook(context.Background(), "lms.example.com", "", nil)
+	assert.Error(t, err)
+	_, err = webhooks.CreateWebhook(context.Background(), "http://localhost:8080/hooks", "", nil)
+	assert.ErrorIs(t, err, service.ErrInternalWebhookAddress)
+
+	mockRepo.AssertNumberOfCalls(t, "CreateWebhook", 2)
+}
+
+func TestWebhookService_Notify(t *testing.T) {
+	// Create mocks
+	mockRepo := new(repomocks.MockWebhookRepository)
+	webhooks := service.NewWebhookService(mockRepo, "secret", time.Second)
+
+	// Set up mock expectations
+	mockRepo.On("GetWebhooksForEvent", mock.Anything, models.EventAnalysisCompleted).Return(
+		[]models.Webhook{{ID: "hook123", URL: "https://lms.example.com/hooks"}}, nil,
+	)
+	mockRepo.On("GetWebhooksForEvent", mock.Anything, models.EventPlagiarismDetected).Return(
+		[]models.Webhook{}, nil,
+	)
+	var deliveries []models.WebhookDelivery
+	mockRepo.On("CreateWebhookDeliveries", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { deliveries = args.Get(1).([]models.WebhookDelivery) }).
+		Return(nil)
+
+	// Call the method
+	err := webhooks.Notify(context.Background(), service.AnalysisNotification{
+		FileID: "file123",
+		JobID:  "job123",
+		Result: &models.AnalysisResult{
+			FileID:       "file123",
+			WordCount:    100,
+			IsPlagiarism: true,
+			SimilarFiles: []models.SimilarFile{{FileID: "file456"}},
+		},
+		WebhookURL: "https://request.example.com/hook",
+	})
+
+	// Assert: the registered webhook gets the completion, the request URL gets both events
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	assert.Equal(t, "hook123", deliveries[0].WebhookID)
+	assert.Equal(t, models.EventAnalysisCompleted, deliveries[0].Event)
+	assert.Equal(t, "", deliveries[1].WebhookID)
+	assert.Equal(t, "https://request.example.com/hook", deliveries[1].URL)
+	assert.Equal(t, models.EventAnalysisCompleted, deliveries[1].Event)
+	assert.Equal(t, models.EventPlagiarismDetected, deliveries[2].Event)
+
+	var payload service.WebhookPayload
+	require.NoError(t, json.Unmarshal(deliveries[2].Payload, &payload))
+	assert.Equal(t, models.EventPlagiarismDetected, payload.Event)
+	assert.Equal(t, "file123", payload.FileID)
+	assert.Equal(t, "job123", payload.JobID)
+	require.NotNil(t, payload.Result)
+	assert.True(t, payload.Result.IsPlagiarism)
+	assert.Equal(t, []string{"file456"}, payload.Result.SimilarFileIDs)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_NotifyFailure(t *testing.T) {
+	// Create mocks
+	mockRepo := new(repomocks.MockWebhookRepository)
+	webhooks := service.NewWebhookService(mockRepo, "secret", time.Second)
+
+	// Set up mock expectations, nobody is notified of failures
+	mockRepo.On("GetWebhooksForEvent", mock.Anything, models.EventAnalysisFailed).Return([]models.Webhook(nil), nil)
+
+	// Call the method
+	err := webhooks.Notify(context.Background(), service.AnalysisNotification{FileID: "file123", Error: "file not found"})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "CreateWebhookDeliveries")
+}
+
+func TestWebhookService_DeliverNext(t *testing.T) {
+	receiver, requests := newWebhookReceiver(t, http.StatusNoContent)
+
+	// Create mocks
+	mockRepo := new(repomocks.MockWebhookRepository)
+	webhooks := service.NewWebhookService(mockRepo, "request-secret", time.Second)
+	webhooks.AllowInternalAddresses = true // The test receiver listens on the loopback address
+
+	payload := []byte(`{"event":"analysis.completed","file_id":"file123"}`)
+
+	// Set up mock expectations
+	mockRepo.On("ClaimWebhookDelivery", mock.Anything, mock.Anything).Return(&models.WebhookDelivery{
+		ID: "delivery1", WebhookID: "hook123", URL: receiver.URL, Event: models.EventAnalysisCompleted,
+		FileID: "file123", Payload: payload, Status: models.DeliveryPending, Attempts: 1,
+	}, nil).Once()
+	mockRepo.On("GetWebhook", mock.Anything, "hook123").Return(&models.Webhook{ID: "hook123", Secret: "hook-secret"}, nil)
+	mockRepo.On("RecordWebhookAttempt", mock.Anything, mock.MatchedBy(func(delivery *models.WebhookDelivery) bool {
+		return delivery.Status == models.DeliveryDelivered && delivery.ResponseStatus == http.StatusNoContent
+	})).Return(nil)
+
+	// Call the method
+	sent, err := webhooks.DeliverNext(context.Background())
+
+	// Assert: the payload is signed with the secret of the webhook
+	require.NoError(t, err)
+	assert.True(t, sent)
+
+	request := <-requests
+	assert.Equal(t, payload, request.body)
+	assert.Equal(t, "application/json", request.header.Get("Content-Type"))
+	assert.Equal(t, "analysis.completed", request.header.Get(service.WebhookEventHeader))
+	assert.Equal(t, "delivery1", request.header.Get(service.WebhookDeliveryHeader))
+	assert.Equal(t, service.SignWebhookPayload("hook-secret", payload), request.header.Get(service.WebhookSignatureHeader))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_DeliverNext_Retry(t *testing.T) {
+	receiver, _ := newWebhookReceiver(t, http.StatusServiceUnavailable)
+
+	// Create mocks
+	mockRepo := new(repomocks.MockWebhookRepository)
+	webhooks := service.NewWebhookService(mockRepo, "request-secret", time.Second)
+	webhooks.AllowInternalAddresses = true // The test receiver listens on the loopback address
+	webhooks.RetryDelay = time.Minute
+
+	// Set up mock expectations for the third attempt of a request webhook
+	mockRepo.On("ClaimWebhookDelivery", mock.Anything, mock.Anything).Return(&models.WebhookDelivery{
+		ID: "delivery1", URL: receiver.URL, Event: models.EventAnalysisCompleted, Payload: []byte(`{}`),
+		Status: models.DeliveryPending, Attempts: 3,
+	}, nil).Once()
+	var recorded *models.WebhookDelivery
+	mockRepo.On("RecordWebhookAttempt", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(*models.WebhookDelivery) }).
+		Return(nil)
+
+	// Call the method
+	before := time.Now()
+	sent, err := webhooks.DeliverNext(context.Background())
+
+	// Assert: the delay doubles after each failed attempt
+	require.NoError(t, err)
+	assert.True(t, sent)
+	require.NotNil(t, recorded)
+	assert.Equal(t, models.DeliveryPending, recorded.Status)
+	assert.Equal(t, int32(http.StatusServiceUnavailable), recorded.ResponseStatus)
+	assert.Contains(t, recorded.Error, "503")
+	assert.WithinDuration(t, before.Add(4*time.Minute), recorded.NextAttemptAt, 5*time.Second)
+	mockRepo.AssertNotCalled(t, "GetWebhook")
+}
+
+func TestWebhookService_DeliverNext_LastAttempt(t *testing.T) {
+	receiver, _ := newWebhookReceiver(t, http.StatusInternalServerError)
+
+	// Create mocks
+	mockRepo := new(repomocks.MockWebhookRepository)
+	webhooks := service.NewWebhookService(mockRepo, "request-secret", time.Second)
+	webhooks.AllowInternalAddresses = true // The test receiver listens on the loopback address
+
+	// Set up mock expectations for the last attempt
+	mockRepo.On("ClaimWebhookDelivery", mock.Anything, mock.Anything).Return(&models.WebhookDelivery{
+		ID: "delivery1", URL: receiver.URL, Event: models.EventAnalysisCompleted, Payload: []byte(`{}`),
+		Status: models.DeliveryPending, Attempts: service.DefaultWebhookMaxAttempts,
+	}, nil).Once()
+	mockRepo.On("RecordWebhookAttempt", mock.Anything, mock.MatchedBy(func(delivery *models.WebhookDelivery) bool {
+		return delivery.Status == models.DeliveryFailed && delivery.ResponseStatus == http.StatusInternalServerError
+	})).Return(nil)
+
+	// Call the method
+	sent, err := webhooks.DeliverNext(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, sent)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_DeliverNext_InternalAddress(t *testing.T) {
+	receiver, requests := newWebhookReceiver(t, http.StatusOK)
+
+	// Create mocks
+	mockRepo := new(repomocks.MockWebhookRepository)
+	webhooks := service.NewWebhookService(mockRepo, "request-secret", time.Second)
+
+	// Set up mock expectations for the last attempt of a delivery to the loopback address
+	mockRepo.On("ClaimWebhookDelivery", mock.Anything, mock.Anything).Return(&models.WebhookDelivery{
+		ID: "delivery1", URL: receiver.URL, Event: models.EventAnalysisCompleted, Payload: []byte(`{}`),
+		Status: models.DeliveryPending, Attempts: service.DefaultWebhookMaxAttempts,
+	}, nil).Once()
+	mockRepo.On("RecordWebhookAttempt", mock.Anything, mock.MatchedBy(func(delivery *models.WebhookDelivery) bool {
+		return delivery.Status == models.DeliveryFailed && strings.Contains(delivery.Error, "internal address")
+	})).Return(nil)
+
+	// Call the method
+	sent, err := webhooks.DeliverNext(context.Background())
+
+	// Assert: the connection is refused before anything is sent
+	assert.NoError(t, err)
+	assert.True(t, sent)
+	assert.Empty(t, requests)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_DeliverNext_Redirect(t *testing.T) {
+	internal, requests := newWebhookReceiver(t, http.StatusOK)
+	redirecting := httptest.NewServer(http.RedirectHandler(internal.URL, http.StatusTemporaryRedirect))
+	t.Cleanup(redirecting.Close)
+
+	// Create mocks
+	mockRepo := new(repomocks.MockWebhookRepository)
+	webhooks := service.NewWebhookService(mockRepo, "request-secret", time.Second)
+	webhooks.AllowInternalAddresses = true // The test receivers listen on the loopback address
+
+	// Set up mock expectations for the last attempt
+	mockRepo.On("ClaimWebhookDelivery", mock.Anything, mock.Anything).Return(&models.WebhookDelivery{
+		ID: "delivery1", URL: redirecting.URL, Event: models.EventAnalysisCompleted, Payload: []byte(`{}`),
+		Status: models.DeliveryPending, Attempts: service.DefaultWebhookMaxAttempts,
+	}, nil).Once()
+	mockRepo.On("RecordWebhookAttempt", mock.Anything, mock.MatchedBy(func(delivery *models.WebhookDelivery) bool {
+		return delivery.Status == models.DeliveryFailed && delivery.ResponseStatus == http.StatusTemporaryRedirect
+	})).Return(nil)
+
+	// Call the method
+	sent, err := webhooks.DeliverNext(context.Background())
+
+	// Assert: the redirect is a failed attempt, it is not followed
+	assert.NoError(t, err)
+	assert.True(t, sent)
+	assert.Empty(t, requests)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_DeliverNext_DeletedWebhook(t *testing.T) {
+	// Create mocks
+	mockRepo := new(repomocks.MockWebhookRepository)
+	webhooks := service.NewWebhookService(mockRepo, "request-secret", time.Second)
+
+	// Set up mock expectations
+	mockRepo.On("ClaimWebhookDelivery", mock.Anything, mock.Anything).Return(&models.WebhookDelivery{
+		ID: "delivery1", WebhookID: "hook123", URL: "http://127.0.0.1:1/hooks", Attempts: 1,
+	}, nil).Once()
+	mockRepo.On("GetWebhook", mock.Anything, "hook123").Return(nil, repository.ErrNotFound)
+	mockRepo.On("RecordWebhookAttempt", mock.Anything, mock.MatchedBy(func(delivery *models.WebhookDelivery) bool {
+		return delivery.Status == models.DeliveryFailed
+	})).Return(nil)
+
+	// Call the method
+	sent, err := webhooks.DeliverNext(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, sent)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_DeliverNext_NothingDue(t *testing.T) {
+	// Create mocks
+	mockRepo := new(repomocks.MockWebhookRepository)
+	webhooks := service.NewWebhookService(mockRepo, "request-secret", time.Second)
+
+	// Set up mock expectations
+	mockRepo.On("ClaimWebhookDelivery", mock.Anything, mock.Anything).Return(nil, errors.New("database error")).Once()
+	mockRepo.On("ClaimWebhookDelivery", mock.Anything, mock.Anything).Return(nil, nil)
+
+	// Errors and an empty queue are both reported as nothing sent
+	sent, err := webhooks.DeliverNext(context.Background())
+	assert.Error(t, err)
+	assert.False(t, sent)
+
+	sent, err = webhooks.DeliverNext(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, sent)
+}
+
+func TestWebhookDispatcher_Run(t *testing.T) {
+	receiver, requests := newWebhookReceiver(t, http.StatusOK)
+
+	// Create mocks
+	mockRepo := new(repomocks.MockWebhookRepository)
+	webhooks := service.NewWebhookService(mockRepo, "request-secret", time.Second)
+	webhooks.AllowInternalAddresses = true // The test receiver listens on the loopback address
+
+	// Set up mock expectations, the created delivery is claimed by the woken dispatcher
+	idle := make(chan struct{}, 1)
+	created := make(chan models.WebhookDelivery, 1)
+	mockRepo.On("ClaimWebhookDelivery", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { idle <- struct{}{} }).
+		Return(nil, nil).Once()
+	mockRepo.On("GetWebhooksForEvent", mock.Anything, mock.Anything).Return([]models.Webhook(nil), nil)
+	mockRepo.On("CreateWebhookDeliveries", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			delivery := args.Get(1).([]models.WebhookDelivery)[0]
+			created <- delivery
+			delivery.Attempts = 1
+			mockRepo.On("ClaimWebhookDelivery", mock.Anything, mock.Anything).Return(&delivery, nil).Once()
+			mockRepo.On("ClaimWebhookDelivery", mock.Anything, mock.Anything).Return(nil, nil)
+		}).
+		Return(nil)
+	mockRepo.On("RecordWebhookAttempt", mock.Anything, mock.Anything).Return(nil)
+
+	// Run the dispatcher
+	dispatcher := service.NewWebhookDispatcher(webhooks, 1)
+	dispatcher.PollInterval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx)
+		close(stopped)
+	}()
+	<-idle
+
+	err := webhooks.Notify(context.Background(), service.AnalysisNotification{
+		FileID:     "file123",
+		Result:     &models.AnalysisResult{FileID: "file123"},
+		WebhookURL: receiver.URL,
+	})
+	require.NoError(t, err)
+	delivery := <-created
+
+	// Assert
+	select {
+	case request := <-requests:
+		assert.Equal(t, delivery.Payload, request.body)
+		assert.Equal(t, service.SignWebhookPayload("request-secret", request.body), request.header.Get(service.WebhookSignatureHeader))
+	case <-time.After(time.Second):
+		t.Fatal("the delivery was not sent")
+	}
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("the dispatcher did not stop")
+	}
+}
@@ -1,17 +1,21 @@
 package local
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
 
 	"local.dev/doc-analyzer/internal/pkg/analyzer/storage"
+	"local.dev/doc-analyzer/internal/pkg/storageroot"
 )
 
-// LocalStorage implements the WordCloudStorage interface using the local filesystem
+// LocalStorage implements the WordCloudStorage interface using the local filesystem,
+// locations are resolved inside the base directory
 type LocalStorage struct {
-	basePath string
+	root *storageroot.Root
 }
 
 // NewLocalStorage creates a new LocalStorage instance
@@ -23,7 +27,12 @@ func NewLocalStorage(basePath string) (storage.WordCloudStorage, error) {
 	if err := os.MkdirAll(basePath, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create storage directory: %w", err)
 	}
-	return &LocalStorage{basePath: basePath}, nil
+
+	root, err := storageroot.Open(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage directory: %w", err)
+	}
+	return &LocalStorage{root: root}, nil
 }
 
 // SaveWordCloud saves a word cloud image to the local filesystem
@@ -32,14 +41,7 @@ func (s *LocalStorage) SaveWordCloud(ctx context.Context, location string, image
 		location = "default.png"
 	}
 
-	fullPath := filepath.Join(s.basePath, location)
-
-	dir := filepath.Dir(fullPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	if err := os.WriteFile(fullPath, image, 0644); err != nil {
+	if err := s.root.WriteFile(location, bytes.NewReader(image)); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -52,15 +54,19 @@ func (s *LocalStorage) GetWordCloud(ctx context.Context, location string) ([]byt
 		location = "default.png"
 	}
 
-	fullPath := filepath.Join(s.basePath, location)
-
-	image, err := os.ReadFile(fullPath)
+	file, err := s.root.Open(location)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("word cloud image not found at location %s", location)
+		if errors.Is(err, storageroot.ErrNotFound) {
+			return nil, fmt.Errorf("word cloud image %w at location %s", storageroot.ErrNotFound, location)
 		}
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
+	defer file.Close()
+
+	image, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
 
 	return image, nil
 }
@@ -2,6 +2,7 @@ package local_test
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -10,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"local.dev/doc-analyzer/internal/pkg/analyzer/storage/local"
+	"local.dev/doc-analyzer/internal/pkg/storageroot"
 )
 
 func TestNewLocalStorage(t *testing.T) {
@@ -213,9 +215,24 @@ func TestGetWordCloud(t *testing.T) {
 
 	// Test retrieving a non-existent word cloud
 	_, err = storage.GetWordCloud(context.Background(), "non_existent.png")
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, storageroot.ErrNotFound)
 	assert.Contains(t, err.Error(), "not found")
 
+	// Test retrieving word clouds outside of the storage directory
+	t.Run("Location outside storage", func(t *testing.T) {
+		dir := t.TempDir()
+		basePath := filepath.Join(dir, "wordclouds")
+		storage, err := local.NewLocalStorage(basePath)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "secret.png"), []byte("secret"), 0644))
+		require.NoError(t, os.Symlink(dir, filepath.Join(basePath, "escape")))
+
+		for _, location := range []string{"../secret.png", "/etc/passwd", "escape/secret.png", "a/../../secret.png"} {
+			_, err := storage.GetWordCloud(context.Background(), location)
+			assert.ErrorIs(t, err, storageroot.ErrInvalidLocation, location)
+		}
+	})
+
 	// Test retrieving with empty location
 	t.Run("Empty location", func(t *testing.T) {
 		// Create a file with default filename
@@ -282,3 +299,37 @@ func TestSaveAndGetWordCloud(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, testImage, retrievedImage)
 }
+
+// FuzzGetWordCloud checks that no location reads an image outside of the storage directory,
+// locations come from the requests of the gateway
+func FuzzGetWordCloud(f *testing.F) {
+	for _, location := range []string{"test.png", "subdir/test.png", "../secret.png", "escape/secret.png",
+		"/etc/passwd", "subdir/../../secret.png", "escape/../secret.png", "..", "%2e%2e/secret.png"} {
+		f.Add(location)
+	}
+
+	dir := f.TempDir()
+	basePath := filepath.Join(dir, "wordclouds")
+	storage, err := local.NewLocalStorage(basePath)
+	require.NoError(f, err)
+	require.NoError(f, os.WriteFile(filepath.Join(dir, "secret.png"), []byte("secret"), 0644))
+	require.NoError(f, os.Symlink(dir, filepath.Join(basePath, "escape")))
+	require.NoError(f, storage.SaveWordCloud(context.Background(), "subdir/test.png", []byte("image")))
+
+	f.Fuzz(func(t *testing.T, location string) {
+		image, err := storage.GetWordCloud(context.Background(), location)
+		if err == nil {
+			if string(image) == "secret" {
+				t.Fatalf("GetWordCloud(%q) read an image outside of the storage directory", location)
+			}
+			return
+		}
+		if errors.Is(err, storageroot.ErrInvalidLocation) || errors.Is(err, storageroot.ErrNotFound) {
+			return
+		}
+		// Directories inside of the storage cannot be read as images
+		if _, cleanErr := storageroot.Clean(location); cleanErr != nil {
+			t.Fatalf("GetWordCloud(%q) returned untyped error %v", location, err)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/storage"
+	"local.dev/doc-analyzer/internal/pkg/s3client"
+	"local.dev/doc-analyzer/internal/pkg/storageroot"
+)
+
+// S3Storage implements the WordCloudStorage interface using an S3-compatible object storage,
+// locations are the keys of objects under the prefix
+type S3Storage struct {
+	client *s3client.Client
+	prefix string
+}
+
+// NewS3Storage creates a new S3Storage instance
+func NewS3Storage(client *s3client.Client, prefix string) storage.WordCloudStorage {
+	return &S3Storage{client: client, prefix: prefix}
+}
+
+// SaveWordCloud saves a word cloud image to the object storage
+func (s *S3Storage) SaveWordCloud(ctx context.Context, location string, image []byte) error {
+	key, err := s.key(location)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := s.client.PutObject(ctx, key, bytes.NewReader(image)); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// GetWordCloud retrieves a word cloud image from the object storage
+func (s *S3Storage) GetWordCloud(ctx context.Context, location string) ([]byte, error) {
+	key, err := s.key(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	file, err := s.client.GetObject(ctx, key)
+	if err != nil {
+		if errors.Is(err, s3client.ErrNotFound) {
+			return nil, fmt.Errorf("word cloud image %w at location %s", storageroot.ErrNotFound, location)
+		}
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer file.Close()
+
+	image, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return image, nil
+}
+
+// key is the key of the object of a location,
+// locations are validated as for the local storage so that both backends accept the same ones
+func (s *S3Storage) key(location string) (string, error) {
+	if location == "" {
+		location = "default.png"
+	}
+	if _, err := storageroot.Clean(location); err != nil {
+		return "", err
+	}
+	return s.prefix + location, nil
+}
@@ -0,0 +1,51 @@
+package s3_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"local.dev/doc-analyzer/internal/pkg/analyzer/storage/s3"
+	"local.dev/doc-analyzer/internal/pkg/s3client"
+	"local.dev/doc-analyzer/internal/pkg/s3client/s3fake"
+	"local.dev/doc-analyzer/internal/pkg/storageroot"
+)
+
+func TestSaveAndGetWordCloud(t *testing.T) {
+	fake := s3fake.NewServer("documents")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := s3client.NewClient(s3client.Config{
+		Endpoint:        server.URL,
+		Bucket:          "documents",
+		AccessKeyID:     "minio",
+		SecretAccessKey: "minio-secret",
+	})
+	require.NoError(t, err)
+	storage := s3.NewS3Storage(client, "wordclouds/")
+	ctx := context.Background()
+
+	image := []byte("\x89PNG\r\n\x1a\nimage")
+	require.NoError(t, storage.SaveWordCloud(ctx, "file123.png", image))
+
+	// The image is stored under the prefix
+	stored, ok := fake.Object("wordclouds/file123.png")
+	require.True(t, ok)
+	assert.Equal(t, image, stored)
+
+	retrieved, err := storage.GetWordCloud(ctx, "file123.png")
+	assert.NoError(t, err)
+	assert.Equal(t, image, retrieved)
+
+	_, err = storage.GetWordCloud(ctx, "missing.png")
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, storageroot.ErrNotFound)
+	assert.Contains(t, err.Error(), "word cloud image not found")
+
+	_, err = storage.GetWordCloud(ctx, "../files/file123")
+	assert.ErrorIs(t, err, storageroot.ErrInvalidLocation)
+}
@@ -0,0 +1,254 @@
+// Package archive extracts the files of uploaded ZIP and gzip-compressed tar archives
+// within limits, so that archive bombs cannot exhaust the memory of the gateway
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Format is the format of an archive
+type Format string
+
+const (
+	// ZipFormat archives are ZIP files
+	ZipFormat Format = "zip"
+
+	// TarGzFormat archives are gzip-compressed tar files
+	TarGzFormat Format = "tar.gz"
+)
+
+// Errors of archives that are not extracted at all
+var (
+	ErrUnsupportedFormat = errors.New("unsupported archive format, only ZIP and tar.gz archives are allowed")
+	ErrTooManyEntries    = errors.New("archive has too many files")
+	ErrTooLarge          = errors.New("archive is too large when extracted")
+)
+
+// ErrEntryTooLarge is the error of files too large to be extracted, the other files are extracted
+var ErrEntryTooLarge = errors.New("file is too large")
+
+// Limits bound the extraction of an archive
+// Sizes are counted on the extracted content, the sizes recorded in the archive are not trusted
+type Limits struct {
+	// MaxEntries is the maximum number of files
+	MaxEntries int
+
+	// MaxEntrySize is the maximum size of a file, larger files are rejected
+	MaxEntrySize int64
+
+	// MaxTotalSize is the maximum size of all files
+	// For tar.gz archives the rejected files count too, since they have to be decompressed to be skipped
+	MaxTotalSize int64
+}
+
+// DefaultLimits are the limits of uploaded archives
+var DefaultLimits = Limits{
+	MaxEntries:   1000,
+	MaxEntrySize: 2 << 20,
+	MaxTotalSize: 100 << 20,
+}
+
+// Entry is a file of an archive
+type Entry struct {
+	// Name is the path of the file in the archive
+	Name string
+
+	Content []byte
+
+	// Err is the reason the file was not extracted, Content is nil then
+	Err error
+}
+
+// DetectFormat detects the format of an archive from its first bytes
+func DetectFormat(header []byte) (Format, error) {
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return ZipFormat, nil
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return TarGzFormat, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// Extract extracts the regular files of a ZIP or tar.gz archive of the given size
+// Directories and macOS metadata files are skipped
+func Extract(r io.ReaderAt, size int64, limits Limits) ([]Entry, error) {
+	header := make([]byte, 4)
+	n, err := r.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	format, err := DetectFormat(header[:n])
+	if err != nil {
+		return nil, err
+	}
+
+	if format == ZipFormat {
+		return extractZip(r, size, limits)
+	}
+	return extractTarGz(io.NewSectionReader(r, 0, size), limits)
+}
+
+// extractZip extracts the files of a ZIP archive
+func extractZip(r io.ReaderAt, size int64, limits Limits) ([]Entry, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ZIP archive: %w", err)
+	}
+
+	extractor := &extractor{limits: limits}
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || isMetadata(file.Name) {
+			continue
+		}
+		if !file.Mode().IsRegular() {
+			if err := extractor.reject(file.Name, errors.New("not a regular file")); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Reject files declared too large without decompressing them, the declared size is checked again on reading
+		if file.UncompressedSize64 > uint64(limits.MaxEntrySize) {
+			if err := extractor.reject(file.Name, ErrEntryTooLarge); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		content, err := file.Open()
+		if err != nil {
+			if err := extractor.reject(file.Name, fmt.Errorf("failed to open file: %w", err)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		err = extractor.extract(file.Name, content)
+		content.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return extractor.entries, nil
+}
+
+// extractTarGz extracts the files of a gzip-compressed tar archive
+func extractTarGz(r io.Reader, limits Limits) ([]Entry, error) {
+	decompressed, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip stream: %w", err)
+	}
+	defer decompressed.Close()
+
+	// Bound the decompressed stream, skipping a rejected file decompresses it too
+	stream := &limitedReader{r: decompressed, remaining: limits.MaxTotalSize + tarOverhead(limits)}
+	archive := tar.NewReader(stream)
+
+	extractor := &extractor{limits: limits}
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return extractor.entries, nil
+		}
+		if err != nil {
+			if errors.Is(err, ErrTooLarge) {
+				return nil, ErrTooLarge
+			}
+			return nil, fmt.Errorf("invalid tar archive: %w", err)
+		}
+
+		switch {
+		case header.Typeflag == tar.TypeDir || isMetadata(header.Name):
+			continue
+		case header.Typeflag != tar.TypeReg:
+			if err := extractor.reject(header.Name, errors.New("not a regular file")); err != nil {
+				return nil, err
+			}
+		default:
+			if err := extractor.extract(header.Name, archive); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// tarOverhead is the size of the tar headers and padding of the maximum number of files
+func tarOverhead(limits Limits) int64 {
+	return int64(limits.MaxEntries+1) * 2 * 512
+}
+
+// extractor collects the files of an archive within the limits
+type extractor struct {
+	limits    Limits
+	entries   []Entry
+	totalSize int64
+}
+
+// reject records a file that is not extracted
+func (e *extractor) reject(name string, reason error) error {
+	if len(e.entries) >= e.limits.MaxEntries {
+		return ErrTooManyEntries
+	}
+	e.entries = append(e.entries, Entry{Name: name, Err: reason})
+	return nil
+}
+
+// extract reads a file, rejecting it if it is too large
+func (e *extractor) extract(name string, r io.Reader) error {
+	if len(e.entries) >= e.limits.MaxEntries {
+		return ErrTooManyEntries
+	}
+
+	content, err := io.ReadAll(io.LimitReader(r, e.limits.MaxEntrySize+1))
+	if err != nil {
+		if errors.Is(err, ErrTooLarge) {
+			return ErrTooLarge
+		}
+		return e.reject(name, fmt.Errorf("failed to read file: %w", err))
+	}
+	if int64(len(content)) > e.limits.MaxEntrySize {
+		return e.reject(name, ErrEntryTooLarge)
+	}
+
+	e.totalSize += int64(len(content))
+	if e.totalSize > e.limits.MaxTotalSize {
+		return ErrTooLarge
+	}
+
+	e.entries = append(e.entries, Entry{Name: name, Content: content})
+	return nil
+}
+
+// isMetadata reports whether a file holds metadata added to archives by macOS
+func isMetadata(name string) bool {
+	base := path.Base(name)
+	return strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(base, "._") || base == ".DS_Store"
+}
+
+// limitedReader reads at most remaining bytes and fails with ErrTooLarge afterwards
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		return 0, ErrTooLarge
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
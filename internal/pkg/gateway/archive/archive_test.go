@@ -0,0 +1,201 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFile is a file of a test archive
+type testFile struct {
+	name    string
+	content string
+	dir     bool
+	symlink bool
+}
+
+// buildZip creates a ZIP archive of the files
+func buildZip(t *testing.T, files []testFile) []byte {
+	buf := new(bytes.Buffer)
+	writer := zip.NewWriter(buf)
+	for _, file := range files {
+		header := &zip.FileHeader{Name: file.name, Method: zip.Deflate}
+		switch {
+		case file.dir:
+			header.Name += "/"
+		case file.symlink:
+			header.SetMode(0o777 | 1<<27) // fs.ModeSymlink
+		}
+		w, err := writer.CreateHeader(header)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(file.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+// buildTarGz creates a gzip-compressed tar archive of the files
+func buildTarGz(t *testing.T, files []testFile) []byte {
+	buf := new(bytes.Buffer)
+	compressed := gzip.NewWriter(buf)
+	writer := tar.NewWriter(compressed)
+	for _, file := range files {
+		header := &tar.Header{Name: file.name, Mode: 0o644, Size: int64(len(file.content)), Typeflag: tar.TypeReg}
+		switch {
+		case file.dir:
+			header.Typeflag, header.Size = tar.TypeDir, 0
+		case file.symlink:
+			header.Typeflag, header.Size, header.Linkname = tar.TypeSymlink, 0, "/etc/passwd"
+		}
+		require.NoError(t, writer.WriteHeader(header))
+		if header.Size > 0 {
+			_, err := writer.Write([]byte(file.content))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, writer.Close())
+	require.NoError(t, compressed.Close())
+	return buf.Bytes()
+}
+
+func extract(data []byte, limits Limits) ([]Entry, error) {
+	return Extract(bytes.NewReader(data), int64(len(data)), limits)
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		header   []byte
+		expected Format
+		err      error
+	}{
+		{[]byte("PK\x03\x04"), ZipFormat, nil},
+		{[]byte("PK\x05\x06"), ZipFormat, nil},
+		{[]byte{0x1f, 0x8b, 0x08, 0x00}, TarGzFormat, nil},
+		{[]byte("Rar!"), "", ErrUnsupportedFormat},
+		{[]byte("text"), "", ErrUnsupportedFormat},
+		{nil, "", ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		format, err := DetectFormat(tt.header)
+		assert.Equal(t, tt.expected, format, "%q", tt.header)
+		assert.Equal(t, tt.err, err, "%q", tt.header)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	files := []testFile{
+		{name: "essays", dir: true},
+		{name: "essays/first.txt", content: "first essay"},
+		{name: "essays/second.TXT", content: "second essay"},
+		{name: "link.txt", symlink: true},
+		{name: "__MACOSX/essays/._first.txt", content: "metadata"},
+		{name: "essays/.DS_Store", content: "metadata"},
+		{name: "empty.txt"},
+	}
+
+	builders := map[string]func(*testing.T, []testFile) []byte{"zip": buildZip, "tar.gz": buildTarGz}
+	for name, build := range builders {
+		t.Run(name, func(t *testing.T) {
+			entries, err := extract(build(t, files), DefaultLimits)
+
+			require.NoError(t, err)
+			require.Len(t, entries, 4)
+			assert.Equal(t, Entry{Name: "essays/first.txt", Content: []byte("first essay")}, entries[0])
+			assert.Equal(t, Entry{Name: "essays/second.TXT", Content: []byte("second essay")}, entries[1])
+			assert.Equal(t, "link.txt", entries[2].Name)
+			assert.Nil(t, entries[2].Content)
+			assert.EqualError(t, entries[2].Err, "not a regular file")
+			assert.Equal(t, "empty.txt", entries[3].Name)
+			assert.Empty(t, entries[3].Content)
+			assert.NoError(t, entries[3].Err)
+		})
+	}
+}
+
+func TestExtract_Limits(t *testing.T) {
+	limits := Limits{MaxEntries: 3, MaxEntrySize: 10, MaxTotalSize: 25}
+
+	builders := map[string]func(*testing.T, []testFile) []byte{"zip": buildZip, "tar.gz": buildTarGz}
+	for name, build := range builders {
+		t.Run(name, func(t *testing.T) {
+			// Files too large are rejected, the other ones are extracted
+			entries, err := extract(build(t, []testFile{
+				{name: "large.txt", content: strings.Repeat("a", 11)},
+				{name: "small.txt", content: "small"},
+			}), limits)
+			require.NoError(t, err)
+			require.Len(t, entries, 2)
+			assert.ErrorIs(t, entries[0].Err, ErrEntryTooLarge)
+			assert.Equal(t, []byte("small"), entries[1].Content)
+
+			// Too many files
+			_, err = extract(build(t, []testFile{
+				{name: "1.txt", content: "1"}, {name: "2.txt", content: "2"},
+				{name: "3.txt", content: "3"}, {name: "4.txt", content: "4"},
+			}), limits)
+			assert.ErrorIs(t, err, ErrTooManyEntries)
+
+			// Too large in total
+			_, err = extract(build(t, []testFile{
+				{name: "1.txt", content: strings.Repeat("a", 10)},
+				{name: "2.txt", content: strings.Repeat("b", 10)},
+				{name: "3.txt", content: strings.Repeat("c", 10)},
+			}), limits)
+			assert.ErrorIs(t, err, ErrTooLarge)
+		})
+	}
+}
+
+func TestExtract_TarGzBomb(t *testing.T) {
+	// A single file that decompresses far beyond the limits is not decompressed completely
+	limits := Limits{MaxEntries: 10, MaxEntrySize: 1 << 10, MaxTotalSize: 1 << 20}
+	data := buildTarGz(t, []testFile{{name: "bomb.txt", content: strings.Repeat("0", 64<<20)}})
+	assert.Less(t, len(data), 1<<20)
+
+	_, err := extract(data, limits)
+
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestExtract_ZipUnderstatedSize(t *testing.T) {
+	// Sizes recorded in the archive are not trusted
+	data := buildZip(t, []testFile{{name: "bomb.txt", content: strings.Repeat("0", 1<<20)}})
+
+	// Understate the uncompressed size in the central directory
+	offset := bytes.LastIndex(data, []byte("PK\x01\x02"))
+	require.NotEqual(t, -1, offset)
+	tampered := append([]byte(nil), data...)
+	copy(tampered[offset+24:offset+28], []byte{10, 0, 0, 0})
+
+	entries, err := extract(tampered, Limits{MaxEntries: 10, MaxEntrySize: 100, MaxTotalSize: 1000})
+
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Nil(t, entries[0].Content)
+	assert.Error(t, entries[0].Err)
+}
+
+func TestExtract_Invalid(t *testing.T) {
+	tests := map[string][]byte{
+		"unsupported": []byte("plain text"),
+		"empty":       nil,
+		"zip":         []byte("PK\x03\x04 truncated"),
+		"gzip":        {0x1f, 0x8b, 0x08, 0x00, 0x01},
+	}
+
+	for name, data := range tests {
+		_, err := extract(data, DefaultLimits)
+		assert.Error(t, err, name)
+	}
+
+	_, err := extract([]byte("plain text"), DefaultLimits)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
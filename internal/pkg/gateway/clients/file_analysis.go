@@ -3,6 +3,7 @@ package clients
 import (
 	"context"
 	"fmt"
+	"io"
 	"local.dev/doc-analyzer/internal/pkg/grpcConn"
 	"time"
 
@@ -52,7 +53,8 @@ func (c *FileAnalysisClient) Close() error {
 }
 
 // AnalyzeFile sends a request to analyze a file
-func (c *FileAnalysisClient) AnalyzeFile(ctx context.Context, fileID string, generateWordCloud bool) (*pb.AnalyzeFileResponse, error) {
+// Word cloud options are ignored unless a word cloud is generated, nil selects the defaults
+func (c *FileAnalysisClient) AnalyzeFile(ctx context.Context, fileID string, generateWordCloud bool, wordCloudOptions *pb.WordCloudOptions) (*pb.AnalyzeFileResponse, error) {
 	ctx, cancel := context.WithTimeout(ctx, 60*time.Second) // Analysis might take longer
 	defer cancel()
 
@@ -66,6 +68,7 @@ func (c *FileAnalysisClient) AnalyzeFile(ctx context.Context, fileID string, gen
 		resp, err = c.client.AnalyzeFile(ctx, &pb.AnalyzeFileRequest{
 			FileId:            fileID,
 			GenerateWordCloud: generateWordCloud,
+			WordCloudOptions:  wordCloudOptions,
 		})
 
 		if err == nil {
@@ -88,8 +91,8 @@ func (c *FileAnalysisClient) AnalyzeFile(ctx context.Context, fileID string, gen
 	return resp, nil
 }
 
-// GetWordCloud retrieves a word cloud image
-func (c *FileAnalysisClient) GetWordCloud(ctx context.Context, location string) ([]byte, error) {
+// GetWordCloud retrieves a word cloud image together with its format (png or svg)
+func (c *FileAnalysisClient) GetWordCloud(ctx context.Context, location string) ([]byte, string, error) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -110,16 +113,405 @@ func (c *FileAnalysisClient) GetWordCloud(ctx context.Context, location string)
 
 		s, ok := status.FromError(err)
 		if !ok || (s.Code() != codes.Unavailable && s.Code() != codes.DeadlineExceeded) {
-			return nil, fmt.Errorf("failed to get word cloud: %w", err)
+			return nil, "", fmt.Errorf("failed to get word cloud: %w", err)
 		}
 
 		if attempt == maxRetries-1 {
-			return nil, fmt.Errorf("failed to get word cloud after %d attempts: %w", maxRetries, err)
+			return nil, "", fmt.Errorf("failed to get word cloud after %d attempts: %w", maxRetries, err)
 		}
 
 		time.Sleep(retryDelay)
 		retryDelay *= 2
 	}
 
-	return resp.Image, nil
+	return resp.Image, resp.Format, nil
+}
+
+// GetMatchedPassages retrieves the passages of a file copied from a similar file
+func (c *FileAnalysisClient) GetMatchedPassages(ctx context.Context, fileID, similarFileID string) ([]*pb.MatchedPassage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	maxRetries := 3
+	retryDelay := 1 * time.Second
+
+	var resp *pb.GetMatchedPassagesResponse
+	var err error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		resp, err = c.client.GetMatchedPassages(ctx, &pb.GetMatchedPassagesRequest{
+			FileId:        fileID,
+			SimilarFileId: similarFileID,
+		})
+
+		if err == nil {
+			break
+		}
+
+		s, ok := status.FromError(err)
+		if !ok || (s.Code() != codes.Unavailable && s.Code() != codes.DeadlineExceeded) {
+			return nil, fmt.Errorf("failed to get matched passages: %w", err)
+		}
+
+		if attempt == maxRetries-1 {
+			return nil, fmt.Errorf("failed to get matched passages after %d attempts: %w", maxRetries, err)
+		}
+
+		time.Sleep(retryDelay)
+		retryDelay *= 2
+	}
+
+	return resp.Passages, nil
+}
+
+// GetKeywords retrieves the TF-IDF keywords of an analyzed file, at most limit of them
+func (c *FileAnalysisClient) GetKeywords(ctx context.Context, fileID string, limit int32) ([]*pb.Keyword, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	maxRetries := 3
+	retryDelay := 1 * time.Second
+
+	var resp *pb.GetKeywordsResponse
+	var err error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		resp, err = c.client.GetKeywords(ctx, &pb.GetKeywordsRequest{
+			FileId: fileID,
+			Limit:  limit,
+		})
+
+		if err == nil {
+			break
+		}
+
+		s, ok := status.FromError(err)
+		if !ok || (s.Code() != codes.Unavailable && s.Code() != codes.DeadlineExceeded) {
+			return nil, fmt.Errorf("failed to get keywords: %w", err)
+		}
+
+		if attempt == maxRetries-1 {
+			return nil, fmt.Errorf("failed to get keywords after %d attempts: %w", maxRetries, err)
+		}
+
+		time.Sleep(retryDelay)
+		retryDelay *= 2
+	}
+
+	return resp.Keywords, nil
+}
+
+// GetWordFrequencies retrieves the word counts of a file, most frequent first
+func (c *FileAnalysisClient) GetWordFrequencies(ctx context.Context, request *pb.GetWordFrequenciesRequest) ([]*pb.WordItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	maxRetries := 3
+	retryDelay := 1 * time.Second
+
+	var resp *pb.GetWordFrequenciesResponse
+	var err error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		resp, err = c.client.GetWordFrequencies(ctx, request)
+
+		if err == nil {
+			break
+		}
+
+		s, ok := status.FromError(err)
+		if !ok || (s.Code() != codes.Unavailable && s.Code() != codes.DeadlineExceeded) {
+			return nil, fmt.Errorf("failed to get word frequencies: %w", err)
+		}
+
+		if attempt == maxRetries-1 {
+			return nil, fmt.Errorf("failed to get word frequencies after %d attempts: %w", maxRetries, err)
+		}
+
+		time.Sleep(retryDelay)
+		retryDelay *= 2
+	}
+
+	return resp.Words, nil
+}
+
+// SubmitAnalysis queues an analysis of a file and returns the queued job without waiting for it
+// Only unavailable servers are retried, a timed out request may have queued a job already
+// The webhook URL, if not empty, is notified once the job has finished
+func (c *FileAnalysisClient) SubmitAnalysis(ctx context.Context, fileID string, generateWordCloud bool, wordCloudOptions *pb.WordCloudOptions, webhookURL string) (*pb.AnalysisJob, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	maxRetries := 3
+	retryDelay := 1 * time.Second
+
+	var resp *pb.AnalysisJob
+	var err error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		resp, err = c.client.SubmitAnalysis(ctx, &pb.AnalyzeFileRequest{
+			FileId:            fileID,
+			GenerateWordCloud: generateWordCloud,
+			WordCloudOptions:  wordCloudOptions,
+			WebhookUrl:        webhookURL,
+		})
+
+		if err == nil {
+			break
+		}
+
+		s, ok := status.FromError(err)
+		if !ok || s.Code() != codes.Unavailable {
+			return nil, fmt.Errorf("failed to submit analysis: %w", err)
+		}
+
+		if attempt == maxRetries-1 {
+			return nil, fmt.Errorf("failed to submit analysis after %d attempts: %w", maxRetries, err)
+		}
+
+		time.Sleep(retryDelay)
+		retryDelay *= 2
+	}
+
+	return resp, nil
+}
+
+// GetAnalysisJob retrieves the state of an analysis job, with the analysis results once it is done
+func (c *FileAnalysisClient) GetAnalysisJob(ctx context.Context, jobID string) (*pb.AnalysisJob, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	maxRetries := 3
+	retryDelay := 1 * time.Second
+
+	var resp *pb.AnalysisJob
+	var err error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		resp, err = c.client.GetAnalysisJob(ctx, &pb.GetAnalysisJobRequest{
+			JobId: jobID,
+		})
+
+		if err == nil {
+			break
+		}
+
+		s, ok := status.FromError(err)
+		if !ok || (s.Code() != codes.Unavailable && s.Code() != codes.DeadlineExceeded) {
+			return nil, fmt.Errorf("failed to get analysis job: %w", err)
+		}
+
+		if attempt == maxRetries-1 {
+			return nil, fmt.Errorf("failed to get analysis job after %d attempts: %w", maxRetries, err)
+		}
+
+		time.Sleep(retryDelay)
+		retryDelay *= 2
+	}
+
+	return resp, nil
+}
+
+// WatchAnalysis passes the stages of the analysis of a file to handle until the analysis finishes,
+// ctx is done or handle returns an error
+func (c *FileAnalysisClient) WatchAnalysis(ctx context.Context, fileID string, handle func(*pb.AnalysisEvent) error) error {
+	maxRetries := 3
+	retryDelay := 1 * time.Second
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		received, err := c.watchAnalysis(ctx, fileID, handle)
+		if err == nil {
+			return nil
+		}
+
+		// The stream is only retried before the first event, so that no event is handled twice
+		s, ok := status.FromError(err)
+		if received || !ok || s.Code() != codes.Unavailable {
+			return fmt.Errorf("failed to watch analysis: %w", err)
+		}
+
+		if attempt == maxRetries-1 {
+			return fmt.Errorf("failed to watch analysis after %d attempts: %w", maxRetries, err)
+		}
+
+		time.Sleep(retryDelay)
+		retryDelay *= 2
+	}
+
+	return nil
+}
+
+// watchAnalysis receives the events of a single analysis stream and reports whether any was received
+func (c *FileAnalysisClient) watchAnalysis(ctx context.Context, fileID string, handle func(*pb.AnalysisEvent) error) (bool, error) {
+	stream, err := c.client.WatchAnalysis(ctx, &pb.WatchAnalysisRequest{
+		FileId: fileID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	received := false
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return received, nil
+		}
+		if err != nil {
+			return received, err
+		}
+
+		received = true
+		if err := handle(event); err != nil {
+			return received, err
+		}
+	}
+}
+
+// CreateWebhook registers a webhook notified of the events, of all events if none are given
+// The returned webhook holds its secret, which is not returned again
+func (c *FileAnalysisClient) CreateWebhook(ctx context.Context, url, secret string, events []string) (*pb.Webhook, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	maxRetries := 3
+	retryDelay := 1 * time.Second
+
+	var resp *pb.Webhook
+	var err error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		resp, err = c.client.CreateWebhook(ctx, &pb.CreateWebhookRequest{
+			Url:    url,
+			Secret: secret,
+			Events: events,
+		})
+
+		if err == nil {
+			break
+		}
+
+		// A timed out registration may have been saved, so only unavailable servers are retried
+		s, ok := status.FromError(err)
+		if !ok || s.Code() != codes.Unavailable {
+			return nil, fmt.Errorf("failed to create webhook: %w", err)
+		}
+
+		if attempt == maxRetries-1 {
+			return nil, fmt.Errorf("failed to create webhook after %d attempts: %w", maxRetries, err)
+		}
+
+		time.Sleep(retryDelay)
+		retryDelay *= 2
+	}
+
+	return resp, nil
+}
+
+// ListWebhooks retrieves the registered webhooks without their secrets
+func (c *FileAnalysisClient) ListWebhooks(ctx context.Context) (*pb.ListWebhooksResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	maxRetries := 3
+	retryDelay := 1 * time.Second
+
+	var resp *pb.ListWebhooksResponse
+	var err error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		resp, err = c.client.ListWebhooks(ctx, &pb.ListWebhooksRequest{})
+
+		if err == nil {
+			break
+		}
+
+		s, ok := status.FromError(err)
+		if !ok || (s.Code() != codes.Unavailable && s.Code() != codes.DeadlineExceeded) {
+			return nil, fmt.Errorf("failed to list webhooks: %w", err)
+		}
+
+		if attempt == maxRetries-1 {
+			return nil, fmt.Errorf("failed to list webhooks after %d attempts: %w", maxRetries, err)
+		}
+
+		time.Sleep(retryDelay)
+		retryDelay *= 2
+	}
+
+	return resp, nil
+}
+
+// DeleteWebhook deletes a registered webhook
+func (c *FileAnalysisClient) DeleteWebhook(ctx context.Context, webhookID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	maxRetries := 3
+	retryDelay := 1 * time.Second
+
+	var err error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		_, err = c.client.DeleteWebhook(ctx, &pb.DeleteWebhookRequest{
+			WebhookId: webhookID,
+		})
+
+		if err == nil {
+			break
+		}
+
+		// A timed out deletion may have succeeded, a retry would report the webhook as not found
+		s, ok := status.FromError(err)
+		if !ok || s.Code() != codes.Unavailable {
+			return fmt.Errorf("failed to delete webhook: %w", err)
+		}
+
+		if attempt == maxRetries-1 {
+			return fmt.Errorf("failed to delete webhook after %d attempts: %w", maxRetries, err)
+		}
+
+		time.Sleep(retryDelay)
+		retryDelay *= 2
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries retrieves the webhook delivery log, newest first,
+// optionally only the deliveries of a webhook or of a file
+func (c *FileAnalysisClient) ListWebhookDeliveries(ctx context.Context, webhookID, fileID string, limit int32) (*pb.ListWebhookDeliveriesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	maxRetries := 3
+	retryDelay := 1 * time.Second
+
+	var resp *pb.ListWebhookDeliveriesResponse
+	var err error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		resp, err = c.client.ListWebhookDeliveries(ctx, &pb.ListWebhookDeliveriesRequest{
+			WebhookId: webhookID,
+			FileId:    fileID,
+			Limit:     limit,
+		})
+
+		if err == nil {
+			break
+		}
+
+		s, ok := status.FromError(err)
+		if !ok || (s.Code() != codes.Unavailable && s.Code() != codes.DeadlineExceeded) {
+			return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+		}
+
+		if attempt == maxRetries-1 {
+			return nil, fmt.Errorf("failed to list webhook deliveries after %d attempts: %w", maxRetries, err)
+		}
+
+		time.Sleep(retryDelay)
+		retryDelay *= 2
+	}
+
+	return resp, nil
 }
@@ -3,6 +3,7 @@ package clients
 import (
 	"context"
 	"errors"
+	"io"
 	"local.dev/doc-analyzer/internal/pkg/grpcConn"
 	"net"
 	"testing"
@@ -10,6 +11,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	pb "local.dev/doc-analyzer/internal/proto/analyzer"
 )
@@ -35,6 +38,105 @@ func (m *MockFileAnalysisServiceClient) GetWordCloud(ctx context.Context, in *pb
 	return args.Get(0).(*pb.GetWordCloudResponse), args.Error(1)
 }
 
+func (m *MockFileAnalysisServiceClient) GetMatchedPassages(ctx context.Context, in *pb.GetMatchedPassagesRequest, opts ...grpc.CallOption) (*pb.GetMatchedPassagesResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.GetMatchedPassagesResponse), args.Error(1)
+}
+
+func (m *MockFileAnalysisServiceClient) GetKeywords(ctx context.Context, in *pb.GetKeywordsRequest, opts ...grpc.CallOption) (*pb.GetKeywordsResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.GetKeywordsResponse), args.Error(1)
+}
+
+func (m *MockFileAnalysisServiceClient) GetWordFrequencies(ctx context.Context, in *pb.GetWordFrequenciesRequest, opts ...grpc.CallOption) (*pb.GetWordFrequenciesResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.GetWordFrequenciesResponse), args.Error(1)
+}
+
+func (m *MockFileAnalysisServiceClient) SubmitAnalysis(ctx context.Context, in *pb.AnalyzeFileRequest, opts ...grpc.CallOption) (*pb.AnalysisJob, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.AnalysisJob), args.Error(1)
+}
+
+func (m *MockFileAnalysisServiceClient) GetAnalysisJob(ctx context.Context, in *pb.GetAnalysisJobRequest, opts ...grpc.CallOption) (*pb.AnalysisJob, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.AnalysisJob), args.Error(1)
+}
+
+func (m *MockFileAnalysisServiceClient) WatchAnalysis(ctx context.Context, in *pb.WatchAnalysisRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[pb.AnalysisEvent], error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(grpc.ServerStreamingClient[pb.AnalysisEvent]), args.Error(1)
+}
+
+// fakeAnalysisEventStream returns its events and then its error, io.EOF if it has none
+type fakeAnalysisEventStream struct {
+	grpc.ClientStream
+	events []*pb.AnalysisEvent
+	err    error
+}
+
+func (s *fakeAnalysisEventStream) Recv() (*pb.AnalysisEvent, error) {
+	if len(s.events) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	event := s.events[0]
+	s.events = s.events[1:]
+	return event, nil
+}
+
+func (m *MockFileAnalysisServiceClient) CreateWebhook(ctx context.Context, in *pb.CreateWebhookRequest, opts ...grpc.CallOption) (*pb.Webhook, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.Webhook), args.Error(1)
+}
+
+func (m *MockFileAnalysisServiceClient) ListWebhooks(ctx context.Context, in *pb.ListWebhooksRequest, opts ...grpc.CallOption) (*pb.ListWebhooksResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.ListWebhooksResponse), args.Error(1)
+}
+
+func (m *MockFileAnalysisServiceClient) DeleteWebhook(ctx context.Context, in *pb.DeleteWebhookRequest, opts ...grpc.CallOption) (*pb.DeleteWebhookResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.DeleteWebhookResponse), args.Error(1)
+}
+
+func (m *MockFileAnalysisServiceClient) ListWebhookDeliveries(ctx context.Context, in *pb.ListWebhookDeliveriesRequest, opts ...grpc.CallOption) (*pb.ListWebhookDeliveriesResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.ListWebhookDeliveriesResponse), args.Error(1)
+}
+
 // Test wrapper for FileAnalysisClient
 type testFileAnalysisClient struct {
 	*FileAnalysisClient
@@ -63,17 +165,19 @@ func TestAnalyzeFile(t *testing.T) {
 		mockClient.On("AnalyzeFile", mock.Anything, &pb.AnalyzeFileRequest{
 			FileId:            "file123",
 			GenerateWordCloud: true,
+			WordCloudOptions:  &pb.WordCloudOptions{Format: "svg"},
 		}).Return(&pb.AnalyzeFileResponse{
 			ParagraphCount:    5,
 			WordCount:         100,
 			CharacterCount:    500,
 			IsPlagiarism:      false,
 			SimilarFileIds:    []string{},
-			WordCloudLocation: "wordclouds/file123.png",
+			WordCloudLocation: "wordclouds/file123.svg",
+			WordCloudFormat:   "svg",
 		}, nil)
 
 		// Call the method
-		resp, err := client.AnalyzeFile(context.Background(), "file123", true)
+		resp, err := client.AnalyzeFile(context.Background(), "file123", true, &pb.WordCloudOptions{Format: "svg"})
 
 		// Assert
 		assert.NoError(t, err)
@@ -82,7 +186,8 @@ func TestAnalyzeFile(t *testing.T) {
 		assert.Equal(t, int32(500), resp.CharacterCount)
 		assert.False(t, resp.IsPlagiarism)
 		assert.Empty(t, resp.SimilarFileIds)
-		assert.Equal(t, "wordclouds/file123.png", resp.WordCloudLocation)
+		assert.Equal(t, "wordclouds/file123.svg", resp.WordCloudLocation)
+		assert.Equal(t, "svg", resp.WordCloudFormat)
 
 		mockClient.AssertExpectations(t)
 	})
@@ -100,7 +205,7 @@ func TestAnalyzeFile(t *testing.T) {
 		}).Return(nil, errors.New("analysis error"))
 
 		// Call the method
-		_, err := client.AnalyzeFile(context.Background(), "file123", true)
+		_, err := client.AnalyzeFile(context.Background(), "file123", true, nil)
 
 		// Assert
 		assert.Error(t, err)
@@ -118,6 +223,382 @@ func TestAnalyzeFile(t *testing.T) {
 	*/
 }
 
+func TestGetMatchedPassages(t *testing.T) {
+	// Test case: successful get
+	t.Run("Successful get", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		passages := []*pb.MatchedPassage{
+			{Start: 10, End: 50, SimilarStart: 0, SimilarEnd: 40, Text: "copied passage"},
+		}
+		mockClient.On("GetMatchedPassages", mock.Anything, &pb.GetMatchedPassagesRequest{
+			FileId:        "file123",
+			SimilarFileId: "file456",
+		}).Return(&pb.GetMatchedPassagesResponse{Passages: passages}, nil)
+
+		// Call the method
+		result, err := client.GetMatchedPassages(context.Background(), "file123", "file456")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, passages, result)
+		mockClient.AssertExpectations(t)
+	})
+
+	// Test case: error from service
+	t.Run("Error from service", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		mockClient.On("GetMatchedPassages", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+
+		// Call the method
+		_, err := client.GetMatchedPassages(context.Background(), "file123", "file456")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to get matched passages")
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestGetKeywords(t *testing.T) {
+	// Test case: successful get
+	t.Run("Successful get", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		keywords := []*pb.Keyword{
+			{Word: "плагиата", Term: "плагиат", Count: 2, DocumentFrequency: 1, Score: 0.77},
+		}
+		mockClient.On("GetKeywords", mock.Anything, &pb.GetKeywordsRequest{
+			FileId: "file123",
+			Limit:  10,
+		}).Return(&pb.GetKeywordsResponse{Keywords: keywords}, nil)
+
+		// Call the method
+		result, err := client.GetKeywords(context.Background(), "file123", 10)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, keywords, result)
+		mockClient.AssertExpectations(t)
+	})
+
+	// Test case: error from service
+	t.Run("Error from service", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		mockClient.On("GetKeywords", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+
+		// Call the method
+		_, err := client.GetKeywords(context.Background(), "file123", 10)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to get keywords")
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestGetWordFrequencies(t *testing.T) {
+	// Test case: successful get
+	t.Run("Successful get", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		request := &pb.GetWordFrequenciesRequest{FileId: "file123", Limit: 50, KeepStopWords: true}
+		words := []*pb.WordItem{
+			{Text: "плагиат", Value: 5},
+			{Text: "текст", Value: 3},
+		}
+		mockClient.On("GetWordFrequencies", mock.Anything, request).Return(&pb.GetWordFrequenciesResponse{Words: words}, nil)
+
+		// Call the method
+		result, err := client.GetWordFrequencies(context.Background(), request)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, words, result)
+		mockClient.AssertExpectations(t)
+	})
+
+	// Test case: error from service
+	t.Run("Error from service", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		mockClient.On("GetWordFrequencies", mock.Anything, mock.Anything).Return(nil, errors.New("file not found"))
+
+		// Call the method
+		_, err := client.GetWordFrequencies(context.Background(), &pb.GetWordFrequenciesRequest{FileId: "file123"})
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to get word frequencies")
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestSubmitAnalysis(t *testing.T) {
+	// Test case: successful submit
+	t.Run("Successful submit", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		job := &pb.AnalysisJob{Id: "job123", FileId: "file123", Status: "queued"}
+		mockClient.On("SubmitAnalysis", mock.Anything, &pb.AnalyzeFileRequest{
+			FileId:            "file123",
+			GenerateWordCloud: true,
+			WebhookUrl:        "https://lms.example.com/hooks",
+		}).Return(job, nil)
+
+		// Call the method
+		result, err := client.SubmitAnalysis(context.Background(), "file123", true, nil, "https://lms.example.com/hooks")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, job, result)
+		mockClient.AssertExpectations(t)
+	})
+
+	// Test case: timed out submit is not retried
+	t.Run("Timed out submit", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		mockClient.On("SubmitAnalysis", mock.Anything, mock.Anything).
+			Return(nil, status.Error(codes.DeadlineExceeded, "deadline exceeded")).Once()
+
+		// Call the method
+		_, err := client.SubmitAnalysis(context.Background(), "file123", false, nil, "")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to submit analysis")
+		mockClient.AssertNumberOfCalls(t, "SubmitAnalysis", 1)
+	})
+}
+
+func TestGetAnalysisJob(t *testing.T) {
+	// Test case: successful get
+	t.Run("Successful get", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		job := &pb.AnalysisJob{
+			Id: "job123", FileId: "file123", Status: "done",
+			Result: &pb.AnalyzeFileResponse{WordCount: 100},
+		}
+		mockClient.On("GetAnalysisJob", mock.Anything, &pb.GetAnalysisJobRequest{JobId: "job123"}).Return(job, nil)
+
+		// Call the method
+		result, err := client.GetAnalysisJob(context.Background(), "job123")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, job, result)
+		mockClient.AssertExpectations(t)
+	})
+
+	// Test case: job not found
+	t.Run("Job not found", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		mockClient.On("GetAnalysisJob", mock.Anything, mock.Anything).
+			Return(nil, status.Error(codes.NotFound, "analysis job job123 not found"))
+
+		// Call the method
+		_, err := client.GetAnalysisJob(context.Background(), "job123")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestWatchAnalysis(t *testing.T) {
+	// Test case: events are handled until the stream ends
+	t.Run("Successful watch", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		events := []*pb.AnalysisEvent{
+			{FileId: "file123", Stage: "fetching_file"},
+			{FileId: "file123", Stage: "done"},
+		}
+		mockClient.On("WatchAnalysis", mock.Anything, &pb.WatchAnalysisRequest{FileId: "file123"}).
+			Return(&fakeAnalysisEventStream{events: events}, nil)
+
+		// Call the method
+		var handled []*pb.AnalysisEvent
+		err := client.WatchAnalysis(context.Background(), "file123", func(event *pb.AnalysisEvent) error {
+			handled = append(handled, event)
+			return nil
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, events, handled)
+		mockClient.AssertExpectations(t)
+	})
+
+	// Test case: a broken stream is not retried after an event was handled
+	t.Run("Broken stream", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		mockClient.On("WatchAnalysis", mock.Anything, mock.Anything).Return(&fakeAnalysisEventStream{
+			events: []*pb.AnalysisEvent{{FileId: "file123", Stage: "queued"}},
+			err:    status.Error(codes.Unavailable, "connection lost"),
+		}, nil)
+
+		// Call the method
+		err := client.WatchAnalysis(context.Background(), "file123", func(event *pb.AnalysisEvent) error {
+			return nil
+		})
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to watch analysis")
+		mockClient.AssertNumberOfCalls(t, "WatchAnalysis", 1)
+	})
+
+	// Test case: handler errors stop the watch
+	t.Run("Handler error", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		mockClient.On("WatchAnalysis", mock.Anything, mock.Anything).Return(&fakeAnalysisEventStream{
+			events: []*pb.AnalysisEvent{{Stage: "queued"}, {Stage: "done"}},
+		}, nil)
+
+		// Call the method
+		calls := 0
+		err := client.WatchAnalysis(context.Background(), "file123", func(event *pb.AnalysisEvent) error {
+			calls++
+			return errors.New("client gone")
+		})
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestCreateWebhook(t *testing.T) {
+	// Test case: successful create
+	t.Run("Successful create", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		webhook := &pb.Webhook{Id: "hook123", Url: "https://lms.example.com/hooks", Secret: "secret"}
+		mockClient.On("CreateWebhook", mock.Anything, &pb.CreateWebhookRequest{
+			Url:    "https://lms.example.com/hooks",
+			Events: []string{"plagiarism.detected"},
+		}).Return(webhook, nil)
+
+		// Call the method
+		result, err := client.CreateWebhook(context.Background(), "https://lms.example.com/hooks", "", []string{"plagiarism.detected"})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, webhook, result)
+		mockClient.AssertExpectations(t)
+	})
+
+	// Test case: invalid webhook is not retried
+	t.Run("Invalid webhook", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		mockClient.On("CreateWebhook", mock.Anything, mock.Anything).
+			Return(nil, status.Error(codes.InvalidArgument, "unknown webhook event")).Once()
+
+		// Call the method
+		_, err := client.CreateWebhook(context.Background(), "https://lms.example.com/hooks", "", []string{"unknown"})
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		mockClient.AssertNumberOfCalls(t, "CreateWebhook", 1)
+	})
+}
+
+func TestListWebhooks(t *testing.T) {
+	mockClient := new(MockFileAnalysisServiceClient)
+	client := newTestFileAnalysisClient(mockClient)
+
+	response := &pb.ListWebhooksResponse{Webhooks: []*pb.Webhook{{Id: "hook123"}}}
+	mockClient.On("ListWebhooks", mock.Anything, mock.Anything).Return(response, nil)
+
+	// Call the method
+	result, err := client.ListWebhooks(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, response, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	// Test case: successful delete
+	t.Run("Successful delete", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		mockClient.On("DeleteWebhook", mock.Anything, &pb.DeleteWebhookRequest{WebhookId: "hook123"}).
+			Return(&pb.DeleteWebhookResponse{}, nil)
+
+		// Call the method
+		err := client.DeleteWebhook(context.Background(), "hook123")
+
+		// Assert
+		assert.NoError(t, err)
+		mockClient.AssertExpectations(t)
+	})
+
+	// Test case: webhook not found
+	t.Run("Webhook not found", func(t *testing.T) {
+		mockClient := new(MockFileAnalysisServiceClient)
+		client := newTestFileAnalysisClient(mockClient)
+
+		mockClient.On("DeleteWebhook", mock.Anything, mock.Anything).
+			Return(nil, status.Error(codes.NotFound, "webhook hook123 not found"))
+
+		// Call the method
+		err := client.DeleteWebhook(context.Background(), "hook123")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		mockClient.AssertNumberOfCalls(t, "DeleteWebhook", 1)
+	})
+}
+
+func TestListWebhookDeliveries(t *testing.T) {
+	mockClient := new(MockFileAnalysisServiceClient)
+	client := newTestFileAnalysisClient(mockClient)
+
+	response := &pb.ListWebhookDeliveriesResponse{Deliveries: []*pb.WebhookDelivery{{Id: "delivery1", Status: "delivered"}}}
+	mockClient.On("ListWebhookDeliveries", mock.Anything, &pb.ListWebhookDeliveriesRequest{
+		FileId: "file123",
+		Limit:  10,
+	}).Return(response, nil)
+
+	// Call the method
+	result, err := client.ListWebhookDeliveries(context.Background(), "", "file123", 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, response, result)
+	mockClient.AssertExpectations(t)
+}
+
 func TestNewFileAnalysisClient(t *testing.T) {
 	// Test case: invalid address
 	t.Run("Invalid address", func(t *testing.T) {
@@ -218,15 +699,17 @@ func TestGetWordCloud(t *testing.T) {
 		mockClient.On("GetWordCloud", mock.Anything, &pb.GetWordCloudRequest{
 			Location: "wordclouds/file123.png",
 		}).Return(&pb.GetWordCloudResponse{
-			Image: []byte("fake-image-data"),
+			Image:  []byte("fake-image-data"),
+			Format: "png",
 		}, nil)
 
 		// Call the method
-		image, err := client.GetWordCloud(context.Background(), "wordclouds/file123.png")
+		image, format, err := client.GetWordCloud(context.Background(), "wordclouds/file123.png")
 
 		// Assert
 		assert.NoError(t, err)
 		assert.Equal(t, []byte("fake-image-data"), image)
+		assert.Equal(t, "png", format)
 
 		mockClient.AssertExpectations(t)
 	})
@@ -243,7 +726,7 @@ func TestGetWordCloud(t *testing.T) {
 		}).Return(nil, errors.New("get error"))
 
 		// Call the method
-		_, err := client.GetWordCloud(context.Background(), "wordclouds/file123.png")
+		_, _, err := client.GetWordCloud(context.Background(), "wordclouds/file123.png")
 
 		// Assert
 		assert.Error(t, err)
@@ -3,6 +3,7 @@ package clients
 import (
 	"context"
 	"fmt"
+	"io"
 	"local.dev/doc-analyzer/internal/pkg/grpcConn"
 	"time"
 
@@ -14,6 +15,13 @@ import (
 	pb "local.dev/doc-analyzer/internal/proto/storage"
 )
 
+// fileChunkSize is the size of the file chunks sent by UploadFileStream,
+// well below the default gRPC message size limit of 4 MB
+const fileChunkSize = 64 << 10
+
+// streamTimeout bounds the streamed uploads and downloads, which take as long as the files are large
+const streamTimeout = 10 * time.Minute
+
 // FileStoringClient provides methods for interacting with the File Storing Service
 type FileStoringClient struct {
 	client pb.FileStoringServiceClient
@@ -88,8 +96,18 @@ func (c *FileStoringClient) UploadFile(ctx context.Context, fileName string, con
 	return resp.FileId, nil
 }
 
-// GetFile retrieves a file from the File Storing Service
+// GetFile retrieves a file as uploaded from the File Storing Service
 func (c *FileStoringClient) GetFile(ctx context.Context, fileID string) (string, []byte, error) {
+	return c.getFile(ctx, fileID, true)
+}
+
+// GetFileText retrieves the plain text extracted from a file from the File Storing Service
+func (c *FileStoringClient) GetFileText(ctx context.Context, fileID string) (string, []byte, error) {
+	return c.getFile(ctx, fileID, false)
+}
+
+// getFile retrieves a file as uploaded or its plain text from the File Storing Service
+func (c *FileStoringClient) getFile(ctx context.Context, fileID string, original bool) (string, []byte, error) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -101,7 +119,8 @@ func (c *FileStoringClient) GetFile(ctx context.Context, fileID string) (string,
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		resp, err = c.client.GetFile(ctx, &pb.GetFileRequest{
-			FileId: fileID,
+			FileId:   fileID,
+			Original: original,
 		})
 
 		if err == nil {
@@ -123,3 +142,122 @@ func (c *FileStoringClient) GetFile(ctx context.Context, fileID string) (string,
 
 	return resp.FileName, resp.Content, nil
 }
+
+// UploadFileStream uploads a file read until EOF to the File Storing Service in chunks,
+// for files larger than a gRPC message
+// The content is read once, so unlike UploadFile the upload is not retried
+func (c *FileStoringClient) UploadFileStream(ctx context.Context, fileName string, content io.Reader) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, streamTimeout)
+	defer cancel()
+
+	stream, err := c.client.UploadFileStream(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	// The first chunk carries the file name, even for an empty file
+	buf := make([]byte, fileChunkSize)
+	chunk := &pb.UploadFileChunk{FileName: fileName}
+	for {
+		n, readErr := io.ReadFull(content, buf)
+		if n > 0 || chunk.FileName != "" {
+			chunk.Content = buf[:n]
+			if err := stream.Send(chunk); err != nil {
+				// The service ended the stream, CloseAndRecv returns its error
+				if err == io.EOF {
+					break
+				}
+				return "", fmt.Errorf("failed to upload file: %w", err)
+			}
+			chunk = &pb.UploadFileChunk{}
+		}
+
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return "", fmt.Errorf("failed to read file: %w", readErr)
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return "", fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	return resp.FileId, nil
+}
+
+// GetFileStream retrieves a file as uploaded, or its plain text, from the File Storing Service in chunks
+// It returns the name of the file and its content, which the caller closes
+func (c *FileStoringClient) GetFileStream(ctx context.Context, fileID string, original bool) (string, io.ReadCloser, error) {
+	ctx, cancel := context.WithTimeout(ctx, streamTimeout)
+
+	maxRetries := 3
+	retryDelay := 1 * time.Second
+
+	var stream pb.FileStoringService_GetFileStreamClient
+	var first *pb.FileChunk
+	var err error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		// The service fails the stream before the first chunk, which carries the file name
+		stream, err = c.client.GetFileStream(ctx, &pb.GetFileRequest{
+			FileId:   fileID,
+			Original: original,
+		})
+		if err == nil {
+			first, err = stream.Recv()
+		}
+
+		if err == nil {
+			break
+		}
+
+		s, ok := status.FromError(err)
+		if !ok || (s.Code() != codes.Unavailable && s.Code() != codes.DeadlineExceeded) {
+			cancel()
+			return "", nil, fmt.Errorf("failed to get file: %w", err)
+		}
+
+		if attempt == maxRetries-1 {
+			cancel()
+			return "", nil, fmt.Errorf("failed to get file after %d attempts: %w", maxRetries, err)
+		}
+
+		time.Sleep(retryDelay)
+		retryDelay *= 2
+	}
+
+	return first.FileName, &fileChunkReader{stream: stream, chunk: first.Content, cancel: cancel}, nil
+}
+
+// fileChunkReader reads the content of the chunks received from a download stream,
+// closing it cancels the stream
+type fileChunkReader struct {
+	stream pb.FileStoringService_GetFileStreamClient
+	chunk  []byte
+	cancel context.CancelFunc
+}
+
+func (r *fileChunkReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		msg, err := r.stream.Recv()
+		if err == io.EOF {
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get file: %w", err)
+		}
+		r.chunk = msg.Content
+	}
+
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+func (r *fileChunkReader) Close() error {
+	r.cancel()
+	return nil
+}
@@ -5,9 +5,12 @@ import (
 	"errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"io"
 	"local.dev/doc-analyzer/internal/pkg/grpcConn"
 	"net"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -37,6 +40,61 @@ func (m *MockFileStoringServiceClient) GetFile(ctx context.Context, in *pb.GetFi
 	return args.Get(0).(*pb.GetFileResponse), args.Error(1)
 }
 
+func (m *MockFileStoringServiceClient) UploadFileStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[pb.UploadFileChunk, pb.UploadFileResponse], error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(grpc.ClientStreamingClient[pb.UploadFileChunk, pb.UploadFileResponse]), args.Error(1)
+}
+
+func (m *MockFileStoringServiceClient) GetFileStream(ctx context.Context, in *pb.GetFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[pb.FileChunk], error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(grpc.ServerStreamingClient[pb.FileChunk]), args.Error(1)
+}
+
+// fakeUploadStream records the chunks sent and answers with its response or its error
+type fakeUploadStream struct {
+	grpc.ClientStream
+	chunks []*pb.UploadFileChunk
+	resp   *pb.UploadFileResponse
+	err    error
+}
+
+func (s *fakeUploadStream) Send(chunk *pb.UploadFileChunk) error {
+	s.chunks = append(s.chunks, &pb.UploadFileChunk{
+		FileName: chunk.FileName,
+		Content:  append([]byte(nil), chunk.Content...),
+	})
+	return nil
+}
+
+func (s *fakeUploadStream) CloseAndRecv() (*pb.UploadFileResponse, error) {
+	return s.resp, s.err
+}
+
+// fakeFileChunkStream returns its chunks and then its error, io.EOF if it has none
+type fakeFileChunkStream struct {
+	grpc.ClientStream
+	chunks []*pb.FileChunk
+	err    error
+}
+
+func (s *fakeFileChunkStream) Recv() (*pb.FileChunk, error) {
+	if len(s.chunks) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
+
 // Test wrapper for FileStoringClient
 type testFileStoringClient struct {
 	*FileStoringClient
@@ -228,7 +286,8 @@ func TestGetFile(t *testing.T) {
 	t.Run("Successful get", func(t *testing.T) {
 		// Set up mock expectations
 		mockClient.On("GetFile", mock.Anything, &pb.GetFileRequest{
-			FileId: "file123",
+			FileId:   "file123",
+			Original: true,
 		}).Return(&pb.GetFileResponse{
 			FileName: "test.txt",
 			Content:  []byte("test content"),
@@ -253,7 +312,8 @@ func TestGetFile(t *testing.T) {
 
 		// Set up mock expectations
 		mockClient.On("GetFile", mock.Anything, &pb.GetFileRequest{
-			FileId: "file123",
+			FileId:   "file123",
+			Original: true,
 		}).Return(nil, errors.New("get error"))
 
 		// Call the method
@@ -274,3 +334,157 @@ func TestGetFile(t *testing.T) {
 		})
 	*/
 }
+
+func TestGetFileText(t *testing.T) {
+	// Create mock
+	mockClient := new(MockFileStoringServiceClient)
+
+	// Create test client
+	client := newTestFileStoringClient(mockClient)
+
+	// Set up mock expectations: the text is requested instead of the original
+	mockClient.On("GetFile", mock.Anything, &pb.GetFileRequest{
+		FileId: "file123",
+	}).Return(&pb.GetFileResponse{
+		FileName: "essay.docx",
+		Content:  []byte("essay text"),
+	}, nil)
+
+	// Call the method
+	fileName, content, err := client.GetFileText(context.Background(), "file123")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "essay.docx", fileName)
+	assert.Equal(t, []byte("essay text"), content)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestUploadFileStream(t *testing.T) {
+	// Test case: the file is sent in chunks, the first one carries the file name
+	t.Run("Successful upload", func(t *testing.T) {
+		mockClient := new(MockFileStoringServiceClient)
+		client := newTestFileStoringClient(mockClient)
+
+		stream := &fakeUploadStream{resp: &pb.UploadFileResponse{FileId: "file123"}}
+		mockClient.On("UploadFileStream", mock.Anything).Return(stream, nil)
+
+		content := strings.Repeat("a", fileChunkSize+10)
+		fileID, err := client.UploadFileStream(context.Background(), "large.txt", strings.NewReader(content))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "file123", fileID)
+		if assert.Len(t, stream.chunks, 2) {
+			assert.Equal(t, "large.txt", stream.chunks[0].FileName)
+			assert.Len(t, stream.chunks[0].Content, fileChunkSize)
+			assert.Empty(t, stream.chunks[1].FileName)
+			assert.Equal(t, strings.Repeat("a", 10), string(stream.chunks[1].Content))
+		}
+	})
+
+	// Test case: an empty file is sent as the chunk with the file name
+	t.Run("Empty file", func(t *testing.T) {
+		mockClient := new(MockFileStoringServiceClient)
+		client := newTestFileStoringClient(mockClient)
+
+		stream := &fakeUploadStream{resp: &pb.UploadFileResponse{FileId: "file123"}}
+		mockClient.On("UploadFileStream", mock.Anything).Return(stream, nil)
+
+		_, err := client.UploadFileStream(context.Background(), "empty.txt", strings.NewReader(""))
+
+		assert.NoError(t, err)
+		if assert.Len(t, stream.chunks, 1) {
+			assert.Equal(t, "empty.txt", stream.chunks[0].FileName)
+			assert.Empty(t, stream.chunks[0].Content)
+		}
+	})
+
+	// Test case: the service rejects the file
+	t.Run("Upload error", func(t *testing.T) {
+		mockClient := new(MockFileStoringServiceClient)
+		client := newTestFileStoringClient(mockClient)
+
+		stream := &fakeUploadStream{err: status.Error(codes.InvalidArgument, "invalid document")}
+		mockClient.On("UploadFileStream", mock.Anything).Return(stream, nil)
+
+		_, err := client.UploadFileStream(context.Background(), "essay.pdf", strings.NewReader("not a PDF"))
+
+		assert.Error(t, err)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	// Test case: reading the file fails
+	t.Run("Read error", func(t *testing.T) {
+		mockClient := new(MockFileStoringServiceClient)
+		client := newTestFileStoringClient(mockClient)
+
+		stream := &fakeUploadStream{}
+		mockClient.On("UploadFileStream", mock.Anything).Return(stream, nil)
+
+		content := io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("connection reset")))
+		_, err := client.UploadFileStream(context.Background(), "test.txt", content)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read file")
+	})
+}
+
+func TestGetFileStream(t *testing.T) {
+	// Test case: the chunks following the file name are read as the content
+	t.Run("Successful retrieval", func(t *testing.T) {
+		mockClient := new(MockFileStoringServiceClient)
+		client := newTestFileStoringClient(mockClient)
+
+		mockClient.On("GetFileStream", mock.Anything, &pb.GetFileRequest{FileId: "file123", Original: true}).
+			Return(&fakeFileChunkStream{chunks: []*pb.FileChunk{
+				{FileName: "essay.docx"},
+				{Content: []byte("first ")},
+				{Content: []byte("second")},
+			}}, nil)
+
+		fileName, content, err := client.GetFileStream(context.Background(), "file123", true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "essay.docx", fileName)
+		data, err := io.ReadAll(content)
+		assert.NoError(t, err)
+		assert.Equal(t, "first second", string(data))
+		assert.NoError(t, content.Close())
+	})
+
+	// Test case: the file is not found, which is not retried
+	t.Run("Not found", func(t *testing.T) {
+		mockClient := new(MockFileStoringServiceClient)
+		client := newTestFileStoringClient(mockClient)
+
+		mockClient.On("GetFileStream", mock.Anything, mock.Anything).
+			Return(&fakeFileChunkStream{err: status.Error(codes.NotFound, "file not found")}, nil)
+
+		_, _, err := client.GetFileStream(context.Background(), "file123", false)
+
+		assert.Error(t, err)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		mockClient.AssertNumberOfCalls(t, "GetFileStream", 1)
+	})
+
+	// Test case: the stream breaks after the file name
+	t.Run("Stream error", func(t *testing.T) {
+		mockClient := new(MockFileStoringServiceClient)
+		client := newTestFileStoringClient(mockClient)
+
+		mockClient.On("GetFileStream", mock.Anything, mock.Anything).
+			Return(&fakeFileChunkStream{
+				chunks: []*pb.FileChunk{{FileName: "essay.docx"}, {Content: []byte("first")}},
+				err:    status.Error(codes.Unavailable, "connection reset"),
+			}, nil)
+
+		_, content, err := client.GetFileStream(context.Background(), "file123", false)
+		assert.NoError(t, err)
+		defer content.Close()
+
+		data, err := io.ReadAll(content)
+		assert.Error(t, err)
+		assert.Equal(t, "first", string(data))
+	})
+}
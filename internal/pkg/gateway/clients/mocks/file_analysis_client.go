@@ -12,8 +12,8 @@ type MockFileAnalysisClient struct {
 }
 
 // AnalyzeFile mocks the AnalyzeFile method
-func (m *MockFileAnalysisClient) AnalyzeFile(ctx context.Context, fileID string, generateWordCloud bool) (*pb.AnalyzeFileResponse, error) {
-	args := m.Called(ctx, fileID, generateWordCloud)
+func (m *MockFileAnalysisClient) AnalyzeFile(ctx context.Context, fileID string, generateWordCloud bool, wordCloudOptions *pb.WordCloudOptions) (*pb.AnalyzeFileResponse, error) {
+	args := m.Called(ctx, fileID, generateWordCloud, wordCloudOptions)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -21,12 +21,103 @@ func (m *MockFileAnalysisClient) AnalyzeFile(ctx context.Context, fileID string,
 }
 
 // GetWordCloud mocks the GetWordCloud method
-func (m *MockFileAnalysisClient) GetWordCloud(ctx context.Context, location string) ([]byte, error) {
+func (m *MockFileAnalysisClient) GetWordCloud(ctx context.Context, location string) ([]byte, string, error) {
 	args := m.Called(ctx, location)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]byte), args.String(1), args.Error(2)
+}
+
+// GetMatchedPassages mocks the GetMatchedPassages method
+func (m *MockFileAnalysisClient) GetMatchedPassages(ctx context.Context, fileID, similarFileID string) ([]*pb.MatchedPassage, error) {
+	args := m.Called(ctx, fileID, similarFileID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*pb.MatchedPassage), args.Error(1)
+}
+
+// GetKeywords mocks the GetKeywords method
+func (m *MockFileAnalysisClient) GetKeywords(ctx context.Context, fileID string, limit int32) ([]*pb.Keyword, error) {
+	args := m.Called(ctx, fileID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*pb.Keyword), args.Error(1)
+}
+
+// GetWordFrequencies mocks the GetWordFrequencies method
+func (m *MockFileAnalysisClient) GetWordFrequencies(ctx context.Context, request *pb.GetWordFrequenciesRequest) ([]*pb.WordItem, error) {
+	args := m.Called(ctx, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*pb.WordItem), args.Error(1)
+}
+
+// SubmitAnalysis mocks the SubmitAnalysis method
+func (m *MockFileAnalysisClient) SubmitAnalysis(ctx context.Context, fileID string, generateWordCloud bool, wordCloudOptions *pb.WordCloudOptions, webhookURL string) (*pb.AnalysisJob, error) {
+	args := m.Called(ctx, fileID, generateWordCloud, wordCloudOptions, webhookURL)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.AnalysisJob), args.Error(1)
+}
+
+// GetAnalysisJob mocks the GetAnalysisJob method
+func (m *MockFileAnalysisClient) GetAnalysisJob(ctx context.Context, jobID string) (*pb.AnalysisJob, error) {
+	args := m.Called(ctx, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.AnalysisJob), args.Error(1)
+}
+
+// WatchAnalysis mocks the WatchAnalysis method, passing the events given to Return to handle
+func (m *MockFileAnalysisClient) WatchAnalysis(ctx context.Context, fileID string, handle func(*pb.AnalysisEvent) error) error {
+	args := m.Called(ctx, fileID, handle)
+	if events, ok := args.Get(0).([]*pb.AnalysisEvent); ok {
+		for _, event := range events {
+			if err := handle(event); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+// CreateWebhook mocks the CreateWebhook method
+func (m *MockFileAnalysisClient) CreateWebhook(ctx context.Context, url, secret string, events []string) (*pb.Webhook, error) {
+	args := m.Called(ctx, url, secret, events)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.Webhook), args.Error(1)
+}
+
+// ListWebhooks mocks the ListWebhooks method
+func (m *MockFileAnalysisClient) ListWebhooks(ctx context.Context) (*pb.ListWebhooksResponse, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.ListWebhooksResponse), args.Error(1)
+}
+
+// DeleteWebhook mocks the DeleteWebhook method
+func (m *MockFileAnalysisClient) DeleteWebhook(ctx context.Context, webhookID string) error {
+	args := m.Called(ctx, webhookID)
+	return args.Error(0)
+}
+
+// ListWebhookDeliveries mocks the ListWebhookDeliveries method
+func (m *MockFileAnalysisClient) ListWebhookDeliveries(ctx context.Context, webhookID, fileID string, limit int32) (*pb.ListWebhookDeliveriesResponse, error) {
+	args := m.Called(ctx, webhookID, fileID, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]byte), args.Error(1)
+	return args.Get(0).(*pb.ListWebhookDeliveriesResponse), args.Error(1)
 }
 
 // Close mocks the Close method
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"io"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -25,6 +26,30 @@ func (m *MockFileStoringClient) GetFile(ctx context.Context, fileID string) (str
 	return args.String(0), args.Get(1).([]byte), args.Error(2)
 }
 
+// GetFileText mocks the GetFileText method
+func (m *MockFileStoringClient) GetFileText(ctx context.Context, fileID string) (string, []byte, error) {
+	args := m.Called(ctx, fileID)
+	if args.Get(1) == nil {
+		return args.String(0), nil, args.Error(2)
+	}
+	return args.String(0), args.Get(1).([]byte), args.Error(2)
+}
+
+// UploadFileStream mocks the UploadFileStream method
+func (m *MockFileStoringClient) UploadFileStream(ctx context.Context, fileName string, content io.Reader) (string, error) {
+	args := m.Called(ctx, fileName, content)
+	return args.String(0), args.Error(1)
+}
+
+// GetFileStream mocks the GetFileStream method
+func (m *MockFileStoringClient) GetFileStream(ctx context.Context, fileID string, original bool) (string, io.ReadCloser, error) {
+	args := m.Called(ctx, fileID, original)
+	if args.Get(1) == nil {
+		return args.String(0), nil, args.Error(2)
+	}
+	return args.String(0), args.Get(1).(io.ReadCloser), args.Error(2)
+}
+
 // Close mocks the Close method
 func (m *MockFileStoringClient) Close() error {
 	args := m.Called()
@@ -1,18 +1,43 @@
+// Package handlers implements the HTTP API of the gateway, documented by its Swagger annotations
+// Files are streamed to and from the storage service without buffering, archives uploaded in a batch
+// are unpacked by the archive package, asynchronous analyses are answered with 202 and their job at
+// the Location header, and the progress of an analysis is streamed as server-sent events
+// Word clouds are served in the format asked by the format parameter or the Accept header,
+// clouds stored before both formats were kept are only served in their own one
 package handlers
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	pb "local.dev/doc-analyzer/internal/proto/analyzer"
 )
 
 // FileAnalysisClientInterface defines the interface for the File Analysis Client
 type FileAnalysisClientInterface interface {
-	AnalyzeFile(ctx context.Context, fileID string, generateWordCloud bool) (*pb.AnalyzeFileResponse, error)
-	GetWordCloud(ctx context.Context, location string) ([]byte, error)
+	AnalyzeFile(ctx context.Context, fileID string, generateWordCloud bool, wordCloudOptions *pb.WordCloudOptions) (*pb.AnalyzeFileResponse, error)
+	GetWordCloud(ctx context.Context, location string) ([]byte, string, error)
+	GetMatchedPassages(ctx context.Context, fileID, similarFileID string) ([]*pb.MatchedPassage, error)
+	GetKeywords(ctx context.Context, fileID string, limit int32) ([]*pb.Keyword, error)
+	GetWordFrequencies(ctx context.Context, request *pb.GetWordFrequenciesRequest) ([]*pb.WordItem, error)
+	SubmitAnalysis(ctx context.Context, fileID string, generateWordCloud bool, wordCloudOptions *pb.WordCloudOptions, webhookURL string) (*pb.AnalysisJob, error)
+	GetAnalysisJob(ctx context.Context, jobID string) (*pb.AnalysisJob, error)
+	WatchAnalysis(ctx context.Context, fileID string, handle func(*pb.AnalysisEvent) error) error
+	CreateWebhook(ctx context.Context, url, secret string, events []string) (*pb.Webhook, error)
+	ListWebhooks(ctx context.Context) (*pb.ListWebhooksResponse, error)
+	DeleteWebhook(ctx context.Context, webhookID string) error
+	ListWebhookDeliveries(ctx context.Context, webhookID, fileID string, limit int32) (*pb.ListWebhookDeliveriesResponse, error)
 	Close() error
 }
 
@@ -30,26 +55,94 @@ func NewAnalysisHandler(client FileAnalysisClientInterface) *AnalysisHandler {
 type AnalyzeFileRequest struct {
 	FileID            string `json:"file_id" binding:"required" example:"file123"`
 	GenerateWordCloud bool   `json:"generate_word_cloud" example:"true"`
+
+	// Word cloud options, unset options select the defaults
+	// Setting any of them generates a new word cloud for an analyzed file
+	WordCloudFormat        string `json:"word_cloud_format" binding:"omitempty,oneof=png svg" example:"svg"`
+	WordCloudWidth         int32  `json:"word_cloud_width" binding:"omitempty,min=64,max=4096" example:"1024"`
+	WordCloudHeight        int32  `json:"word_cloud_height" binding:"omitempty,min=64,max=4096" example:"768"`
+	WordCloudMaxWords      int32  `json:"word_cloud_max_words" binding:"omitempty,min=1,max=1000" example:"100"`
+	WordCloudColorScheme   string `json:"word_cloud_color_scheme" binding:"omitempty,oneof=default blues warm grayscale dark" example:"blues"`
+	WordCloudCase          string `json:"word_cloud_case" binding:"omitempty,oneof=lower upper original" example:"original"`
+	WordCloudKeepStopWords bool   `json:"word_cloud_keep_stop_words" example:"false"`
+
+	// Async queues the analysis and returns the job instead of waiting for the results
+	Async bool `json:"async" example:"false"`
+
+	// WebhookURL is notified once the queued job has finished, only with async set
+	WebhookURL string `json:"webhook_url" binding:"omitempty,url" example:"https://lms.example.com/hooks/analysis"`
 }
 
 // AnalyzeFileResponse represents the response for file analysis
 type AnalyzeFileResponse struct {
-	ParagraphCount    int32    `json:"paragraph_count" example:"5"`
-	WordCount         int32    `json:"word_count" example:"100"`
-	CharacterCount    int32    `json:"character_count" example:"500"`
-	IsPlagiarism      bool     `json:"is_plagiarism" example:"false"`
-	SimilarFileIds    []string `json:"similar_file_ids" example:"[]"`
-	WordCloudLocation string   `json:"word_cloud_location" example:"wordclouds/file123.png"`
+	ParagraphCount    int32         `json:"paragraph_count" example:"5"`
+	WordCount         int32         `json:"word_count" example:"100"`
+	Summary           string        `json:"summary" example:"Искусственный интеллект представляет собой область компьютерных наук."`
+	CharacterCount    int32         `json:"character_count" example:"500"`
+	IsPlagiarism      bool          `json:"is_plagiarism" example:"false"`
+	SimilarFileIds    []string      `json:"similar_file_ids" example:"[]"`
+	SimilarFiles      []SimilarFile `json:"similar_files"`
+	WordCloudLocation string        `json:"word_cloud_location" example:"wordclouds/file123.png"`
+	WordCloudFormat   string        `json:"word_cloud_format" example:"png"`
+	Language          string        `json:"language" example:"ru"`
+
+	// Homoglyphs and invisible characters found in the text, used to hide copied text
+	ObfuscationDetected bool  `json:"obfuscation_detected" example:"false"`
+	HomoglyphCount      int32 `json:"homoglyph_count" example:"0"`
+	InvisibleCharCount  int32 `json:"invisible_char_count" example:"0"`
+
+	// Text metrics, characters are counted as Unicode characters, not bytes
+	CharacterCountNoSpaces int32   `json:"character_count_no_spaces" example:"420"`
+	SentenceCount          int32   `json:"sentence_count" example:"8"`
+	AvgSentenceLength      float64 `json:"avg_sentence_length" example:"12.5"`
+	AvgWordLength          float64 `json:"avg_word_length" example:"5.2"`
+	LexicalDiversity       float64 `json:"lexical_diversity" example:"0.65"`
+
+	// Readability indices: Flesch-Kincaid for English, Oborneva for Russian
+	ReadabilityFormula string  `json:"readability_formula" example:"oborneva"`
+	ReadingEase        float64 `json:"reading_ease" example:"45.3"`
+	GradeLevel         float64 `json:"grade_level" example:"11.2"`
+}
+
+// SimilarFile represents a previously analyzed file similar to the analyzed one
+type SimilarFile struct {
+	FileID             string  `json:"file_id" example:"file456"`
+	Score              float64 `json:"score" example:"0.35"`
+	Metric             string  `json:"metric" example:"jaccard"`
+	NGramSize          int32   `json:"ngram_size" example:"3"`
+	Threshold          float64 `json:"threshold" example:"0.3"`
+	AlgorithmVersion   int32   `json:"algorithm_version" example:"1"`
+	Containment        float64 `json:"containment" example:"0.25"`
+	SimilarContainment float64 `json:"similar_containment" example:"0.9"`
+	Coverage           float64 `json:"coverage" example:"0.42"`
+	MatchedWords       int32   `json:"matched_words" example:"120"`
+}
+
+// AnalysisJobResponse represents an analysis queued with async set
+// Times are in RFC 3339, empty until the event happens
+type AnalysisJobResponse struct {
+	JobID      string               `json:"job_id" example:"7f1c7a3e-1f0e-4d5c-9b0a-3c2d1e0f9a8b"`
+	FileID     string               `json:"file_id" example:"file123"`
+	Status     string               `json:"status" example:"done" enums:"queued,running,done,failed"`
+	Error      string               `json:"error,omitempty" example:""`
+	Attempts   int32                `json:"attempts" example:"1"`
+	CreatedAt  string               `json:"created_at" example:"2024-05-01T12:00:00Z"`
+	StartedAt  string               `json:"started_at,omitempty" example:"2024-05-01T12:00:01Z"`
+	FinishedAt string               `json:"finished_at,omitempty" example:"2024-05-01T12:00:05Z"`
+	Result     *AnalyzeFileResponse `json:"result,omitempty"`
 }
 
 // AnalyzeFile godoc
 // @Summary Analyze a file
 // @Description Analyze a file by its ID
+// @Description With async set the analysis is queued and the job is returned, its state is available at the Location header
+// @Description With webhook_url set the URL is notified once the job has finished, see the webhooks API for the payload
 // @Tags analysis
 // @Accept json
 // @Produce json
 // @Param request body AnalyzeFileRequest true "Analysis request"
 // @Success 200 {object} AnalyzeFileResponse "Analysis results"
+// @Success 202 {object} AnalysisJobResponse "Queued analysis job"
 // @Failure 400 {object} map[string]string "Bad request"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/v1/analysis [post]
@@ -60,31 +153,528 @@ func (h *AnalysisHandler) AnalyzeFile(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.client.AnalyzeFile(c.Request.Context(), request.FileID, request.GenerateWordCloud)
+	wordCloudOptions := &pb.WordCloudOptions{
+		Format:        request.WordCloudFormat,
+		Width:         request.WordCloudWidth,
+		Height:        request.WordCloudHeight,
+		MaxWords:      request.WordCloudMaxWords,
+		ColorScheme:   request.WordCloudColorScheme,
+		Case:          request.WordCloudCase,
+		KeepStopWords: request.WordCloudKeepStopWords,
+	}
+
+	if request.WebhookURL != "" && !request.Async {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "webhook_url requires async"})
+		return
+	}
+
+	if request.Async {
+		job, err := h.client.SubmitAnalysis(c.Request.Context(), request.FileID, request.GenerateWordCloud, wordCloudOptions, request.WebhookURL)
+		if err != nil {
+			if status.Code(err) == codes.InvalidArgument {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Location", "/api/v1/analysis/jobs/"+job.Id)
+		c.JSON(http.StatusAccepted, toAnalysisJobResponse(job))
+		return
+	}
+
+	resp, err := h.client.AnalyzeFile(c.Request.Context(), request.FileID, request.GenerateWordCloud, wordCloudOptions)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, toAnalyzeFileResponse(resp))
+}
+
+// toAnalyzeFileResponse converts analysis results from their protobuf representation
+func toAnalyzeFileResponse(resp *pb.AnalyzeFileResponse) AnalyzeFileResponse {
+	return AnalyzeFileResponse{
+		ParagraphCount:      resp.ParagraphCount,
+		WordCount:           resp.WordCount,
+		Summary:             resp.Summary,
+		CharacterCount:      resp.CharacterCount,
+		IsPlagiarism:        resp.IsPlagiarism,
+		SimilarFileIds:      resp.SimilarFileIds,
+		SimilarFiles:        toSimilarFiles(resp.SimilarFiles),
+		WordCloudLocation:   resp.WordCloudLocation,
+		WordCloudFormat:     resp.WordCloudFormat,
+		Language:            resp.Language,
+		ObfuscationDetected: resp.ObfuscationDetected,
+		HomoglyphCount:      resp.HomoglyphCount,
+		InvisibleCharCount:  resp.InvisibleCharCount,
+
+		CharacterCountNoSpaces: resp.CharacterCountNoSpaces,
+		SentenceCount:          resp.SentenceCount,
+		AvgSentenceLength:      resp.AvgSentenceLength,
+		AvgWordLength:          resp.AvgWordLength,
+		LexicalDiversity:       resp.LexicalDiversity,
+
+		ReadabilityFormula: resp.ReadabilityFormula,
+		ReadingEase:        resp.ReadingEase,
+		GradeLevel:         resp.GradeLevel,
+	}
+}
+
+// toAnalysisJobResponse converts an analysis job from its protobuf representation
+func toAnalysisJobResponse(job *pb.AnalysisJob) AnalysisJobResponse {
+	response := AnalysisJobResponse{
+		JobID:      job.Id,
+		FileID:     job.FileId,
+		Status:     job.Status,
+		Error:      job.Error,
+		Attempts:   job.Attempts,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if job.Result != nil {
+		result := toAnalyzeFileResponse(job.Result)
+		response.Result = &result
+	}
+	return response
+}
+
+// GetAnalysisJob godoc
+// @Summary Get an analysis job
+// @Description Get the state of an analysis queued with async set, with the analysis results once it is done
+// @Tags analysis
+// @Produce json
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} AnalysisJobResponse "Analysis job"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Job not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/v1/analysis/jobs/{job_id} [get]
+func (h *AnalysisHandler) GetAnalysisJob(c *gin.Context) {
+	jobID := c.Param("job_id")
+	if jobID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Job ID is required"})
+		return
+	}
+
+	job, err := h.client.GetAnalysisJob(c.Request.Context(), jobID)
 	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Analysis job not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, AnalyzeFileResponse{
-		ParagraphCount:    resp.ParagraphCount,
-		WordCount:         resp.WordCount,
-		CharacterCount:    resp.CharacterCount,
-		IsPlagiarism:      resp.IsPlagiarism,
-		SimilarFileIds:    resp.SimilarFileIds,
-		WordCloudLocation: resp.WordCloudLocation,
+	c.JSON(http.StatusOK, toAnalysisJobResponse(job))
+}
+
+// analysisEventsKeepAlive is how often a comment is sent on an idle event stream,
+// so that proxies do not close it
+const analysisEventsKeepAlive = 15 * time.Second
+
+// AnalysisEventResponse represents a stage of a file analysis
+type AnalysisEventResponse struct {
+	FileID string `json:"file_id" example:"file123"`
+	Stage  string `json:"stage" example:"comparing" enums:"queued,fetching_file,analyzing_text,comparing,generating_word_cloud,saving,done,failed"`
+
+	// Current and Total count the steps of the stage, the compared documents for comparing
+	Current int32 `json:"current" example:"12"`
+	Total   int32 `json:"total" example:"40"`
+
+	Error string `json:"error,omitempty" example:""`
+	Time  string `json:"time" example:"2024-05-01T12:00:02Z"`
+}
+
+// GetAnalysisEvents godoc
+// @Summary Stream analysis progress
+// @Description Stream the stages of the analysis of a file as server-sent events named "progress"
+// @Description An analyzed file without a running analysis gets a single done event, otherwise the next analysis is waited for
+// @Description The stream ends after the done or failed event, clients should close it then instead of reconnecting
+// @Description Failures of the stream itself are sent as an event named "error"
+// @Tags analysis
+// @Produce text/event-stream
+// @Param file_id path string true "File ID"
+// @Success 200 {object} AnalysisEventResponse "Stream of analysis events"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Router /api/v1/analysis/{file_id}/events [get]
+func (h *AnalysisHandler) GetAnalysisEvents(c *gin.Context) {
+	fileID := c.Param("file_id")
+	if fileID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File ID is required"})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	// Receive the events in the background, so that the stream can be kept alive while waiting for them
+	events := make(chan *pb.AnalysisEvent)
+	watched := make(chan error, 1)
+	go func() {
+		watched <- h.client.WatchAnalysis(ctx, fileID, func(event *pb.AnalysisEvent) error {
+			select {
+			case events <- event:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(analysisEventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event := <-events:
+			c.SSEvent("progress", AnalysisEventResponse{
+				FileID:  event.FileId,
+				Stage:   event.Stage,
+				Current: event.Current,
+				Total:   event.Total,
+				Error:   event.Error,
+				Time:    event.Time,
+			})
+			c.Writer.Flush()
+		case err := <-watched:
+			if err != nil && ctx.Err() == nil {
+				c.SSEvent("error", gin.H{"error": err.Error()})
+				c.Writer.Flush()
+			}
+			return
+		case <-keepAlive.C:
+			io.WriteString(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// toSimilarFiles converts similar files from their protobuf representation
+// Files are sorted by the highest of their measures, highest first, so that the closest copies
+// come first whichever metric flagged them
+func toSimilarFiles(pbSimilarFiles []*pb.SimilarFile) []SimilarFile {
+	similarFiles := make([]SimilarFile, 0, len(pbSimilarFiles))
+	for _, similarFile := range pbSimilarFiles {
+		similarFiles = append(similarFiles, SimilarFile{
+			FileID:             similarFile.FileId,
+			Score:              similarFile.Score,
+			Metric:             similarFile.Metric,
+			NGramSize:          similarFile.NgramSize,
+			Threshold:          similarFile.Threshold,
+			AlgorithmVersion:   similarFile.AlgorithmVersion,
+			Containment:        similarFile.Containment,
+			SimilarContainment: similarFile.SimilarContainment,
+			Coverage:           similarFile.Coverage,
+			MatchedWords:       similarFile.MatchedWords,
+		})
+	}
+
+	sort.SliceStable(similarFiles, func(i, j int) bool {
+		if strength, otherStrength := similarFiles[i].strength(), similarFiles[j].strength(); strength != otherStrength {
+			return strength > otherStrength
+		}
+		return similarFiles[i].Score > similarFiles[j].Score
 	})
+
+	return similarFiles
+}
+
+// strength is the highest of the similar file's measures
+// Score only holds the Jaccard similarity, which stays low for files flagged by containment or winnowing
+func (f SimilarFile) strength() float64 {
+	return max(f.Score, f.Containment, f.SimilarContainment, f.Coverage)
+}
+
+// MatchedPassagesResponse represents the passages of a file copied from a similar file
+type MatchedPassagesResponse struct {
+	FileID        string           `json:"file_id" example:"file123"`
+	SimilarFileID string           `json:"similar_file_id" example:"file456"`
+	Passages      []MatchedPassage `json:"passages"`
+}
+
+// MatchedPassage represents a passage found in both files
+// Offsets are in characters of the original texts, ends are exclusive
+type MatchedPassage struct {
+	Start        int32  `json:"start" example:"120"`
+	End          int32  `json:"end" example:"480"`
+	SimilarStart int32  `json:"similar_start" example:"0"`
+	SimilarEnd   int32  `json:"similar_end" example:"362"`
+	Text         string `json:"text" example:"copied passage"`
+}
+
+// GetMatchedPassages godoc
+// @Summary Get matched passages
+// @Description Get the passages of a file copied from a similar file, with character offsets in both files
+// @Tags analysis
+// @Produce json
+// @Param file_id path string true "Analyzed file ID"
+// @Param similar_file_id path string true "Similar file ID"
+// @Success 200 {object} MatchedPassagesResponse "Matched passages"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "File not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/v1/analysis/{file_id}/matches/{similar_file_id} [get]
+func (h *AnalysisHandler) GetMatchedPassages(c *gin.Context) {
+	fileID := c.Param("file_id")
+	similarFileID := c.Param("similar_file_id")
+	if fileID == "" || similarFileID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File ID and similar file ID are required"})
+		return
+	}
+
+	pbPassages, err := h.client.GetMatchedPassages(c.Request.Context(), fileID, similarFileID)
+	if err != nil {
+		switch status.Code(err) {
+		case codes.InvalidArgument:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file ID"})
+		case codes.NotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	passages := make([]MatchedPassage, 0, len(pbPassages))
+	for _, passage := range pbPassages {
+		passages = append(passages, MatchedPassage{
+			Start:        passage.Start,
+			End:          passage.End,
+			SimilarStart: passage.SimilarStart,
+			SimilarEnd:   passage.SimilarEnd,
+			Text:         passage.Text,
+		})
+	}
+
+	c.JSON(http.StatusOK, MatchedPassagesResponse{
+		FileID:        fileID,
+		SimilarFileID: similarFileID,
+		Passages:      passages,
+	})
+}
+
+// Limits of the number of keywords returned for a file
+const (
+	defaultKeywordsLimit = 10
+	maxKeywordsLimit     = 100
+)
+
+// KeywordsResponse represents the keywords of a file
+type KeywordsResponse struct {
+	FileID   string    `json:"file_id" example:"file123"`
+	Keywords []Keyword `json:"keywords"`
+}
+
+// Keyword represents a word of a file ranked by TF-IDF against all analyzed files
+type Keyword struct {
+	Word              string  `json:"word" example:"плагиата"`
+	Term              string  `json:"term" example:"плагиат"`
+	Count             int32   `json:"count" example:"12"`
+	DocumentFrequency int32   `json:"document_frequency" example:"3"`
+	Score             float64 `json:"score" example:"0.042"`
+}
+
+// GetKeywords godoc
+// @Summary Get file keywords
+// @Description Get the most distinctive words of an analyzed file, ranked by TF-IDF against all analyzed files
+// @Tags analysis
+// @Produce json
+// @Param file_id path string true "File ID"
+// @Param limit query int false "Maximum number of keywords (1-100)" default(10)
+// @Success 200 {object} KeywordsResponse "Keywords, most distinctive first"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "File not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/v1/files/{file_id}/keywords [get]
+func (h *AnalysisHandler) GetKeywords(c *gin.Context) {
+	fileID := c.Param("file_id")
+	if fileID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File ID is required"})
+		return
+	}
+
+	limit := defaultKeywordsLimit
+	if value := c.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxKeywordsLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Limit must be an integer from 1 to " + strconv.Itoa(maxKeywordsLimit)})
+			return
+		}
+	}
+
+	pbKeywords, err := h.client.GetKeywords(c.Request.Context(), fileID, int32(limit))
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	keywords := make([]Keyword, 0, len(pbKeywords))
+	for _, keyword := range pbKeywords {
+		keywords = append(keywords, Keyword{
+			Word:              keyword.Word,
+			Term:              keyword.Term,
+			Count:             keyword.Count,
+			DocumentFrequency: keyword.DocumentFrequency,
+			Score:             keyword.Score,
+		})
+	}
+
+	c.JSON(http.StatusOK, KeywordsResponse{
+		FileID:   fileID,
+		Keywords: keywords,
+	})
+}
+
+// Limits of the number of words returned for a file
+const (
+	defaultWordsLimit = 100
+	maxWordsLimit     = 1000
+)
+
+// WordFrequenciesResponse represents the word counts of a file
+type WordFrequenciesResponse struct {
+	FileID string     `json:"file_id" example:"file123"`
+	Words  []WordItem `json:"words"`
+}
+
+// WordItem represents a word and the number of its occurrences, as expected by word cloud libraries
+type WordItem struct {
+	Text  string `json:"text" example:"плагиат"`
+	Value int32  `json:"value" example:"12"`
+}
+
+// GetWordFrequencies godoc
+// @Summary Get word frequencies of a file
+// @Description Get the words of a file with their counts, most frequent first, to draw a word cloud on the client
+// @Description Stop words of the file language are removed and inflected forms are counted as one word by default
+// @Tags analysis
+// @Produce json
+// @Param file_id path string true "File ID"
+// @Param limit query int false "Maximum number of words (1-1000)" default(100)
+// @Param case query string false "Case of the words" Enums(lower, upper, original) default(lower)
+// @Param keep_stop_words query bool false "Keep the stop words" default(false)
+// @Param separate_forms query bool false "Count inflected forms of a word as different words" default(false)
+// @Success 200 {object} WordFrequenciesResponse "Words, most frequent first"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "File not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/v1/files/{file_id}/words [get]
+func (h *AnalysisHandler) GetWordFrequencies(c *gin.Context) {
+	fileID := c.Param("file_id")
+	if fileID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File ID is required"})
+		return
+	}
+
+	limit := defaultWordsLimit
+	if value := c.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxWordsLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Limit must be an integer from 1 to " + strconv.Itoa(maxWordsLimit)})
+			return
+		}
+	}
+
+	wordCase := c.Query("case")
+	if wordCase != "" && wordCase != "lower" && wordCase != "upper" && wordCase != "original" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Case must be lower, upper or original"})
+		return
+	}
+
+	keepStopWords, err := queryBool(c, "keep_stop_words")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	separateForms, err := queryBool(c, "separate_forms")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pbWords, err := h.client.GetWordFrequencies(c.Request.Context(), &pb.GetWordFrequenciesRequest{
+		FileId:        fileID,
+		Limit:         int32(limit),
+		Case:          wordCase,
+		KeepStopWords: keepStopWords,
+		SeparateForms: separateForms,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	words := make([]WordItem, 0, len(pbWords))
+	for _, word := range pbWords {
+		words = append(words, WordItem{
+			Text:  word.Text,
+			Value: word.Value,
+		})
+	}
+
+	c.JSON(http.StatusOK, WordFrequenciesResponse{
+		FileID: fileID,
+		Words:  words,
+	})
+}
+
+// queryBool parses an optional boolean query parameter, false if it is not set
+func queryBool(c *gin.Context, name string) (bool, error) {
+	value := c.Query(name)
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", name)
+	}
+	return parsed, nil
+}
+
+// wordCloudContentTypes maps word cloud formats to their media types
+var wordCloudContentTypes = map[string]string{
+	"png": "image/png",
+	"svg": "image/svg+xml",
 }
 
 // GetWordCloud godoc
 // @Summary Get a word cloud
 // @Description Get a word cloud image by its location
+// @Description The image is returned in the format selected by the format query parameter, or by the Accept header
+// @Description if the parameter is not set. Word clouds are stored as PNG and SVG, clouds stored before that
+// @Description are only available in the format they were generated in.
 // @Tags analysis
 // @Produce image/png
+// @Produce image/svg+xml
 // @Param location path string true "Word cloud location"
+// @Param format query string false "Expected image format" Enums(png, svg)
 // @Success 200 {file} binary "Word cloud image"
 // @Failure 400 {object} map[string]string "Bad request"
 // @Failure 404 {object} map[string]string "Word cloud not found"
+// @Failure 406 {object} map[string]string "Word cloud is not available in the requested format"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/v1/wordcloud/{location} [get]
 func (h *AnalysisHandler) GetWordCloud(c *gin.Context) {
@@ -94,11 +684,72 @@ func (h *AnalysisHandler) GetWordCloud(c *gin.Context) {
 		return
 	}
 
-	image, err := h.client.GetWordCloud(c.Request.Context(), location)
+	requestedFormat := c.Query("format")
+	if _, ok := wordCloudContentTypes[requestedFormat]; requestedFormat != "" && !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be png or svg"})
+		return
+	}
+
+	image, format, err := h.client.GetWordCloud(c.Request.Context(), location)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.wordCloudError(c, err)
 		return
 	}
 
-	c.Data(http.StatusOK, "image/png", image)
+	// Word clouds stored before SVG support have no recorded format
+	if format == "" {
+		format = "png"
+	}
+	otherFormat := "svg"
+	if format == "svg" {
+		otherFormat = "png"
+	}
+
+	// The format parameter takes precedence over the Accept header,
+	// so that links to the image can select the format
+	c.Header("Vary", "Accept")
+	if requestedFormat == "" {
+		switch c.NegotiateFormat(wordCloudContentTypes[format], wordCloudContentTypes[otherFormat]) {
+		case wordCloudContentTypes[format]:
+			requestedFormat = format
+		case wordCloudContentTypes[otherFormat]:
+			requestedFormat = otherFormat
+		default:
+			c.JSON(http.StatusNotAcceptable, gin.H{"error": "Word cloud is only available as png or svg"})
+			return
+		}
+	}
+
+	// The cloud in the other format is stored next to it, under the extension of that format
+	if requestedFormat != format {
+		image, _, err = h.client.GetWordCloud(c.Request.Context(), wordCloudLocationAs(location, requestedFormat))
+		if status.Code(err) == codes.NotFound {
+			c.JSON(http.StatusNotAcceptable, gin.H{"error": "Word cloud is only available as " + format})
+			return
+		}
+		if err != nil {
+			h.wordCloudError(c, err)
+			return
+		}
+	}
+
+	c.Data(http.StatusOK, wordCloudContentTypes[requestedFormat], image)
+}
+
+// wordCloudError responds with the status matching an error of a word cloud request
+func (h *AnalysisHandler) wordCloudError(c *gin.Context, err error) {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid word cloud location"})
+	case codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Word cloud not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// wordCloudLocationAs returns the location of a word cloud stored in another format,
+// which differs from the location only by the extension of the format
+func wordCloudLocationAs(location, format string) string {
+	return strings.TrimSuffix(location, path.Ext(location)) + "." + format
 }
@@ -5,13 +5,17 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	pb "local.dev/doc-analyzer/internal/proto/analyzer"
 )
@@ -21,14 +25,69 @@ type MockFileAnalysisClient struct {
 	mock.Mock
 }
 
-func (m *MockFileAnalysisClient) AnalyzeFile(ctx context.Context, fileID string, generateWordCloud bool) (*pb.AnalyzeFileResponse, error) {
-	args := m.Called(ctx, fileID, generateWordCloud)
+func (m *MockFileAnalysisClient) AnalyzeFile(ctx context.Context, fileID string, generateWordCloud bool, wordCloudOptions *pb.WordCloudOptions) (*pb.AnalyzeFileResponse, error) {
+	args := m.Called(ctx, fileID, generateWordCloud, wordCloudOptions)
 	return args.Get(0).(*pb.AnalyzeFileResponse), args.Error(1)
 }
 
-func (m *MockFileAnalysisClient) GetWordCloud(ctx context.Context, location string) ([]byte, error) {
+func (m *MockFileAnalysisClient) GetWordCloud(ctx context.Context, location string) ([]byte, string, error) {
 	args := m.Called(ctx, location)
-	return args.Get(0).([]byte), args.Error(1)
+	return args.Get(0).([]byte), args.String(1), args.Error(2)
+}
+
+func (m *MockFileAnalysisClient) GetMatchedPassages(ctx context.Context, fileID, similarFileID string) ([]*pb.MatchedPassage, error) {
+	args := m.Called(ctx, fileID, similarFileID)
+	return args.Get(0).([]*pb.MatchedPassage), args.Error(1)
+}
+
+func (m *MockFileAnalysisClient) GetKeywords(ctx context.Context, fileID string, limit int32) ([]*pb.Keyword, error) {
+	args := m.Called(ctx, fileID, limit)
+	return args.Get(0).([]*pb.Keyword), args.Error(1)
+}
+
+func (m *MockFileAnalysisClient) GetWordFrequencies(ctx context.Context, request *pb.GetWordFrequenciesRequest) ([]*pb.WordItem, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).([]*pb.WordItem), args.Error(1)
+}
+
+func (m *MockFileAnalysisClient) SubmitAnalysis(ctx context.Context, fileID string, generateWordCloud bool, wordCloudOptions *pb.WordCloudOptions, webhookURL string) (*pb.AnalysisJob, error) {
+	args := m.Called(ctx, fileID, generateWordCloud, wordCloudOptions, webhookURL)
+	return args.Get(0).(*pb.AnalysisJob), args.Error(1)
+}
+
+func (m *MockFileAnalysisClient) GetAnalysisJob(ctx context.Context, jobID string) (*pb.AnalysisJob, error) {
+	args := m.Called(ctx, jobID)
+	return args.Get(0).(*pb.AnalysisJob), args.Error(1)
+}
+
+func (m *MockFileAnalysisClient) WatchAnalysis(ctx context.Context, fileID string, handle func(*pb.AnalysisEvent) error) error {
+	args := m.Called(ctx, fileID, handle)
+	for _, event := range args.Get(0).([]*pb.AnalysisEvent) {
+		if err := handle(event); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockFileAnalysisClient) CreateWebhook(ctx context.Context, url, secret string, events []string) (*pb.Webhook, error) {
+	args := m.Called(ctx, url, secret, events)
+	return args.Get(0).(*pb.Webhook), args.Error(1)
+}
+
+func (m *MockFileAnalysisClient) ListWebhooks(ctx context.Context) (*pb.ListWebhooksResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*pb.ListWebhooksResponse), args.Error(1)
+}
+
+func (m *MockFileAnalysisClient) DeleteWebhook(ctx context.Context, webhookID string) error {
+	args := m.Called(ctx, webhookID)
+	return args.Error(0)
+}
+
+func (m *MockFileAnalysisClient) ListWebhookDeliveries(ctx context.Context, webhookID, fileID string, limit int32) (*pb.ListWebhookDeliveriesResponse, error) {
+	args := m.Called(ctx, webhookID, fileID, limit)
+	return args.Get(0).(*pb.ListWebhookDeliveriesResponse), args.Error(1)
 }
 
 func (m *MockFileAnalysisClient) Close() error {
@@ -47,7 +106,7 @@ func TestAnalyzeFile_Success(t *testing.T) {
 	router.POST("/api/v1/analysis", handler.AnalyzeFile)
 
 	// Mock the client response
-	mockClient.On("AnalyzeFile", mock.Anything, "file123", true).Return(
+	mockClient.On("AnalyzeFile", mock.Anything, "file123", true, &pb.WordCloudOptions{}).Return(
 		&pb.AnalyzeFileResponse{
 			ParagraphCount:    5,
 			WordCount:         100,
@@ -55,6 +114,24 @@ func TestAnalyzeFile_Success(t *testing.T) {
 			IsPlagiarism:      false,
 			SimilarFileIds:    []string{},
 			WordCloudLocation: "wordclouds/file123.png",
+			WordCloudFormat:   "png",
+			Language:          "ru",
+
+			ObfuscationDetected: true,
+			HomoglyphCount:      3,
+			InvisibleCharCount:  2,
+
+			CharacterCountNoSpaces: 420,
+			SentenceCount:          8,
+			AvgSentenceLength:      12.5,
+			AvgWordLength:          5.2,
+			LexicalDiversity:       0.65,
+
+			ReadabilityFormula: "oborneva",
+			ReadingEase:        45.3,
+			GradeLevel:         11.2,
+
+			Summary: "Краткое содержание.",
 		},
 		nil,
 	)
@@ -89,6 +166,78 @@ func TestAnalyzeFile_Success(t *testing.T) {
 	assert.Equal(t, false, response.IsPlagiarism)
 	assert.Empty(t, response.SimilarFileIds)
 	assert.Equal(t, "wordclouds/file123.png", response.WordCloudLocation)
+	assert.Equal(t, "png", response.WordCloudFormat)
+	assert.Equal(t, "ru", response.Language)
+	assert.True(t, response.ObfuscationDetected)
+	assert.Equal(t, int32(3), response.HomoglyphCount)
+	assert.Equal(t, int32(2), response.InvisibleCharCount)
+	assert.Equal(t, int32(420), response.CharacterCountNoSpaces)
+	assert.Equal(t, int32(8), response.SentenceCount)
+	assert.Equal(t, 12.5, response.AvgSentenceLength)
+	assert.Equal(t, 5.2, response.AvgWordLength)
+	assert.Equal(t, 0.65, response.LexicalDiversity)
+	assert.Equal(t, "oborneva", response.ReadabilityFormula)
+	assert.Equal(t, 45.3, response.ReadingEase)
+	assert.Equal(t, 11.2, response.GradeLevel)
+	assert.Equal(t, "Краткое содержание.", response.Summary)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestAnalyzeFile_WithSimilarFiles(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.POST("/api/v1/analysis", handler.AnalyzeFile)
+
+	// Mock the client response
+	mockClient.On("AnalyzeFile", mock.Anything, "file123", false, &pb.WordCloudOptions{}).Return(
+		&pb.AnalyzeFileResponse{
+			ParagraphCount: 5,
+			WordCount:      100,
+			CharacterCount: 500,
+			IsPlagiarism:   true,
+			SimilarFileIds: []string{"file456", "file789", "file999", "file321"},
+			SimilarFiles: []*pb.SimilarFile{
+				{FileId: "file456", Score: 0.35, Metric: "jaccard", NgramSize: 3, Threshold: 0.3, AlgorithmVersion: 1, Containment: 0.5, SimilarContainment: 0.6, Coverage: 0.4, MatchedWords: 40},
+				{FileId: "file789", Score: 1, Metric: "exact", NgramSize: 3, Threshold: 0.3, AlgorithmVersion: 1, Coverage: 1, MatchedWords: 100},
+				{FileId: "file999", Score: 0.1, Metric: "winnowing", NgramSize: 3, Threshold: 0.3, AlgorithmVersion: 1, Coverage: 0.2, MatchedWords: 20},
+				{FileId: "file321", Score: 0.05, Metric: "containment", NgramSize: 3, Threshold: 0.3, AlgorithmVersion: 1, Containment: 0.1, SimilarContainment: 0.9, Coverage: 0.1, MatchedWords: 30},
+			},
+		},
+		nil,
+	)
+
+	// Create a test request
+	jsonBody, _ := json.Marshal(AnalyzeFileRequest{FileID: "file123"})
+	req, _ := http.NewRequest("POST", "/api/v1/analysis", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response AnalyzeFileResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.True(t, response.IsPlagiarism)
+	assert.Equal(t, []string{"file456", "file789", "file999", "file321"}, response.SimilarFileIds)
+
+	// Similar files are sorted by their highest measure, highest first
+	assert.Equal(t, []SimilarFile{
+		{FileID: "file789", Score: 1, Metric: "exact", NGramSize: 3, Threshold: 0.3, AlgorithmVersion: 1, Coverage: 1, MatchedWords: 100},
+		{FileID: "file321", Score: 0.05, Metric: "containment", NGramSize: 3, Threshold: 0.3, AlgorithmVersion: 1, Containment: 0.1, SimilarContainment: 0.9, Coverage: 0.1, MatchedWords: 30},
+		{FileID: "file456", Score: 0.35, Metric: "jaccard", NGramSize: 3, Threshold: 0.3, AlgorithmVersion: 1, Containment: 0.5, SimilarContainment: 0.6, Coverage: 0.4, MatchedWords: 40},
+		{FileID: "file999", Score: 0.1, Metric: "winnowing", NGramSize: 3, Threshold: 0.3, AlgorithmVersion: 1, Coverage: 0.2, MatchedWords: 20},
+	}, response.SimilarFiles)
 
 	mockClient.AssertExpectations(t)
 }
@@ -122,6 +271,123 @@ func TestAnalyzeFile_InvalidRequest(t *testing.T) {
 	mockClient.AssertNotCalled(t, "AnalyzeFile")
 }
 
+func TestAnalyzeFile_InvalidWordCloudFormat(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.POST("/api/v1/analysis", handler.AnalyzeFile)
+
+	// Create a request with an unsupported word cloud format
+	jsonBody, _ := json.Marshal(AnalyzeFileRequest{
+		FileID:            "file123",
+		GenerateWordCloud: true,
+		WordCloudFormat:   "gif",
+	})
+
+	// Create a test request
+	req, _ := http.NewRequest("POST", "/api/v1/analysis", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	mockClient.AssertNotCalled(t, "AnalyzeFile")
+}
+
+func TestAnalyzeFile_WordCloudOptions(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.POST("/api/v1/analysis", handler.AnalyzeFile)
+
+	// The options are passed to the analysis service
+	mockClient.On("AnalyzeFile", mock.Anything, "file123", true, &pb.WordCloudOptions{
+		Format:        "svg",
+		Width:         800,
+		Height:        600,
+		MaxWords:      50,
+		ColorScheme:   "dark",
+		Case:          "original",
+		KeepStopWords: true,
+	}).Return(
+		&pb.AnalyzeFileResponse{WordCloudLocation: "wordclouds/file123.svg", WordCloudFormat: "svg"},
+		nil,
+	)
+
+	jsonBody := []byte(`{
+		"file_id": "file123",
+		"generate_word_cloud": true,
+		"word_cloud_format": "svg",
+		"word_cloud_width": 800,
+		"word_cloud_height": 600,
+		"word_cloud_max_words": 50,
+		"word_cloud_color_scheme": "dark",
+		"word_cloud_case": "original",
+		"word_cloud_keep_stop_words": true
+	}`)
+
+	// Create a test request
+	req, _ := http.NewRequest("POST", "/api/v1/analysis", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAnalyzeFile_InvalidWordCloudOptions(t *testing.T) {
+	testCases := []struct {
+		name string
+		body string
+	}{
+		{"Too small", `{"file_id": "file123", "word_cloud_width": 10}`},
+		{"Too large", `{"file_id": "file123", "word_cloud_height": 10000}`},
+		{"Too many words", `{"file_id": "file123", "word_cloud_max_words": 5000}`},
+		{"Unknown color scheme", `{"file_id": "file123", "word_cloud_color_scheme": "rainbow"}`},
+		{"Unknown case", `{"file_id": "file123", "word_cloud_case": "title"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockClient := new(MockFileAnalysisClient)
+			handler := NewAnalysisHandler(mockClient)
+
+			// Create a test server
+			router := gin.Default()
+			router.POST("/api/v1/analysis", handler.AnalyzeFile)
+
+			// Create a test request
+			req, _ := http.NewRequest("POST", "/api/v1/analysis", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			// Perform the request
+			router.ServeHTTP(resp, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+			mockClient.AssertNotCalled(t, "AnalyzeFile")
+		})
+	}
+}
+
 func TestAnalyzeFile_ClientError(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -133,7 +399,7 @@ func TestAnalyzeFile_ClientError(t *testing.T) {
 	router.POST("/api/v1/analysis", handler.AnalyzeFile)
 
 	// Mock the client to return an error
-	mockClient.On("AnalyzeFile", mock.Anything, "file123", true).Return(
+	mockClient.On("AnalyzeFile", mock.Anything, "file123", true, &pb.WordCloudOptions{}).Return(
 		&pb.AnalyzeFileResponse{},
 		errors.New("analysis error"),
 	)
@@ -172,7 +438,7 @@ func TestGetWordCloud_Success(t *testing.T) {
 	imageData := []byte("fake-image-data")
 
 	// Mock the client response
-	mockClient.On("GetWordCloud", mock.Anything, "file123.png").Return(imageData, nil)
+	mockClient.On("GetWordCloud", mock.Anything, "file123.png").Return(imageData, "png", nil)
 
 	// Create a test request
 	req, _ := http.NewRequest("GET", "/api/v1/wordcloud/file123.png", nil)
@@ -189,6 +455,128 @@ func TestGetWordCloud_Success(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestGetWordCloud_Negotiation(t *testing.T) {
+	notFound := status.Error(codes.NotFound, "word cloud not found")
+
+	testCases := []struct {
+		name          string
+		location      string
+		format        string
+		query         string
+		accept        string
+		otherLocation string
+		otherErr      error
+		expectedCode  int
+		expectedType  string
+	}{
+		{"SVG without preferences", "file123.svg", "svg", "", "", "", nil, http.StatusOK, "image/svg+xml"},
+		{"SVG accepted", "file123.svg", "svg", "", "image/svg+xml", "", nil, http.StatusOK, "image/svg+xml"},
+		{"Any image accepted", "file123.svg", "svg", "", "image/*", "", nil, http.StatusOK, "image/svg+xml"},
+		{"Anything accepted", "file123.png", "png", "", "text/html, */*;q=0.8", "", nil, http.StatusOK, "image/png"},
+		{"PNG accepted", "file123.svg", "svg", "", "image/png", "file123.png", nil, http.StatusOK, "image/png"},
+		{"No format accepted", "file123.svg", "svg", "", "text/html", "", nil, http.StatusNotAcceptable, ""},
+		{"Format parameter", "file123.svg", "svg", "?format=svg", "image/png", "", nil, http.StatusOK, "image/svg+xml"},
+		{"Other format requested", "file123.png", "png", "?format=svg", "", "file123.svg", nil, http.StatusOK, "image/svg+xml"},
+		{"Other format not stored", "file123.png", "png", "?format=svg", "", "file123.svg", notFound, http.StatusNotAcceptable, ""},
+		{"Format not recorded", "file123", "", "?format=svg", "", "file123.svg", nil, http.StatusOK, "image/svg+xml"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockClient := new(MockFileAnalysisClient)
+			handler := NewAnalysisHandler(mockClient)
+
+			// Create a test server
+			router := gin.Default()
+			router.GET("/api/v1/wordcloud/:location", handler.GetWordCloud)
+
+			// Mock the client response
+			imageData := []byte("fake-image-data")
+			mockClient.On("GetWordCloud", mock.Anything, tc.location).Return(imageData, tc.format, nil)
+			if tc.otherLocation != "" {
+				imageData = []byte("fake-other-image-data")
+				mockClient.On("GetWordCloud", mock.Anything, tc.otherLocation).Return(imageData, "", tc.otherErr)
+			}
+
+			// Create a test request
+			req, _ := http.NewRequest("GET", "/api/v1/wordcloud/"+tc.location+tc.query, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			resp := httptest.NewRecorder()
+
+			// Perform the request
+			router.ServeHTTP(resp, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedCode, resp.Code)
+			assert.Equal(t, "Accept", resp.Header().Get("Vary"))
+			if tc.expectedCode == http.StatusOK {
+				assert.Equal(t, tc.expectedType, resp.Header().Get("Content-Type"))
+				assert.Equal(t, imageData, resp.Body.Bytes())
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetWordCloud_InvalidFormat(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/wordcloud/:location", handler.GetWordCloud)
+
+	// Create a test request with an unsupported format
+	req, _ := http.NewRequest("GET", "/api/v1/wordcloud/file123.png?format=gif", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	mockClient.AssertNotCalled(t, "GetWordCloud")
+}
+
+func TestGetWordCloud_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"Invalid location", status.Error(codes.InvalidArgument, `invalid location: "..\\secret.png"`), http.StatusBadRequest},
+		{"Not found", status.Error(codes.NotFound, "word cloud ..\\secret.png not found"), http.StatusNotFound},
+		{"Internal error", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			mockClient := new(MockFileAnalysisClient)
+			handler := NewAnalysisHandler(mockClient)
+
+			router := gin.Default()
+			router.GET("/api/v1/wordcloud/:location", handler.GetWordCloud)
+
+			mockClient.On("GetWordCloud", mock.Anything, "..\\secret.png").
+				Return([]byte(nil), "", fmt.Errorf("failed to get word cloud: %w", tc.err))
+
+			req, _ := http.NewRequest("GET", "/api/v1/wordcloud/..%5Csecret.png", nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.status, resp.Code)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
 func TestGetWordCloud_MissingLocation(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -222,7 +610,7 @@ func TestGetWordCloud_ClientError(t *testing.T) {
 	router.GET("/api/v1/wordcloud/:location", handler.GetWordCloud)
 
 	// Mock the client to return an error
-	mockClient.On("GetWordCloud", mock.Anything, "file123.png").Return([]byte(nil), errors.New("get wordcloud error"))
+	mockClient.On("GetWordCloud", mock.Anything, "file123.png").Return([]byte(nil), "", errors.New("get wordcloud error"))
 
 	// Create a test request
 	req, _ := http.NewRequest("GET", "/api/v1/wordcloud/file123.png", nil)
@@ -235,3 +623,736 @@ func TestGetWordCloud_ClientError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 	mockClient.AssertExpectations(t)
 }
+
+func TestAnalyzeFile_Async(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.POST("/api/v1/analysis", handler.AnalyzeFile)
+
+	// The analysis is queued instead of run
+	mockClient.On("SubmitAnalysis", mock.Anything, "file123", true, &pb.WordCloudOptions{Format: "svg"}, "").Return(
+		&pb.AnalysisJob{Id: "job123", FileId: "file123", Status: "queued", CreatedAt: "2024-05-01T12:00:00Z"},
+		nil,
+	)
+
+	jsonBody := []byte(`{"file_id": "file123", "generate_word_cloud": true, "word_cloud_format": "svg", "async": true}`)
+
+	// Create a test request
+	req, _ := http.NewRequest("POST", "/api/v1/analysis", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, "/api/v1/analysis/jobs/job123", resp.Header().Get("Location"))
+
+	var response AnalysisJobResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, AnalysisJobResponse{
+		JobID:     "job123",
+		FileID:    "file123",
+		Status:    "queued",
+		CreatedAt: "2024-05-01T12:00:00Z",
+	}, response)
+
+	mockClient.AssertExpectations(t)
+	mockClient.AssertNotCalled(t, "AnalyzeFile")
+}
+
+func TestAnalyzeFile_AsyncClientError(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.POST("/api/v1/analysis", handler.AnalyzeFile)
+
+	// Mock the client to return an error
+	mockClient.On("SubmitAnalysis", mock.Anything, "file123", false, &pb.WordCloudOptions{}, "").Return(
+		(*pb.AnalysisJob)(nil),
+		errors.New("database error"),
+	)
+
+	// Create a test request
+	req, _ := http.NewRequest("POST", "/api/v1/analysis", bytes.NewBufferString(`{"file_id": "file123", "async": true}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Empty(t, resp.Header().Get("Location"))
+	mockClient.AssertExpectations(t)
+}
+
+func TestAnalyzeFile_AsyncWebhookURL(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.POST("/api/v1/analysis", handler.AnalyzeFile)
+
+	// The webhook URL is passed with the job, rejected URLs are bad requests
+	mockClient.On("SubmitAnalysis", mock.Anything, "file123", false, &pb.WordCloudOptions{}, "https://lms.example.com/hooks").Return(
+		&pb.AnalysisJob{Id: "job123", FileId: "file123", Status: "queued"},
+		nil,
+	).Once()
+	mockClient.On("SubmitAnalysis", mock.Anything, "file456", false, &pb.WordCloudOptions{}, "https://lms.example.com/hooks").Return(
+		(*pb.AnalysisJob)(nil),
+		status.Error(codes.InvalidArgument, "webhook URLs of requests are disabled"),
+	).Once()
+
+	tests := []struct {
+		body     string
+		expected int
+	}{
+		{`{"file_id": "file123", "async": true, "webhook_url": "https://lms.example.com/hooks"}`, http.StatusAccepted},
+		{`{"file_id": "file456", "async": true, "webhook_url": "https://lms.example.com/hooks"}`, http.StatusBadRequest},
+		{`{"file_id": "file123", "webhook_url": "https://lms.example.com/hooks"}`, http.StatusBadRequest},
+		{`{"file_id": "file123", "async": true, "webhook_url": "not a url"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("POST", "/api/v1/analysis", bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, tt.expected, resp.Code, tt.body)
+	}
+
+	mockClient.AssertExpectations(t)
+	mockClient.AssertNotCalled(t, "AnalyzeFile")
+}
+
+func TestGetAnalysisEvents(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server with the routes sharing the prefix
+	router := gin.Default()
+	router.GET("/api/v1/analysis/jobs/:job_id", handler.GetAnalysisJob)
+	router.GET("/api/v1/analysis/:file_id/events", handler.GetAnalysisEvents)
+	router.GET("/api/v1/analysis/:file_id/matches/:similar_file_id", handler.GetMatchedPassages)
+
+	// Mock the client response
+	mockClient.On("WatchAnalysis", mock.Anything, "file123", mock.Anything).Return(
+		[]*pb.AnalysisEvent{
+			{FileId: "file123", Stage: "comparing", Current: 1, Total: 2, Time: "2024-05-01T12:00:02Z"},
+			{FileId: "file123", Stage: "done", Time: "2024-05-01T12:00:03Z"},
+		},
+		nil,
+	)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/analysis/file123/events", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "text/event-stream", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header().Get("Cache-Control"))
+	assert.Equal(t,
+		"event:progress\n"+
+			`data:{"file_id":"file123","stage":"comparing","current":1,"total":2,"time":"2024-05-01T12:00:02Z"}`+"\n\n"+
+			"event:progress\n"+
+			`data:{"file_id":"file123","stage":"done","current":0,"total":0,"time":"2024-05-01T12:00:03Z"}`+"\n\n",
+		resp.Body.String(),
+	)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetAnalysisEvents_ClientError(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/analysis/:file_id/events", handler.GetAnalysisEvents)
+
+	// Mock the client to fail after the first event
+	mockClient.On("WatchAnalysis", mock.Anything, "file123", mock.Anything).Return(
+		[]*pb.AnalysisEvent{{FileId: "file123", Stage: "queued"}},
+		errors.New("failed to watch analysis: connection lost"),
+	)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/analysis/file123/events", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert: the failure ends the stream with an error event
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"stage":"queued"`)
+	assert.True(t, strings.HasSuffix(resp.Body.String(),
+		"event:error\n"+`data:{"error":"failed to watch analysis: connection lost"}`+"\n\n"))
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetAnalysisJob_Done(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server with the routes sharing the prefix
+	router := gin.Default()
+	router.GET("/api/v1/analysis/jobs/:job_id", handler.GetAnalysisJob)
+	router.GET("/api/v1/analysis/:file_id/matches/:similar_file_id", handler.GetMatchedPassages)
+
+	// Mock the client response
+	mockClient.On("GetAnalysisJob", mock.Anything, "job123").Return(
+		&pb.AnalysisJob{
+			Id: "job123", FileId: "file123", Status: "done", Attempts: 1,
+			CreatedAt: "2024-05-01T12:00:00Z", StartedAt: "2024-05-01T12:00:01Z", FinishedAt: "2024-05-01T12:00:05Z",
+			Result: &pb.AnalyzeFileResponse{WordCount: 100, Language: "ru"},
+		},
+		nil,
+	)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/analysis/jobs/job123", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response AnalysisJobResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "done", response.Status)
+	assert.Equal(t, int32(1), response.Attempts)
+	assert.Equal(t, "2024-05-01T12:00:05Z", response.FinishedAt)
+	if assert.NotNil(t, response.Result) {
+		assert.Equal(t, int32(100), response.Result.WordCount)
+		assert.Equal(t, "ru", response.Result.Language)
+	}
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetAnalysisJob_Failed(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/analysis/jobs/:job_id", handler.GetAnalysisJob)
+
+	// Mock the client response
+	mockClient.On("GetAnalysisJob", mock.Anything, "job123").Return(
+		&pb.AnalysisJob{Id: "job123", FileId: "file123", Status: "failed", Error: "file not found"},
+		nil,
+	)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/analysis/jobs/job123", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "failed", response["status"])
+	assert.Equal(t, "file not found", response["error"])
+	assert.NotContains(t, response, "result")
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetAnalysisJob_NotFound(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/analysis/jobs/:job_id", handler.GetAnalysisJob)
+
+	// Mock the client response
+	mockClient.On("GetAnalysisJob", mock.Anything, "missing").Return(
+		(*pb.AnalysisJob)(nil),
+		fmt.Errorf("failed to get analysis job: %w", status.Error(codes.NotFound, "analysis job missing not found")),
+	)
+	mockClient.On("GetAnalysisJob", mock.Anything, "job123").Return(
+		(*pb.AnalysisJob)(nil),
+		errors.New("analysis service error"),
+	)
+
+	// A missing job is reported as not found
+	req, _ := http.NewRequest("GET", "/api/v1/analysis/jobs/missing", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	// Other errors are internal
+	req, _ = http.NewRequest("GET", "/api/v1/analysis/jobs/job123", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Contains(t, resp.Body.String(), "analysis service error")
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetMatchedPassages_Success(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/analysis/:file_id/matches/:similar_file_id", handler.GetMatchedPassages)
+
+	// Mock the client response
+	mockClient.On("GetMatchedPassages", mock.Anything, "file123", "file456").Return(
+		[]*pb.MatchedPassage{
+			{Start: 10, End: 50, SimilarStart: 0, SimilarEnd: 40, Text: "copied passage"},
+		},
+		nil,
+	)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/analysis/file123/matches/file456", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response MatchedPassagesResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, MatchedPassagesResponse{
+		FileID:        "file123",
+		SimilarFileID: "file456",
+		Passages: []MatchedPassage{
+			{Start: 10, End: 50, SimilarStart: 0, SimilarEnd: 40, Text: "copied passage"},
+		},
+	}, response)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetMatchedPassages_ClientError(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/analysis/:file_id/matches/:similar_file_id", handler.GetMatchedPassages)
+
+	// Mock the client response
+	mockClient.On("GetMatchedPassages", mock.Anything, "file123", "file456").Return(
+		[]*pb.MatchedPassage(nil),
+		errors.New("analysis service error"),
+	)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/analysis/file123/matches/file456", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Contains(t, resp.Body.String(), "analysis service error")
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetMatchedPassages_StatusErrors(t *testing.T) {
+	testCases := []struct {
+		name         string
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{"Invalid argument", status.Error(codes.InvalidArgument, "invalid file ID"), http.StatusBadRequest, "Invalid file ID"},
+		{"Not found", fmt.Errorf("failed to get matched passages: %w", status.Error(codes.NotFound, "file file456 not found")), http.StatusNotFound, "File not found"},
+		{"Unavailable", status.Error(codes.Unavailable, "connection refused"), http.StatusInternalServerError, "connection refused"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockClient := new(MockFileAnalysisClient)
+			handler := NewAnalysisHandler(mockClient)
+
+			// Create a test server
+			router := gin.Default()
+			router.GET("/api/v1/analysis/:file_id/matches/:similar_file_id", handler.GetMatchedPassages)
+
+			// Mock the client response
+			mockClient.On("GetMatchedPassages", mock.Anything, "file123", "file456").Return(
+				[]*pb.MatchedPassage(nil), tc.err,
+			)
+
+			// Create a test request
+			req, _ := http.NewRequest("GET", "/api/v1/analysis/file123/matches/file456", nil)
+			resp := httptest.NewRecorder()
+
+			// Perform the request
+			router.ServeHTTP(resp, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedCode, resp.Code)
+			assert.Contains(t, resp.Body.String(), tc.expectedBody)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetKeywords_Success(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/files/:file_id/keywords", handler.GetKeywords)
+
+	// Mock the client response
+	mockClient.On("GetKeywords", mock.Anything, "file123", int32(5)).Return(
+		[]*pb.Keyword{
+			{Word: "плагиата", Term: "плагиат", Count: 2, DocumentFrequency: 1, Score: 0.77},
+		},
+		nil,
+	)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/files/file123/keywords?limit=5", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response KeywordsResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, KeywordsResponse{
+		FileID: "file123",
+		Keywords: []Keyword{
+			{Word: "плагиата", Term: "плагиат", Count: 2, DocumentFrequency: 1, Score: 0.77},
+		},
+	}, response)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetKeywords_DefaultLimit(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/files/:file_id/keywords", handler.GetKeywords)
+
+	// Mock the client response
+	mockClient.On("GetKeywords", mock.Anything, "file123", int32(10)).Return([]*pb.Keyword{}, nil)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/files/file123/keywords", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"file_id": "file123", "keywords": []}`, resp.Body.String())
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetKeywords_InvalidLimit(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/files/:file_id/keywords", handler.GetKeywords)
+
+	for _, limit := range []string{"0", "101", "ten"} {
+		// Create a test request
+		req, _ := http.NewRequest("GET", "/api/v1/files/file123/keywords?limit="+limit, nil)
+		resp := httptest.NewRecorder()
+
+		// Perform the request
+		router.ServeHTTP(resp, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "limit=%s", limit)
+	}
+
+	mockClient.AssertNotCalled(t, "GetKeywords", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetKeywords_ClientError(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/files/:file_id/keywords", handler.GetKeywords)
+
+	// Mock the client response
+	mockClient.On("GetKeywords", mock.Anything, "file123", int32(10)).Return(
+		[]*pb.Keyword(nil),
+		errors.New("analysis result not found for file ID file123"),
+	)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/files/file123/keywords", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Contains(t, resp.Body.String(), "analysis result not found")
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetKeywords_NotFound(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/files/:file_id/keywords", handler.GetKeywords)
+
+	// Mock the client response
+	mockClient.On("GetKeywords", mock.Anything, "missing", int32(10)).Return(
+		[]*pb.Keyword(nil),
+		fmt.Errorf("failed to get keywords: %w", status.Error(codes.NotFound, "file missing not found")),
+	)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/files/missing/keywords", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Contains(t, resp.Body.String(), "File not found")
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetWordFrequencies_Success(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/files/:file_id/words", handler.GetWordFrequencies)
+
+	// Mock the client response
+	mockClient.On("GetWordFrequencies", mock.Anything, &pb.GetWordFrequenciesRequest{
+		FileId:        "file123",
+		Limit:         20,
+		Case:          "original",
+		KeepStopWords: true,
+		SeparateForms: true,
+	}).Return(
+		[]*pb.WordItem{
+			{Text: "Плагиат", Value: 5},
+			{Text: "и", Value: 4},
+		},
+		nil,
+	)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/files/file123/words?limit=20&case=original&keep_stop_words=true&separate_forms=1", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response WordFrequenciesResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, WordFrequenciesResponse{
+		FileID: "file123",
+		Words: []WordItem{
+			{Text: "Плагиат", Value: 5},
+			{Text: "и", Value: 4},
+		},
+	}, response)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetWordFrequencies_Defaults(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/files/:file_id/words", handler.GetWordFrequencies)
+
+	// Mock the client response
+	mockClient.On("GetWordFrequencies", mock.Anything, &pb.GetWordFrequenciesRequest{
+		FileId: "file123",
+		Limit:  100,
+	}).Return([]*pb.WordItem{}, nil)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/files/file123/words", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"file_id": "file123", "words": []}`, resp.Body.String())
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetWordFrequencies_InvalidQuery(t *testing.T) {
+	for _, query := range []string{"limit=0", "limit=1001", "limit=many", "case=title", "keep_stop_words=maybe", "separate_forms=2"} {
+		t.Run(query, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockClient := new(MockFileAnalysisClient)
+			handler := NewAnalysisHandler(mockClient)
+
+			// Create a test server
+			router := gin.Default()
+			router.GET("/api/v1/files/:file_id/words", handler.GetWordFrequencies)
+
+			// Create a test request
+			req, _ := http.NewRequest("GET", "/api/v1/files/file123/words?"+query, nil)
+			resp := httptest.NewRecorder()
+
+			// Perform the request
+			router.ServeHTTP(resp, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+			mockClient.AssertNotCalled(t, "GetWordFrequencies")
+		})
+	}
+}
+
+func TestGetWordFrequencies_ClientError(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/files/:file_id/words", handler.GetWordFrequencies)
+
+	// Mock the client to return an error
+	mockClient.On("GetWordFrequencies", mock.Anything, mock.Anything).Return([]*pb.WordItem(nil), errors.New("file not found"))
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/files/file123/words", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetWordFrequencies_NotFound(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileAnalysisClient)
+	handler := NewAnalysisHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/files/:file_id/words", handler.GetWordFrequencies)
+
+	// Mock the client to return an error
+	mockClient.On("GetWordFrequencies", mock.Anything, mock.Anything).Return(
+		[]*pb.WordItem(nil), status.Error(codes.NotFound, "file missing not found"),
+	)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/files/missing/words", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	mockClient.AssertExpectations(t)
+}
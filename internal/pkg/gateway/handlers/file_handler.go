@@ -2,17 +2,50 @@ package handlers
 
 import (
 	"context"
+	"crypto/sha256"
+	"errors"
 	"io"
 	"net/http"
+	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"local.dev/doc-analyzer/internal/pkg/gateway/archive"
+)
+
+// allowedExtensions are the extensions of the files the File Storing Service extracts the text of
+var allowedExtensions = map[string]bool{
+	".txt": true, ".md": true, ".docx": true, ".odt": true, ".pdf": true, ".rtf": true,
+}
+
+// allowedFilesMessage is the error of files of other extensions
+const allowedFilesMessage = "Only .txt, .md, .docx, .odt, .pdf and .rtf files are allowed"
+
+// maxFileSize is the maximum size of an uploaded file, the default limit of the File Storing Service
+const maxFileSize = 100 << 20
+
+// maxArchiveSize is the maximum size of an uploaded archive, the limits
+// of the extracted files are archive.DefaultLimits
+const maxArchiveSize = 50 << 20
+
+// Statuses of the files of an uploaded archive
+const (
+	BatchEntryUploaded  = "uploaded"
+	BatchEntryDuplicate = "duplicate"
+	BatchEntryRejected  = "rejected"
+	BatchEntryFailed    = "failed"
 )
 
 // FileStoringClientInterface defines the interface for the File Storing Client
 type FileStoringClientInterface interface {
 	UploadFile(ctx context.Context, fileName string, content []byte) (string, error)
-	GetFile(ctx context.Context, fileID string) (string, []byte, error)
+	UploadFileStream(ctx context.Context, fileName string, content io.Reader) (string, error)
+	GetFileStream(ctx context.Context, fileID string, original bool) (string, io.ReadCloser, error)
 	Close() error
 }
 
@@ -28,46 +61,180 @@ func NewFileHandler(client FileStoringClientInterface) *FileHandler {
 
 // UploadFile godoc
 // @Summary Upload a file
-// @Description Upload a file to the storage
+// @Description Upload a file to the storage, documents (.md, .docx, .odt, .pdf, .rtf) are stored with their extracted
+// @Description plain text, which is what the file is analyzed by
+// @Description The file is streamed to the storage, its size is not limited to a gRPC message; files of more than 100 MiB are refused
 // @Tags files
 // @Accept multipart/form-data
 // @Produce json
-// @Param file formData file true "File to upload"
+// @Param file formData file true "File to upload (.txt, .md, .docx, .odt, .pdf or .rtf)"
 // @Success 200 {object} map[string]string "Returns the file ID"
 // @Failure 400 {object} map[string]string "Bad request"
+// @Failure 413 {object} map[string]string "File too large"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/v1/files [post]
 func (h *FileHandler) UploadFile(c *gin.Context) {
-	file, header, err := c.Request.FormFile("file")
+	// Leave room for the multipart headers, the storage checks the size of the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxFileSize+(1<<20))
+
+	// The file is piped from the request body to the storage, not buffered
+	fileName, file, err := formFile(c.Request, "file")
 	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No file provided"})
 		return
 	}
-	defer file.Close()
 
-	if filepath.Ext(header.Filename) != ".txt" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Only .txt files are allowed"})
+	if !isAllowedFile(fileName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": allowedFilesMessage})
 		return
 	}
 
-	content, err := io.ReadAll(file)
+	fileID, err := h.client.UploadFileStream(c.Request.Context(), fileName, file)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) || status.Code(err) == codes.ResourceExhausted {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+			return
+		}
+		// The text of invalid documents cannot be extracted
+		if status.Code(err) == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	fileID, err := h.client.UploadFile(c.Request.Context(), header.Filename, content)
+	c.JSON(http.StatusOK, gin.H{"file_id": fileID})
+}
+
+// BatchEntryResponse represents a file of an uploaded archive
+type BatchEntryResponse struct {
+	// Name is the path of the file in the archive
+	Name   string `json:"name" example:"essays/ivanov.txt"`
+	Status string `json:"status" example:"uploaded" enums:"uploaded,duplicate,rejected,failed"`
+	FileID string `json:"file_id,omitempty" example:"file123"`
+
+	// DuplicateOf is the name of the earlier file of the archive with the same content
+	DuplicateOf string `json:"duplicate_of,omitempty" example:""`
+
+	// Error is the reason a file was rejected or failed to upload
+	Error string `json:"error,omitempty" example:""`
+}
+
+// BatchUploadResponse represents the manifest of an uploaded archive
+type BatchUploadResponse struct {
+	Files      []BatchEntryResponse `json:"files"`
+	Uploaded   int                  `json:"uploaded" example:"1"`
+	Duplicates int                  `json:"duplicates" example:"0"`
+	Rejected   int                  `json:"rejected" example:"0"`
+	Failed     int                  `json:"failed" example:"0"`
+}
+
+// BatchUploadFile godoc
+// @Summary Upload an archive of files
+// @Description Upload the files of a ZIP or tar.gz archive to the storage, like POST /api/v1/files does
+// @Description Each file is reported in the manifest: uploaded with its file ID, duplicate of an earlier file
+// @Description of the archive, rejected (not an allowed file, invalid or too large) or failed to upload
+// @Description Archives of more than 50 MiB, 1000 files or 100 MiB extracted are refused, files of more than 2 MiB are rejected
+// @Tags files
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "ZIP or tar.gz archive"
+// @Success 200 {object} BatchUploadResponse "Manifest of the files of the archive"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 413 {object} map[string]string "Archive too large"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/v1/files/batch [post]
+func (h *FileHandler) BatchUploadFile(c *gin.Context) {
+	// Leave room for the multipart headers, the size of the archive is checked below
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxArchiveSize+(1<<20))
+
+	file, header, err := c.Request.FormFile("file")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Archive is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file provided"})
 		return
 	}
+	defer file.Close()
 
-	c.JSON(http.StatusOK, gin.H{"file_id": fileID})
+	if header.Size > maxArchiveSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Archive is too large"})
+		return
+	}
+
+	entries, err := archive.Extract(file, header.Size, archive.DefaultLimits)
+	if err != nil {
+		switch {
+		case errors.Is(err, archive.ErrTooManyEntries):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Archive has more than " + strconv.Itoa(archive.DefaultLimits.MaxEntries) + " files"})
+		case errors.Is(err, archive.ErrTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Archive is too large when extracted"})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	response := BatchUploadResponse{Files: make([]BatchEntryResponse, 0, len(entries))}
+	uploaded := make(map[[sha256.Size]byte]BatchEntryResponse)
+	for _, entry := range entries {
+		result := BatchEntryResponse{Name: entry.Name}
+		hash := sha256.Sum256(entry.Content)
+		original, duplicate := uploaded[hash]
+
+		switch {
+		case entry.Err != nil:
+			result.Status = BatchEntryRejected
+			result.Error = entry.Err.Error()
+			response.Rejected++
+		case !isAllowedFile(entry.Name):
+			result.Status = BatchEntryRejected
+			result.Error = allowedFilesMessage
+			response.Rejected++
+		case duplicate:
+			result.Status = BatchEntryDuplicate
+			result.FileID = original.FileID
+			result.DuplicateOf = original.Name
+			response.Duplicates++
+		default:
+			fileID, err := h.client.UploadFile(c.Request.Context(), path.Base(entry.Name), entry.Content)
+			if status.Code(err) == codes.InvalidArgument {
+				result.Status = BatchEntryRejected
+				result.Error = err.Error()
+				response.Rejected++
+				break
+			}
+			if err != nil {
+				result.Status = BatchEntryFailed
+				result.Error = err.Error()
+				response.Failed++
+				break
+			}
+			result.Status = BatchEntryUploaded
+			result.FileID = fileID
+			uploaded[hash] = result
+			response.Uploaded++
+		}
+
+		response.Files = append(response.Files, result)
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // GetFile godoc
 // @Summary Get a file
-// @Description Get a file by its ID
+// @Description Get a file as uploaded by its ID
 // @Tags files
 // @Produce octet-stream
 // @Param file_id path string true "File ID"
@@ -83,12 +250,64 @@ func (h *FileHandler) GetFile(c *gin.Context) {
 		return
 	}
 
-	fileName, content, err := h.client.GetFile(c.Request.Context(), fileID)
+	fileName, content, err := h.client.GetFileStream(c.Request.Context(), fileID, true)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	defer content.Close()
 
 	c.Header("Content-Disposition", "attachment; filename="+fileName)
-	c.Data(http.StatusOK, "application/octet-stream", content)
+	c.DataFromReader(http.StatusOK, -1, "application/octet-stream", content, nil)
+}
+
+// GetFileText godoc
+// @Summary Get the text of a file
+// @Description Get the plain text extracted from a file by its ID, the text the file is analyzed by
+// @Tags files
+// @Produce plain
+// @Param file_id path string true "File ID"
+// @Success 200 {string} string "Plain text of the file"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/v1/files/{file_id}/text [get]
+func (h *FileHandler) GetFileText(c *gin.Context) {
+	fileID := c.Param("file_id")
+	if fileID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File ID is required"})
+		return
+	}
+
+	_, content, err := h.client.GetFileStream(c.Request.Context(), fileID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, -1, "text/plain; charset=utf-8", content, nil)
+}
+
+// formFile finds the file of a field of a multipart form, the file is read
+// from the request body as it comes instead of being buffered like by FormFile
+func formFile(r *http.Request, field string) (string, io.Reader, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return "", nil, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return "", nil, err
+		}
+		if part.FormName() == field && part.FileName() != "" {
+			return part.FileName(), part, nil
+		}
+	}
+}
+
+// isAllowedFile reports whether the text of a file can be extracted, by its extension
+func isAllowedFile(fileName string) bool {
+	return allowedExtensions[strings.ToLower(filepath.Ext(fileName))]
 }
@@ -1,17 +1,26 @@
 package handlers
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Mock FileStoringClient
@@ -24,9 +33,17 @@ func (m *MockFileStoringClient) UploadFile(ctx context.Context, fileName string,
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockFileStoringClient) GetFile(ctx context.Context, fileID string) (string, []byte, error) {
-	args := m.Called(ctx, fileID)
-	return args.String(0), args.Get(1).([]byte), args.Error(2)
+func (m *MockFileStoringClient) UploadFileStream(ctx context.Context, fileName string, content io.Reader) (string, error) {
+	args := m.Called(ctx, fileName, content)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockFileStoringClient) GetFileStream(ctx context.Context, fileID string, original bool) (string, io.ReadCloser, error) {
+	args := m.Called(ctx, fileID, original)
+	if args.Get(1) == nil {
+		return args.String(0), nil, args.Error(2)
+	}
+	return args.String(0), args.Get(1).(io.ReadCloser), args.Error(2)
 }
 
 func (m *MockFileStoringClient) Close() error {
@@ -52,7 +69,10 @@ func TestUploadFile_ValidTxtFile(t *testing.T) {
 	writer.Close()
 
 	// Mock the client response
-	mockClient.On("UploadFile", mock.Anything, "test.txt", mock.Anything).Return("file123", nil)
+	mockClient.On("UploadFileStream", mock.Anything, "test.txt", mock.Anything).Return("file123", nil).Run(func(args mock.Arguments) {
+		content, _ := io.ReadAll(args.Get(2).(io.Reader))
+		assert.Equal(t, "test content", string(content))
+	})
 
 	// Create a test request
 	req, _ := http.NewRequest("POST", "/api/v1/files", body)
@@ -77,10 +97,10 @@ func TestUploadFile_InvalidFileExtension(t *testing.T) {
 	router := gin.Default()
 	router.POST("/api/v1/files", handler.UploadFile)
 
-	// Create a multipart form with a non-.txt file
+	// Create a multipart form with a file of a format the text cannot be extracted from
 	body := new(bytes.Buffer)
 	writer := multipart.NewWriter(body)
-	part, _ := writer.CreateFormFile("file", "test.pdf")
+	part, _ := writer.CreateFormFile("file", "test.doc")
 	part.Write([]byte("test content"))
 	writer.Close()
 
@@ -95,7 +115,47 @@ func TestUploadFile_InvalidFileExtension(t *testing.T) {
 	// Assert
 	assert.Equal(t, http.StatusBadRequest, resp.Code)
 	// The client should not be called because the file extension is invalid
-	mockClient.AssertNotCalled(t, "UploadFile")
+	mockClient.AssertNotCalled(t, "UploadFileStream")
+}
+
+func TestUploadFile_Documents(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileStoringClient)
+	handler := NewFileHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.POST("/api/v1/files", handler.UploadFile)
+
+	// Mock the client responses, the storage refuses documents it cannot extract the text of
+	mockClient.On("UploadFileStream", mock.Anything, "essay.DOCX", mock.Anything).Return("file123", nil)
+	mockClient.On("UploadFileStream", mock.Anything, "broken.pdf", mock.Anything).Return("", status.Error(codes.InvalidArgument, "invalid document"))
+
+	tests := []struct {
+		fileName string
+		expected int
+	}{
+		{"essay.DOCX", http.StatusOK},
+		{"broken.pdf", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", tt.fileName)
+		part.Write([]byte("content"))
+		writer.Close()
+
+		req, _ := http.NewRequest("POST", "/api/v1/files", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, tt.expected, resp.Code, tt.fileName)
+	}
+
+	mockClient.AssertExpectations(t)
 }
 
 func TestUploadFile_NoFileProvided(t *testing.T) {
@@ -119,7 +179,7 @@ func TestUploadFile_NoFileProvided(t *testing.T) {
 	// Assert
 	assert.Equal(t, http.StatusBadRequest, resp.Code)
 	// The client should not be called because no file was provided
-	mockClient.AssertNotCalled(t, "UploadFile")
+	mockClient.AssertNotCalled(t, "UploadFileStream")
 }
 
 func TestUploadFile_ClientError(t *testing.T) {
@@ -140,7 +200,7 @@ func TestUploadFile_ClientError(t *testing.T) {
 	writer.Close()
 
 	// Mock the client to return an error
-	mockClient.On("UploadFile", mock.Anything, "test.txt", mock.Anything).Return("", errors.New("upload error"))
+	mockClient.On("UploadFileStream", mock.Anything, "test.txt", mock.Anything).Return("", errors.New("upload error"))
 
 	// Create a test request
 	req, _ := http.NewRequest("POST", "/api/v1/files", body)
@@ -155,6 +215,237 @@ func TestUploadFile_ClientError(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+// repeatReader endlessly reads its byte
+type repeatReader byte
+
+func (r repeatReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r)
+	}
+	return len(p), nil
+}
+
+func TestUploadFile_TooLarge(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileStoringClient)
+	handler := NewFileHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.POST("/api/v1/files", handler.UploadFile)
+
+	// The request body is cut at the limit, the file is streamed to avoid holding it in memory
+	body, pipeWriter := io.Pipe()
+	defer body.Close()
+	writer := multipart.NewWriter(pipeWriter)
+	go func() {
+		part, _ := writer.CreateFormFile("file", "test.txt")
+		io.Copy(part, io.LimitReader(repeatReader('a'), maxFileSize+(2<<20)))
+		writer.Close()
+		pipeWriter.Close()
+	}()
+
+	tooLarge := fmt.Errorf("failed to read file: %w", &http.MaxBytesError{Limit: maxFileSize + (1 << 20)})
+	mockClient.On("UploadFileStream", mock.Anything, "test.txt", mock.Anything).Return("", tooLarge).Run(func(args mock.Arguments) {
+		_, err := io.Copy(io.Discard, args.Get(2).(io.Reader))
+		var maxBytesErr *http.MaxBytesError
+		assert.ErrorAs(t, err, &maxBytesErr)
+	}).Once()
+
+	req, _ := http.NewRequest("POST", "/api/v1/files", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+
+	// The storage refuses files above its own limit
+	mockClient.On("UploadFileStream", mock.Anything, "small.txt", mock.Anything).
+		Return("", status.Error(codes.ResourceExhausted, "file is too large")).Once()
+
+	smallBody := new(bytes.Buffer)
+	smallWriter := multipart.NewWriter(smallBody)
+	part, _ := smallWriter.CreateFormFile("file", "small.txt")
+	part.Write([]byte("test content"))
+	smallWriter.Close()
+
+	req, _ = http.NewRequest("POST", "/api/v1/files", smallBody)
+	req.Header.Set("Content-Type", smallWriter.FormDataContentType())
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+	mockClient.AssertExpectations(t)
+}
+
+// newBatchUploadRequest creates a request uploading the archive
+func newBatchUploadRequest(fileName string, archive []byte) *http.Request {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", fileName)
+	part.Write(archive)
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", "/api/v1/files/batch", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+// newZip creates a ZIP archive of the files, given as name and content pairs
+func newZip(files ...string) []byte {
+	buf := new(bytes.Buffer)
+	writer := zip.NewWriter(buf)
+	for i := 0; i < len(files); i += 2 {
+		w, _ := writer.Create(files[i])
+		w.Write([]byte(files[i+1]))
+	}
+	writer.Close()
+	return buf.Bytes()
+}
+
+func TestBatchUploadFile_Zip(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileStoringClient)
+	handler := NewFileHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.POST("/api/v1/files/batch", handler.BatchUploadFile)
+
+	// Mock the client responses
+	mockClient.On("UploadFile", mock.Anything, "first.txt", []byte("first essay")).Return("file1", nil)
+	mockClient.On("UploadFile", mock.Anything, "second.TXT", []byte("second essay")).Return("", errors.New("upload error"))
+	mockClient.On("UploadFile", mock.Anything, "broken.pdf", []byte("not a PDF")).Return("", status.Error(codes.InvalidArgument, "invalid document"))
+
+	archive := newZip(
+		"essays/first.txt", "first essay",
+		"essays/copy.txt", "first essay",
+		"essays/second.TXT", "second essay",
+		"essays/report.doc", "binary",
+		"essays/broken.pdf", "not a PDF",
+	)
+
+	// Perform the request
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newBatchUploadRequest("essays.zip", archive))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response BatchUploadResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, BatchUploadResponse{
+		Files: []BatchEntryResponse{
+			{Name: "essays/first.txt", Status: BatchEntryUploaded, FileID: "file1"},
+			{Name: "essays/copy.txt", Status: BatchEntryDuplicate, FileID: "file1", DuplicateOf: "essays/first.txt"},
+			{Name: "essays/second.TXT", Status: BatchEntryFailed, Error: "upload error"},
+			{Name: "essays/report.doc", Status: BatchEntryRejected, Error: allowedFilesMessage},
+			{Name: "essays/broken.pdf", Status: BatchEntryRejected, Error: "rpc error: code = InvalidArgument desc = invalid document"},
+		},
+		Uploaded: 1, Duplicates: 1, Rejected: 2, Failed: 1,
+	}, response)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestBatchUploadFile_TarGz(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileStoringClient)
+	handler := NewFileHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.POST("/api/v1/files/batch", handler.BatchUploadFile)
+
+	// Mock the client response
+	mockClient.On("UploadFile", mock.Anything, "essay.txt", []byte("essay")).Return("file1", nil)
+
+	buf := new(bytes.Buffer)
+	compressed := gzip.NewWriter(buf)
+	writer := tar.NewWriter(compressed)
+	writer.WriteHeader(&tar.Header{Name: "essay.txt", Mode: 0o644, Size: 5, Typeflag: tar.TypeReg})
+	writer.Write([]byte("essay"))
+	writer.Close()
+	compressed.Close()
+
+	// Perform the request
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newBatchUploadRequest("essays.tar.gz", buf.Bytes()))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response BatchUploadResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, []BatchEntryResponse{{Name: "essay.txt", Status: BatchEntryUploaded, FileID: "file1"}}, response.Files)
+	assert.Equal(t, 1, response.Uploaded)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestBatchUploadFile_InvalidArchive(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileStoringClient)
+	handler := NewFileHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.POST("/api/v1/files/batch", handler.BatchUploadFile)
+
+	tests := map[string][]byte{
+		"essay.txt":  []byte("not an archive"),
+		"broken.zip": []byte("PK\x03\x04 truncated"),
+	}
+	for fileName, archive := range tests {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, newBatchUploadRequest(fileName, archive))
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code, fileName)
+	}
+
+	// No file provided
+	req, _ := http.NewRequest("POST", "/api/v1/files/batch", nil)
+	req.Header.Set("Content-Type", "multipart/form-data")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	mockClient.AssertNotCalled(t, "UploadFile")
+}
+
+func TestBatchUploadFile_TooLarge(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileStoringClient)
+	handler := NewFileHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.POST("/api/v1/files/batch", handler.BatchUploadFile)
+
+	// Too many files
+	var files []string
+	for i := 0; i <= 1000; i++ {
+		files = append(files, fmt.Sprintf("%d.txt", i), "")
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, newBatchUploadRequest("essays.zip", newZip(files...)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+
+	// Archive too large
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, newBatchUploadRequest("essays.zip", make([]byte, maxArchiveSize+(2<<20))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+
+	mockClient.AssertNotCalled(t, "UploadFile")
+}
+
 func TestGetFile_Success(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -170,7 +461,7 @@ func TestGetFile_Success(t *testing.T) {
 	fileContent := []byte("test content")
 
 	// Mock the client response
-	mockClient.On("GetFile", mock.Anything, "file123").Return(fileName, fileContent, nil)
+	mockClient.On("GetFileStream", mock.Anything, "file123", true).Return(fileName, io.NopCloser(bytes.NewReader(fileContent)), nil)
 
 	// Create a test request
 	req, _ := http.NewRequest("GET", "/api/v1/files/file123", nil)
@@ -207,7 +498,7 @@ func TestGetFile_MissingFileID(t *testing.T) {
 
 	// Assert
 	assert.Equal(t, http.StatusNotFound, resp.Code) // Gin returns 404 for missing path parameters
-	mockClient.AssertNotCalled(t, "GetFile")
+	mockClient.AssertNotCalled(t, "GetFileStream")
 }
 
 func TestGetFile_ClientError(t *testing.T) {
@@ -221,7 +512,7 @@ func TestGetFile_ClientError(t *testing.T) {
 	router.GET("/api/v1/files/:file_id", handler.GetFile)
 
 	// Mock the client to return an error
-	mockClient.On("GetFile", mock.Anything, "file123").Return("", []byte(nil), errors.New("get file error"))
+	mockClient.On("GetFileStream", mock.Anything, "file123", true).Return("", nil, errors.New("get file error"))
 
 	// Create a test request
 	req, _ := http.NewRequest("GET", "/api/v1/files/file123", nil)
@@ -234,3 +525,35 @@ func TestGetFile_ClientError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 	mockClient.AssertExpectations(t)
 }
+
+func TestGetFileText(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockClient := new(MockFileStoringClient)
+	handler := NewFileHandler(mockClient)
+
+	// Create a test server
+	router := gin.Default()
+	router.GET("/api/v1/files/:file_id/text", handler.GetFileText)
+
+	// Mock the client responses
+	mockClient.On("GetFileStream", mock.Anything, "file123", false).Return("essay.docx", io.NopCloser(strings.NewReader("Текст работы")), nil)
+	mockClient.On("GetFileStream", mock.Anything, "file456", false).Return("", nil, errors.New("get file error"))
+
+	// Perform the requests
+	req, _ := http.NewRequest("GET", "/api/v1/files/file123/text", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "Текст работы", resp.Body.String())
+
+	req, _ = http.NewRequest("GET", "/api/v1/files/file456/text", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	mockClient.AssertExpectations(t)
+}
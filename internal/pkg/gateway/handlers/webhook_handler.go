@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "local.dev/doc-analyzer/internal/proto/analyzer"
+)
+
+// Limits of the number of webhook deliveries returned
+const (
+	defaultDeliveriesLimit = 100
+	maxDeliveriesLimit     = 1000
+)
+
+// WebhookHandler handles webhook registrations and their delivery log
+type WebhookHandler struct {
+	client FileAnalysisClientInterface
+}
+
+// NewWebhookHandler creates a new WebhookHandler instance
+func NewWebhookHandler(client FileAnalysisClientInterface) *WebhookHandler {
+	return &WebhookHandler{client: client}
+}
+
+// CreateWebhookRequest represents the request body for a webhook registration
+type CreateWebhookRequest struct {
+	URL string `json:"url" binding:"required,url" example:"https://lms.example.com/hooks/analysis"`
+
+	// Secret signs the requests, a random one is generated if it is empty
+	Secret string `json:"secret" example:""`
+
+	// Events the webhook is notified of, all events if empty
+	Events []string `json:"events" binding:"omitempty,dive,oneof=analysis.completed analysis.failed plagiarism.detected" example:"analysis.completed,plagiarism.detected"`
+}
+
+// WebhookResponse represents a registered webhook
+// The secret is only returned on registration
+type WebhookResponse struct {
+	WebhookID string   `json:"webhook_id" example:"5b0e3c1a-8d2f-4e7a-9c6b-1a2b3c4d5e6f"`
+	URL       string   `json:"url" example:"https://lms.example.com/hooks/analysis"`
+	Events    []string `json:"events" example:"analysis.completed,plagiarism.detected"`
+	Secret    string   `json:"secret,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	CreatedAt string   `json:"created_at" example:"2024-05-01T12:00:00Z"`
+}
+
+// WebhooksResponse represents the registered webhooks
+type WebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+// WebhookDeliveryResponse represents a delivery of an event to a webhook
+// Times are in RFC 3339, empty until the event happens
+type WebhookDeliveryResponse struct {
+	DeliveryID string `json:"delivery_id" example:"0c9d8e7f-6a5b-4c3d-2e1f-0a9b8c7d6e5f"`
+
+	// WebhookID is empty for the webhook URL of an analysis request
+	WebhookID      string `json:"webhook_id" example:"5b0e3c1a-8d2f-4e7a-9c6b-1a2b3c4d5e6f"`
+	URL            string `json:"url" example:"https://lms.example.com/hooks/analysis"`
+	Event          string `json:"event" example:"analysis.completed"`
+	FileID         string `json:"file_id" example:"file123"`
+	Status         string `json:"status" example:"delivered" enums:"pending,delivered,failed"`
+	Attempts       int32  `json:"attempts" example:"1"`
+	ResponseStatus int32  `json:"response_status" example:"200"`
+	Error          string `json:"error,omitempty" example:""`
+	CreatedAt      string `json:"created_at" example:"2024-05-01T12:00:05Z"`
+	NextAttemptAt  string `json:"next_attempt_at,omitempty" example:""`
+	LastAttemptAt  string `json:"last_attempt_at,omitempty" example:"2024-05-01T12:00:06Z"`
+}
+
+// WebhookDeliveriesResponse represents the webhook delivery log
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
+
+// CreateWebhook godoc
+// @Summary Register a webhook
+// @Description Register a URL notified of finished analyses with a signed JSON POST request
+// @Description The X-Webhook-Signature header holds "sha256=" and the hex HMAC-SHA256 of the body with the secret
+// @Description Failed deliveries are retried with exponential backoff
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body CreateWebhookRequest true "Webhook registration"
+// @Success 201 {object} WebhookResponse "Registered webhook with its secret"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/v1/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var request CreateWebhookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.client.CreateWebhook(c.Request.Context(), request.URL, request.Secret, request.Events)
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toWebhookResponse(webhook))
+}
+
+// ListWebhooks godoc
+// @Summary List webhooks
+// @Description List the registered webhooks without their secrets
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} WebhooksResponse "Registered webhooks"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/v1/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	resp, err := h.client.ListWebhooks(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhooks := make([]WebhookResponse, 0, len(resp.Webhooks))
+	for _, webhook := range resp.Webhooks {
+		webhooks = append(webhooks, toWebhookResponse(webhook))
+	}
+
+	c.JSON(http.StatusOK, WebhooksResponse{Webhooks: webhooks})
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook
+// @Description Delete a registered webhook, its pending deliveries are not sent
+// @Tags webhooks
+// @Param webhook_id path string true "Webhook ID"
+// @Success 204 "Webhook deleted"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Webhook not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/v1/webhooks/{webhook_id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	webhookID := c.Param("webhook_id")
+	if webhookID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Webhook ID is required"})
+		return
+	}
+
+	if err := h.client.DeleteWebhook(c.Request.Context(), webhookID); err != nil {
+		if status.Code(err) == codes.NotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries godoc
+// @Summary List webhook deliveries
+// @Description List the deliveries of events to webhooks, newest first
+// @Tags webhooks
+// @Produce json
+// @Param webhook_id query string false "Only the deliveries of the webhook"
+// @Param file_id query string false "Only the deliveries of events of the file"
+// @Param limit query int false "Maximum number of deliveries (1-1000)" default(100)
+// @Success 200 {object} WebhookDeliveriesResponse "Webhook deliveries, newest first"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/v1/webhooks/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	limit := defaultDeliveriesLimit
+	if value := c.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxDeliveriesLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Limit must be an integer from 1 to " + strconv.Itoa(maxDeliveriesLimit)})
+			return
+		}
+	}
+
+	resp, err := h.client.ListWebhookDeliveries(c.Request.Context(), c.Query("webhook_id"), c.Query("file_id"), int32(limit))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	deliveries := make([]WebhookDeliveryResponse, 0, len(resp.Deliveries))
+	for _, delivery := range resp.Deliveries {
+		deliveries = append(deliveries, WebhookDeliveryResponse{
+			DeliveryID:     delivery.Id,
+			WebhookID:      delivery.WebhookId,
+			URL:            delivery.Url,
+			Event:          delivery.Event,
+			FileID:         delivery.FileId,
+			Status:         delivery.Status,
+			Attempts:       delivery.Attempts,
+			ResponseStatus: delivery.ResponseStatus,
+			Error:          delivery.Error,
+			CreatedAt:      delivery.CreatedAt,
+			NextAttemptAt:  delivery.NextAttemptAt,
+			LastAttemptAt:  delivery.LastAttemptAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, WebhookDeliveriesResponse{Deliveries: deliveries})
+}
+
+// toWebhookResponse converts a webhook from its protobuf representation
+func toWebhookResponse(webhook *pb.Webhook) WebhookResponse {
+	return WebhookResponse{
+		WebhookID: webhook.Id,
+		URL:       webhook.Url,
+		Events:    webhook.Events,
+		Secret:    webhook.Secret,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
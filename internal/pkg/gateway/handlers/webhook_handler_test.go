@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "local.dev/doc-analyzer/internal/proto/analyzer"
+)
+
+// newWebhookRouter creates a test server with the webhook routes
+func newWebhookRouter(mockClient *MockFileAnalysisClient) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewWebhookHandler(mockClient)
+
+	router := gin.Default()
+	router.POST("/api/v1/webhooks", handler.CreateWebhook)
+	router.GET("/api/v1/webhooks", handler.ListWebhooks)
+	router.GET("/api/v1/webhooks/deliveries", handler.ListWebhookDeliveries)
+	router.DELETE("/api/v1/webhooks/:webhook_id", handler.DeleteWebhook)
+	return router
+}
+
+func TestCreateWebhook(t *testing.T) {
+	// Setup
+	mockClient := new(MockFileAnalysisClient)
+	router := newWebhookRouter(mockClient)
+
+	// Mock the client response
+	mockClient.On("CreateWebhook", mock.Anything, "https://lms.example.com/hooks", "", []string{"plagiarism.detected"}).Return(
+		&pb.Webhook{
+			Id: "hook123", Url: "https://lms.example.com/hooks", Events: []string{"plagiarism.detected"},
+			Secret: "secret", CreatedAt: "2024-05-01T12:00:00Z",
+		},
+		nil,
+	)
+
+	// Create a test request
+	jsonBody := []byte(`{"url": "https://lms.example.com/hooks", "events": ["plagiarism.detected"]}`)
+	req, _ := http.NewRequest("POST", "/api/v1/webhooks", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert: the secret is returned on registration
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var response WebhookResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, WebhookResponse{
+		WebhookID: "hook123",
+		URL:       "https://lms.example.com/hooks",
+		Events:    []string{"plagiarism.detected"},
+		Secret:    "secret",
+		CreatedAt: "2024-05-01T12:00:00Z",
+	}, response)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestCreateWebhook_BadRequest(t *testing.T) {
+	// Setup
+	mockClient := new(MockFileAnalysisClient)
+	router := newWebhookRouter(mockClient)
+
+	mockClient.On("CreateWebhook", mock.Anything, "http://localhost/hooks", "", []string(nil)).Return(
+		(*pb.Webhook)(nil),
+		status.Error(codes.InvalidArgument, "webhook URL must be an absolute http or https URL"),
+	)
+
+	tests := []string{
+		`{}`,
+		`{"url": "not a url"}`,
+		`{"url": "https://lms.example.com/hooks", "events": ["analysis.started"]}`,
+		`{"url": "http://localhost/hooks"}`,
+	}
+	for _, body := range tests {
+		req, _ := http.NewRequest("POST", "/api/v1/webhooks", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code, body)
+	}
+
+	mockClient.AssertNumberOfCalls(t, "CreateWebhook", 1)
+}
+
+func TestListWebhooks(t *testing.T) {
+	// Setup
+	mockClient := new(MockFileAnalysisClient)
+	router := newWebhookRouter(mockClient)
+
+	// Mock the client response
+	mockClient.On("ListWebhooks", mock.Anything).Return(
+		&pb.ListWebhooksResponse{Webhooks: []*pb.Webhook{
+			{Id: "hook123", Url: "https://lms.example.com/hooks", Events: []string{"analysis.completed"}},
+		}},
+		nil,
+	)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/webhooks", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotContains(t, resp.Body.String(), "secret")
+
+	var response WebhooksResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Webhooks, 1)
+	assert.Equal(t, "hook123", response.Webhooks[0].WebhookID)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	// Setup
+	mockClient := new(MockFileAnalysisClient)
+	router := newWebhookRouter(mockClient)
+
+	// Mock the client responses
+	mockClient.On("DeleteWebhook", mock.Anything, "hook123").Return(nil)
+	mockClient.On("DeleteWebhook", mock.Anything, "hook456").Return(status.Error(codes.NotFound, "webhook hook456 not found"))
+	mockClient.On("DeleteWebhook", mock.Anything, "hook789").Return(errors.New("database error"))
+
+	tests := []struct {
+		webhookID string
+		expected  int
+	}{
+		{"hook123", http.StatusNoContent},
+		{"hook456", http.StatusNotFound},
+		{"hook789", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("DELETE", "/api/v1/webhooks/"+tt.webhookID, nil)
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, tt.expected, resp.Code, tt.webhookID)
+	}
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestListWebhookDeliveries(t *testing.T) {
+	// Setup
+	mockClient := new(MockFileAnalysisClient)
+	router := newWebhookRouter(mockClient)
+
+	// Mock the client response
+	mockClient.On("ListWebhookDeliveries", mock.Anything, "", "file123", int32(20)).Return(
+		&pb.ListWebhookDeliveriesResponse{Deliveries: []*pb.WebhookDelivery{{
+			Id: "delivery1", Url: "https://lms.example.com/hooks", Event: "analysis.completed", FileId: "file123",
+			Status: "pending", Attempts: 2, ResponseStatus: 503, Error: "unexpected status 503 Service Unavailable",
+			CreatedAt: "2024-05-01T12:00:05Z", NextAttemptAt: "2024-05-01T12:01:06Z", LastAttemptAt: "2024-05-01T12:00:06Z",
+		}}},
+		nil,
+	)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/webhooks/deliveries?file_id=file123&limit=20", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response WebhookDeliveriesResponse
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, []WebhookDeliveryResponse{{
+		DeliveryID: "delivery1", URL: "https://lms.example.com/hooks", Event: "analysis.completed", FileID: "file123",
+		Status: "pending", Attempts: 2, ResponseStatus: 503, Error: "unexpected status 503 Service Unavailable",
+		CreatedAt: "2024-05-01T12:00:05Z", NextAttemptAt: "2024-05-01T12:01:06Z", LastAttemptAt: "2024-05-01T12:00:06Z",
+	}}, response.Deliveries)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestListWebhookDeliveries_InvalidLimit(t *testing.T) {
+	// Setup
+	mockClient := new(MockFileAnalysisClient)
+	router := newWebhookRouter(mockClient)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/webhooks/deliveries?limit=5000", nil)
+	resp := httptest.NewRecorder()
+
+	// Perform the request
+	router.ServeHTTP(resp, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	mockClient.AssertNotCalled(t, "ListWebhookDeliveries")
+}
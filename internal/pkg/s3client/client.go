@@ -0,0 +1,371 @@
+// Package s3client is a client of S3-compatible object storages, which hold the files and word clouds
+// instead of the local disk so that several replicas of the services can share them
+package s3client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults and limits of the client configuration
+const (
+	DefaultRegion   = "us-east-1"
+	DefaultPartSize = 8 << 20
+
+	// MinPartSize is the smallest part of a multipart upload S3 accepts, but for the last one
+	MinPartSize = 5 << 20
+)
+
+// abortTimeout bounds aborting a failed multipart upload, which is done even if the upload was canceled
+const abortTimeout = 30 * time.Second
+
+var (
+	// ErrNotFound is matched by the errors of requests for missing objects or buckets
+	ErrNotFound = errors.New("object not found")
+
+	// ErrChecksumMismatch is returned when a downloaded object does not match its stored checksum
+	ErrChecksumMismatch = errors.New("object checksum mismatch")
+)
+
+// Config is the configuration of a client
+type Config struct {
+	// Endpoint is the URL of the object storage, such as http://minio:9000
+	Endpoint string
+	Region   string
+	Bucket   string
+
+	// AccessKeyID and SecretAccessKey sign the requests, without them the requests are anonymous
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// PartSize is the size of the parts of multipart uploads, smaller objects are uploaded in one request
+	PartSize int64
+}
+
+// Client is a client of one bucket of an S3-compatible object storage, such as MinIO
+// Objects are addressed path-style; every upload carries the SHA-256 checksum of its content,
+// which the storage verifies before accepting it
+type Client struct {
+	endpoint        *url.URL
+	region          string
+	bucket          string
+	accessKeyID     string
+	secretAccessKey string
+	partSize        int64
+	httpClient      *http.Client
+}
+
+// NewClient creates a new Client instance
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("endpoint is required")
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" {
+		return nil, errors.New("bucket is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = DefaultRegion
+	}
+	if cfg.PartSize == 0 {
+		cfg.PartSize = DefaultPartSize
+	}
+	if cfg.PartSize < MinPartSize {
+		return nil, fmt.Errorf("part size must be at least %d bytes", MinPartSize)
+	}
+
+	return &Client{
+		endpoint:        endpoint,
+		region:          cfg.Region,
+		bucket:          cfg.Bucket,
+		accessKeyID:     cfg.AccessKeyID,
+		secretAccessKey: cfg.SecretAccessKey,
+		partSize:        cfg.PartSize,
+		httpClient:      &http.Client{},
+	}, nil
+}
+
+// Error is an error response of the object storage
+type Error struct {
+	StatusCode int    `xml:"-"`
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("s3 error %d: %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("s3 error %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Is makes responses for missing objects and buckets match ErrNotFound
+func (e *Error) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// CheckBucket checks that the bucket exists and is accessible
+func (c *Client) CheckBucket(ctx context.Context) error {
+	resp, err := c.do(ctx, http.MethodHead, "", nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to check bucket %s: %w", c.bucket, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// PutObject uploads an object read until EOF
+// Objects larger than the part size are uploaded in parts, a failed multipart upload is aborted,
+// so the object is either stored whole or not changed
+func (c *Client) PutObject(ctx context.Context, key string, content io.Reader) error {
+	// One byte more than a part tells whether the object fits into one request
+	first, err := io.ReadAll(io.LimitReader(content, c.partSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read object: %w", err)
+	}
+
+	if int64(len(first)) <= c.partSize {
+		header := http.Header{}
+		header.Set("X-Amz-Checksum-Sha256", checksum(first))
+		resp, err := c.do(ctx, http.MethodPut, key, nil, header, first)
+		if err != nil {
+			return fmt.Errorf("failed to put object %s: %w", key, err)
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	return c.putMultipart(ctx, key, io.MultiReader(bytes.NewReader(first), content))
+}
+
+// completedPart is an uploaded part of a multipart upload
+type completedPart struct {
+	ChecksumSHA256 string `xml:"ChecksumSHA256"`
+	ETag           string `xml:"ETag"`
+	PartNumber     int    `xml:"PartNumber"`
+}
+
+// putMultipart uploads an object in parts of the part size
+func (c *Client) putMultipart(ctx context.Context, key string, content io.Reader) error {
+	uploadID, err := c.createMultipartUpload(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	var parts []completedPart
+	buf := make([]byte, c.partSize)
+	for number := 1; ; number++ {
+		n, readErr := io.ReadFull(content, buf)
+		if n > 0 {
+			part, err := c.uploadPart(ctx, key, uploadID, number, buf[:n])
+			if err != nil {
+				c.abortMultipartUpload(ctx, key, uploadID)
+				return err
+			}
+			parts = append(parts, part)
+		}
+
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			c.abortMultipartUpload(ctx, key, uploadID)
+			return fmt.Errorf("failed to read object: %w", readErr)
+		}
+	}
+
+	if err := c.completeMultipartUpload(ctx, key, uploadID, parts); err != nil {
+		c.abortMultipartUpload(ctx, key, uploadID)
+		return err
+	}
+	return nil
+}
+
+func (c *Client) createMultipartUpload(ctx context.Context, key string) (string, error) {
+	header := http.Header{}
+	header.Set("X-Amz-Checksum-Algorithm", "SHA256")
+	resp, err := c.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, header, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create multipart upload of %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil || result.UploadID == "" {
+		return "", fmt.Errorf("failed to create multipart upload of %s: invalid response", key)
+	}
+	return result.UploadID, nil
+}
+
+func (c *Client) uploadPart(ctx context.Context, key, uploadID string, number int, content []byte) (completedPart, error) {
+	part := completedPart{ChecksumSHA256: checksum(content), PartNumber: number}
+
+	header := http.Header{}
+	header.Set("X-Amz-Checksum-Sha256", part.ChecksumSHA256)
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+	resp, err := c.do(ctx, http.MethodPut, key, query, header, content)
+	if err != nil {
+		return part, fmt.Errorf("failed to upload part %d of %s: %w", number, key, err)
+	}
+	resp.Body.Close()
+
+	part.ETag = resp.Header.Get("ETag")
+	return part, nil
+}
+
+func (c *Client) completeMultipartUpload(ctx context.Context, key, uploadID string, parts []completedPart) error {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload of %s: %w", key, err)
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, nil, body)
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload of %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	// The completion can fail after the response status is sent, with an error in the body
+	result, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload of %s: %w", key, err)
+	}
+	if bytes.Contains(result, []byte("<Error>")) {
+		apiErr := &Error{StatusCode: resp.StatusCode}
+		xml.Unmarshal(result, apiErr)
+		return fmt.Errorf("failed to complete multipart upload of %s: %w", key, apiErr)
+	}
+	return nil
+}
+
+// abortMultipartUpload removes the parts of a failed upload, errors leave them to the bucket lifecycle
+func (c *Client) abortMultipartUpload(ctx context.Context, key, uploadID string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), abortTimeout)
+	defer cancel()
+
+	resp, err := c.do(ctx, http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, nil)
+	if err == nil {
+		resp.Body.Close()
+	}
+}
+
+// GetObject downloads an object, the caller closes its content
+// Objects uploaded in one request are verified against their checksum when read to the end
+func (c *Client) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	header := http.Header{}
+	header.Set("X-Amz-Checksum-Mode", "ENABLED")
+	resp, err := c.do(ctx, http.MethodGet, key, nil, header, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+
+	// Checksums of multipart uploads are checksums of the part checksums, marked by the part count
+	expected := resp.Header.Get("X-Amz-Checksum-Sha256")
+	if expected == "" || strings.Contains(expected, "-") {
+		return resp.Body, nil
+	}
+	return &checksumReader{body: resp.Body, hash: sha256.New(), expected: expected}, nil
+}
+
+// DeleteObject deletes an object, a missing object is not an error
+func (c *Client) DeleteObject(ctx context.Context, key string) error {
+	resp, err := c.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete object %s: %w", key, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends a signed request for an object, or for the bucket if the key is empty
+// Responses other than 2xx are returned as *Error
+func (c *Client) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u := *c.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + c.bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	if c.accessKeyID != "" {
+		payloadHash := emptyPayloadHash
+		if len(body) > 0 {
+			payloadHash = hashHex(body)
+		}
+		sign(req, c.accessKeyID, c.secretAccessKey, c.region, payloadHash, time.Now())
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		apiErr := &Error{StatusCode: resp.StatusCode}
+		if data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10)); err == nil {
+			xml.Unmarshal(data, apiErr)
+		}
+		return nil, apiErr
+	}
+
+	return resp, nil
+}
+
+// checksum is the base64 SHA-256 checksum of content, as sent in X-Amz-Checksum-Sha256
+func checksum(content []byte) string {
+	hash := sha256.Sum256(content)
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// checksumReader verifies the content read against its checksum at EOF
+type checksumReader struct {
+	body     io.ReadCloser
+	hash     hash.Hash
+	expected string
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF && base64.StdEncoding.EncodeToString(r.hash.Sum(nil)) != r.expected {
+		return n, ErrChecksumMismatch
+	}
+	return n, err
+}
+
+func (r *checksumReader) Close() error {
+	return r.body.Close()
+}
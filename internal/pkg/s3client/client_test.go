@@ -0,0 +1,179 @@
+package s3client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"local.dev/doc-analyzer/internal/pkg/s3client"
+	"local.dev/doc-analyzer/internal/pkg/s3client/s3fake"
+)
+
+// newTestClient creates a client of a fake object storage with the bucket
+func newTestClient(t *testing.T) (*s3client.Client, *s3fake.Server) {
+	fake := s3fake.NewServer("documents")
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := s3client.NewClient(s3client.Config{
+		Endpoint:        server.URL,
+		Bucket:          "documents",
+		AccessKeyID:     "minio",
+		SecretAccessKey: "minio-secret",
+		PartSize:        s3client.MinPartSize,
+	})
+	require.NoError(t, err)
+	return client, fake
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name   string
+		config s3client.Config
+		err    string
+	}{
+		{"Valid", s3client.Config{Endpoint: "http://minio:9000", Bucket: "files"}, ""},
+		{"No endpoint", s3client.Config{Bucket: "files"}, "endpoint is required"},
+		{"Invalid endpoint", s3client.Config{Endpoint: "minio:9000", Bucket: "files"}, "invalid endpoint"},
+		{"No bucket", s3client.Config{Endpoint: "http://minio:9000"}, "bucket is required"},
+		{"Small parts", s3client.Config{Endpoint: "http://minio:9000", Bucket: "files", PartSize: 1 << 20}, "part size must be at least"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := s3client.NewClient(tt.config)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				assert.NotNil(t, client)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestClient_PutAndGetObject(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	require.NoError(t, client.CheckBucket(ctx))
+
+	// Small objects are uploaded in one request, keys are escaped
+	require.NoError(t, client.PutObject(ctx, "files/отчёт 1.txt", strings.NewReader("report")))
+	stored, ok := fake.Object("files/отчёт 1.txt")
+	require.True(t, ok)
+	assert.Equal(t, "report", string(stored))
+	assert.Equal(t, []string{"HEAD", "PUT"}, fake.Requests())
+
+	content, err := client.GetObject(ctx, "files/отчёт 1.txt")
+	require.NoError(t, err)
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	require.NoError(t, content.Close())
+	assert.Equal(t, "report", string(data))
+
+	// Missing objects match ErrNotFound
+	_, err = client.GetObject(ctx, "files/missing.txt")
+	assert.ErrorIs(t, err, s3client.ErrNotFound)
+
+	require.NoError(t, client.DeleteObject(ctx, "files/отчёт 1.txt"))
+	_, ok = fake.Object("files/отчёт 1.txt")
+	assert.False(t, ok)
+	assert.NoError(t, client.DeleteObject(ctx, "files/отчёт 1.txt"))
+}
+
+func TestClient_PutObject_Multipart(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	// Objects larger than a part are uploaded in parts, the last one smaller
+	large := bytes.Repeat([]byte("0123456789abcdef"), (2*s3client.MinPartSize+1024)/16)
+	require.NoError(t, client.PutObject(ctx, "large.bin", bytes.NewReader(large)))
+
+	stored, ok := fake.Object("large.bin")
+	require.True(t, ok)
+	assert.Equal(t, large, stored)
+	assert.Equal(t, []string{
+		"POST uploads=",
+		"PUT partNumber=1&uploadId=1",
+		"PUT partNumber=2&uploadId=1",
+		"PUT partNumber=3&uploadId=1",
+		"POST uploadId=1",
+	}, fake.Requests())
+	assert.Zero(t, fake.Uploads())
+
+	// The checksum of a multipart object is not verified on download
+	content, err := client.GetObject(ctx, "large.bin")
+	require.NoError(t, err)
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	assert.Equal(t, large, data)
+
+	// An object of exactly one part is uploaded in one request
+	exact := bytes.Repeat([]byte("a"), s3client.MinPartSize)
+	require.NoError(t, client.PutObject(ctx, "exact.bin", bytes.NewReader(exact)))
+	requests := fake.Requests()
+	assert.Equal(t, "PUT", requests[len(requests)-1])
+}
+
+func TestClient_PutObject_ReadError(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	// A failed multipart upload is aborted and leaves no object
+	content := io.MultiReader(
+		bytes.NewReader(bytes.Repeat([]byte("a"), s3client.MinPartSize+1)),
+		iotest.ErrReader(errors.New("connection reset")),
+	)
+	err := client.PutObject(ctx, "broken.bin", content)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read object")
+	_, ok := fake.Object("broken.bin")
+	assert.False(t, ok)
+	assert.Zero(t, fake.Uploads())
+}
+
+func TestClient_GetObject_ChecksumMismatch(t *testing.T) {
+	client, fake := newTestClient(t)
+	ctx := context.Background()
+
+	// The content of the object changed after its checksum was stored
+	require.NoError(t, client.PutObject(ctx, "essay.txt", strings.NewReader("original")))
+	fake.PutObject("essay.txt", []byte("corrupted"), "Z2LJvjJ6dPEs5fxJCNFQg2rTPTP5B+PJqWq9ePJbnfo=")
+
+	content, err := client.GetObject(ctx, "essay.txt")
+	require.NoError(t, err)
+	_, err = io.ReadAll(content)
+	assert.ErrorIs(t, err, s3client.ErrChecksumMismatch)
+}
+
+func TestClient_Errors(t *testing.T) {
+	fake := s3fake.NewServer("documents")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	// A missing bucket
+	client, err := s3client.NewClient(s3client.Config{Endpoint: server.URL, Bucket: "missing", AccessKeyID: "minio"})
+	require.NoError(t, err)
+	err = client.CheckBucket(context.Background())
+	assert.ErrorIs(t, err, s3client.ErrNotFound)
+
+	// Unsigned requests are refused with the error of the response
+	client, err = s3client.NewClient(s3client.Config{Endpoint: server.URL, Bucket: "documents"})
+	require.NoError(t, err)
+	err = client.PutObject(context.Background(), "essay.txt", strings.NewReader("essay"))
+	var apiErr *s3client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 403, apiErr.StatusCode)
+	assert.Equal(t, "AccessDenied", apiErr.Code)
+}
@@ -0,0 +1,244 @@
+// Package s3fake is an in-memory fake of the S3 API for tests, covering the requests of s3client
+package s3fake
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// minPartSize is the smallest part of a multipart upload accepted, but for the last one
+const minPartSize = 5 << 20
+
+// object is a stored object with its checksum
+type object struct {
+	content  []byte
+	checksum string
+}
+
+// upload is a multipart upload in progress
+type upload struct {
+	key   string
+	parts map[int]object
+}
+
+// Server is an in-memory S3-compatible object storage of one bucket
+// It verifies the payload hashes and the SHA-256 checksums of uploads like S3 does,
+// requests are required to be signed but the signatures are not checked
+type Server struct {
+	mu       sync.Mutex
+	bucket   string
+	objects  map[string]object
+	uploads  map[string]*upload
+	uploadID int
+	requests []string
+}
+
+// NewServer creates a new Server instance for the bucket
+func NewServer(bucket string) *Server {
+	return &Server{
+		bucket:  bucket,
+		objects: make(map[string]object),
+		uploads: make(map[string]*upload),
+	}
+}
+
+// Object returns the content of a stored object
+func (s *Server) Object(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[key]
+	return obj.content, ok
+}
+
+// PutObject stores an object directly, with the checksum given, to simulate corruption
+func (s *Server) PutObject(key string, content []byte, checksum string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = object{content: content, checksum: checksum}
+}
+
+// Uploads returns the number of multipart uploads neither completed nor aborted
+func (s *Server) Uploads() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.uploads)
+}
+
+// Requests returns the requests served, as method and query, such as "PUT partNumber=1&uploadId=1"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// ServeHTTP serves the S3 API requests
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, strings.TrimSpace(r.Method+" "+r.URL.RawQuery))
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=") {
+		writeError(w, http.StatusForbidden, "AccessDenied", "Request is not signed")
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != s.bucket {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+	if hash := sha256.Sum256(body); r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(hash[:]) {
+		writeError(w, http.StatusBadRequest, "XAmzContentSHA256Mismatch", "The payload hash does not match")
+		return
+	}
+
+	query := r.URL.Query()
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.createUpload(w, key)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		s.uploadPart(w, r, key, body)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		s.completeUpload(w, query.Get("uploadId"), key, body)
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(s.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		if !verifyChecksum(w, r, body) {
+			return
+		}
+		s.objects[key] = object{content: body, checksum: r.Header.Get("X-Amz-Checksum-Sha256")}
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet:
+		obj, ok := s.objects[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist")
+			return
+		}
+		if r.Header.Get("X-Amz-Checksum-Mode") == "ENABLED" && obj.checksum != "" {
+			w.Header().Set("X-Amz-Checksum-Sha256", obj.checksum)
+		}
+		w.Write(obj.content)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented", "The request is not supported")
+	}
+}
+
+func (s *Server) createUpload(w http.ResponseWriter, key string) {
+	s.uploadID++
+	id := strconv.Itoa(s.uploadID)
+	s.uploads[id] = &upload{key: key, parts: make(map[int]object)}
+	fmt.Fprintf(w, "<InitiateMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>",
+		s.bucket, key, id)
+}
+
+func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, key string, body []byte) {
+	up, ok := s.uploads[r.URL.Query().Get("uploadId")]
+	if !ok || up.key != key {
+		writeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist")
+		return
+	}
+	number, err := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if err != nil || number < 1 || number > 10000 {
+		writeError(w, http.StatusBadRequest, "InvalidArgument", "Invalid part number")
+		return
+	}
+	if !verifyChecksum(w, r, body) {
+		return
+	}
+
+	up.parts[number] = object{content: body, checksum: r.Header.Get("X-Amz-Checksum-Sha256")}
+	w.Header().Set("ETag", fmt.Sprintf("\"part-%d\"", number))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) completeUpload(w http.ResponseWriter, id, key string, body []byte) {
+	up, ok := s.uploads[id]
+	if !ok || up.key != key {
+		writeError(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist")
+		return
+	}
+
+	var request struct {
+		Parts []struct {
+			ChecksumSHA256 string `xml:"ChecksumSHA256"`
+			PartNumber     int    `xml:"PartNumber"`
+		} `xml:"Part"`
+	}
+	if err := xml.Unmarshal(body, &request); err != nil || len(request.Parts) == 0 {
+		writeError(w, http.StatusBadRequest, "MalformedXML", "The XML is not well-formed")
+		return
+	}
+	if !sort.SliceIsSorted(request.Parts, func(i, j int) bool {
+		return request.Parts[i].PartNumber < request.Parts[j].PartNumber
+	}) {
+		writeError(w, http.StatusBadRequest, "InvalidPartOrder", "The parts are not in ascending order")
+		return
+	}
+
+	var content, checksums []byte
+	for i, p := range request.Parts {
+		part, ok := up.parts[p.PartNumber]
+		if !ok || part.checksum != p.ChecksumSHA256 {
+			writeError(w, http.StatusBadRequest, "InvalidPart", "A part was not uploaded or its checksum does not match")
+			return
+		}
+		if i < len(request.Parts)-1 && len(part.content) < minPartSize {
+			writeError(w, http.StatusBadRequest, "EntityTooSmall", "A part is smaller than the minimum allowed size")
+			return
+		}
+		content = append(content, part.content...)
+		raw, _ := base64.StdEncoding.DecodeString(part.checksum)
+		checksums = append(checksums, raw...)
+	}
+
+	// The checksum of a multipart object is the checksum of the part checksums with the part count
+	hash := sha256.Sum256(checksums)
+	s.objects[key] = object{
+		content:  content,
+		checksum: fmt.Sprintf("%s-%d", base64.StdEncoding.EncodeToString(hash[:]), len(request.Parts)),
+	}
+	delete(s.uploads, id)
+	fmt.Fprintf(w, "<CompleteMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key></CompleteMultipartUploadResult>", s.bucket, key)
+}
+
+// verifyChecksum verifies the SHA-256 checksum of an upload, if there is one
+func verifyChecksum(w http.ResponseWriter, r *http.Request, body []byte) bool {
+	expected := r.Header.Get("X-Amz-Checksum-Sha256")
+	if expected == "" {
+		return true
+	}
+	hash := sha256.Sum256(body)
+	if base64.StdEncoding.EncodeToString(hash[:]) != expected {
+		writeError(w, http.StatusBadRequest, "BadDigest", "The SHA256 you specified did not match the calculated checksum")
+		return false
+	}
+	return true
+}
+
+func writeError(w http.ResponseWriter, statusCode int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(statusCode)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, message)
+}
@@ -0,0 +1,116 @@
+package extract
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	textunicode "golang.org/x/text/encoding/unicode"
+)
+
+// Encodings of text files detected by DecodeText
+const (
+	EncodingUTF8        = "utf-8"
+	EncodingUTF16LE     = "utf-16le"
+	EncodingUTF16BE     = "utf-16be"
+	EncodingWindows1251 = "windows-1251"
+	EncodingKOI8R       = "koi8-r"
+)
+
+// frequentCyrillicLetters are the most frequent letters of Russian texts,
+// they tell the right single-byte code page from the wrong one
+const frequentCyrillicLetters = "оеаинтсрвлкмдпу"
+
+// DecodeText detects the encoding of a text file and converts its content to UTF-8
+// Byte order marks are honored and removed, UTF-16 is also detected without them;
+// content that is not UTF-8 is taken for Windows-1251 or KOI8-R, whichever reads as Russian text
+func DecodeText(content []byte) (string, string) {
+	switch {
+	case len(content) >= 3 && content[0] == 0xEF && content[1] == 0xBB && content[2] == 0xBF:
+		return string(content[3:]), EncodingUTF8
+	case len(content) >= 2 && content[0] == 0xFF && content[1] == 0xFE:
+		return decodeUTF16(content[2:], textunicode.LittleEndian), EncodingUTF16LE
+	case len(content) >= 2 && content[0] == 0xFE && content[1] == 0xFF:
+		return decodeUTF16(content[2:], textunicode.BigEndian), EncodingUTF16BE
+	}
+
+	if endianness, ok := detectUTF16(content); ok {
+		if endianness == textunicode.LittleEndian {
+			return decodeUTF16(content, endianness), EncodingUTF16LE
+		}
+		return decodeUTF16(content, endianness), EncodingUTF16BE
+	}
+
+	if utf8.Valid(content) {
+		return string(content), EncodingUTF8
+	}
+
+	windows1251 := decodeCharmap(content, charmap.Windows1251)
+	koi8r := decodeCharmap(content, charmap.KOI8R)
+	if cyrillicScore(koi8r) > cyrillicScore(windows1251) {
+		return koi8r, EncodingKOI8R
+	}
+	return windows1251, EncodingWindows1251
+}
+
+// detectUTF16 detects UTF-16 without a byte order mark by its high bytes: the text of
+// Latin and Cyrillic scripts has almost all of them 0x00 or 0x04, in the odd bytes for
+// little endian and in the even ones for big endian
+func detectUTF16(content []byte) (textunicode.Endianness, bool) {
+	if len(content) < 2 || len(content)%2 != 0 {
+		return textunicode.LittleEndian, false
+	}
+
+	pairs := len(content) / 2
+	evenHigh, oddHigh := 0, 0
+	for i := 0; i < len(content); i += 2 {
+		if content[i] == 0x00 || content[i] == 0x04 {
+			evenHigh++
+		}
+		if content[i+1] == 0x00 || content[i+1] == 0x04 {
+			oddHigh++
+		}
+	}
+
+	switch {
+	case oddHigh*10 >= pairs*9 && evenHigh*2 < pairs:
+		return textunicode.LittleEndian, true
+	case evenHigh*10 >= pairs*9 && oddHigh*2 < pairs:
+		return textunicode.BigEndian, true
+	default:
+		return textunicode.LittleEndian, false
+	}
+}
+
+// decodeUTF16 converts UTF-16 content without a byte order mark to UTF-8
+// The decoder does not fail, invalid sequences and a trailing odd byte are replaced with U+FFFD
+func decodeUTF16(content []byte, endianness textunicode.Endianness) string {
+	decoded, _ := textunicode.UTF16(endianness, textunicode.IgnoreBOM).NewDecoder().Bytes(content)
+	return string(decoded)
+}
+
+// decodeCharmap converts content of a single-byte code page to UTF-8
+func decodeCharmap(content []byte, codePage *charmap.Charmap) string {
+	var b strings.Builder
+	b.Grow(len(content) * 2)
+	for _, c := range content {
+		b.WriteRune(codePage.DecodeByte(c))
+	}
+	return b.String()
+}
+
+// cyrillicScore rates how much a text reads as Russian: frequent lowercase letters count double,
+// uppercase letters, which most letters of a wrongly decoded text are, do not count
+func cyrillicScore(text string) int {
+	score := 0
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune(frequentCyrillicLetters, r):
+			score += 2
+		case unicode.Is(unicode.Cyrillic, r) && unicode.IsLower(r):
+			score++
+		}
+	}
+	return score
+}
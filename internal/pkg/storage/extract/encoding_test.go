@@ -0,0 +1,67 @@
+package extract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	textunicode "golang.org/x/text/encoding/unicode"
+)
+
+// sampleText is a Russian text with Latin words, punctuation and line breaks
+const sampleText = "Отчёт о лабораторной работе №1.\r\nЦель работы: изучить протокол HTTP и написать простой сервер.\r\n"
+
+// encode encodes the text with the encoding
+func encode(t *testing.T, enc encoding.Encoding, text string) []byte {
+	encoded, err := enc.NewEncoder().Bytes([]byte(text))
+	require.NoError(t, err)
+	return encoded
+}
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []byte
+		encoding string
+	}{
+		{"UTF-8", []byte(sampleText), EncodingUTF8},
+		{"UTF-8 with BOM", append([]byte{0xEF, 0xBB, 0xBF}, sampleText...), EncodingUTF8},
+		{"UTF-16LE with BOM", encode(t, textunicode.UTF16(textunicode.LittleEndian, textunicode.UseBOM), sampleText), EncodingUTF16LE},
+		{"UTF-16BE with BOM", encode(t, textunicode.UTF16(textunicode.BigEndian, textunicode.UseBOM), sampleText), EncodingUTF16BE},
+		{"UTF-16LE", encode(t, textunicode.UTF16(textunicode.LittleEndian, textunicode.IgnoreBOM), sampleText), EncodingUTF16LE},
+		{"UTF-16BE", encode(t, textunicode.UTF16(textunicode.BigEndian, textunicode.IgnoreBOM), sampleText), EncodingUTF16BE},
+		{"Windows-1251", encode(t, charmap.Windows1251, sampleText), EncodingWindows1251},
+		{"KOI8-R", encode(t, charmap.KOI8R, "Отчёт о лабораторной работе.\nЦель работы: изучить протокол HTTP.\n"), EncodingKOI8R},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, encoding := DecodeText(tt.content)
+
+			assert.Equal(t, tt.encoding, encoding)
+			if tt.encoding == EncodingKOI8R {
+				assert.Equal(t, "Отчёт о лабораторной работе.\nЦель работы: изучить протокол HTTP.\n", text)
+			} else {
+				assert.Equal(t, sampleText, text)
+			}
+		})
+	}
+}
+
+func TestDecodeText_ShortAndLatin(t *testing.T) {
+	// ASCII text is UTF-8, also when it is short
+	text, encoding := DecodeText([]byte("ok"))
+	assert.Equal(t, "ok", text)
+	assert.Equal(t, EncodingUTF8, encoding)
+
+	text, encoding = DecodeText(nil)
+	assert.Empty(t, text)
+	assert.Equal(t, EncodingUTF8, encoding)
+
+	// A single Windows-1251 word
+	text, encoding = DecodeText([]byte{0xe4, 0xe0})
+	assert.Equal(t, "да", text)
+	assert.Equal(t, EncodingWindows1251, encoding)
+}
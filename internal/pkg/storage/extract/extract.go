@@ -0,0 +1,131 @@
+// Package extract extracts the plain text of uploaded documents, the text is what the files are analyzed by
+// Plain text and Markdown in UTF-8, UTF-16, Windows-1251 or KOI8-R, DOCX, ODT, RTF and PDF documents
+// are read without external programs
+package extract
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// maxDecompressedSize is the maximum decompressed size of the parts of a document holding its text,
+// larger documents are refused as compression bombs
+const maxDecompressedSize = 64 << 20
+
+// ErrUnsupportedFormat is returned for files of formats the text cannot be extracted from
+var ErrUnsupportedFormat = errors.New("unsupported file format, only .txt, .md, .docx, .odt, .pdf and .rtf files are allowed")
+
+// ErrInvalidDocument is returned, wrapped, for documents that cannot be read
+var ErrInvalidDocument = errors.New("invalid document")
+
+// extractors extract the text of the supported formats by file extension
+var extractors = map[string]func(content []byte) (string, error){
+	".txt": func(content []byte) (string, error) { return string(content), nil },
+	".md":  markdownText,
+	".pdf": pdfText,
+	".rtf": rtfText,
+}
+
+// archiveExtractors extract the text of the ZIP-based formats, which are read in place
+var archiveExtractors = map[string]func(content io.ReaderAt, size int64) (string, error){
+	".docx": docxText,
+	".odt":  odtText,
+}
+
+// Supported reports whether the text of a file can be extracted, by its extension
+func Supported(fileName string) bool {
+	extension := strings.ToLower(filepath.Ext(fileName))
+	_, ok := extractors[extension]
+	_, archive := archiveExtractors[extension]
+	return ok || archive
+}
+
+// textFormats are the extensions of the formats stored as text of any encoding, not only UTF-8
+var textFormats = map[string]bool{".txt": true, ".md": true}
+
+// isPlainText reports whether a file is plain text, its text is only converted to UTF-8
+func isPlainText(fileName string) bool {
+	return strings.ToLower(filepath.Ext(fileName)) == ".txt"
+}
+
+// Text extracts the plain text of a file in UTF-8, the format is chosen by its extension
+// For text formats the detected encoding of the file is returned too, it is empty for the other formats
+// Lines are separated by "\n" and paragraphs by a blank line, the text of .txt files is only converted to UTF-8
+func Text(fileName string, content []byte) (text string, encoding string, err error) {
+	extension := strings.ToLower(filepath.Ext(fileName))
+	if extractor, ok := archiveExtractors[extension]; ok {
+		return archiveText(extractor, bytes.NewReader(content), int64(len(content)))
+	}
+
+	extractor, ok := extractors[extension]
+	if !ok {
+		return "", "", ErrUnsupportedFormat
+	}
+
+	if textFormats[extension] {
+		var decoded string
+		decoded, encoding = DecodeText(content)
+		content = []byte(decoded)
+	}
+
+	text, err = extractor(content)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	if isPlainText(fileName) {
+		return text, encoding, nil
+	}
+	return normalize(text), encoding, nil
+}
+
+// TextFrom extracts the plain text of a file of the given size like Text does, reading it from content
+// ZIP-based documents are read in place, only their part holding the text is held in memory;
+// the parsers of the other formats need all of the content, it is read into memory
+func TextFrom(fileName string, content io.ReaderAt, size int64) (text string, encoding string, err error) {
+	extension := strings.ToLower(filepath.Ext(fileName))
+	if extractor, ok := archiveExtractors[extension]; ok {
+		return archiveText(extractor, content, size)
+	}
+	if _, ok := extractors[extension]; !ok {
+		return "", "", ErrUnsupportedFormat
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(content, 0, size))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return Text(fileName, data)
+}
+
+// archiveText extracts and normalizes the text of a ZIP-based document, which has no encoding
+func archiveText(extractor func(content io.ReaderAt, size int64) (string, error), content io.ReaderAt, size int64) (string, string, error) {
+	text, err := extractor(content, size)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	return normalize(text), "", nil
+}
+
+// normalize unifies the line breaks of extracted text, trims the spaces at the ends of the lines
+// and collapses runs of blank lines, which separate paragraphs, into a single one
+func normalize(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
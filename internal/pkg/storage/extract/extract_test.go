@@ -0,0 +1,96 @@
+package extract
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupported(t *testing.T) {
+	for _, name := range []string{"essay.txt", "essay.md", "essay.DOCX", "essay.odt", "essay.pdf", "essay.rtf"} {
+		assert.True(t, Supported(name), name)
+	}
+	for _, name := range []string{"essay.doc", "essay.html", "essay", "essay.txt.exe"} {
+		assert.False(t, Supported(name), name)
+	}
+}
+
+func TestText(t *testing.T) {
+	// Plain text is returned as is
+	text, encoding, err := Text("essay.txt", []byte("  First line\r\n\r\n\r\nSecond line  "))
+	require.NoError(t, err)
+	assert.Equal(t, "  First line\r\n\r\n\r\nSecond line  ", text)
+	assert.Equal(t, EncodingUTF8, encoding)
+
+	// Text formats are converted to UTF-8, the other ones have no encoding
+	text, encoding, err = Text("essay.md", []byte{0xcf, 0xf0, 0xe8, 0xe2, 0xe5, 0xf2, 0x20, 0x2a, 0xec, 0xe8, 0xf0, 0x2a})
+	require.NoError(t, err)
+	assert.Equal(t, "Привет мир", text)
+	assert.Equal(t, EncodingWindows1251, encoding)
+
+	_, encoding, err = Text("essay.docx", newOfficeDocument(t, "word/document.xml", "<document/>"))
+	require.NoError(t, err)
+	assert.Empty(t, encoding)
+
+	// Extracted text is normalized
+	text, _, err = Text("essay.md", []byte("# Title\r\n\r\n\r\n\r\nFirst line  \r\nsecond line\n\n\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "Title\n\nFirst line\nsecond line", text)
+
+	_, _, err = Text("essay.doc", []byte("content"))
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	_, _, err = Text("essay.docx", []byte("not a zip"))
+	assert.True(t, errors.Is(err, ErrInvalidDocument))
+}
+
+func TestTextFrom(t *testing.T) {
+	// Documents are read from a file like from memory
+	document := newOfficeDocument(t, "word/document.xml",
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`+
+			`<w:p><w:r><w:t>First</w:t></w:r></w:p><w:p><w:r><w:t>Second</w:t></w:r></w:p></w:body></w:document>`)
+	path := filepath.Join(t.TempDir(), "essay.docx")
+	require.NoError(t, os.WriteFile(path, document, 0644))
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	text, encoding, err := TextFrom("essay.docx", file, int64(len(document)))
+	require.NoError(t, err)
+	assert.Equal(t, "First\n\nSecond", text)
+	assert.Empty(t, encoding)
+
+	// Text formats are converted to UTF-8
+	content := []byte{0xcf, 0xf0, 0xe8, 0xe2, 0xe5, 0xf2}
+	text, encoding, err = TextFrom("essay.txt", bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	assert.Equal(t, "Привет", text)
+	assert.Equal(t, EncodingWindows1251, encoding)
+
+	_, _, err = TextFrom("essay.doc", bytes.NewReader(content), int64(len(content)))
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	_, _, err = TextFrom("essay.odt", bytes.NewReader(content), int64(len(content)))
+	assert.ErrorIs(t, err, ErrInvalidDocument)
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"", ""},
+		{"\n\n  \n", ""},
+		{"one\r\ntwo\rthree", "one\ntwo\nthree"},
+		{"\n\n\tone \n\n\n\n two\t\n\n", "one\n\ntwo"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, normalize(tt.text), "%q", tt.text)
+	}
+}
@@ -0,0 +1,102 @@
+package extract
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Block-level Markdown syntax, matched on single lines
+var (
+	mdFencePattern      = regexp.MustCompile("^\\s*(```|~~~)")
+	mdRulePattern       = regexp.MustCompile(`^\s*([-*_=]\s*){3,}$`)
+	mdHeadingPattern    = regexp.MustCompile(`^\s*#{1,6}(\s+|$)`)
+	mdHeadingEnd        = regexp.MustCompile(`\s+#+\s*$`)
+	mdQuotePattern      = regexp.MustCompile(`^\s*(>\s?)+`)
+	mdListPattern       = regexp.MustCompile(`^\s*([-*+]|\d{1,9}[.)])\s+(\[[ xX]\]\s+)?`)
+	mdLinkDefinition    = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s+\S+`)
+	mdTableSeparator    = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)+\|?\s*$`)
+	mdTableCellBoundary = regexp.MustCompile(`\s*\|\s*`)
+)
+
+// Inline Markdown syntax, replaced in order
+var mdInlinePatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile("`+([^`]+?)`+"), "$1"},
+	{regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`), "$1"},
+	{regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`), "$1"},
+	{regexp.MustCompile(`\[([^\]]+)\]\[[^\]]*\]`), "$1"},
+	{regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`), "$1"},
+	{regexp.MustCompile(`</?[a-zA-Z][^>]*>`), ""},
+	{regexp.MustCompile(`\*\*([^*]+)\*\*`), "$1"},
+	{regexp.MustCompile(`__([^_]+)__`), "$1"},
+	{regexp.MustCompile(`\*([^*\s][^*]*)\*`), "$1"},
+	{regexp.MustCompile(`\b_([^_]+)_\b`), "$1"},
+	{regexp.MustCompile(`~~([^~]+)~~`), "$1"},
+}
+
+// mdEscapePattern matches the backslash escapes of Markdown
+var mdEscapePattern = regexp.MustCompile("\\\\([\\\\`*_{}\\[\\]()#+\\-.!|>~<])")
+
+// escapeBase is the first rune of the private use area escaped characters are kept in
+// while the inline syntax is removed
+const escapeBase = 0xE000
+
+// markdownText strips the Markdown syntax of a document, keeping the text of links and images
+// Code blocks are kept as they are
+func markdownText(content []byte) (string, error) {
+	var b strings.Builder
+	inCode := false
+	for _, line := range strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n") {
+		if mdFencePattern.MatchString(line) {
+			inCode = !inCode
+			b.WriteString("\n")
+			continue
+		}
+		if inCode {
+			b.WriteString(line + "\n")
+			continue
+		}
+
+		switch {
+		case mdTableSeparator.MatchString(line):
+			continue
+		case mdRulePattern.MatchString(line), mdLinkDefinition.MatchString(line):
+			b.WriteString("\n")
+			continue
+		case mdHeadingPattern.MatchString(line):
+			// Headings are paragraphs of their own
+			line = mdHeadingEnd.ReplaceAllString(mdHeadingPattern.ReplaceAllString(line, ""), "")
+			b.WriteString("\n" + markdownInline(line) + "\n\n")
+			continue
+		}
+
+		line = mdQuotePattern.ReplaceAllString(line, "")
+		line = mdListPattern.ReplaceAllString(line, "")
+		if strings.HasPrefix(strings.TrimSpace(line), "|") {
+			line = strings.TrimSpace(mdTableCellBoundary.ReplaceAllString(line, " "))
+		}
+		b.WriteString(markdownInline(line) + "\n")
+	}
+	return b.String(), nil
+}
+
+// markdownInline strips the inline Markdown syntax of a line
+func markdownInline(line string) string {
+	// Hide the escaped characters from the inline syntax
+	line = mdEscapePattern.ReplaceAllStringFunc(line, func(escape string) string {
+		return string(rune(escapeBase + int(escape[1])))
+	})
+
+	for _, inline := range mdInlinePatterns {
+		line = inline.pattern.ReplaceAllString(line, inline.replacement)
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r >= escapeBase && r < escapeBase+0x80 {
+			return r - escapeBase
+		}
+		return r
+	}, line)
+}
@@ -0,0 +1,55 @@
+package extract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdownText(t *testing.T) {
+	document := "Title\n" +
+		"=====\n" +
+		"## Введение ##\n" +
+		"Текст с **жирным**, *курсивом*, __подчёркнутым__, _наклонным_ и ~~зачёркнутым~~ словами.\n" +
+		"Ссылка на [документацию](https://example.com \"title\"), ![рисунок](image.png) и <https://example.com>.\n" +
+		"Код `go test` и snake_case_name, экранированные \\*звёздочки\\*.\n" +
+		"\n" +
+		"> Цитата\n" +
+		"> > вложенная\n" +
+		"\n" +
+		"- первый пункт\n" +
+		"* [x] второй пункт\n" +
+		"10. третий пункт\n" +
+		"\n" +
+		"---\n" +
+		"\n" +
+		"| Имя | Оценка |\n" +
+		"|-----|:------:|\n" +
+		"| Иван | 5 |\n" +
+		"\n" +
+		"```go\n" +
+		"fmt.Println(\"**not bold**\")\n" +
+		"```\n" +
+		"Текст <b>с разметкой</b> и [сноской][1].\n" +
+		"\n" +
+		"[1]: https://example.com\n"
+
+	text, _, err := Text("essay.md", []byte(document))
+
+	require.NoError(t, err)
+	assert.Equal(t, "Title\n\n"+
+		"Введение\n\n"+
+		"Текст с жирным, курсивом, подчёркнутым, наклонным и зачёркнутым словами.\n"+
+		"Ссылка на документацию, рисунок и https://example.com.\n"+
+		"Код go test и snake_case_name, экранированные *звёздочки*.\n\n"+
+		"Цитата\n"+
+		"вложенная\n\n"+
+		"первый пункт\n"+
+		"второй пункт\n"+
+		"третий пункт\n\n"+
+		"Имя Оценка\n"+
+		"Иван 5\n\n"+
+		"fmt.Println(\"**not bold**\")\n\n"+
+		"Текст с разметкой и сноской.", text)
+}
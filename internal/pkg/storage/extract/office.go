@@ -0,0 +1,175 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// XML namespaces of the elements the text of office documents is read from
+const (
+	wordprocessingNamespace = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	odfTextNamespace        = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	odfOfficeNamespace      = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+)
+
+// docxText extracts the text of a Word document from its word/document.xml part
+func docxText(content io.ReaderAt, size int64) (string, error) {
+	part, err := openZipPart(content, size, "word/document.xml")
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	inText := false
+	decoder := xml.NewDecoder(part)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return b.String(), nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to parse document.xml: %w", err)
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if token.Name.Space != wordprocessingNamespace {
+				continue
+			}
+			switch token.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				b.WriteString("\t")
+			case "br", "cr":
+				b.WriteString("\n")
+			case "noBreakHyphen":
+				b.WriteString("-")
+			}
+		case xml.EndElement:
+			if token.Name.Space != wordprocessingNamespace {
+				continue
+			}
+			switch token.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				b.WriteString("\n\n")
+			}
+		case xml.CharData:
+			// Only w:t holds the text, deleted text (w:delText) and field instructions (w:instrText) are skipped
+			if inText {
+				b.Write(token)
+			}
+		}
+	}
+}
+
+// odtText extracts the text of an OpenDocument text document from its content.xml part
+// Comments are skipped
+func odtText(content io.ReaderAt, size int64) (string, error) {
+	part, err := openZipPart(content, size, "content.xml")
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	paragraphDepth, annotationDepth := 0, 0
+	decoder := xml.NewDecoder(part)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return b.String(), nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to parse content.xml: %w", err)
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if token.Name.Space == odfOfficeNamespace && token.Name.Local == "annotation" {
+				annotationDepth++
+			}
+			if token.Name.Space != odfTextNamespace || annotationDepth > 0 {
+				continue
+			}
+			switch token.Name.Local {
+			case "p", "h":
+				paragraphDepth++
+			case "s":
+				b.WriteString(strings.Repeat(" ", odtSpaceCount(token)))
+			case "tab":
+				b.WriteString("\t")
+			case "line-break":
+				b.WriteString("\n")
+			}
+		case xml.EndElement:
+			if token.Name.Space == odfOfficeNamespace && token.Name.Local == "annotation" {
+				annotationDepth--
+			}
+			if token.Name.Space != odfTextNamespace || annotationDepth > 0 {
+				continue
+			}
+			if token.Name.Local == "p" || token.Name.Local == "h" {
+				paragraphDepth--
+				b.WriteString("\n\n")
+			}
+		case xml.CharData:
+			if paragraphDepth > 0 && annotationDepth == 0 {
+				b.Write(token)
+			}
+		}
+	}
+}
+
+// odtSpaceCount returns the number of spaces of a text:s element
+func odtSpaceCount(element xml.StartElement) int {
+	for _, attr := range element.Attr {
+		if attr.Name.Space == odfTextNamespace && attr.Name.Local == "c" {
+			count, err := strconv.Atoi(attr.Value)
+			if err != nil || count < 1 {
+				return 1
+			}
+			// Bound the count, it is not trusted
+			return min(count, 1000)
+		}
+	}
+	return 1
+}
+
+// openZipPart opens a part of a ZIP-based office document, limited to maxDecompressedSize
+func openZipPart(content io.ReaderAt, size int64, name string) (io.Reader, error) {
+	archive, err := zip.NewReader(content, size)
+	if err != nil {
+		return nil, fmt.Errorf("not a ZIP-based document: %w", err)
+	}
+
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+		if file.UncompressedSize64 > maxDecompressedSize {
+			return nil, fmt.Errorf("%s is too large", name)
+		}
+		part, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", name, err)
+		}
+		defer part.Close()
+
+		data, err := io.ReadAll(io.LimitReader(part, maxDecompressedSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if len(data) > maxDecompressedSize {
+			return nil, fmt.Errorf("%s is too large", name)
+		}
+		return bytes.NewReader(data), nil
+	}
+	return nil, fmt.Errorf("%s not found", name)
+}
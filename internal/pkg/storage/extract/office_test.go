@@ -0,0 +1,83 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOfficeDocument creates a ZIP-based office document with the part
+func newOfficeDocument(t *testing.T, name, part string) []byte {
+	buf := new(bytes.Buffer)
+	writer := zip.NewWriter(buf)
+	w, err := writer.Create("mimetype")
+	require.NoError(t, err)
+	w.Write([]byte("application/octet-stream"))
+	w, err = writer.Create(name)
+	require.NoError(t, err)
+	w.Write([]byte(part))
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestDocxText(t *testing.T) {
+	document := newOfficeDocument(t, "word/document.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+  <w:body>
+    <w:p><w:r><w:t>Первый </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>абзац</w:t></w:r><w:r><w:br/><w:t>вторая строка</w:t></w:r></w:p>
+    <w:p>
+      <w:r><w:t xml:space="preserve">Second</w:t><w:tab/><w:t>paragraph</w:t></w:r>
+      <w:del><w:r><w:delText>deleted</w:delText></w:r></w:del>
+      <w:r><w:fldChar w:fldCharType="begin"/><w:instrText>PAGE</w:instrText></w:r>
+      <w:r><w:t>co</w:t><w:noBreakHyphen/><w:t>author</w:t></w:r>
+    </w:p>
+    <w:sectPr/>
+  </w:body>
+</w:document>`)
+
+	text, _, err := Text("essay.docx", document)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Первый абзац\nвторая строка\n\nSecond\tparagraphco-author", text)
+}
+
+func TestOdtText(t *testing.T) {
+	document := newOfficeDocument(t, "content.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+    xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+    xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <office:automatic-styles><style>ignored</style></office:automatic-styles>
+  <office:body>
+    <office:text>
+      <text:h text:outline-level="1">Заголовок</text:h>
+      <text:p>Первый<text:s text:c="3"/>абзац<text:line-break/>вторая <text:span>строка</text:span></text:p>
+      <text:p>Second<text:tab/>paragraph<office:annotation><dc:creator>Teacher</dc:creator><text:p>comment</text:p></office:annotation></text:p>
+    </office:text>
+  </office:body>
+</office:document-content>`)
+
+	text, _, err := Text("essay.odt", document)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Заголовок\n\nПервый   абзац\nвторая строка\n\nSecond\tparagraph", text)
+}
+
+func TestOfficeText_Invalid(t *testing.T) {
+	// Not a ZIP file
+	_, err := docxText(strings.NewReader("plain text"), int64(len("plain text")))
+	assert.Error(t, err)
+
+	// The part holding the text is missing
+	document := newOfficeDocument(t, "content.xml", "<document/>")
+	_, err = docxText(bytes.NewReader(document), int64(len(document)))
+	assert.EqualError(t, err, "word/document.xml not found")
+
+	// Malformed XML
+	document = newOfficeDocument(t, "content.xml", "<office:document-content><text:p>")
+	_, err = odtText(bytes.NewReader(document), int64(len(document)))
+	assert.Error(t, err)
+}
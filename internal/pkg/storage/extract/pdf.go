@@ -0,0 +1,391 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// pdfSkippedStreams are the names marking the streams of PDF documents that are not page contents
+var pdfSkippedStreams = []string{
+	"/Image", "/Length1", "/Length2", "/Length3", "/FontFile", "/Type1C", "/CIDFontType0C", "/OpenType",
+	"/XRef", "/ObjStm", "/Metadata", "/EmbeddedFile", "/N ",
+}
+
+// errStreamTooLarge is returned for streams decompressing beyond the limit
+var errStreamTooLarge = errors.New("document is too large when decompressed")
+
+// pdfText extracts the text shown by the content streams of a PDF document
+// This is a basic extraction: strings are decoded as PDFDocEncoding or UTF-16 and
+// the encodings of the fonts are not read, so text of fonts with custom encodings is lost
+func pdfText(content []byte) (string, error) {
+	if !bytes.HasPrefix(content, []byte("%PDF-")) {
+		return "", errors.New("not a PDF document")
+	}
+
+	var b strings.Builder
+	decompressed := 0
+	for offset := 0; ; {
+		dictionary, data, next, ok := nextPDFStream(content, offset)
+		if !ok {
+			break
+		}
+		offset = next
+
+		if skipPDFStream(dictionary) {
+			continue
+		}
+		if bytes.Contains(dictionary, []byte("/Filter")) {
+			if !bytes.Contains(dictionary, []byte("/FlateDecode")) {
+				// Streams of other filters are images or fonts
+				continue
+			}
+			var err error
+			data, err = inflate(data, maxDecompressedSize-decompressed)
+			if errors.Is(err, errStreamTooLarge) {
+				return "", err
+			}
+			if err != nil {
+				// Damaged streams are skipped, the other streams may still hold the text
+				continue
+			}
+			decompressed += len(data)
+		}
+
+		showPDFText(&b, data)
+		b.WriteString("\n\n")
+	}
+	return b.String(), nil
+}
+
+// nextPDFStream finds the next stream of a PDF document from the offset and returns
+// the dictionary of its object, its data and the offset after it
+func nextPDFStream(content []byte, offset int) (dictionary, data []byte, next int, ok bool) {
+	for {
+		index := bytes.Index(content[offset:], []byte("stream"))
+		if index < 0 {
+			return nil, nil, 0, false
+		}
+		start := offset + index
+		offset = start + len("stream")
+
+		// Skip "endstream" and the word inside other tokens
+		if start >= 3 && string(content[start-3:start]) == "end" {
+			continue
+		}
+		if offset < len(content) && content[offset] == '\r' {
+			offset++
+		}
+		if offset >= len(content) || content[offset] != '\n' {
+			continue
+		}
+		offset++
+
+		end := bytes.Index(content[offset:], []byte("endstream"))
+		if end < 0 {
+			return nil, nil, 0, false
+		}
+		objStart := bytes.LastIndex(content[:start], []byte("obj"))
+		if objStart < 0 {
+			objStart = 0
+		}
+		return content[objStart:start], bytes.TrimRight(content[offset:offset+end], "\r\n"), offset + end + len("endstream"), true
+	}
+}
+
+// skipPDFStream reports whether a stream is not page content, by its dictionary
+func skipPDFStream(dictionary []byte) bool {
+	for _, name := range pdfSkippedStreams {
+		if bytes.Contains(dictionary, []byte(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// inflate decompresses zlib data, failing if it is larger than the limit
+func inflate(data []byte, limit int) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress stream: %w", err)
+	}
+	defer reader.Close()
+
+	inflated, err := io.ReadAll(io.LimitReader(reader, int64(limit)+1))
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("failed to decompress stream: %w", err)
+	}
+	if len(inflated) > limit {
+		return nil, errStreamTooLarge
+	}
+	return inflated, nil
+}
+
+// showPDFText writes the text shown by the operators of a content stream
+// Moves to another line start a new line, large negative offsets in TJ arrays are taken for spaces
+func showPDFText(b *strings.Builder, data []byte) {
+	lexer := pdfLexer{data: data}
+	var operands []pdfToken
+	inText := false
+	for {
+		token, ok := lexer.next()
+		if !ok {
+			return
+		}
+		if token.kind != pdfOperator {
+			operands = append(operands, token)
+			continue
+		}
+
+		switch token.value {
+		case "BT":
+			inText = true
+		case "ET":
+			inText = false
+			b.WriteString("\n")
+		case "Tj":
+			if inText && len(operands) > 0 {
+				b.WriteString(decodePDFString(operands[len(operands)-1]))
+			}
+		case "'", "\"":
+			if inText && len(operands) > 0 {
+				b.WriteString("\n" + decodePDFString(operands[len(operands)-1]))
+			}
+		case "TJ":
+			if inText {
+				for _, operand := range operands {
+					switch operand.kind {
+					case pdfString, pdfHexString:
+						b.WriteString(decodePDFString(operand))
+					case pdfNumber:
+						if offset, err := strconv.ParseFloat(operand.value, 64); err == nil && offset < -200 {
+							b.WriteString(" ")
+						}
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				if ty, err := strconv.ParseFloat(operands[len(operands)-1].value, 64); err == nil && ty != 0 {
+					b.WriteString("\n")
+				} else {
+					b.WriteString(" ")
+				}
+			}
+		case "T*", "Tm":
+			b.WriteString("\n")
+		}
+		operands = operands[:0]
+	}
+}
+
+// decodePDFString decodes a string of a content stream, as UTF-16 if it starts with its byte order mark
+// and as PDFDocEncoding, taken for Latin-1, otherwise
+func decodePDFString(token pdfToken) string {
+	raw := []byte(token.value)
+	if len(raw) >= 2 && raw[0] == 0xFE && raw[1] == 0xFF {
+		units := make([]uint16, 0, len(raw)/2)
+		for i := 2; i+1 < len(raw); i += 2 {
+			units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+
+	runes := make([]rune, 0, len(raw))
+	for _, c := range raw {
+		if c < 0x20 && c != '\t' && c != '\n' {
+			continue
+		}
+		runes = append(runes, rune(c))
+	}
+	return string(runes)
+}
+
+// pdfTokenKind is the kind of a token of a content stream
+type pdfTokenKind int
+
+const (
+	pdfOperator pdfTokenKind = iota
+	pdfNumber
+	pdfString
+	pdfHexString
+	pdfOther
+)
+
+// pdfToken is a token of a content stream, strings hold their decoded bytes
+type pdfToken struct {
+	kind  pdfTokenKind
+	value string
+}
+
+// pdfLexer splits a content stream into tokens
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+// next returns the next token, ok is false at the end of the stream
+func (l *pdfLexer) next() (token pdfToken, ok bool) {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isPDFSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		case c == '(':
+			return pdfToken{kind: pdfString, value: l.literalString()}, true
+		case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<', c == '>' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '>':
+			l.pos += 2
+			return pdfToken{kind: pdfOther}, true
+		case c == '<':
+			return pdfToken{kind: pdfHexString, value: l.hexString()}, true
+		case c == '[', c == ']', c == '{', c == '}', c == ')', c == '>':
+			l.pos++
+			return pdfToken{kind: pdfOther}, true
+		case c == '/':
+			l.pos++
+			return pdfToken{kind: pdfOther, value: l.regular()}, true
+		default:
+			value := l.regular()
+			if value == "" {
+				l.pos++
+				continue
+			}
+			if _, err := strconv.ParseFloat(value, 64); err == nil {
+				return pdfToken{kind: pdfNumber, value: value}, true
+			}
+			if value == "BI" {
+				l.skipInlineImage()
+				continue
+			}
+			return pdfToken{kind: pdfOperator, value: value}, true
+		}
+	}
+	return pdfToken{}, false
+}
+
+// regular reads a run of regular characters
+func (l *pdfLexer) regular() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// literalString reads a literal string, balanced parentheses are part of it
+func (l *pdfLexer) literalString() string {
+	var b []byte
+	depth := 0
+	for l.pos++; l.pos < len(l.data); l.pos++ {
+		c := l.data[l.pos]
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				l.pos++
+				return string(b)
+			}
+			depth--
+		case '\\':
+			l.pos++
+			if l.pos >= len(l.data) {
+				return string(b)
+			}
+			c = l.data[l.pos]
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				// A backslash at the end of a line continues the string on the next one
+				if c == '\r' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '\n' {
+					l.pos++
+				}
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					value := 0
+					for n := 0; n < 3 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; n++ {
+						value = value*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					l.pos--
+					c = byte(value)
+				}
+			}
+		}
+		b = append(b, c)
+	}
+	return string(b)
+}
+
+// hexString reads a hexadecimal string, a missing last digit is taken for zero
+func (l *pdfLexer) hexString() string {
+	var digits []byte
+	for l.pos++; l.pos < len(l.data) && l.data[l.pos] != '>'; l.pos++ {
+		if c := l.data[l.pos]; isHexDigit(c) {
+			digits = append(digits, c)
+		}
+	}
+	l.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	value := make([]byte, len(digits)/2)
+	for i := range value {
+		n, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		value[i] = byte(n)
+	}
+	return string(value)
+}
+
+// skipInlineImage skips the data of an inline image up to its EI operator
+func (l *pdfLexer) skipInlineImage() {
+	end := bytes.Index(l.data[l.pos:], []byte("EI"))
+	for end >= 0 {
+		after := l.pos + end + 2
+		if after >= len(l.data) || isPDFSpace(l.data[after]) {
+			l.pos = after
+			return
+		}
+		next := bytes.Index(l.data[after:], []byte("EI"))
+		if next < 0 {
+			break
+		}
+		end = after - l.pos + next
+	}
+	l.pos = len(l.data)
+}
+
+// isPDFSpace reports whether a byte is a white-space character of PDF
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+// isPDFDelimiter reports whether a byte is a delimiter character of PDF
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// isHexDigit reports whether a byte is a hexadecimal digit
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
@@ -0,0 +1,61 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPDF creates a PDF document of the streams, given as dictionary and data pairs
+func newPDF(streams ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	b.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	for i := 0; i < len(streams); i += 2 {
+		fmt.Fprintf(&b, "%d 0 obj\n<< %s /Length %d >>\nstream\n%s\nendstream\nendobj\n", i/2+3, streams[i], len(streams[i+1]), streams[i+1])
+	}
+	b.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return b.Bytes()
+}
+
+// deflate compresses data with zlib
+func deflate(data string) string {
+	var b bytes.Buffer
+	writer := zlib.NewWriter(&b)
+	writer.Write([]byte(data))
+	writer.Close()
+	return b.String()
+}
+
+func TestPdfText(t *testing.T) {
+	firstPage := "BT /F1 12 Tf 72 720 Td (Hello, \\(PDF\\) world!) Tj 0 -14 Td [(Kern)-20(ing) -300 (works)] TJ ET"
+	secondPage := "q 1 0 0 1 0 0 cm Q\nBT /F1 12 Tf 1 0 0 1 72 720 Tm <FEFF041F04400438043204350442> Tj (line\\040two\\\nend) ' ET\n" +
+		"BI /W 1 /H 1 /BPC 8 /CS /G ID \x00EI\x01 EI"
+
+	document := newPDF(
+		"", firstPage,
+		"/Filter /FlateDecode", deflate(secondPage),
+		"/Subtype /Image /Filter /FlateDecode", deflate("BT (image) Tj ET"),
+		"/Filter /DCTDecode", "BT (jpeg) Tj ET",
+		"/Filter /FlateDecode", "damaged",
+	)
+
+	text, _, err := Text("essay.pdf", document)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, (PDF) world!\nKerning works\n\nПривет\nline twoend", text)
+}
+
+func TestPdfText_Invalid(t *testing.T) {
+	_, err := pdfText([]byte("plain text"))
+	assert.Error(t, err)
+
+	// Streams decompressing beyond the limit are refused
+	_, err = pdfText(newPDF("/Filter /FlateDecode", deflate(strings.Repeat(" ", maxDecompressedSize+1))))
+	assert.ErrorIs(t, err, errStreamTooLarge)
+}
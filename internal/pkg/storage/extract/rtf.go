@@ -0,0 +1,176 @@
+package extract
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// rtfSkippedDestinations are the destinations of RTF documents that hold no text of the document
+// Destinations marked ignorable with \* are skipped too
+var rtfSkippedDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true, "pict": true, "object": true,
+	"header": true, "headerl": true, "headerr": true, "headerf": true,
+	"footer": true, "footerl": true, "footerr": true, "footerf": true,
+	"footnote": true, "annotation": true, "listtable": true, "listoverridetable": true, "revtbl": true,
+	"filetbl": true, "rsidtbl": true, "themedata": true, "colorschememapping": true, "datastore": true,
+	"latentstyles": true, "xmlnstbl": true, "generator": true, "fldinst": true,
+}
+
+// rtfSymbols are the control words of RTF documents standing for characters
+var rtfSymbols = map[string]string{
+	"par": "\n\n", "sect": "\n\n", "page": "\n\n", "line": "\n", "row": "\n",
+	"tab": "\t", "cell": "\t",
+	"emdash": "—", "endash": "–", "bullet": "•", "emspace": " ", "enspace": " ", "qmspace": " ",
+	"lquote": "‘", "rquote": "’", "ldblquote": "“", "rdblquote": "”",
+}
+
+// rtfCodePages are the code pages of RTF documents set by \ansicpg, Windows-1252 is the default
+var rtfCodePages = map[int]*charmap.Charmap{
+	437: charmap.CodePage437, 850: charmap.CodePage850, 866: charmap.CodePage866, 874: charmap.Windows874,
+	1250: charmap.Windows1250, 1251: charmap.Windows1251, 1252: charmap.Windows1252, 1253: charmap.Windows1253,
+	1254: charmap.Windows1254, 1255: charmap.Windows1255, 1256: charmap.Windows1256, 1257: charmap.Windows1257,
+	1258: charmap.Windows1258, 10000: charmap.Macintosh, 20866: charmap.KOI8R, 21866: charmap.KOI8U,
+}
+
+// rtfGroup is the state of a group of an RTF document
+type rtfGroup struct {
+	// skip is set in destinations without text
+	skip bool
+
+	// unicodeSkip is the number of fallback characters following \u, set by \uc
+	unicodeSkip int
+}
+
+// rtfText extracts the text of an RTF document
+// Characters are decoded from the code page of the document or read from their \u code points
+func rtfText(content []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(content, " \t\r\n"), []byte(`{\rtf`)) {
+		return "", errors.New("not an RTF document")
+	}
+
+	var b strings.Builder
+	codePage := charmap.Windows1252
+	group := rtfGroup{unicodeSkip: 1}
+	var groups []rtfGroup
+
+	// pendingSkip is the number of fallback characters still to skip after \u
+	pendingSkip := 0
+	writeByte := func(c byte) {
+		if pendingSkip > 0 {
+			pendingSkip--
+			return
+		}
+		if !group.skip {
+			b.WriteRune(codePage.DecodeByte(c))
+		}
+	}
+	write := func(text string) {
+		pendingSkip = 0
+		if !group.skip {
+			b.WriteString(text)
+		}
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch c {
+		case '{':
+			groups = append(groups, group)
+			pendingSkip = 0
+		case '}':
+			if len(groups) == 0 {
+				return "", errors.New("unbalanced group")
+			}
+			group, groups = groups[len(groups)-1], groups[:len(groups)-1]
+			pendingSkip = 0
+		case '\r', '\n':
+			// Line breaks of the source are not text
+		case '\\':
+			if i+1 >= len(content) {
+				return "", errors.New("unexpected end of document")
+			}
+			i++
+			c = content[i]
+			switch {
+			case isASCIILetter(c):
+				start := i
+				for i < len(content) && isASCIILetter(content[i]) {
+					i++
+				}
+				word := string(content[start:i])
+
+				paramStart := i
+				if i < len(content) && content[i] == '-' {
+					i++
+				}
+				for i < len(content) && content[i] >= '0' && content[i] <= '9' {
+					i++
+				}
+				param, hasParam := 0, i > paramStart
+				if hasParam {
+					param, _ = strconv.Atoi(string(content[paramStart:i]))
+				}
+				// A space delimits the control word and is part of it
+				if i >= len(content) || content[i] != ' ' {
+					i--
+				}
+
+				switch {
+				case rtfSkippedDestinations[word]:
+					group.skip = true
+				case word == "bin":
+					// Skip the binary data following the control word
+					i += max(param, 0)
+				case word == "ansicpg":
+					if cp, ok := rtfCodePages[param]; ok {
+						codePage = cp
+					}
+				case word == "uc":
+					group.unicodeSkip = max(param, 0)
+				case word == "u" && hasParam:
+					if param < 0 {
+						param += 65536
+					}
+					write(string(rune(param)))
+					pendingSkip = group.unicodeSkip
+				default:
+					if symbol, ok := rtfSymbols[word]; ok {
+						write(symbol)
+					}
+				}
+			case c == '\'':
+				if i+2 >= len(content) {
+					return "", errors.New("unexpected end of document")
+				}
+				value, err := strconv.ParseUint(string(content[i+1:i+3]), 16, 8)
+				if err != nil {
+					return "", errors.New("invalid hex character")
+				}
+				i += 2
+				writeByte(byte(value))
+			case c == '*':
+				group.skip = true
+			case c == '~':
+				write(" ")
+			case c == '_':
+				write("-")
+			case c == '\r' || c == '\n':
+				write("\n\n")
+			case c == '\\' || c == '{' || c == '}':
+				writeByte(c)
+			}
+		default:
+			writeByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+// isASCIILetter reports whether a byte is an ASCII letter
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,49 @@
+package extract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRtfText(t *testing.T) {
+	document := `{\rtf1\ansi\ansicpg1251\deff0
+{\fonttbl{\f0\froman\fcharset204 Times New Roman;}}
+{\colortbl;\red0\green0\blue0;}
+{\*\generator Riched20 10.0.19041}
+{\info{\author Ivanov}}
+\viewkind4\uc1\pard\f0\fs24 \'cf\'e5\'f0\'e2\'fb\'e9 {\b \'e0\'e1\'e7\'e0\'f6}\par
+Second\tab paragraph\line with a \u8470?  sign and \{braces\}\par
+{\header page header}
+{\field{\*\fldinst HYPERLINK "https://example.com"}{\fldrslt link}}\~text\_end\par
+}`
+
+	text, _, err := Text("essay.rtf", []byte(document))
+
+	require.NoError(t, err)
+	assert.Equal(t, "Первый абзац\n\nSecond\tparagraph\nwith a №  sign and {braces}\n\nlink\u00a0text-end", text)
+}
+
+func TestRtfText_Unicode(t *testing.T) {
+	// \uc2 skips two fallback characters, negative code points wrap around
+	text, err := rtfText([]byte(`{\rtf1{\uc2\u1055\'3f\'3f\u-1 ??}\u1088?}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, "П￿р", text)
+}
+
+func TestRtfText_Invalid(t *testing.T) {
+	tests := []string{
+		"plain text",
+		`{\rtf1 text}}`,
+		`{\rtf1 \'4`,
+		`{\rtf1 \'zz}`,
+		`{\rtf1 \`,
+	}
+
+	for _, document := range tests {
+		_, err := rtfText([]byte(document))
+		assert.Error(t, err, document)
+	}
+}
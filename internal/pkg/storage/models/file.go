@@ -0,0 +1,21 @@
+package models
+
+// File is the metadata of a stored file
+type File struct {
+	ID   string
+	Name string
+
+	// Hash is the hex SHA-256 of the content of the file as uploaded
+	Hash string
+
+	// Location is where the file is stored as uploaded
+	Location string
+
+	// TextLocation is where the plain text extracted from the file is stored in UTF-8,
+	// it equals Location for UTF-8 plain text files
+	TextLocation string
+
+	// Encoding is the detected encoding of text files, such as "utf-8" or "windows-1251",
+	// it is empty for documents of other formats
+	Encoding string
+}
@@ -2,15 +2,17 @@ package repository
 
 import (
 	"context"
+
+	"local.dev/doc-analyzer/internal/pkg/storage/models"
 )
 
 // FileRepository defines the interface for file metadata operations
 type FileRepository interface {
 	// SaveFile saves file metadata to the database
-	SaveFile(ctx context.Context, id, name, hash, location string) error
+	SaveFile(ctx context.Context, file *models.File) error
 	
 	// GetFileByID retrieves file metadata by ID
-	GetFileByID(ctx context.Context, id string) (name string, location string, err error)
+	GetFileByID(ctx context.Context, id string) (*models.File, error)
 	
 	// GetFileByHash retrieves file metadata by hash
 	GetFileByHash(ctx context.Context, hash string) (id string, err error)
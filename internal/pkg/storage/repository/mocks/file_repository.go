@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"github.com/stretchr/testify/mock"
+	"local.dev/doc-analyzer/internal/pkg/storage/models"
 	"local.dev/doc-analyzer/internal/pkg/storage/repository"
 )
 
@@ -15,15 +16,18 @@ type MockFileRepository struct {
 var _ repository.FileRepository = (*MockFileRepository)(nil)
 
 // SaveFile mocks the SaveFile method
-func (m *MockFileRepository) SaveFile(ctx context.Context, id, name, hash, location string) error {
-	args := m.Called(ctx, id, name, hash, location)
+func (m *MockFileRepository) SaveFile(ctx context.Context, file *models.File) error {
+	args := m.Called(ctx, file)
 	return args.Error(0)
 }
 
 // GetFileByID mocks the GetFileByID method
-func (m *MockFileRepository) GetFileByID(ctx context.Context, id string) (name string, location string, err error) {
+func (m *MockFileRepository) GetFileByID(ctx context.Context, id string) (*models.File, error) {
 	args := m.Called(ctx, id)
-	return args.String(0), args.String(1), args.Error(2)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.File), args.Error(1)
 }
 
 // GetFileByHash mocks the GetFileByHash method
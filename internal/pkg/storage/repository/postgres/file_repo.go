@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 
+	"local.dev/doc-analyzer/internal/pkg/storage/models"
 	"local.dev/doc-analyzer/internal/pkg/storage/repository"
 )
 
@@ -20,12 +21,12 @@ func NewFileRepo(db *sql.DB) repository.FileRepository {
 }
 
 // SaveFile saves file metadata to the database
-func (r *FileRepo) SaveFile(ctx context.Context, id, name, hash, location string) error {
+func (r *FileRepo) SaveFile(ctx context.Context, file *models.File) error {
 	query := `
-		INSERT INTO files (id, name, hash, location, created_at)
-		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		INSERT INTO files (id, name, hash, location, text_location, encoding, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP)
 	`
-	_, err := r.db.ExecContext(ctx, query, id, name, hash, location)
+	_, err := r.db.ExecContext(ctx, query, file.ID, file.Name, file.Hash, file.Location, file.TextLocation, file.Encoding)
 	if err != nil {
 		return fmt.Errorf("failed to save file metadata: %w", err)
 	}
@@ -33,19 +34,23 @@ func (r *FileRepo) SaveFile(ctx context.Context, id, name, hash, location string
 }
 
 // GetFileByID retrieves file metadata by ID
-func (r *FileRepo) GetFileByID(ctx context.Context, id string) (string, string, error) {
+// Files stored before text extraction have no text location, their content is plain text
+func (r *FileRepo) GetFileByID(ctx context.Context, id string) (*models.File, error) {
 	query := `
-		SELECT name, location FROM files WHERE id = $1
+		SELECT name, hash, location, text_location, encoding FROM files WHERE id = $1
 	`
-	var name, location string
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&name, &location)
+	file := &models.File{ID: id}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&file.Name, &file.Hash, &file.Location, &file.TextLocation, &file.Encoding)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", "", fmt.Errorf("file not found with id %s", id)
+			return nil, fmt.Errorf("file not found with id %s", id)
 		}
-		return "", "", fmt.Errorf("failed to get file by id: %w", err)
+		return nil, fmt.Errorf("failed to get file by id: %w", err)
 	}
-	return name, location, nil
+	if file.TextLocation == "" {
+		file.TextLocation = file.Location
+	}
+	return file, nil
 }
 
 // GetFileByHash retrieves file metadata by hash
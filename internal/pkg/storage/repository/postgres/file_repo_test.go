@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"local.dev/doc-analyzer/internal/pkg/storage/models"
 	"local.dev/doc-analyzer/internal/pkg/storage/repository/postgres"
 )
 
@@ -26,17 +27,18 @@ func TestSaveFile(t *testing.T) {
 	t.Run("Successful save", func(t *testing.T) {
 		// Set up mock expectations
 		mock.ExpectExec("INSERT INTO files").
-			WithArgs("file123", "test.txt", "hash123", "files/test.txt").
+			WithArgs("file123", "test.txt", "hash123", "files/file123", "files/file123.txt", "windows-1251").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		// Call the method
-		err := repo.SaveFile(
-			context.Background(),
-			"file123",
-			"test.txt",
-			"hash123",
-			"files/test.txt",
-		)
+		err := repo.SaveFile(context.Background(), &models.File{
+			ID:           "file123",
+			Name:         "test.txt",
+			Hash:         "hash123",
+			Location:     "files/file123",
+			TextLocation: "files/file123.txt",
+			Encoding:     "windows-1251",
+		})
 
 		// Assert
 		assert.NoError(t, err)
@@ -47,17 +49,18 @@ func TestSaveFile(t *testing.T) {
 	t.Run("Database error", func(t *testing.T) {
 		// Set up mock expectations
 		mock.ExpectExec("INSERT INTO files").
-			WithArgs("file123", "test.txt", "hash123", "files/test.txt").
+			WithArgs("file123", "test.txt", "hash123", "files/file123", "files/file123.txt", "windows-1251").
 			WillReturnError(errors.New("database error"))
 
 		// Call the method
-		err := repo.SaveFile(
-			context.Background(),
-			"file123",
-			"test.txt",
-			"hash123",
-			"files/test.txt",
-		)
+		err := repo.SaveFile(context.Background(), &models.File{
+			ID:           "file123",
+			Name:         "test.txt",
+			Hash:         "hash123",
+			Location:     "files/file123",
+			TextLocation: "files/file123.txt",
+			Encoding:     "windows-1251",
+		})
 
 		// Assert
 		assert.Error(t, err)
@@ -78,35 +81,63 @@ func TestGetFileByID(t *testing.T) {
 	// Test case: successful get
 	t.Run("Successful get", func(t *testing.T) {
 		// Set up mock expectations
-		rows := sqlmock.NewRows([]string{"name", "location"}).
-			AddRow("test.txt", "files/test.txt")
+		rows := sqlmock.NewRows([]string{"name", "hash", "location", "text_location", "encoding"}).
+			AddRow("test.txt", "hash123", "files/file123", "files/file123.txt", "windows-1251")
 
-		mock.ExpectQuery("SELECT name, location FROM files").
+		mock.ExpectQuery("SELECT name, hash, location, text_location, encoding FROM files").
 			WithArgs("file123").
 			WillReturnRows(rows)
 
 		// Call the method
-		name, location, err := repo.GetFileByID(
+		file, err := repo.GetFileByID(
 			context.Background(),
 			"file123",
 		)
 
 		// Assert
 		assert.NoError(t, err)
-		assert.Equal(t, "test.txt", name)
-		assert.Equal(t, "files/test.txt", location)
+		assert.Equal(t, &models.File{
+			ID:           "file123",
+			Name:         "test.txt",
+			Hash:         "hash123",
+			Location:     "files/file123",
+			TextLocation: "files/file123.txt",
+			Encoding:     "windows-1251",
+		}, file)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: file stored before text extraction
+	t.Run("Without text location", func(t *testing.T) {
+		// Set up mock expectations
+		rows := sqlmock.NewRows([]string{"name", "hash", "location", "text_location", "encoding"}).
+			AddRow("test.txt", "hash123", "files/file123", "", "")
+
+		mock.ExpectQuery("SELECT name, hash, location, text_location, encoding FROM files").
+			WithArgs("file123").
+			WillReturnRows(rows)
+
+		// Call the method
+		file, err := repo.GetFileByID(
+			context.Background(),
+			"file123",
+		)
+
+		// Assert: the text is the content of the file
+		assert.NoError(t, err)
+		assert.Equal(t, "files/file123", file.TextLocation)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	// Test case: not found
 	t.Run("Not found", func(t *testing.T) {
 		// Set up mock expectations
-		mock.ExpectQuery("SELECT name, location FROM files").
+		mock.ExpectQuery("SELECT name, hash, location, text_location, encoding FROM files").
 			WithArgs("file123").
 			WillReturnError(sql.ErrNoRows)
 
 		// Call the method
-		_, _, err := repo.GetFileByID(
+		_, err := repo.GetFileByID(
 			context.Background(),
 			"file123",
 		)
@@ -120,12 +151,12 @@ func TestGetFileByID(t *testing.T) {
 	// Test case: database error
 	t.Run("Database error", func(t *testing.T) {
 		// Set up mock expectations
-		mock.ExpectQuery("SELECT name, location FROM files").
+		mock.ExpectQuery("SELECT name, hash, location, text_location, encoding FROM files").
 			WithArgs("file123").
 			WillReturnError(errors.New("database error"))
 
 		// Call the method
-		_, _, err := repo.GetFileByID(
+		_, err := repo.GetFileByID(
 			context.Background(),
 			"file123",
 		)
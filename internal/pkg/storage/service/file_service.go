@@ -1,32 +1,57 @@
+// Package service implements the storage of uploaded files together with the text extracted from them
+// Uploads are streamed into the storage and spooled to a temporary file for the extraction,
+// files larger than the maximum size are refused with ErrFileTooLarge
 package service
 
 import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"os"
+
 	"github.com/google/uuid"
 
+	"local.dev/doc-analyzer/internal/pkg/storage/extract"
+	"local.dev/doc-analyzer/internal/pkg/storage/models"
 	"local.dev/doc-analyzer/internal/pkg/storage/repository"
 	"local.dev/doc-analyzer/internal/pkg/storage/storage"
 )
 
+// DefaultMaxFileSize is the default maximum size of an uploaded file
+const DefaultMaxFileSize = 100 << 20
+
+// ErrFileTooLarge is returned for uploaded files larger than the maximum size
+var ErrFileTooLarge = errors.New("file is too large")
+
 // FileService handles the business logic for file operations
 type FileService struct {
 	repo    repository.FileRepository
 	storage storage.FileStorage
+
+	// MaxFileSize is the maximum size of an uploaded file in bytes
+	MaxFileSize int64
 }
 
 // NewFileService creates a new FileService instance
 func NewFileService(repo repository.FileRepository, storage storage.FileStorage) *FileService {
 	return &FileService{
-		repo:    repo,
-		storage: storage,
+		repo:        repo,
+		storage:     storage,
+		MaxFileSize: DefaultMaxFileSize,
 	}
 }
 
 // UploadFile handles the file upload process
+// The file is stored as uploaded together with its plain text in UTF-8, which is what GetFile returns
 func (s *FileService) UploadFile(ctx context.Context, fileName string, content []byte) (string, error) {
+	if int64(len(content)) > s.MaxFileSize {
+		return "", ErrFileTooLarge
+	}
+
 	// Calculate file hash
 	hash := sha256.Sum256(content)
 	hashStr := hex.EncodeToString(hash[:])
@@ -42,6 +67,12 @@ func (s *FileService) UploadFile(ctx context.Context, fileName string, content [
 		return fileID, nil
 	}
 
+	// Extract the text analyzed for the file, converted to UTF-8
+	text, encoding, err := extract.Text(fileName, content)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract text: %w", err)
+	}
+
 	// Generate a new file ID
 	fileID = uuid.New().String()
 
@@ -53,27 +84,200 @@ func (s *FileService) UploadFile(ctx context.Context, fileName string, content [
 		return "", fmt.Errorf("failed to save file content: %w", err)
 	}
 
-	// Save file metadata to repository
-	if err := s.repo.SaveFile(ctx, fileID, fileName, hashStr, location); err != nil {
-		return "", fmt.Errorf("failed to save file metadata: %w", err)
+	file := &models.File{
+		ID:       fileID,
+		Name:     fileName,
+		Hash:     hashStr,
+		Location: location,
+		Encoding: encoding,
+	}
+	if err := s.saveText(ctx, file, text); err != nil {
+		s.deleteFile(ctx, fileID)
+		return "", err
+	}
+
+	return fileID, nil
+}
+
+// UploadFileStream handles the upload of a file read until EOF, for files too large for one message
+// The content is written through to storage as it is read; the text extraction needs all of it,
+// so it is also spooled to a temporary file. Duplicates and documents without text are removed from storage afterwards
+// Files larger than MaxFileSize are refused with ErrFileTooLarge
+func (s *FileService) UploadFileStream(ctx context.Context, fileName string, content io.Reader) (string, error) {
+	// Reject unsupported formats before reading any content
+	if !extract.Supported(fileName) {
+		return "", fmt.Errorf("failed to extract text: %w", extract.ErrUnsupportedFormat)
+	}
+
+	spool, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}()
+
+	// Generate a new file ID, the hash is only known once the file is stored
+	fileID := uuid.New().String()
+	location := fileID
+
+	// Save file content to storage, hashing and spooling it on the way
+	hash := sha256.New()
+	limited := &sizeLimitReader{reader: content, remaining: s.MaxFileSize}
+	if err := s.storage.SaveFileStream(ctx, location, io.TeeReader(limited, io.MultiWriter(hash, spool))); err != nil {
+		if limited.exceeded {
+			return "", ErrFileTooLarge
+		}
+		return "", fmt.Errorf("failed to save file content: %w", err)
+	}
+	hashStr := hex.EncodeToString(hash.Sum(nil))
+
+	// Check if file with this hash already exists
+	existingID, err := s.repo.GetFileByHash(ctx, hashStr)
+	if err != nil {
+		s.deleteFile(ctx, fileID)
+		return "", fmt.Errorf("failed to check file existence: %w", err)
+	}
+
+	// If file exists, drop the new copy and return its ID
+	if existingID != "" {
+		s.deleteFile(ctx, fileID)
+		return existingID, nil
+	}
+
+	// Extract the text analyzed for the file, converted to UTF-8
+	size := s.MaxFileSize - limited.remaining
+	text, encoding, err := extract.TextFrom(fileName, spool, size)
+	if err != nil {
+		s.deleteFile(ctx, fileID)
+		return "", fmt.Errorf("failed to extract text: %w", err)
+	}
+
+	file := &models.File{
+		ID:       fileID,
+		Name:     fileName,
+		Hash:     hashStr,
+		Location: location,
+		Encoding: encoding,
+	}
+	if err := s.saveText(ctx, file, text); err != nil {
+		s.deleteFile(ctx, fileID)
+		return "", err
 	}
 
 	return fileID, nil
 }
 
-// GetFile retrieves a file by its ID
+// sizeLimitReader reads at most remaining bytes, it fails with ErrFileTooLarge if there are more
+type sizeLimitReader struct {
+	reader    io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (r *sizeLimitReader) Read(p []byte) (int, error) {
+	// Read one byte more than remains to tell a file of exactly the maximum size from a larger one
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.reader.Read(p)
+	if int64(n) > r.remaining {
+		r.exceeded = true
+		return 0, ErrFileTooLarge
+	}
+	r.remaining -= int64(n)
+	return n, err
+}
+
+// saveText saves the extracted text beside the stored file, then the file metadata
+// UTF-8 plain text files are their own text, which has the hash of the file
+func (s *FileService) saveText(ctx context.Context, file *models.File, text string) error {
+	file.TextLocation = file.Location
+	textHash := sha256.Sum256([]byte(text))
+	if hex.EncodeToString(textHash[:]) != file.Hash {
+		file.TextLocation = file.ID + ".txt"
+		if err := s.storage.SaveFile(ctx, file.TextLocation, []byte(text)); err != nil {
+			return fmt.Errorf("failed to save file text: %w", err)
+		}
+	}
+
+	// Save file metadata to repository
+	if err := s.repo.SaveFile(ctx, file); err != nil {
+		return fmt.Errorf("failed to save file metadata: %w", err)
+	}
+
+	return nil
+}
+
+// deleteFile removes the content and the text of a file that was not uploaded after all
+// Failures are only logged, they leave unreferenced content behind but do not fail the request
+func (s *FileService) deleteFile(ctx context.Context, fileID string) {
+	for _, location := range []string{fileID, fileID + ".txt"} {
+		if err := s.storage.DeleteFile(ctx, location); err != nil {
+			log.Printf("Failed to delete file content at %s: %v", location, err)
+		}
+	}
+}
+
+// GetFile retrieves the plain text of a file by its ID, together with the name of the file
 func (s *FileService) GetFile(ctx context.Context, fileID string) (string, []byte, error) {
-	// Get file metadata from repository
-	fileName, location, err := s.repo.GetFileByID(ctx, fileID)
+	file, err := s.getFileMetadata(ctx, fileID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Get file text from storage
+	content, err := s.storage.GetFile(ctx, file.TextLocation)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	return file.Name, content, nil
+}
+
+// GetOriginalFile retrieves a file as uploaded by its ID
+func (s *FileService) GetOriginalFile(ctx context.Context, fileID string) (string, []byte, error) {
+	file, err := s.getFileMetadata(ctx, fileID)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to get file metadata: %w", err)
+		return "", nil, err
 	}
 
 	// Get file content from storage
-	content, err := s.storage.GetFile(ctx, location)
+	content, err := s.storage.GetFile(ctx, file.Location)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to get file content: %w", err)
 	}
 
-	return fileName, content, nil
+	return file.Name, content, nil
+}
+
+// OpenFile opens the plain text of a file, or the file as uploaded, by its ID for reading
+// It returns the name of the file, the caller closes the content
+func (s *FileService) OpenFile(ctx context.Context, fileID string, original bool) (string, io.ReadCloser, error) {
+	file, err := s.getFileMetadata(ctx, fileID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	location := file.TextLocation
+	if original {
+		location = file.Location
+	}
+
+	content, err := s.storage.OpenFile(ctx, location)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	return file.Name, content, nil
+}
+
+// getFileMetadata retrieves the metadata of a file from the repository
+func (s *FileService) getFileMetadata(ctx context.Context, fileID string) (*models.File, error) {
+	file, err := s.repo.GetFileByID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file metadata: %w", err)
+	}
+	return file, nil
 }
\ No newline at end of file
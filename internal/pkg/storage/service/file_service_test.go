@@ -1,13 +1,20 @@
 package service_test
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"errors"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"local.dev/doc-analyzer/internal/pkg/storage/extract"
+	"local.dev/doc-analyzer/internal/pkg/storage/models"
 	"local.dev/doc-analyzer/internal/pkg/storage/service"
 )
 
@@ -16,14 +23,17 @@ type MockFileRepository struct {
 	mock.Mock
 }
 
-func (m *MockFileRepository) SaveFile(ctx context.Context, fileID, fileName, hash, location string) error {
-	args := m.Called(ctx, fileID, fileName, hash, location)
+func (m *MockFileRepository) SaveFile(ctx context.Context, file *models.File) error {
+	args := m.Called(ctx, file)
 	return args.Error(0)
 }
 
-func (m *MockFileRepository) GetFileByID(ctx context.Context, fileID string) (string, string, error) {
+func (m *MockFileRepository) GetFileByID(ctx context.Context, fileID string) (*models.File, error) {
 	args := m.Called(ctx, fileID)
-	return args.String(0), args.String(1), args.Error(2)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.File), args.Error(1)
 }
 
 func (m *MockFileRepository) GetFileByHash(ctx context.Context, hash string) (string, error) {
@@ -46,6 +56,24 @@ func (m *MockFileStorage) GetFile(ctx context.Context, location string) ([]byte,
 	return args.Get(0).([]byte), args.Error(1)
 }
 
+func (m *MockFileStorage) SaveFileStream(ctx context.Context, location string, content io.Reader) error {
+	args := m.Called(ctx, location, content)
+	return args.Error(0)
+}
+
+func (m *MockFileStorage) OpenFile(ctx context.Context, location string) (io.ReadCloser, error) {
+	args := m.Called(ctx, location)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockFileStorage) DeleteFile(ctx context.Context, location string) error {
+	args := m.Called(ctx, location)
+	return args.Error(0)
+}
+
 func TestFileService_UploadFile(t *testing.T) {
 	// Setup
 	mockRepo := new(MockFileRepository)
@@ -64,8 +92,11 @@ func TestFileService_UploadFile(t *testing.T) {
 		// Mock storage to save file successfully
 		mockStorage.On("SaveFile", ctx, mock.Anything, content).Return(nil)
 		
-		// Mock repository to save file metadata successfully
-		mockRepo.On("SaveFile", ctx, mock.Anything, fileName, mock.Anything, mock.Anything).Return(nil)
+		// Mock repository to save file metadata successfully, the file is its own text
+		mockRepo.On("SaveFile", ctx, mock.MatchedBy(func(file *models.File) bool {
+			return file.Name == fileName && file.Location == file.ID && file.TextLocation == file.Location &&
+				file.Encoding == extract.EncodingUTF8
+		})).Return(nil)
 		
 		// Call the method
 		fileID, err := fileService.UploadFile(ctx, fileName, content)
@@ -148,6 +179,57 @@ func TestFileService_UploadFile(t *testing.T) {
 		// Repository should not be called to save metadata because of error
 		mockRepo.AssertNotCalled(t, "SaveFile")
 	})
+
+	// Test case: the stored file is deleted if its metadata cannot be saved
+	t.Run("Error saving file metadata", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockStorage := new(MockFileStorage)
+		fileService := service.NewFileService(mockRepo, mockStorage)
+
+		mockRepo.On("GetFileByHash", ctx, mock.Anything).Return("", nil)
+		mockStorage.On("SaveFile", ctx, mock.Anything, content).Return(nil)
+		mockRepo.On("SaveFile", ctx, mock.Anything).Return(errors.New("database error"))
+		mockStorage.On("DeleteFile", ctx, mock.Anything).Return(nil)
+
+		_, err := fileService.UploadFile(ctx, fileName, content)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to save file metadata")
+		mockStorage.AssertNumberOfCalls(t, "DeleteFile", 2)
+	})
+
+	// Test case: the stored file is deleted if its text cannot be saved
+	t.Run("Error saving file text", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockStorage := new(MockFileStorage)
+		fileService := service.NewFileService(mockRepo, mockStorage)
+
+		document := newDocx(t, "Текст работы")
+		mockRepo.On("GetFileByHash", ctx, mock.Anything).Return("", nil)
+		mockStorage.On("SaveFile", ctx, mock.Anything, document).Return(nil)
+		mockStorage.On("SaveFile", ctx, mock.Anything, []byte("Текст работы")).Return(errors.New("storage error"))
+		mockStorage.On("DeleteFile", ctx, mock.Anything).Return(nil)
+
+		_, err := fileService.UploadFile(ctx, "essay.docx", document)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to save file text")
+		mockStorage.AssertNumberOfCalls(t, "DeleteFile", 2)
+		mockRepo.AssertNotCalled(t, "SaveFile", mock.Anything, mock.Anything)
+	})
+
+	// Test case: files larger than the maximum size are refused
+	t.Run("File too large", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockStorage := new(MockFileStorage)
+		fileService := service.NewFileService(mockRepo, mockStorage)
+		fileService.MaxFileSize = int64(len(content)) - 1
+
+		_, err := fileService.UploadFile(ctx, fileName, content)
+
+		assert.ErrorIs(t, err, service.ErrFileTooLarge)
+		mockRepo.AssertNotCalled(t, "GetFileByHash", mock.Anything, mock.Anything)
+	})
 }
 
 func TestFileService_GetFile(t *testing.T) {
@@ -164,7 +246,7 @@ func TestFileService_GetFile(t *testing.T) {
 		// Mock repository to return file metadata
 		fileName := "test.txt"
 		location := "file123"
-		mockRepo.On("GetFileByID", ctx, fileID).Return(fileName, location, nil)
+		mockRepo.On("GetFileByID", ctx, fileID).Return(&models.File{ID: fileID, Name: fileName, Location: location, TextLocation: location}, nil)
 		
 		// Mock storage to return file content
 		content := []byte("test content")
@@ -190,7 +272,7 @@ func TestFileService_GetFile(t *testing.T) {
 		fileService = service.NewFileService(mockRepo, mockStorage)
 
 		// Mock repository to return error
-		mockRepo.On("GetFileByID", ctx, fileID).Return("", "", errors.New("database error"))
+		mockRepo.On("GetFileByID", ctx, fileID).Return(nil, errors.New("database error"))
 		
 		// Call the method
 		_, _, err := fileService.GetFile(ctx, fileID)
@@ -214,7 +296,7 @@ func TestFileService_GetFile(t *testing.T) {
 		// Mock repository to return file metadata
 		fileName := "test.txt"
 		location := "file123"
-		mockRepo.On("GetFileByID", ctx, fileID).Return(fileName, location, nil)
+		mockRepo.On("GetFileByID", ctx, fileID).Return(&models.File{ID: fileID, Name: fileName, Location: location, TextLocation: location}, nil)
 		
 		// Mock storage to return error
 		mockStorage.On("GetFile", ctx, location).Return([]byte{}, errors.New("storage error"))
@@ -229,4 +311,265 @@ func TestFileService_GetFile(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 		mockStorage.AssertExpectations(t)
 	})
-}
\ No newline at end of file
+}
+
+// newDocx creates a Word document with a paragraph of the text
+func newDocx(t *testing.T, text string) []byte {
+	buf := new(bytes.Buffer)
+	writer := zip.NewWriter(buf)
+	w, err := writer.Create("word/document.xml")
+	require.NoError(t, err)
+	w.Write([]byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:r><w:t>` +
+		text + `</w:t></w:r></w:p></w:body></w:document>`))
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestFileService_UploadFile_Document(t *testing.T) {
+	ctx := context.Background()
+
+	// Test case: the document is stored with its text
+	t.Run("Document with text", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockStorage := new(MockFileStorage)
+		fileService := service.NewFileService(mockRepo, mockStorage)
+
+		content := newDocx(t, "Текст работы")
+		mockRepo.On("GetFileByHash", ctx, mock.Anything).Return("", nil)
+		mockStorage.On("SaveFile", ctx, mock.Anything, content).Return(nil)
+		mockStorage.On("SaveFile", ctx, mock.Anything, []byte("Текст работы")).Return(nil)
+		mockRepo.On("SaveFile", ctx, mock.MatchedBy(func(file *models.File) bool {
+			return file.Name == "essay.docx" && file.Location == file.ID && file.TextLocation == file.ID+".txt" &&
+				file.Encoding == ""
+		})).Return(nil)
+
+		fileID, err := fileService.UploadFile(ctx, "essay.docx", content)
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, fileID)
+		mockStorage.AssertCalled(t, "SaveFile", ctx, fileID, content)
+		mockStorage.AssertCalled(t, "SaveFile", ctx, fileID+".txt", []byte("Текст работы"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	// Test case: a legacy encoded text file is stored as uploaded with its text in UTF-8
+	t.Run("Windows-1251 text file", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockStorage := new(MockFileStorage)
+		fileService := service.NewFileService(mockRepo, mockStorage)
+
+		content := []byte{0xd2, 0xe5, 0xea, 0xf1, 0xf2, 0x20, 0xee, 0xf2, 0xf7, 0xb8, 0xf2, 0xe0}
+		mockRepo.On("GetFileByHash", ctx, mock.Anything).Return("", nil)
+		mockStorage.On("SaveFile", ctx, mock.Anything, content).Return(nil)
+		mockStorage.On("SaveFile", ctx, mock.Anything, []byte("Текст отчёта")).Return(nil)
+		mockRepo.On("SaveFile", ctx, mock.MatchedBy(func(file *models.File) bool {
+			return file.TextLocation == file.ID+".txt" && file.Encoding == extract.EncodingWindows1251
+		})).Return(nil)
+
+		fileID, err := fileService.UploadFile(ctx, "report.txt", content)
+
+		assert.NoError(t, err)
+		mockStorage.AssertCalled(t, "SaveFile", ctx, fileID, content)
+		mockStorage.AssertCalled(t, "SaveFile", ctx, fileID+".txt", []byte("Текст отчёта"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	// Test case: the text cannot be extracted
+	t.Run("Unsupported and invalid documents", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockStorage := new(MockFileStorage)
+		fileService := service.NewFileService(mockRepo, mockStorage)
+
+		mockRepo.On("GetFileByHash", ctx, mock.Anything).Return("", nil)
+
+		_, err := fileService.UploadFile(ctx, "essay.doc", []byte("content"))
+		assert.ErrorIs(t, err, extract.ErrUnsupportedFormat)
+
+		_, err = fileService.UploadFile(ctx, "essay.pdf", []byte("not a PDF"))
+		assert.ErrorIs(t, err, extract.ErrInvalidDocument)
+
+		mockStorage.AssertNotCalled(t, "SaveFile")
+		mockRepo.AssertNotCalled(t, "SaveFile")
+	})
+}
+
+func TestFileService_GetOriginalFile(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	mockStorage := new(MockFileStorage)
+	fileService := service.NewFileService(mockRepo, mockStorage)
+
+	ctx := context.Background()
+	file := &models.File{ID: "file123", Name: "essay.docx", Location: "file123", TextLocation: "file123.txt"}
+	mockRepo.On("GetFileByID", ctx, "file123").Return(file, nil)
+	mockStorage.On("GetFile", ctx, "file123").Return([]byte("original"), nil)
+	mockStorage.On("GetFile", ctx, "file123.txt").Return([]byte("text"), nil)
+
+	// The original is returned as uploaded
+	fileName, content, err := fileService.GetOriginalFile(ctx, "file123")
+	assert.NoError(t, err)
+	assert.Equal(t, "essay.docx", fileName)
+	assert.Equal(t, []byte("original"), content)
+
+	// GetFile returns the text
+	fileName, content, err = fileService.GetFile(ctx, "file123")
+	assert.NoError(t, err)
+	assert.Equal(t, "essay.docx", fileName)
+	assert.Equal(t, []byte("text"), content)
+
+	mockRepo.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+}
+
+// readStream makes the SaveFileStream mock read the content like the storage does
+func readStream(args mock.Arguments) {
+	io.ReadAll(args.Get(2).(io.Reader))
+}
+
+func TestFileService_UploadFileStream(t *testing.T) {
+	ctx := context.Background()
+
+	// Test case: the streamed file is stored as it is read, with its text
+	t.Run("New file upload", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockStorage := new(MockFileStorage)
+		fileService := service.NewFileService(mockRepo, mockStorage)
+
+		content := newDocx(t, "Текст работы")
+		mockStorage.On("SaveFileStream", ctx, mock.Anything, mock.Anything).Run(readStream).Return(nil)
+		mockRepo.On("GetFileByHash", ctx, mock.Anything).Return("", nil)
+		mockStorage.On("SaveFile", ctx, mock.Anything, []byte("Текст работы")).Return(nil)
+		mockRepo.On("SaveFile", ctx, mock.MatchedBy(func(file *models.File) bool {
+			return file.Name == "essay.docx" && file.Location == file.ID && file.TextLocation == file.ID+".txt"
+		})).Return(nil)
+
+		fileID, err := fileService.UploadFileStream(ctx, "essay.docx", bytes.NewReader(content))
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, fileID)
+		mockStorage.AssertCalled(t, "SaveFileStream", ctx, fileID, mock.Anything)
+		mockStorage.AssertCalled(t, "SaveFile", ctx, fileID+".txt", []byte("Текст работы"))
+		mockStorage.AssertNotCalled(t, "DeleteFile", mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	// Test case: the duplicate of a stored file is deleted
+	t.Run("File already exists", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockStorage := new(MockFileStorage)
+		fileService := service.NewFileService(mockRepo, mockStorage)
+
+		// The hash is the one of the whole content
+		mockStorage.On("SaveFileStream", ctx, mock.Anything, mock.Anything).Run(readStream).Return(nil)
+		mockRepo.On("GetFileByHash", ctx, "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72").
+			Return("existing-file-id", nil)
+		mockStorage.On("DeleteFile", ctx, mock.Anything).Return(nil)
+
+		fileID, err := fileService.UploadFileStream(ctx, "test.txt", strings.NewReader("test content"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "existing-file-id", fileID)
+		mockStorage.AssertNumberOfCalls(t, "DeleteFile", 2)
+		mockRepo.AssertNotCalled(t, "SaveFile", mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	// Test case: a document without text is deleted
+	t.Run("Invalid document", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockStorage := new(MockFileStorage)
+		fileService := service.NewFileService(mockRepo, mockStorage)
+
+		mockStorage.On("SaveFileStream", ctx, mock.Anything, mock.Anything).Run(readStream).Return(nil)
+		mockRepo.On("GetFileByHash", ctx, mock.Anything).Return("", nil)
+		mockStorage.On("DeleteFile", ctx, mock.Anything).Return(nil)
+
+		_, err := fileService.UploadFileStream(ctx, "essay.pdf", strings.NewReader("not a PDF"))
+
+		assert.ErrorIs(t, err, extract.ErrInvalidDocument)
+		mockStorage.AssertNumberOfCalls(t, "DeleteFile", 2)
+		mockRepo.AssertNotCalled(t, "SaveFile", mock.Anything, mock.Anything)
+	})
+
+	// Test case: unsupported formats are rejected before reading
+	t.Run("Unsupported format", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockStorage := new(MockFileStorage)
+		fileService := service.NewFileService(mockRepo, mockStorage)
+
+		_, err := fileService.UploadFileStream(ctx, "essay.doc", strings.NewReader("content"))
+
+		assert.ErrorIs(t, err, extract.ErrUnsupportedFormat)
+		mockStorage.AssertNotCalled(t, "SaveFileStream", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	// Test case: error saving file content
+	t.Run("Error saving file content", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockStorage := new(MockFileStorage)
+		fileService := service.NewFileService(mockRepo, mockStorage)
+
+		mockStorage.On("SaveFileStream", ctx, mock.Anything, mock.Anything).Return(errors.New("storage error"))
+
+		_, err := fileService.UploadFileStream(ctx, "test.txt", strings.NewReader("test content"))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to save file content")
+		mockRepo.AssertNotCalled(t, "GetFileByHash", mock.Anything, mock.Anything)
+	})
+
+	// Test case: files larger than the maximum size are refused, files of the maximum size are not
+	t.Run("File too large", func(t *testing.T) {
+		mockRepo := new(MockFileRepository)
+		mockStorage := new(MockFileStorage)
+		fileService := service.NewFileService(mockRepo, mockStorage)
+		fileService.MaxFileSize = 12
+
+		mockStorage.On("SaveFileStream", ctx, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			_, err := io.ReadAll(args.Get(2).(io.Reader))
+			assert.ErrorIs(t, err, service.ErrFileTooLarge)
+		}).Return(errors.New("failed to read file")).Once()
+
+		_, err := fileService.UploadFileStream(ctx, "test.txt", strings.NewReader("test content!"))
+
+		assert.ErrorIs(t, err, service.ErrFileTooLarge)
+		mockRepo.AssertNotCalled(t, "GetFileByHash", mock.Anything, mock.Anything)
+
+		mockStorage.On("SaveFileStream", ctx, mock.Anything, mock.Anything).Run(readStream).Return(nil)
+		mockRepo.On("GetFileByHash", ctx, mock.Anything).Return("", nil)
+		mockRepo.On("SaveFile", ctx, mock.Anything).Return(nil)
+
+		_, err = fileService.UploadFileStream(ctx, "test.txt", strings.NewReader("test content"))
+		assert.NoError(t, err)
+	})
+}
+
+func TestFileService_OpenFile(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	mockStorage := new(MockFileStorage)
+	fileService := service.NewFileService(mockRepo, mockStorage)
+
+	ctx := context.Background()
+	file := &models.File{ID: "file123", Name: "essay.docx", Location: "file123", TextLocation: "file123.txt"}
+	mockRepo.On("GetFileByID", ctx, "file123").Return(file, nil)
+	mockStorage.On("OpenFile", ctx, "file123").Return(io.NopCloser(strings.NewReader("original")), nil)
+	mockStorage.On("OpenFile", ctx, "file123.txt").Return(io.NopCloser(strings.NewReader("text")), nil)
+
+	fileName, content, err := fileService.OpenFile(ctx, "file123", true)
+	require.NoError(t, err)
+	assert.Equal(t, "essay.docx", fileName)
+	data, _ := io.ReadAll(content)
+	assert.Equal(t, "original", string(data))
+
+	_, content, err = fileService.OpenFile(ctx, "file123", false)
+	require.NoError(t, err)
+	data, _ = io.ReadAll(content)
+	assert.Equal(t, "text", string(data))
+
+	// Error getting file content
+	mockRepo.On("GetFileByID", ctx, "missing").Return(&models.File{ID: "missing", Location: "missing", TextLocation: "missing"}, nil)
+	mockStorage.On("OpenFile", ctx, "missing").Return(nil, errors.New("file not found at location missing"))
+
+	_, _, err = fileService.OpenFile(ctx, "missing", false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get file content")
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"io"
 )
 
 // FileStorage defines the interface for file content operations
@@ -11,4 +12,14 @@ type FileStorage interface {
 	
 	// GetFile retrieves file content from storage
 	GetFile(ctx context.Context, location string) ([]byte, error)
+	
+	// SaveFileStream saves file content read until EOF to storage
+	// Nothing is left at the location if reading or writing fails
+	SaveFileStream(ctx context.Context, location string, content io.Reader) error
+	
+	// OpenFile opens file content in storage for reading, the caller closes it
+	OpenFile(ctx context.Context, location string) (io.ReadCloser, error)
+	
+	// DeleteFile removes file content from storage, a missing file is not an error
+	DeleteFile(ctx context.Context, location string) error
 }
\ No newline at end of file
@@ -1,17 +1,21 @@
 package local
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
 
 	"local.dev/doc-analyzer/internal/pkg/storage/storage"
+	"local.dev/doc-analyzer/internal/pkg/storageroot"
 )
 
-// LocalStorage implements the FileStorage interface using the local filesystem
+// LocalStorage implements the FileStorage interface using the local filesystem,
+// locations are resolved inside the base directory
 type LocalStorage struct {
-	basePath string
+	root *storageroot.Root
 }
 
 // NewLocalStorage creates a new LocalStorage instance
@@ -23,44 +27,76 @@ func NewLocalStorage(basePath string) (storage.FileStorage, error) {
 	if err := os.MkdirAll(basePath, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create storage directory: %w", err)
 	}
-	return &LocalStorage{basePath: basePath}, nil
+
+	root, err := storageroot.Open(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage directory: %w", err)
+	}
+	return &LocalStorage{root: root}, nil
 }
 
 // SaveFile saves file content to the local filesystem
 func (s *LocalStorage) SaveFile(ctx context.Context, location string, content []byte) error {
-	if location == "" {
-		location = "default.txt"
+	return s.SaveFileStream(ctx, location, bytes.NewReader(content))
+}
+
+// GetFile retrieves file content from the local filesystem
+func (s *LocalStorage) GetFile(ctx context.Context, location string) ([]byte, error) {
+	file, err := s.OpenFile(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	fullPath := filepath.Join(s.basePath, location)
+	return content, nil
+}
 
-	dir := filepath.Dir(fullPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+// SaveFileStream saves file content read until EOF to the local filesystem
+// The content is written to a temporary file renamed into place once complete,
+// so a failed upload leaves nothing at the location
+func (s *LocalStorage) SaveFileStream(ctx context.Context, location string, content io.Reader) error {
+	if location == "" {
+		location = "default.txt"
 	}
 
-	if err := os.WriteFile(fullPath, content, 0644); err != nil {
+	if err := s.root.WriteFile(location, content); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
 	return nil
 }
 
-// GetFile retrieves file content from the local filesystem
-func (s *LocalStorage) GetFile(ctx context.Context, location string) ([]byte, error) {
+// OpenFile opens file content in the local filesystem for reading
+func (s *LocalStorage) OpenFile(ctx context.Context, location string) (io.ReadCloser, error) {
 	if location == "" {
 		location = "default.txt"
 	}
 
-	fullPath := filepath.Join(s.basePath, location)
-
-	content, err := os.ReadFile(fullPath)
+	file, err := s.root.Open(location)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("file not found at location %s", location)
+		if errors.Is(err, storageroot.ErrNotFound) {
+			return nil, fmt.Errorf("file %w at location %s", storageroot.ErrNotFound, location)
 		}
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
-	return content, nil
+	return file, nil
+}
+
+// DeleteFile removes file content from the local filesystem
+func (s *LocalStorage) DeleteFile(ctx context.Context, location string) error {
+	if location == "" {
+		location = "default.txt"
+	}
+
+	if err := s.root.Remove(location); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
 }
@@ -2,14 +2,19 @@ package local_test
 
 import (
 	"context"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"local.dev/doc-analyzer/internal/pkg/storage/storage/local"
+	"local.dev/doc-analyzer/internal/pkg/storageroot"
 )
 
 func TestNewLocalStorage(t *testing.T) {
@@ -282,3 +287,103 @@ func TestSaveAndGetFile(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, testContent, retrievedContent)
 }
+
+func TestSaveFileStream(t *testing.T) {
+	tempDir := t.TempDir()
+	storage, err := local.NewLocalStorage(tempDir)
+	require.NoError(t, err)
+
+	// The content is read until EOF
+	err = storage.SaveFileStream(context.Background(), "stream.txt", strings.NewReader("streamed content"))
+	require.NoError(t, err)
+
+	savedContent, err := os.ReadFile(filepath.Join(tempDir, "stream.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "streamed content", string(savedContent))
+
+	// A failed read leaves neither the file nor the temporary one behind
+	t.Run("Read error", func(t *testing.T) {
+		content := io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("connection reset")))
+		err := storage.SaveFileStream(context.Background(), "failed.txt", content)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to write file")
+
+		_, err = os.Stat(filepath.Join(tempDir, "failed.txt"))
+		assert.True(t, os.IsNotExist(err))
+		entries, err := os.ReadDir(tempDir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	// An existing file is kept until the new content is complete
+	t.Run("Overwrite existing file", func(t *testing.T) {
+		require.NoError(t, storage.SaveFile(context.Background(), "stream.txt", []byte("first")))
+
+		content := io.MultiReader(strings.NewReader("second"), iotest.ErrReader(errors.New("connection reset")))
+		assert.Error(t, storage.SaveFileStream(context.Background(), "stream.txt", content))
+
+		savedContent, err := storage.GetFile(context.Background(), "stream.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "first", string(savedContent))
+	})
+}
+
+func TestOpenFile(t *testing.T) {
+	storage, err := local.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, storage.SaveFile(context.Background(), "test.txt", []byte("test file content")))
+
+	file, err := storage.OpenFile(context.Background(), "test.txt")
+	require.NoError(t, err)
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.NoError(t, file.Close())
+	assert.Equal(t, "test file content", string(content))
+
+	_, err = storage.OpenFile(context.Background(), "non_existent.txt")
+	assert.ErrorIs(t, err, storageroot.ErrNotFound)
+	assert.Contains(t, err.Error(), "file not found at location non_existent.txt")
+}
+
+func TestDeleteFile(t *testing.T) {
+	tempDir := t.TempDir()
+	storage, err := local.NewLocalStorage(tempDir)
+	require.NoError(t, err)
+
+	require.NoError(t, storage.SaveFile(context.Background(), "test.txt", []byte("test file content")))
+
+	assert.NoError(t, storage.DeleteFile(context.Background(), "test.txt"))
+	_, err = os.Stat(filepath.Join(tempDir, "test.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Deleting a missing file succeeds
+	assert.NoError(t, storage.DeleteFile(context.Background(), "test.txt"))
+}
+
+func TestLocationOutsideStorage(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "files")
+	storage, err := local.NewLocalStorage(basePath)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644))
+	require.NoError(t, os.Symlink(dir, filepath.Join(basePath, "escape")))
+
+	for _, location := range []string{"../secret.txt", "/etc/passwd", "escape/secret.txt", "subdir/../../secret.txt"} {
+		t.Run(location, func(t *testing.T) {
+			_, err := storage.GetFile(context.Background(), location)
+			assert.ErrorIs(t, err, storageroot.ErrInvalidLocation)
+
+			err = storage.SaveFile(context.Background(), location, []byte("overwritten"))
+			assert.ErrorIs(t, err, storageroot.ErrInvalidLocation)
+
+			err = storage.DeleteFile(context.Background(), location)
+			assert.ErrorIs(t, err, storageroot.ErrInvalidLocation)
+		})
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "secret.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(content))
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"io"
 	"github.com/stretchr/testify/mock"
 	"local.dev/doc-analyzer/internal/pkg/storage/storage"
 )
@@ -27,4 +28,25 @@ func (m *MockFileStorage) GetFile(ctx context.Context, location string) ([]byte,
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]byte), args.Error(1)
+}
+
+// SaveFileStream mocks the SaveFileStream method
+func (m *MockFileStorage) SaveFileStream(ctx context.Context, location string, content io.Reader) error {
+	args := m.Called(ctx, location, content)
+	return args.Error(0)
+}
+
+// OpenFile mocks the OpenFile method
+func (m *MockFileStorage) OpenFile(ctx context.Context, location string) (io.ReadCloser, error) {
+	args := m.Called(ctx, location)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+// DeleteFile mocks the DeleteFile method
+func (m *MockFileStorage) DeleteFile(ctx context.Context, location string) error {
+	args := m.Called(ctx, location)
+	return args.Error(0)
 }
\ No newline at end of file
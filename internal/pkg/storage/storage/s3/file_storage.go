@@ -0,0 +1,103 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"local.dev/doc-analyzer/internal/pkg/s3client"
+	"local.dev/doc-analyzer/internal/pkg/storage/storage"
+	"local.dev/doc-analyzer/internal/pkg/storageroot"
+)
+
+// S3Storage implements the FileStorage interface using an S3-compatible object storage,
+// locations are the keys of objects under the prefix
+type S3Storage struct {
+	client *s3client.Client
+	prefix string
+}
+
+// NewS3Storage creates a new S3Storage instance
+func NewS3Storage(client *s3client.Client, prefix string) storage.FileStorage {
+	return &S3Storage{client: client, prefix: prefix}
+}
+
+// SaveFile saves file content to the object storage
+func (s *S3Storage) SaveFile(ctx context.Context, location string, content []byte) error {
+	return s.SaveFileStream(ctx, location, bytes.NewReader(content))
+}
+
+// GetFile retrieves file content from the object storage
+func (s *S3Storage) GetFile(ctx context.Context, location string) ([]byte, error) {
+	file, err := s.OpenFile(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return content, nil
+}
+
+// SaveFileStream saves file content read until EOF to the object storage,
+// large files are uploaded in parts
+func (s *S3Storage) SaveFileStream(ctx context.Context, location string, content io.Reader) error {
+	key, err := s.key(location)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := s.client.PutObject(ctx, key, content); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// OpenFile opens file content in the object storage for reading
+func (s *S3Storage) OpenFile(ctx context.Context, location string) (io.ReadCloser, error) {
+	key, err := s.key(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	file, err := s.client.GetObject(ctx, key)
+	if err != nil {
+		if errors.Is(err, s3client.ErrNotFound) {
+			return nil, fmt.Errorf("file %w at location %s", storageroot.ErrNotFound, location)
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	return file, nil
+}
+
+// DeleteFile removes file content from the object storage
+func (s *S3Storage) DeleteFile(ctx context.Context, location string) error {
+	key, err := s.key(location)
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	if err := s.client.DeleteObject(ctx, key); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// key is the key of the object of a location,
+// locations are validated as for the local storage so that both backends accept the same ones
+func (s *S3Storage) key(location string) (string, error) {
+	if location == "" {
+		location = "default.txt"
+	}
+	if _, err := storageroot.Clean(location); err != nil {
+		return "", err
+	}
+	return s.prefix + location, nil
+}
@@ -0,0 +1,113 @@
+package s3_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"local.dev/doc-analyzer/internal/pkg/s3client"
+	"local.dev/doc-analyzer/internal/pkg/s3client/s3fake"
+	"local.dev/doc-analyzer/internal/pkg/storage/storage"
+	"local.dev/doc-analyzer/internal/pkg/storage/storage/s3"
+	"local.dev/doc-analyzer/internal/pkg/storageroot"
+)
+
+// newTestStorage creates a storage of a fake object storage, with the files under the prefix
+func newTestStorage(t *testing.T) (storage.FileStorage, *s3fake.Server) {
+	fake := s3fake.NewServer("documents")
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := s3client.NewClient(s3client.Config{
+		Endpoint:        server.URL,
+		Bucket:          "documents",
+		AccessKeyID:     "minio",
+		SecretAccessKey: "minio-secret",
+		PartSize:        s3client.MinPartSize,
+	})
+	require.NoError(t, err)
+	return s3.NewS3Storage(client, "files/"), fake
+}
+
+func TestSaveAndGetFile(t *testing.T) {
+	fileStorage, fake := newTestStorage(t)
+	ctx := context.Background()
+
+	require.NoError(t, fileStorage.SaveFile(ctx, "file123", []byte("test file content")))
+
+	// The file is stored under the prefix
+	stored, ok := fake.Object("files/file123")
+	require.True(t, ok)
+	assert.Equal(t, "test file content", string(stored))
+
+	content, err := fileStorage.GetFile(ctx, "file123")
+	assert.NoError(t, err)
+	assert.Equal(t, "test file content", string(content))
+
+	// Empty location uses the default name, like the local storage
+	require.NoError(t, fileStorage.SaveFile(ctx, "", []byte("default content")))
+	_, ok = fake.Object("files/default.txt")
+	assert.True(t, ok)
+
+	_, err = fileStorage.GetFile(ctx, "non_existent")
+	assert.ErrorIs(t, err, storageroot.ErrNotFound)
+	assert.Contains(t, err.Error(), "not found")
+
+	// Locations are validated like for the local storage, nothing is requested
+	requests := len(fake.Requests())
+	_, err = fileStorage.GetFile(ctx, "../wordclouds/file123.png")
+	assert.ErrorIs(t, err, storageroot.ErrInvalidLocation)
+	assert.ErrorIs(t, fileStorage.SaveFile(ctx, "/file123", []byte("content")), storageroot.ErrInvalidLocation)
+	assert.ErrorIs(t, fileStorage.DeleteFile(ctx, "a/../file123"), storageroot.ErrInvalidLocation)
+	assert.Len(t, fake.Requests(), requests)
+}
+
+func TestSaveFileStream(t *testing.T) {
+	fileStorage, fake := newTestStorage(t)
+	ctx := context.Background()
+
+	// Large files are uploaded in parts
+	large := bytes.Repeat([]byte("a"), s3client.MinPartSize+100)
+	require.NoError(t, fileStorage.SaveFileStream(ctx, "large", bytes.NewReader(large)))
+
+	file, err := fileStorage.OpenFile(ctx, "large")
+	require.NoError(t, err)
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.NoError(t, file.Close())
+	assert.Equal(t, large, content)
+
+	// A failed read leaves nothing at the location
+	failing := io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("connection reset")))
+	err = fileStorage.SaveFileStream(ctx, "failed", failing)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to write file")
+	_, ok := fake.Object("files/failed")
+	assert.False(t, ok)
+
+	_, err = fileStorage.OpenFile(ctx, "failed")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestDeleteFile(t *testing.T) {
+	fileStorage, fake := newTestStorage(t)
+	ctx := context.Background()
+
+	require.NoError(t, fileStorage.SaveFile(ctx, "file123", []byte("test file content")))
+
+	assert.NoError(t, fileStorage.DeleteFile(ctx, "file123"))
+	_, ok := fake.Object("files/file123")
+	assert.False(t, ok)
+
+	// Deleting a missing file succeeds
+	assert.NoError(t, fileStorage.DeleteFile(ctx, "file123"))
+}
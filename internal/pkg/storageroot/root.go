@@ -0,0 +1,152 @@
+// Package storageroot resolves the locations of stored files in a root directory, so that
+// no location, whether stored in the database or supplied in a request, reaches outside of it
+package storageroot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned for locations with nothing stored at them
+	ErrNotFound = errors.New("not found")
+
+	// ErrInvalidLocation is returned for locations that are not relative paths inside the root:
+	// absolute paths, paths with . or .. segments and paths leaving the root through symbolic links
+	ErrInvalidLocation = errors.New("invalid location")
+)
+
+// Clean validates a location and converts it to a path of the local filesystem
+// Locations are relative slash-separated paths of non-empty segments other than . and ..;
+// backslashes and NUL bytes are refused, so that a location means the same path on every system
+func Clean(location string) (string, error) {
+	if location == "" {
+		return "", fmt.Errorf("%w: empty location", ErrInvalidLocation)
+	}
+	if strings.ContainsAny(location, "\\\x00") || strings.HasPrefix(location, "/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidLocation, location)
+	}
+
+	for _, segment := range strings.Split(location, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", fmt.Errorf("%w: %q", ErrInvalidLocation, location)
+		}
+	}
+
+	name := filepath.FromSlash(location)
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidLocation, location)
+	}
+	return name, nil
+}
+
+// Root is a directory the locations of a storage are resolved in
+// Files are opened through os.Root, which also refuses symbolic links leading outside of the directory
+type Root struct {
+	root *os.Root
+}
+
+// Open opens an existing directory as a Root
+func Open(path string) (*Root, error) {
+	root, err := os.OpenRoot(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Root{root: root}, nil
+}
+
+// Close closes the directory
+func (r *Root) Close() error {
+	return r.root.Close()
+}
+
+// Open opens the file at a location for reading
+func (r *Root) Open(location string) (*os.File, error) {
+	name, err := Clean(location)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := r.root.Open(name)
+	if err != nil {
+		return nil, locationError(location, err)
+	}
+	return file, nil
+}
+
+// WriteFile writes content read until EOF to the file at a location, creating its directories
+// The content is written to a temporary file renamed into place once complete,
+// so a failed write leaves the location as it was
+func (r *Root) WriteFile(location string, content io.Reader) error {
+	name, err := Clean(location)
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(name); dir != "." {
+		if err := r.root.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", locationError(location, err))
+		}
+	}
+
+	tmpName, tmp, err := r.createTemp(name)
+	if err != nil {
+		return locationError(location, err)
+	}
+	defer r.root.Remove(tmpName)
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// Renaming replaces a symbolic link at the location instead of following it
+	if err := r.root.Rename(tmpName, name); err != nil {
+		return locationError(location, err)
+	}
+	return nil
+}
+
+// Remove removes the file at a location, a missing file is not an error
+func (r *Root) Remove(location string) error {
+	name, err := Clean(location)
+	if err != nil {
+		return err
+	}
+
+	if err := r.root.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return locationError(location, err)
+	}
+	return nil
+}
+
+// createTemp creates a new temporary file beside the file of a location
+func (r *Root) createTemp(name string) (string, *os.File, error) {
+	prefix := filepath.Join(filepath.Dir(name), "."+filepath.Base(name)+".")
+	for i := 0; ; i++ {
+		tmpName := fmt.Sprintf("%s%d-%d.tmp", prefix, os.Getpid(), i)
+		file, err := r.root.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		return tmpName, file, err
+	}
+}
+
+// locationError types the errors of os.Root for a location that passed Clean:
+// missing files are not found, any other failure to resolve the location inside the root,
+// such as a symbolic link leading outside of it or a file in place of a directory, makes it invalid
+func locationError(location string, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w at location %s", ErrNotFound, location)
+	}
+	return fmt.Errorf("%w: %q: %w", ErrInvalidLocation, location, err)
+}
@@ -0,0 +1,208 @@
+package storageroot_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"local.dev/doc-analyzer/internal/pkg/storageroot"
+)
+
+// secret is the content of a file outside of the root, which no location may reach
+const secret = "outside of the root"
+
+// newTestRoot creates a root with a file, a directory and symbolic links leading inside and outside of it
+// The directory of the root also holds a file outside of the root
+func newTestRoot(t *testing.T) (*storageroot.Root, string) {
+	dir := t.TempDir()
+	rootPath := filepath.Join(dir, "root")
+	require.NoError(t, os.MkdirAll(filepath.Join(rootPath, "files"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(rootPath, "files", "essay.txt"), []byte("essay"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret.txt"), []byte(secret), 0644))
+	require.NoError(t, os.Symlink(dir, filepath.Join(rootPath, "escape")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "secret.txt"), filepath.Join(rootPath, "secret.txt")))
+	require.NoError(t, os.Symlink("files", filepath.Join(rootPath, "inside")))
+
+	root, err := storageroot.Open(rootPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { root.Close() })
+	return root, rootPath
+}
+
+func TestClean(t *testing.T) {
+	valid := map[string]string{
+		"file123":              "file123",
+		"file123.txt":          "file123.txt",
+		"wordclouds/file.png":  filepath.Join("wordclouds", "file.png"),
+		"..file":               "..file",
+		"файл с пробелами.txt": "файл с пробелами.txt",
+	}
+	for location, expected := range valid {
+		name, err := storageroot.Clean(location)
+		assert.NoError(t, err, location)
+		assert.Equal(t, expected, name, location)
+	}
+
+	invalid := []string{"", ".", "..", "../secret.txt", "files/../../secret.txt", "files/..", "/etc/passwd",
+		"files//essay.txt", "files/", "./files", "..\\secret.txt", "C:\\secret.txt", "file\x00.txt"}
+	for _, location := range invalid {
+		_, err := storageroot.Clean(location)
+		assert.ErrorIs(t, err, storageroot.ErrInvalidLocation, "%q", location)
+	}
+}
+
+func TestRoot_Open(t *testing.T) {
+	root, _ := newTestRoot(t)
+
+	file, err := root.Open("files/essay.txt")
+	require.NoError(t, err)
+	content, _ := io.ReadAll(file)
+	file.Close()
+	assert.Equal(t, "essay", string(content))
+
+	// Symbolic links inside of the root are followed
+	file, err = root.Open("inside/essay.txt")
+	require.NoError(t, err)
+	file.Close()
+
+	_, err = root.Open("files/missing.txt")
+	assert.ErrorIs(t, err, storageroot.ErrNotFound)
+
+	// Traversal and symbolic links leading outside of the root are refused
+	for _, location := range []string{"../secret.txt", "/etc/passwd", "escape/secret.txt", "secret.txt"} {
+		_, err := root.Open(location)
+		assert.ErrorIs(t, err, storageroot.ErrInvalidLocation, location)
+	}
+}
+
+func TestRoot_WriteFile(t *testing.T) {
+	root, rootPath := newTestRoot(t)
+
+	// Directories are created
+	require.NoError(t, root.WriteFile("wordclouds/2024/file.png", strings.NewReader("image")))
+	content, err := os.ReadFile(filepath.Join(rootPath, "wordclouds", "2024", "file.png"))
+	require.NoError(t, err)
+	assert.Equal(t, "image", string(content))
+
+	// A failed write keeps the file as it was and leaves no temporary file
+	failing := io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("connection reset")))
+	assert.Error(t, root.WriteFile("files/essay.txt", failing))
+	content, err = os.ReadFile(filepath.Join(rootPath, "files", "essay.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "essay", string(content))
+	entries, err := os.ReadDir(filepath.Join(rootPath, "files"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// Writing through a symbolic link leading outside of the root is refused
+	err = root.WriteFile("escape/written.txt", strings.NewReader("escaped"))
+	assert.ErrorIs(t, err, storageroot.ErrInvalidLocation)
+	_, err = os.Stat(filepath.Join(filepath.Dir(rootPath), "written.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	// A symbolic link at the location is replaced instead of followed
+	require.NoError(t, root.WriteFile("secret.txt", strings.NewReader("replaced")))
+	content, err = os.ReadFile(filepath.Join(filepath.Dir(rootPath), "secret.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, secret, string(content))
+
+	assert.ErrorIs(t, root.WriteFile("../written.txt", strings.NewReader("escaped")), storageroot.ErrInvalidLocation)
+
+	// A file in place of a directory makes the location invalid
+	assert.ErrorIs(t, root.WriteFile("files/essay.txt/file.png", strings.NewReader("image")), storageroot.ErrInvalidLocation)
+	assert.ErrorIs(t, root.WriteFile("files/essay.txt/2024/file.png", strings.NewReader("image")), storageroot.ErrInvalidLocation)
+}
+
+func TestRoot_Remove(t *testing.T) {
+	root, rootPath := newTestRoot(t)
+
+	require.NoError(t, root.Remove("files/essay.txt"))
+	_, err := os.Stat(filepath.Join(rootPath, "files", "essay.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Removing a missing file succeeds
+	assert.NoError(t, root.Remove("files/essay.txt"))
+
+	// Files outside of the root are not removed
+	assert.ErrorIs(t, root.Remove("escape/secret.txt"), storageroot.ErrInvalidLocation)
+	assert.ErrorIs(t, root.Remove("../secret.txt"), storageroot.ErrInvalidLocation)
+	_, err = os.Stat(filepath.Join(filepath.Dir(rootPath), "secret.txt"))
+	assert.NoError(t, err)
+}
+
+// FuzzClean checks that valid locations are local paths that stay inside of any directory
+func FuzzClean(f *testing.F) {
+	for _, location := range []string{"file123", "wordclouds/file.png", "../secret.txt", "a/../../b", "/etc/passwd",
+		"a//b", "..\\b", "C:secret", "a/./b", "\x00", "...", "a/...", "~/secret"} {
+		f.Add(location)
+	}
+
+	f.Fuzz(func(t *testing.T, location string) {
+		name, err := storageroot.Clean(location)
+		if err != nil {
+			if !errors.Is(err, storageroot.ErrInvalidLocation) {
+				t.Fatalf("Clean(%q) returned untyped error %v", location, err)
+			}
+			return
+		}
+
+		if !filepath.IsLocal(name) {
+			t.Fatalf("Clean(%q) = %q, not a local path", location, name)
+		}
+		base := filepath.FromSlash("/storage/root")
+		joined := filepath.Join(base, name)
+		if !strings.HasPrefix(joined, base+string(filepath.Separator)) {
+			t.Fatalf("Clean(%q) = %q, joined to %q", location, name, joined)
+		}
+		for _, segment := range strings.Split(location, "/") {
+			if segment == ".." {
+				t.Fatalf("Clean(%q) accepted a .. segment", location)
+			}
+		}
+	})
+}
+
+// FuzzRoot checks that no location reads or writes the file outside of the root
+func FuzzRoot(f *testing.F) {
+	for _, location := range []string{"files/essay.txt", "inside/essay.txt", "secret.txt", "escape/secret.txt",
+		"../secret.txt", "escape/../secret.txt", "inside/../escape/secret.txt", "files/new.txt", "/secret.txt"} {
+		f.Add(location)
+	}
+
+	f.Fuzz(func(t *testing.T, location string) {
+		root, rootPath := newTestRoot(t)
+		secretPath := filepath.Join(filepath.Dir(rootPath), "secret.txt")
+
+		if file, err := root.Open(location); err == nil {
+			content, _ := io.ReadAll(file)
+			file.Close()
+			if string(content) == secret {
+				t.Fatalf("Open(%q) read the file outside of the root", location)
+			}
+		} else if !errors.Is(err, storageroot.ErrNotFound) && !errors.Is(err, storageroot.ErrInvalidLocation) {
+			// Other errors are only expected for locations of directories and the like
+			if _, cleanErr := storageroot.Clean(location); cleanErr != nil {
+				t.Fatalf("Open(%q) returned %v for an invalid location", location, err)
+			}
+		}
+
+		root.WriteFile(location, strings.NewReader("written"))
+		root.Remove(location)
+
+		content, err := os.ReadFile(secretPath)
+		if err != nil || string(content) != secret {
+			t.Fatalf("WriteFile or Remove of %q changed the file outside of the root", location)
+		}
+		entries, err := os.ReadDir(filepath.Dir(rootPath))
+		if err != nil || len(entries) != 2 {
+			t.Fatalf("WriteFile of %q created a file outside of the root", location)
+		}
+	})
+}
@@ -26,8 +26,12 @@ type AnalyzeFileRequest struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	FileId            string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
 	GenerateWordCloud bool                   `protobuf:"varint,2,opt,name=generate_word_cloud,json=generateWordCloud,proto3" json:"generate_word_cloud,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+	WordCloudOptions  *WordCloudOptions      `protobuf:"bytes,3,opt,name=word_cloud_options,json=wordCloudOptions,proto3" json:"word_cloud_options,omitempty"`
+	// URL, который уведомляется о завершении задания (только для SubmitAnalysis),
+	// запросы подписываются секретом сервиса
+	WebhookUrl    string `protobuf:"bytes,4,opt,name=webhook_url,json=webhookUrl,proto3" json:"webhook_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *AnalyzeFileRequest) Reset() {
@@ -74,30 +78,54 @@ func (x *AnalyzeFileRequest) GetGenerateWordCloud() bool {
 	return false
 }
 
-// Ответ на запрос анализа
-type AnalyzeFileResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	WordCount     int32                  `protobuf:"varint,1,opt,name=word_count,json=wordCount,proto3" json:"word_count,omitempty"`
-	UniqueWords   int32                  `protobuf:"varint,2,opt,name=unique_words,json=uniqueWords,proto3" json:"unique_words,omitempty"`
-	Summary       string                 `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`
+func (x *AnalyzeFileRequest) GetWordCloudOptions() *WordCloudOptions {
+	if x != nil {
+		return x.WordCloudOptions
+	}
+	return nil
+}
+
+func (x *AnalyzeFileRequest) GetWebhookUrl() string {
+	if x != nil {
+		return x.WebhookUrl
+	}
+	return ""
+}
+
+// Параметры облака слов, незаданные поля принимают значения по умолчанию
+type WordCloudOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Формат изображения: png (по умолчанию) или svg
+	Format string `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	// Размер изображения в пикселях (64 - 4096, по умолчанию 1024)
+	Width  int32 `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	Height int32 `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	// Максимальное количество слов (до 1000, по умолчанию 150)
+	MaxWords int32 `protobuf:"varint,4,opt,name=max_words,json=maxWords,proto3" json:"max_words,omitempty"`
+	// Цветовая схема: default, blues, warm, grayscale или dark
+	ColorScheme string `protobuf:"bytes,5,opt,name=color_scheme,json=colorScheme,proto3" json:"color_scheme,omitempty"`
+	// Регистр слов: lower (по умолчанию), upper или original (как чаще всего написано в документе)
+	Case string `protobuf:"bytes,6,opt,name=case,proto3" json:"case,omitempty"`
+	// Не удалять стоп-слова языка документа
+	KeepStopWords bool `protobuf:"varint,7,opt,name=keep_stop_words,json=keepStopWords,proto3" json:"keep_stop_words,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AnalyzeFileResponse) Reset() {
-	*x = AnalyzeFileResponse{}
+func (x *WordCloudOptions) Reset() {
+	*x = WordCloudOptions{}
 	mi := &file_proto_analyzer_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AnalyzeFileResponse) String() string {
+func (x *WordCloudOptions) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AnalyzeFileResponse) ProtoMessage() {}
+func (*WordCloudOptions) ProtoMessage() {}
 
-func (x *AnalyzeFileResponse) ProtoReflect() protoreflect.Message {
+func (x *WordCloudOptions) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_analyzer_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -109,54 +137,115 @@ func (x *AnalyzeFileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AnalyzeFileResponse.ProtoReflect.Descriptor instead.
-func (*AnalyzeFileResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use WordCloudOptions.ProtoReflect.Descriptor instead.
+func (*WordCloudOptions) Descriptor() ([]byte, []int) {
 	return file_proto_analyzer_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *AnalyzeFileResponse) GetWordCount() int32 {
+func (x *WordCloudOptions) GetFormat() string {
 	if x != nil {
-		return x.WordCount
+		return x.Format
+	}
+	return ""
+}
+
+func (x *WordCloudOptions) GetWidth() int32 {
+	if x != nil {
+		return x.Width
 	}
 	return 0
 }
 
-func (x *AnalyzeFileResponse) GetUniqueWords() int32 {
+func (x *WordCloudOptions) GetHeight() int32 {
 	if x != nil {
-		return x.UniqueWords
+		return x.Height
 	}
 	return 0
 }
 
-func (x *AnalyzeFileResponse) GetSummary() string {
+func (x *WordCloudOptions) GetMaxWords() int32 {
 	if x != nil {
-		return x.Summary
+		return x.MaxWords
+	}
+	return 0
+}
+
+func (x *WordCloudOptions) GetColorScheme() string {
+	if x != nil {
+		return x.ColorScheme
 	}
 	return ""
 }
 
-// Запрос облака слов
-type GetWordCloudRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Location      string                 `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *WordCloudOptions) GetCase() string {
+	if x != nil {
+		return x.Case
+	}
+	return ""
 }
 
-func (x *GetWordCloudRequest) Reset() {
-	*x = GetWordCloudRequest{}
+func (x *WordCloudOptions) GetKeepStopWords() bool {
+	if x != nil {
+		return x.KeepStopWords
+	}
+	return false
+}
+
+// Ответ на запрос анализа
+type AnalyzeFileResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	WordCount   int32                  `protobuf:"varint,1,opt,name=word_count,json=wordCount,proto3" json:"word_count,omitempty"`
+	UniqueWords int32                  `protobuf:"varint,2,opt,name=unique_words,json=uniqueWords,proto3" json:"unique_words,omitempty"`
+	// Краткое содержание: самые важные предложения текста (TextRank)
+	Summary           string         `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`
+	ParagraphCount    int32          `protobuf:"varint,4,opt,name=paragraph_count,json=paragraphCount,proto3" json:"paragraph_count,omitempty"`
+	CharacterCount    int32          `protobuf:"varint,5,opt,name=character_count,json=characterCount,proto3" json:"character_count,omitempty"`
+	IsPlagiarism      bool           `protobuf:"varint,6,opt,name=is_plagiarism,json=isPlagiarism,proto3" json:"is_plagiarism,omitempty"`
+	SimilarFileIds    []string       `protobuf:"bytes,7,rep,name=similar_file_ids,json=similarFileIds,proto3" json:"similar_file_ids,omitempty"`
+	WordCloudLocation string         `protobuf:"bytes,8,opt,name=word_cloud_location,json=wordCloudLocation,proto3" json:"word_cloud_location,omitempty"`
+	SimilarFiles      []*SimilarFile `protobuf:"bytes,9,rep,name=similar_files,json=similarFiles,proto3" json:"similar_files,omitempty"`
+	// Код языка документа по ISO 639-1 (en, ru), пустой, если язык не определён
+	Language string `protobuf:"bytes,10,opt,name=language,proto3" json:"language,omitempty"`
+	// Признаки маскировки текста: буквы-двойники из другого алфавита и невидимые символы
+	ObfuscationDetected bool  `protobuf:"varint,11,opt,name=obfuscation_detected,json=obfuscationDetected,proto3" json:"obfuscation_detected,omitempty"`
+	HomoglyphCount      int32 `protobuf:"varint,12,opt,name=homoglyph_count,json=homoglyphCount,proto3" json:"homoglyph_count,omitempty"`
+	InvisibleCharCount  int32 `protobuf:"varint,13,opt,name=invisible_char_count,json=invisibleCharCount,proto3" json:"invisible_char_count,omitempty"`
+	// Количество символов без пробелов (character_count тоже считается в символах, а не байтах)
+	CharacterCountNoSpaces int32 `protobuf:"varint,14,opt,name=character_count_no_spaces,json=characterCountNoSpaces,proto3" json:"character_count_no_spaces,omitempty"`
+	SentenceCount          int32 `protobuf:"varint,15,opt,name=sentence_count,json=sentenceCount,proto3" json:"sentence_count,omitempty"`
+	// Средняя длина предложения в словах
+	AvgSentenceLength float64 `protobuf:"fixed64,16,opt,name=avg_sentence_length,json=avgSentenceLength,proto3" json:"avg_sentence_length,omitempty"`
+	// Средняя длина слова в буквах и цифрах
+	AvgWordLength float64 `protobuf:"fixed64,17,opt,name=avg_word_length,json=avgWordLength,proto3" json:"avg_word_length,omitempty"`
+	// Лексическое разнообразие: доля различных слов среди всех слов (0.0 - 1.0)
+	LexicalDiversity float64 `protobuf:"fixed64,18,opt,name=lexical_diversity,json=lexicalDiversity,proto3" json:"lexical_diversity,omitempty"`
+	// Формула читаемости для языка документа: flesch-kincaid (en) или oborneva (ru),
+	// пустая, если для языка нет формулы
+	ReadabilityFormula string `protobuf:"bytes,19,opt,name=readability_formula,json=readabilityFormula,proto3" json:"readability_formula,omitempty"`
+	// Индекс удобочитаемости Флеша (примерно 0 - 100, чем больше, тем проще текст)
+	ReadingEase float64 `protobuf:"fixed64,20,opt,name=reading_ease,json=readingEase,proto3" json:"reading_ease,omitempty"`
+	// Уровень образования (в годах обучения), необходимый для понимания текста
+	GradeLevel float64 `protobuf:"fixed64,21,opt,name=grade_level,json=gradeLevel,proto3" json:"grade_level,omitempty"`
+	// Формат облака слов: png или svg
+	WordCloudFormat string `protobuf:"bytes,22,opt,name=word_cloud_format,json=wordCloudFormat,proto3" json:"word_cloud_format,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AnalyzeFileResponse) Reset() {
+	*x = AnalyzeFileResponse{}
 	mi := &file_proto_analyzer_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetWordCloudRequest) String() string {
+func (x *AnalyzeFileResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetWordCloudRequest) ProtoMessage() {}
+func (*AnalyzeFileResponse) ProtoMessage() {}
 
-func (x *GetWordCloudRequest) ProtoReflect() protoreflect.Message {
+func (x *AnalyzeFileResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_analyzer_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -168,40 +257,204 @@ func (x *GetWordCloudRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetWordCloudRequest.ProtoReflect.Descriptor instead.
-func (*GetWordCloudRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use AnalyzeFileResponse.ProtoReflect.Descriptor instead.
+func (*AnalyzeFileResponse) Descriptor() ([]byte, []int) {
 	return file_proto_analyzer_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *GetWordCloudRequest) GetLocation() string {
+func (x *AnalyzeFileResponse) GetWordCount() int32 {
 	if x != nil {
-		return x.Location
+		return x.WordCount
+	}
+	return 0
+}
+
+func (x *AnalyzeFileResponse) GetUniqueWords() int32 {
+	if x != nil {
+		return x.UniqueWords
+	}
+	return 0
+}
+
+func (x *AnalyzeFileResponse) GetSummary() string {
+	if x != nil {
+		return x.Summary
 	}
 	return ""
 }
 
-// Ответ с облаком слов
-type GetWordCloudResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Image         []byte                 `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *AnalyzeFileResponse) GetParagraphCount() int32 {
+	if x != nil {
+		return x.ParagraphCount
+	}
+	return 0
 }
 
-func (x *GetWordCloudResponse) Reset() {
-	*x = GetWordCloudResponse{}
+func (x *AnalyzeFileResponse) GetCharacterCount() int32 {
+	if x != nil {
+		return x.CharacterCount
+	}
+	return 0
+}
+
+func (x *AnalyzeFileResponse) GetIsPlagiarism() bool {
+	if x != nil {
+		return x.IsPlagiarism
+	}
+	return false
+}
+
+func (x *AnalyzeFileResponse) GetSimilarFileIds() []string {
+	if x != nil {
+		return x.SimilarFileIds
+	}
+	return nil
+}
+
+func (x *AnalyzeFileResponse) GetWordCloudLocation() string {
+	if x != nil {
+		return x.WordCloudLocation
+	}
+	return ""
+}
+
+func (x *AnalyzeFileResponse) GetSimilarFiles() []*SimilarFile {
+	if x != nil {
+		return x.SimilarFiles
+	}
+	return nil
+}
+
+func (x *AnalyzeFileResponse) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *AnalyzeFileResponse) GetObfuscationDetected() bool {
+	if x != nil {
+		return x.ObfuscationDetected
+	}
+	return false
+}
+
+func (x *AnalyzeFileResponse) GetHomoglyphCount() int32 {
+	if x != nil {
+		return x.HomoglyphCount
+	}
+	return 0
+}
+
+func (x *AnalyzeFileResponse) GetInvisibleCharCount() int32 {
+	if x != nil {
+		return x.InvisibleCharCount
+	}
+	return 0
+}
+
+func (x *AnalyzeFileResponse) GetCharacterCountNoSpaces() int32 {
+	if x != nil {
+		return x.CharacterCountNoSpaces
+	}
+	return 0
+}
+
+func (x *AnalyzeFileResponse) GetSentenceCount() int32 {
+	if x != nil {
+		return x.SentenceCount
+	}
+	return 0
+}
+
+func (x *AnalyzeFileResponse) GetAvgSentenceLength() float64 {
+	if x != nil {
+		return x.AvgSentenceLength
+	}
+	return 0
+}
+
+func (x *AnalyzeFileResponse) GetAvgWordLength() float64 {
+	if x != nil {
+		return x.AvgWordLength
+	}
+	return 0
+}
+
+func (x *AnalyzeFileResponse) GetLexicalDiversity() float64 {
+	if x != nil {
+		return x.LexicalDiversity
+	}
+	return 0
+}
+
+func (x *AnalyzeFileResponse) GetReadabilityFormula() string {
+	if x != nil {
+		return x.ReadabilityFormula
+	}
+	return ""
+}
+
+func (x *AnalyzeFileResponse) GetReadingEase() float64 {
+	if x != nil {
+		return x.ReadingEase
+	}
+	return 0
+}
+
+func (x *AnalyzeFileResponse) GetGradeLevel() float64 {
+	if x != nil {
+		return x.GradeLevel
+	}
+	return 0
+}
+
+func (x *AnalyzeFileResponse) GetWordCloudFormat() string {
+	if x != nil {
+		return x.WordCloudFormat
+	}
+	return ""
+}
+
+// Похожий файл, найденный при проверке на плагиат
+type SimilarFile struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	FileId string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	// Доля слов документа, покрытая совпавшими отпечатками (0.0 - 1.0)
+	Coverage float64 `protobuf:"fixed64,2,opt,name=coverage,proto3" json:"coverage,omitempty"`
+	// Количество слов документа, покрытых совпавшими отпечатками
+	MatchedWords int32 `protobuf:"varint,3,opt,name=matched_words,json=matchedWords,proto3" json:"matched_words,omitempty"`
+	// Коэффициент Жаккара по n-граммам (0.0 - 1.0), 1.0 для точных копий
+	Score float64 `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"`
+	// Метрика, по которой файл признан похожим: exact, jaccard, containment или winnowing
+	Metric string `protobuf:"bytes,5,opt,name=metric,proto3" json:"metric,omitempty"`
+	// Размер n-грамм и порог, с которым сравнивался score
+	NgramSize int32   `protobuf:"varint,6,opt,name=ngram_size,json=ngramSize,proto3" json:"ngram_size,omitempty"`
+	Threshold float64 `protobuf:"fixed64,7,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	// Версия алгоритма сравнения
+	AlgorithmVersion int32 `protobuf:"varint,8,opt,name=algorithm_version,json=algorithmVersion,proto3" json:"algorithm_version,omitempty"`
+	// Доля n-грамм анализируемого файла, найденных в похожем файле
+	Containment float64 `protobuf:"fixed64,9,opt,name=containment,proto3" json:"containment,omitempty"`
+	// Доля n-грамм похожего файла, найденных в анализируемом файле
+	SimilarContainment float64 `protobuf:"fixed64,10,opt,name=similar_containment,json=similarContainment,proto3" json:"similar_containment,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *SimilarFile) Reset() {
+	*x = SimilarFile{}
 	mi := &file_proto_analyzer_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetWordCloudResponse) String() string {
+func (x *SimilarFile) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetWordCloudResponse) ProtoMessage() {}
+func (*SimilarFile) ProtoMessage() {}
 
-func (x *GetWordCloudResponse) ProtoReflect() protoreflect.Message {
+func (x *SimilarFile) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_analyzer_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -213,38 +466,1733 @@ func (x *GetWordCloudResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetWordCloudResponse.ProtoReflect.Descriptor instead.
-func (*GetWordCloudResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use SimilarFile.ProtoReflect.Descriptor instead.
+func (*SimilarFile) Descriptor() ([]byte, []int) {
 	return file_proto_analyzer_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *GetWordCloudResponse) GetImage() []byte {
+func (x *SimilarFile) GetFileId() string {
 	if x != nil {
-		return x.Image
+		return x.FileId
 	}
-	return nil
+	return ""
 }
 
-var File_proto_analyzer_proto protoreflect.FileDescriptor
+func (x *SimilarFile) GetCoverage() float64 {
+	if x != nil {
+		return x.Coverage
+	}
+	return 0
+}
 
-const file_proto_analyzer_proto_rawDesc = "" +
-	"\n" +
-	"\x14proto/analyzer.proto\x12\banalyzer\"]\n" +
-	"\x12AnalyzeFileRequest\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12.\n" +
-	"\x13generate_word_cloud\x18\x02 \x01(\bR\x11generateWordCloud\"q\n" +
-	"\x13AnalyzeFileResponse\x12\x1d\n" +
-	"\n" +
-	"word_count\x18\x01 \x01(\x05R\twordCount\x12!\n" +
-	"\funique_words\x18\x02 \x01(\x05R\vuniqueWords\x12\x18\n" +
-	"\asummary\x18\x03 \x01(\tR\asummary\"1\n" +
-	"\x13GetWordCloudRequest\x12\x1a\n" +
-	"\blocation\x18\x01 \x01(\tR\blocation\",\n" +
-	"\x14GetWordCloudResponse\x12\x14\n" +
-	"\x05image\x18\x01 \x01(\fR\x05image2\xac\x01\n" +
-	"\x0fAnalyzerService\x12J\n" +
+func (x *SimilarFile) GetMatchedWords() int32 {
+	if x != nil {
+		return x.MatchedWords
+	}
+	return 0
+}
+
+func (x *SimilarFile) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SimilarFile) GetMetric() string {
+	if x != nil {
+		return x.Metric
+	}
+	return ""
+}
+
+func (x *SimilarFile) GetNgramSize() int32 {
+	if x != nil {
+		return x.NgramSize
+	}
+	return 0
+}
+
+func (x *SimilarFile) GetThreshold() float64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *SimilarFile) GetAlgorithmVersion() int32 {
+	if x != nil {
+		return x.AlgorithmVersion
+	}
+	return 0
+}
+
+func (x *SimilarFile) GetContainment() float64 {
+	if x != nil {
+		return x.Containment
+	}
+	return 0
+}
+
+func (x *SimilarFile) GetSimilarContainment() float64 {
+	if x != nil {
+		return x.SimilarContainment
+	}
+	return 0
+}
+
+// Запрос облака слов
+type GetWordCloudRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Location      string                 `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWordCloudRequest) Reset() {
+	*x = GetWordCloudRequest{}
+	mi := &file_proto_analyzer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWordCloudRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWordCloudRequest) ProtoMessage() {}
+
+func (x *GetWordCloudRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWordCloudRequest.ProtoReflect.Descriptor instead.
+func (*GetWordCloudRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetWordCloudRequest) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+// Ответ с облаком слов
+type GetWordCloudResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Image []byte                 `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	// Формат изображения: png или svg
+	Format        string `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWordCloudResponse) Reset() {
+	*x = GetWordCloudResponse{}
+	mi := &file_proto_analyzer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWordCloudResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWordCloudResponse) ProtoMessage() {}
+
+func (x *GetWordCloudResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWordCloudResponse.ProtoReflect.Descriptor instead.
+func (*GetWordCloudResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetWordCloudResponse) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *GetWordCloudResponse) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+// Запрос совпавших фрагментов пары файлов
+type GetMatchedPassagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	SimilarFileId string                 `protobuf:"bytes,2,opt,name=similar_file_id,json=similarFileId,proto3" json:"similar_file_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMatchedPassagesRequest) Reset() {
+	*x = GetMatchedPassagesRequest{}
+	mi := &file_proto_analyzer_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMatchedPassagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMatchedPassagesRequest) ProtoMessage() {}
+
+func (x *GetMatchedPassagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMatchedPassagesRequest.ProtoReflect.Descriptor instead.
+func (*GetMatchedPassagesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetMatchedPassagesRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *GetMatchedPassagesRequest) GetSimilarFileId() string {
+	if x != nil {
+		return x.SimilarFileId
+	}
+	return ""
+}
+
+// Ответ с совпавшими фрагментами
+type GetMatchedPassagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Passages      []*MatchedPassage      `protobuf:"bytes,1,rep,name=passages,proto3" json:"passages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMatchedPassagesResponse) Reset() {
+	*x = GetMatchedPassagesResponse{}
+	mi := &file_proto_analyzer_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMatchedPassagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMatchedPassagesResponse) ProtoMessage() {}
+
+func (x *GetMatchedPassagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMatchedPassagesResponse.ProtoReflect.Descriptor instead.
+func (*GetMatchedPassagesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetMatchedPassagesResponse) GetPassages() []*MatchedPassage {
+	if x != nil {
+		return x.Passages
+	}
+	return nil
+}
+
+// Фрагмент, найденный в обоих файлах
+// Смещения указаны в символах исходных текстов, конец не включается
+type MatchedPassage struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Start        int32                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End          int32                  `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
+	SimilarStart int32                  `protobuf:"varint,3,opt,name=similar_start,json=similarStart,proto3" json:"similar_start,omitempty"`
+	SimilarEnd   int32                  `protobuf:"varint,4,opt,name=similar_end,json=similarEnd,proto3" json:"similar_end,omitempty"`
+	// Текст фрагмента в анализируемом файле
+	Text          string `protobuf:"bytes,5,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MatchedPassage) Reset() {
+	*x = MatchedPassage{}
+	mi := &file_proto_analyzer_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MatchedPassage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchedPassage) ProtoMessage() {}
+
+func (x *MatchedPassage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchedPassage.ProtoReflect.Descriptor instead.
+func (*MatchedPassage) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *MatchedPassage) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *MatchedPassage) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *MatchedPassage) GetSimilarStart() int32 {
+	if x != nil {
+		return x.SimilarStart
+	}
+	return 0
+}
+
+func (x *MatchedPassage) GetSimilarEnd() int32 {
+	if x != nil {
+		return x.SimilarEnd
+	}
+	return 0
+}
+
+func (x *MatchedPassage) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+// Запрос ключевых слов файла
+type GetKeywordsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	FileId string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	// Максимальное количество ключевых слов, 0 - все
+	Limit         int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetKeywordsRequest) Reset() {
+	*x = GetKeywordsRequest{}
+	mi := &file_proto_analyzer_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetKeywordsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetKeywordsRequest) ProtoMessage() {}
+
+func (x *GetKeywordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetKeywordsRequest.ProtoReflect.Descriptor instead.
+func (*GetKeywordsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetKeywordsRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *GetKeywordsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Ответ с ключевыми словами, от самого значимого
+type GetKeywordsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keywords      []*Keyword             `protobuf:"bytes,1,rep,name=keywords,proto3" json:"keywords,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetKeywordsResponse) Reset() {
+	*x = GetKeywordsResponse{}
+	mi := &file_proto_analyzer_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetKeywordsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetKeywordsResponse) ProtoMessage() {}
+
+func (x *GetKeywordsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetKeywordsResponse.ProtoReflect.Descriptor instead.
+func (*GetKeywordsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetKeywordsResponse) GetKeywords() []*Keyword {
+	if x != nil {
+		return x.Keywords
+	}
+	return nil
+}
+
+// Ключевое слово, оценённое по TF-IDF относительно всех проанализированных файлов
+type Keyword struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Самая частая форма слова в документе
+	Word string `protobuf:"bytes,1,opt,name=word,proto3" json:"word,omitempty"`
+	// Основа слова, общая для всех его форм
+	Term string `protobuf:"bytes,2,opt,name=term,proto3" json:"term,omitempty"`
+	// Количество вхождений в документ
+	Count int32 `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	// Количество проанализированных файлов, содержащих слово
+	DocumentFrequency int32   `protobuf:"varint,4,opt,name=document_frequency,json=documentFrequency,proto3" json:"document_frequency,omitempty"`
+	Score             float64 `protobuf:"fixed64,5,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Keyword) Reset() {
+	*x = Keyword{}
+	mi := &file_proto_analyzer_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Keyword) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Keyword) ProtoMessage() {}
+
+func (x *Keyword) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Keyword.ProtoReflect.Descriptor instead.
+func (*Keyword) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Keyword) GetWord() string {
+	if x != nil {
+		return x.Word
+	}
+	return ""
+}
+
+func (x *Keyword) GetTerm() string {
+	if x != nil {
+		return x.Term
+	}
+	return ""
+}
+
+func (x *Keyword) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *Keyword) GetDocumentFrequency() int32 {
+	if x != nil {
+		return x.DocumentFrequency
+	}
+	return 0
+}
+
+func (x *Keyword) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+// Запрос частот слов файла, например для интерактивного облака слов
+type GetWordFrequenciesRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	FileId string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	// Максимальное количество слов, 0 - все
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Регистр слов: lower (по умолчанию), upper или original
+	Case string `protobuf:"bytes,3,opt,name=case,proto3" json:"case,omitempty"`
+	// Не удалять стоп-слова языка документа
+	KeepStopWords bool `protobuf:"varint,4,opt,name=keep_stop_words,json=keepStopWords,proto3" json:"keep_stop_words,omitempty"`
+	// Считать формы одного слова разными словами, а не объединять их по основе
+	SeparateForms bool `protobuf:"varint,5,opt,name=separate_forms,json=separateForms,proto3" json:"separate_forms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWordFrequenciesRequest) Reset() {
+	*x = GetWordFrequenciesRequest{}
+	mi := &file_proto_analyzer_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWordFrequenciesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWordFrequenciesRequest) ProtoMessage() {}
+
+func (x *GetWordFrequenciesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWordFrequenciesRequest.ProtoReflect.Descriptor instead.
+func (*GetWordFrequenciesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetWordFrequenciesRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *GetWordFrequenciesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetWordFrequenciesRequest) GetCase() string {
+	if x != nil {
+		return x.Case
+	}
+	return ""
+}
+
+func (x *GetWordFrequenciesRequest) GetKeepStopWords() bool {
+	if x != nil {
+		return x.KeepStopWords
+	}
+	return false
+}
+
+func (x *GetWordFrequenciesRequest) GetSeparateForms() bool {
+	if x != nil {
+		return x.SeparateForms
+	}
+	return false
+}
+
+// Ответ с частотами слов, от самого частого
+type GetWordFrequenciesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Words         []*WordItem            `protobuf:"bytes,1,rep,name=words,proto3" json:"words,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWordFrequenciesResponse) Reset() {
+	*x = GetWordFrequenciesResponse{}
+	mi := &file_proto_analyzer_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWordFrequenciesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWordFrequenciesResponse) ProtoMessage() {}
+
+func (x *GetWordFrequenciesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWordFrequenciesResponse.ProtoReflect.Descriptor instead.
+func (*GetWordFrequenciesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetWordFrequenciesResponse) GetWords() []*WordItem {
+	if x != nil {
+		return x.Words
+	}
+	return nil
+}
+
+// Слово и количество его вхождений в документ
+type WordItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Value         int32                  `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WordItem) Reset() {
+	*x = WordItem{}
+	mi := &file_proto_analyzer_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WordItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WordItem) ProtoMessage() {}
+
+func (x *WordItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WordItem.ProtoReflect.Descriptor instead.
+func (*WordItem) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *WordItem) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *WordItem) GetValue() int32 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+// Запрос состояния задания на анализ
+type GetAnalysisJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAnalysisJobRequest) Reset() {
+	*x = GetAnalysisJobRequest{}
+	mi := &file_proto_analyzer_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAnalysisJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAnalysisJobRequest) ProtoMessage() {}
+
+func (x *GetAnalysisJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAnalysisJobRequest.ProtoReflect.Descriptor instead.
+func (*GetAnalysisJobRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetAnalysisJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+// Задание на анализ, выполняемое в фоне
+type AnalysisJob struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FileId string                 `protobuf:"bytes,2,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	// Состояние задания: queued, running, done или failed
+	Status string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	// Причина ошибки для status = failed
+	Error string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	// Сколько раз задание запускалось (задания остановленных обработчиков запускаются повторно)
+	Attempts int32 `protobuf:"varint,5,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	// Время событий в RFC 3339, пустое, если событие ещё не произошло
+	CreatedAt  string `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	StartedAt  string `protobuf:"bytes,7,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt string `protobuf:"bytes,8,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	// Результаты анализа для status = done
+	Result        *AnalyzeFileResponse `protobuf:"bytes,9,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalysisJob) Reset() {
+	*x = AnalysisJob{}
+	mi := &file_proto_analyzer_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalysisJob) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalysisJob) ProtoMessage() {}
+
+func (x *AnalysisJob) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalysisJob.ProtoReflect.Descriptor instead.
+func (*AnalysisJob) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *AnalysisJob) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AnalysisJob) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *AnalysisJob) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AnalysisJob) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *AnalysisJob) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *AnalysisJob) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *AnalysisJob) GetStartedAt() string {
+	if x != nil {
+		return x.StartedAt
+	}
+	return ""
+}
+
+func (x *AnalysisJob) GetFinishedAt() string {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return ""
+}
+
+func (x *AnalysisJob) GetResult() *AnalyzeFileResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+// Запрос хода анализа файла
+type WatchAnalysisRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchAnalysisRequest) Reset() {
+	*x = WatchAnalysisRequest{}
+	mi := &file_proto_analyzer_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchAnalysisRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchAnalysisRequest) ProtoMessage() {}
+
+func (x *WatchAnalysisRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchAnalysisRequest.ProtoReflect.Descriptor instead.
+func (*WatchAnalysisRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *WatchAnalysisRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+// Этап анализа файла
+type AnalysisEvent struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	FileId string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	// Этап: queued, fetching_file, analyzing_text, comparing, generating_word_cloud,
+	// saving, done или failed (последние два завершают поток)
+	Stage string `protobuf:"bytes,2,opt,name=stage,proto3" json:"stage,omitempty"`
+	// Номер текущего и общее количество шагов этапа (сравниваемых документов для comparing)
+	Current int32 `protobuf:"varint,3,opt,name=current,proto3" json:"current,omitempty"`
+	Total   int32 `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	// Причина ошибки для stage = failed
+	Error string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	// Время события в RFC 3339
+	Time          string `protobuf:"bytes,6,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalysisEvent) Reset() {
+	*x = AnalysisEvent{}
+	mi := &file_proto_analyzer_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalysisEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalysisEvent) ProtoMessage() {}
+
+func (x *AnalysisEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalysisEvent.ProtoReflect.Descriptor instead.
+func (*AnalysisEvent) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AnalysisEvent) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *AnalysisEvent) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *AnalysisEvent) GetCurrent() int32 {
+	if x != nil {
+		return x.Current
+	}
+	return 0
+}
+
+func (x *AnalysisEvent) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *AnalysisEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *AnalysisEvent) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+// Запрос регистрации вебхука
+type CreateWebhookRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Url   string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// Секрет для подписи запросов, генерируется, если не задан
+	Secret string `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	// События: analysis.completed, analysis.failed, plagiarism.detected (по умолчанию все)
+	Events        []string `protobuf:"bytes,3,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateWebhookRequest) Reset() {
+	*x = CreateWebhookRequest{}
+	mi := &file_proto_analyzer_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWebhookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWebhookRequest) ProtoMessage() {}
+
+func (x *CreateWebhookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateWebhookRequest.ProtoReflect.Descriptor instead.
+func (*CreateWebhookRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CreateWebhookRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *CreateWebhookRequest) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+func (x *CreateWebhookRequest) GetEvents() []string {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+// Зарегистрированный вебхук
+type Webhook struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url    string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Events []string               `protobuf:"bytes,3,rep,name=events,proto3" json:"events,omitempty"`
+	// Секрет возвращается только при регистрации
+	Secret string `protobuf:"bytes,4,opt,name=secret,proto3" json:"secret,omitempty"`
+	// Время регистрации в RFC 3339
+	CreatedAt     string `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Webhook) Reset() {
+	*x = Webhook{}
+	mi := &file_proto_analyzer_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Webhook) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Webhook) ProtoMessage() {}
+
+func (x *Webhook) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Webhook.ProtoReflect.Descriptor instead.
+func (*Webhook) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Webhook) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Webhook) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Webhook) GetEvents() []string {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *Webhook) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+func (x *Webhook) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type ListWebhooksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebhooksRequest) Reset() {
+	*x = ListWebhooksRequest{}
+	mi := &file_proto_analyzer_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhooksRequest) ProtoMessage() {}
+
+func (x *ListWebhooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebhooksRequest.ProtoReflect.Descriptor instead.
+func (*ListWebhooksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{21}
+}
+
+type ListWebhooksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Webhooks      []*Webhook             `protobuf:"bytes,1,rep,name=webhooks,proto3" json:"webhooks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebhooksResponse) Reset() {
+	*x = ListWebhooksResponse{}
+	mi := &file_proto_analyzer_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhooksResponse) ProtoMessage() {}
+
+func (x *ListWebhooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebhooksResponse.ProtoReflect.Descriptor instead.
+func (*ListWebhooksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListWebhooksResponse) GetWebhooks() []*Webhook {
+	if x != nil {
+		return x.Webhooks
+	}
+	return nil
+}
+
+type DeleteWebhookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WebhookId     string                 `protobuf:"bytes,1,opt,name=webhook_id,json=webhookId,proto3" json:"webhook_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteWebhookRequest) Reset() {
+	*x = DeleteWebhookRequest{}
+	mi := &file_proto_analyzer_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteWebhookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteWebhookRequest) ProtoMessage() {}
+
+func (x *DeleteWebhookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteWebhookRequest.ProtoReflect.Descriptor instead.
+func (*DeleteWebhookRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DeleteWebhookRequest) GetWebhookId() string {
+	if x != nil {
+		return x.WebhookId
+	}
+	return ""
+}
+
+type DeleteWebhookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteWebhookResponse) Reset() {
+	*x = DeleteWebhookResponse{}
+	mi := &file_proto_analyzer_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteWebhookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteWebhookResponse) ProtoMessage() {}
+
+func (x *DeleteWebhookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteWebhookResponse.ProtoReflect.Descriptor instead.
+func (*DeleteWebhookResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{24}
+}
+
+// Запрос журнала доставки вебхуков, незаданные поля не фильтруют журнал
+type ListWebhookDeliveriesRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	WebhookId string                 `protobuf:"bytes,1,opt,name=webhook_id,json=webhookId,proto3" json:"webhook_id,omitempty"`
+	FileId    string                 `protobuf:"bytes,2,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	// Максимальное количество записей (по умолчанию 100)
+	Limit         int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebhookDeliveriesRequest) Reset() {
+	*x = ListWebhookDeliveriesRequest{}
+	mi := &file_proto_analyzer_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhookDeliveriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhookDeliveriesRequest) ProtoMessage() {}
+
+func (x *ListWebhookDeliveriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebhookDeliveriesRequest.ProtoReflect.Descriptor instead.
+func (*ListWebhookDeliveriesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListWebhookDeliveriesRequest) GetWebhookId() string {
+	if x != nil {
+		return x.WebhookId
+	}
+	return ""
+}
+
+func (x *ListWebhookDeliveriesRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *ListWebhookDeliveriesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListWebhookDeliveriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deliveries    []*WebhookDelivery     `protobuf:"bytes,1,rep,name=deliveries,proto3" json:"deliveries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebhookDeliveriesResponse) Reset() {
+	*x = ListWebhookDeliveriesResponse{}
+	mi := &file_proto_analyzer_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhookDeliveriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhookDeliveriesResponse) ProtoMessage() {}
+
+func (x *ListWebhookDeliveriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebhookDeliveriesResponse.ProtoReflect.Descriptor instead.
+func (*ListWebhookDeliveriesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListWebhookDeliveriesResponse) GetDeliveries() []*WebhookDelivery {
+	if x != nil {
+		return x.Deliveries
+	}
+	return nil
+}
+
+// Доставка события вебхуку
+type WebhookDelivery struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Пустой для URL из запроса на анализ
+	WebhookId string `protobuf:"bytes,2,opt,name=webhook_id,json=webhookId,proto3" json:"webhook_id,omitempty"`
+	Url       string `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	Event     string `protobuf:"bytes,4,opt,name=event,proto3" json:"event,omitempty"`
+	FileId    string `protobuf:"bytes,5,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	// Состояние доставки: pending, delivered или failed
+	Status   string `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	Attempts int32  `protobuf:"varint,7,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	// HTTP-статус последнего ответа, 0, если ответа не было
+	ResponseStatus int32 `protobuf:"varint,8,opt,name=response_status,json=responseStatus,proto3" json:"response_status,omitempty"`
+	// Ошибка последней попытки
+	Error string `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	// Время событий в RFC 3339, пустое, если событие ещё не произошло
+	CreatedAt     string `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	NextAttemptAt string `protobuf:"bytes,11,opt,name=next_attempt_at,json=nextAttemptAt,proto3" json:"next_attempt_at,omitempty"`
+	LastAttemptAt string `protobuf:"bytes,12,opt,name=last_attempt_at,json=lastAttemptAt,proto3" json:"last_attempt_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebhookDelivery) Reset() {
+	*x = WebhookDelivery{}
+	mi := &file_proto_analyzer_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookDelivery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookDelivery) ProtoMessage() {}
+
+func (x *WebhookDelivery) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analyzer_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookDelivery.ProtoReflect.Descriptor instead.
+func (*WebhookDelivery) Descriptor() ([]byte, []int) {
+	return file_proto_analyzer_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *WebhookDelivery) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetWebhookId() string {
+	if x != nil {
+		return x.WebhookId
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *WebhookDelivery) GetResponseStatus() int32 {
+	if x != nil {
+		return x.ResponseStatus
+	}
+	return 0
+}
+
+func (x *WebhookDelivery) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetNextAttemptAt() string {
+	if x != nil {
+		return x.NextAttemptAt
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetLastAttemptAt() string {
+	if x != nil {
+		return x.LastAttemptAt
+	}
+	return ""
+}
+
+var File_proto_analyzer_proto protoreflect.FileDescriptor
+
+const file_proto_analyzer_proto_rawDesc = "" +
+	"\n" +
+	"\x14proto/analyzer.proto\x12\banalyzer\"\xc8\x01\n" +
+	"\x12AnalyzeFileRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12.\n" +
+	"\x13generate_word_cloud\x18\x02 \x01(\bR\x11generateWordCloud\x12H\n" +
+	"\x12word_cloud_options\x18\x03 \x01(\v2\x1a.analyzer.WordCloudOptionsR\x10wordCloudOptions\x12\x1f\n" +
+	"\vwebhook_url\x18\x04 \x01(\tR\n" +
+	"webhookUrl\"\xd4\x01\n" +
+	"\x10WordCloudOptions\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12\x14\n" +
+	"\x05width\x18\x02 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x03 \x01(\x05R\x06height\x12\x1b\n" +
+	"\tmax_words\x18\x04 \x01(\x05R\bmaxWords\x12!\n" +
+	"\fcolor_scheme\x18\x05 \x01(\tR\vcolorScheme\x12\x12\n" +
+	"\x04case\x18\x06 \x01(\tR\x04case\x12&\n" +
+	"\x0fkeep_stop_words\x18\a \x01(\bR\rkeepStopWords\"\xb0\a\n" +
+	"\x13AnalyzeFileResponse\x12\x1d\n" +
+	"\n" +
+	"word_count\x18\x01 \x01(\x05R\twordCount\x12!\n" +
+	"\funique_words\x18\x02 \x01(\x05R\vuniqueWords\x12\x18\n" +
+	"\asummary\x18\x03 \x01(\tR\asummary\x12'\n" +
+	"\x0fparagraph_count\x18\x04 \x01(\x05R\x0eparagraphCount\x12'\n" +
+	"\x0fcharacter_count\x18\x05 \x01(\x05R\x0echaracterCount\x12#\n" +
+	"\ris_plagiarism\x18\x06 \x01(\bR\fisPlagiarism\x12(\n" +
+	"\x10similar_file_ids\x18\a \x03(\tR\x0esimilarFileIds\x12.\n" +
+	"\x13word_cloud_location\x18\b \x01(\tR\x11wordCloudLocation\x12:\n" +
+	"\rsimilar_files\x18\t \x03(\v2\x15.analyzer.SimilarFileR\fsimilarFiles\x12\x1a\n" +
+	"\blanguage\x18\n" +
+	" \x01(\tR\blanguage\x121\n" +
+	"\x14obfuscation_detected\x18\v \x01(\bR\x13obfuscationDetected\x12'\n" +
+	"\x0fhomoglyph_count\x18\f \x01(\x05R\x0ehomoglyphCount\x120\n" +
+	"\x14invisible_char_count\x18\r \x01(\x05R\x12invisibleCharCount\x129\n" +
+	"\x19character_count_no_spaces\x18\x0e \x01(\x05R\x16characterCountNoSpaces\x12%\n" +
+	"\x0esentence_count\x18\x0f \x01(\x05R\rsentenceCount\x12.\n" +
+	"\x13avg_sentence_length\x18\x10 \x01(\x01R\x11avgSentenceLength\x12&\n" +
+	"\x0favg_word_length\x18\x11 \x01(\x01R\ravgWordLength\x12+\n" +
+	"\x11lexical_diversity\x18\x12 \x01(\x01R\x10lexicalDiversity\x12/\n" +
+	"\x13readability_formula\x18\x13 \x01(\tR\x12readabilityFormula\x12!\n" +
+	"\freading_ease\x18\x14 \x01(\x01R\vreadingEase\x12\x1f\n" +
+	"\vgrade_level\x18\x15 \x01(\x01R\n" +
+	"gradeLevel\x12*\n" +
+	"\x11word_cloud_format\x18\x16 \x01(\tR\x0fwordCloudFormat\"\xd2\x02\n" +
+	"\vSimilarFile\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x1a\n" +
+	"\bcoverage\x18\x02 \x01(\x01R\bcoverage\x12#\n" +
+	"\rmatched_words\x18\x03 \x01(\x05R\fmatchedWords\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x01R\x05score\x12\x16\n" +
+	"\x06metric\x18\x05 \x01(\tR\x06metric\x12\x1d\n" +
+	"\n" +
+	"ngram_size\x18\x06 \x01(\x05R\tngramSize\x12\x1c\n" +
+	"\tthreshold\x18\a \x01(\x01R\tthreshold\x12+\n" +
+	"\x11algorithm_version\x18\b \x01(\x05R\x10algorithmVersion\x12 \n" +
+	"\vcontainment\x18\t \x01(\x01R\vcontainment\x12/\n" +
+	"\x13similar_containment\x18\n" +
+	" \x01(\x01R\x12similarContainment\"1\n" +
+	"\x13GetWordCloudRequest\x12\x1a\n" +
+	"\blocation\x18\x01 \x01(\tR\blocation\"D\n" +
+	"\x14GetWordCloudResponse\x12\x14\n" +
+	"\x05image\x18\x01 \x01(\fR\x05image\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\"\\\n" +
+	"\x19GetMatchedPassagesRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12&\n" +
+	"\x0fsimilar_file_id\x18\x02 \x01(\tR\rsimilarFileId\"R\n" +
+	"\x1aGetMatchedPassagesResponse\x124\n" +
+	"\bpassages\x18\x01 \x03(\v2\x18.analyzer.MatchedPassageR\bpassages\"\x92\x01\n" +
+	"\x0eMatchedPassage\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x05R\x03end\x12#\n" +
+	"\rsimilar_start\x18\x03 \x01(\x05R\fsimilarStart\x12\x1f\n" +
+	"\vsimilar_end\x18\x04 \x01(\x05R\n" +
+	"similarEnd\x12\x12\n" +
+	"\x04text\x18\x05 \x01(\tR\x04text\"C\n" +
+	"\x12GetKeywordsRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"D\n" +
+	"\x13GetKeywordsResponse\x12-\n" +
+	"\bkeywords\x18\x01 \x03(\v2\x11.analyzer.KeywordR\bkeywords\"\x8c\x01\n" +
+	"\aKeyword\x12\x12\n" +
+	"\x04word\x18\x01 \x01(\tR\x04word\x12\x12\n" +
+	"\x04term\x18\x02 \x01(\tR\x04term\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x05R\x05count\x12-\n" +
+	"\x12document_frequency\x18\x04 \x01(\x05R\x11documentFrequency\x12\x14\n" +
+	"\x05score\x18\x05 \x01(\x01R\x05score\"\xad\x01\n" +
+	"\x19GetWordFrequenciesRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x12\n" +
+	"\x04case\x18\x03 \x01(\tR\x04case\x12&\n" +
+	"\x0fkeep_stop_words\x18\x04 \x01(\bR\rkeepStopWords\x12%\n" +
+	"\x0eseparate_forms\x18\x05 \x01(\bR\rseparateForms\"F\n" +
+	"\x1aGetWordFrequenciesResponse\x12(\n" +
+	"\x05words\x18\x01 \x03(\v2\x12.analyzer.WordItemR\x05words\"4\n" +
+	"\bWordItem\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value\".\n" +
+	"\x15GetAnalysisJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\x96\x02\n" +
+	"\vAnalysisJob\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\afile_id\x18\x02 \x01(\tR\x06fileId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x1a\n" +
+	"\battempts\x18\x05 \x01(\x05R\battempts\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\a \x01(\tR\tstartedAt\x12\x1f\n" +
+	"\vfinished_at\x18\b \x01(\tR\n" +
+	"finishedAt\x125\n" +
+	"\x06result\x18\t \x01(\v2\x1d.analyzer.AnalyzeFileResponseR\x06result\"/\n" +
+	"\x14WatchAnalysisRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\"\x98\x01\n" +
+	"\rAnalysisEvent\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x14\n" +
+	"\x05stage\x18\x02 \x01(\tR\x05stage\x12\x18\n" +
+	"\acurrent\x18\x03 \x01(\x05R\acurrent\x12\x14\n" +
+	"\x05total\x18\x04 \x01(\x05R\x05total\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12\x12\n" +
+	"\x04time\x18\x06 \x01(\tR\x04time\"X\n" +
+	"\x14CreateWebhookRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x16\n" +
+	"\x06secret\x18\x02 \x01(\tR\x06secret\x12\x16\n" +
+	"\x06events\x18\x03 \x03(\tR\x06events\"z\n" +
+	"\aWebhook\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x16\n" +
+	"\x06events\x18\x03 \x03(\tR\x06events\x12\x16\n" +
+	"\x06secret\x18\x04 \x01(\tR\x06secret\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt\"\x15\n" +
+	"\x13ListWebhooksRequest\"E\n" +
+	"\x14ListWebhooksResponse\x12-\n" +
+	"\bwebhooks\x18\x01 \x03(\v2\x11.analyzer.WebhookR\bwebhooks\"5\n" +
+	"\x14DeleteWebhookRequest\x12\x1d\n" +
+	"\n" +
+	"webhook_id\x18\x01 \x01(\tR\twebhookId\"\x17\n" +
+	"\x15DeleteWebhookResponse\"l\n" +
+	"\x1cListWebhookDeliveriesRequest\x12\x1d\n" +
+	"\n" +
+	"webhook_id\x18\x01 \x01(\tR\twebhookId\x12\x17\n" +
+	"\afile_id\x18\x02 \x01(\tR\x06fileId\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"Z\n" +
+	"\x1dListWebhookDeliveriesResponse\x129\n" +
+	"\n" +
+	"deliveries\x18\x01 \x03(\v2\x19.analyzer.WebhookDeliveryR\n" +
+	"deliveries\"\xe3\x02\n" +
+	"\x0fWebhookDelivery\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"webhook_id\x18\x02 \x01(\tR\twebhookId\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12\x14\n" +
+	"\x05event\x18\x04 \x01(\tR\x05event\x12\x17\n" +
+	"\afile_id\x18\x05 \x01(\tR\x06fileId\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12\x1a\n" +
+	"\battempts\x18\a \x01(\x05R\battempts\x12'\n" +
+	"\x0fresponse_status\x18\b \x01(\x05R\x0eresponseStatus\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\tR\tcreatedAt\x12&\n" +
+	"\x0fnext_attempt_at\x18\v \x01(\tR\rnextAttemptAt\x12&\n" +
+	"\x0flast_attempt_at\x18\f \x01(\tR\rlastAttemptAt2\xea\a\n" +
+	"\x13FileAnalysisService\x12J\n" +
 	"\vAnalyzeFile\x12\x1c.analyzer.AnalyzeFileRequest\x1a\x1d.analyzer.AnalyzeFileResponse\x12M\n" +
-	"\fGetWordCloud\x12\x1d.analyzer.GetWordCloudRequest\x1a\x1e.analyzer.GetWordCloudResponseB9Z7local.dev/doc-analyzer/internal/proto/analyzer;analyzerb\x06proto3"
+	"\fGetWordCloud\x12\x1d.analyzer.GetWordCloudRequest\x1a\x1e.analyzer.GetWordCloudResponse\x12_\n" +
+	"\x12GetMatchedPassages\x12#.analyzer.GetMatchedPassagesRequest\x1a$.analyzer.GetMatchedPassagesResponse\x12J\n" +
+	"\vGetKeywords\x12\x1c.analyzer.GetKeywordsRequest\x1a\x1d.analyzer.GetKeywordsResponse\x12_\n" +
+	"\x12GetWordFrequencies\x12#.analyzer.GetWordFrequenciesRequest\x1a$.analyzer.GetWordFrequenciesResponse\x12E\n" +
+	"\x0eSubmitAnalysis\x12\x1c.analyzer.AnalyzeFileRequest\x1a\x15.analyzer.AnalysisJob\x12H\n" +
+	"\x0eGetAnalysisJob\x12\x1f.analyzer.GetAnalysisJobRequest\x1a\x15.analyzer.AnalysisJob\x12J\n" +
+	"\rWatchAnalysis\x12\x1e.analyzer.WatchAnalysisRequest\x1a\x17.analyzer.AnalysisEvent0\x01\x12B\n" +
+	"\rCreateWebhook\x12\x1e.analyzer.CreateWebhookRequest\x1a\x11.analyzer.Webhook\x12M\n" +
+	"\fListWebhooks\x12\x1d.analyzer.ListWebhooksRequest\x1a\x1e.analyzer.ListWebhooksResponse\x12P\n" +
+	"\rDeleteWebhook\x12\x1e.analyzer.DeleteWebhookRequest\x1a\x1f.analyzer.DeleteWebhookResponse\x12h\n" +
+	"\x15ListWebhookDeliveries\x12&.analyzer.ListWebhookDeliveriesRequest\x1a'.analyzer.ListWebhookDeliveriesResponseB9Z7local.dev/doc-analyzer/internal/proto/analyzer;analyzerb\x06proto3"
 
 var (
 	file_proto_analyzer_proto_rawDescOnce sync.Once
@@ -258,23 +2206,75 @@ func file_proto_analyzer_proto_rawDescGZIP() []byte {
 	return file_proto_analyzer_proto_rawDescData
 }
 
-var file_proto_analyzer_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_analyzer_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
 var file_proto_analyzer_proto_goTypes = []any{
-	(*AnalyzeFileRequest)(nil),   // 0: analyzer.AnalyzeFileRequest
-	(*AnalyzeFileResponse)(nil),  // 1: analyzer.AnalyzeFileResponse
-	(*GetWordCloudRequest)(nil),  // 2: analyzer.GetWordCloudRequest
-	(*GetWordCloudResponse)(nil), // 3: analyzer.GetWordCloudResponse
+	(*AnalyzeFileRequest)(nil),            // 0: analyzer.AnalyzeFileRequest
+	(*WordCloudOptions)(nil),              // 1: analyzer.WordCloudOptions
+	(*AnalyzeFileResponse)(nil),           // 2: analyzer.AnalyzeFileResponse
+	(*SimilarFile)(nil),                   // 3: analyzer.SimilarFile
+	(*GetWordCloudRequest)(nil),           // 4: analyzer.GetWordCloudRequest
+	(*GetWordCloudResponse)(nil),          // 5: analyzer.GetWordCloudResponse
+	(*GetMatchedPassagesRequest)(nil),     // 6: analyzer.GetMatchedPassagesRequest
+	(*GetMatchedPassagesResponse)(nil),    // 7: analyzer.GetMatchedPassagesResponse
+	(*MatchedPassage)(nil),                // 8: analyzer.MatchedPassage
+	(*GetKeywordsRequest)(nil),            // 9: analyzer.GetKeywordsRequest
+	(*GetKeywordsResponse)(nil),           // 10: analyzer.GetKeywordsResponse
+	(*Keyword)(nil),                       // 11: analyzer.Keyword
+	(*GetWordFrequenciesRequest)(nil),     // 12: analyzer.GetWordFrequenciesRequest
+	(*GetWordFrequenciesResponse)(nil),    // 13: analyzer.GetWordFrequenciesResponse
+	(*WordItem)(nil),                      // 14: analyzer.WordItem
+	(*GetAnalysisJobRequest)(nil),         // 15: analyzer.GetAnalysisJobRequest
+	(*AnalysisJob)(nil),                   // 16: analyzer.AnalysisJob
+	(*WatchAnalysisRequest)(nil),          // 17: analyzer.WatchAnalysisRequest
+	(*AnalysisEvent)(nil),                 // 18: analyzer.AnalysisEvent
+	(*CreateWebhookRequest)(nil),          // 19: analyzer.CreateWebhookRequest
+	(*Webhook)(nil),                       // 20: analyzer.Webhook
+	(*ListWebhooksRequest)(nil),           // 21: analyzer.ListWebhooksRequest
+	(*ListWebhooksResponse)(nil),          // 22: analyzer.ListWebhooksResponse
+	(*DeleteWebhookRequest)(nil),          // 23: analyzer.DeleteWebhookRequest
+	(*DeleteWebhookResponse)(nil),         // 24: analyzer.DeleteWebhookResponse
+	(*ListWebhookDeliveriesRequest)(nil),  // 25: analyzer.ListWebhookDeliveriesRequest
+	(*ListWebhookDeliveriesResponse)(nil), // 26: analyzer.ListWebhookDeliveriesResponse
+	(*WebhookDelivery)(nil),               // 27: analyzer.WebhookDelivery
 }
 var file_proto_analyzer_proto_depIdxs = []int32{
-	0, // 0: analyzer.AnalyzerService.AnalyzeFile:input_type -> analyzer.AnalyzeFileRequest
-	2, // 1: analyzer.AnalyzerService.GetWordCloud:input_type -> analyzer.GetWordCloudRequest
-	1, // 2: analyzer.AnalyzerService.AnalyzeFile:output_type -> analyzer.AnalyzeFileResponse
-	3, // 3: analyzer.AnalyzerService.GetWordCloud:output_type -> analyzer.GetWordCloudResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1,  // 0: analyzer.AnalyzeFileRequest.word_cloud_options:type_name -> analyzer.WordCloudOptions
+	3,  // 1: analyzer.AnalyzeFileResponse.similar_files:type_name -> analyzer.SimilarFile
+	8,  // 2: analyzer.GetMatchedPassagesResponse.passages:type_name -> analyzer.MatchedPassage
+	11, // 3: analyzer.GetKeywordsResponse.keywords:type_name -> analyzer.Keyword
+	14, // 4: analyzer.GetWordFrequenciesResponse.words:type_name -> analyzer.WordItem
+	2,  // 5: analyzer.AnalysisJob.result:type_name -> analyzer.AnalyzeFileResponse
+	20, // 6: analyzer.ListWebhooksResponse.webhooks:type_name -> analyzer.Webhook
+	27, // 7: analyzer.ListWebhookDeliveriesResponse.deliveries:type_name -> analyzer.WebhookDelivery
+	0,  // 8: analyzer.FileAnalysisService.AnalyzeFile:input_type -> analyzer.AnalyzeFileRequest
+	4,  // 9: analyzer.FileAnalysisService.GetWordCloud:input_type -> analyzer.GetWordCloudRequest
+	6,  // 10: analyzer.FileAnalysisService.GetMatchedPassages:input_type -> analyzer.GetMatchedPassagesRequest
+	9,  // 11: analyzer.FileAnalysisService.GetKeywords:input_type -> analyzer.GetKeywordsRequest
+	12, // 12: analyzer.FileAnalysisService.GetWordFrequencies:input_type -> analyzer.GetWordFrequenciesRequest
+	0,  // 13: analyzer.FileAnalysisService.SubmitAnalysis:input_type -> analyzer.AnalyzeFileRequest
+	15, // 14: analyzer.FileAnalysisService.GetAnalysisJob:input_type -> analyzer.GetAnalysisJobRequest
+	17, // 15: analyzer.FileAnalysisService.WatchAnalysis:input_type -> analyzer.WatchAnalysisRequest
+	19, // 16: analyzer.FileAnalysisService.CreateWebhook:input_type -> analyzer.CreateWebhookRequest
+	21, // 17: analyzer.FileAnalysisService.ListWebhooks:input_type -> analyzer.ListWebhooksRequest
+	23, // 18: analyzer.FileAnalysisService.DeleteWebhook:input_type -> analyzer.DeleteWebhookRequest
+	25, // 19: analyzer.FileAnalysisService.ListWebhookDeliveries:input_type -> analyzer.ListWebhookDeliveriesRequest
+	2,  // 20: analyzer.FileAnalysisService.AnalyzeFile:output_type -> analyzer.AnalyzeFileResponse
+	5,  // 21: analyzer.FileAnalysisService.GetWordCloud:output_type -> analyzer.GetWordCloudResponse
+	7,  // 22: analyzer.FileAnalysisService.GetMatchedPassages:output_type -> analyzer.GetMatchedPassagesResponse
+	10, // 23: analyzer.FileAnalysisService.GetKeywords:output_type -> analyzer.GetKeywordsResponse
+	13, // 24: analyzer.FileAnalysisService.GetWordFrequencies:output_type -> analyzer.GetWordFrequenciesResponse
+	16, // 25: analyzer.FileAnalysisService.SubmitAnalysis:output_type -> analyzer.AnalysisJob
+	16, // 26: analyzer.FileAnalysisService.GetAnalysisJob:output_type -> analyzer.AnalysisJob
+	18, // 27: analyzer.FileAnalysisService.WatchAnalysis:output_type -> analyzer.AnalysisEvent
+	20, // 28: analyzer.FileAnalysisService.CreateWebhook:output_type -> analyzer.Webhook
+	22, // 29: analyzer.FileAnalysisService.ListWebhooks:output_type -> analyzer.ListWebhooksResponse
+	24, // 30: analyzer.FileAnalysisService.DeleteWebhook:output_type -> analyzer.DeleteWebhookResponse
+	26, // 31: analyzer.FileAnalysisService.ListWebhookDeliveries:output_type -> analyzer.ListWebhookDeliveriesResponse
+	20, // [20:32] is the sub-list for method output_type
+	8,  // [8:20] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_proto_analyzer_proto_init() }
@@ -288,7 +2288,7 @@ func file_proto_analyzer_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_analyzer_proto_rawDesc), len(file_proto_analyzer_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   28,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
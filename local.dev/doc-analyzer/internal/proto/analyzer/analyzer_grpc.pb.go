@@ -19,145 +19,535 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AnalyzerService_AnalyzeFile_FullMethodName  = "/analyzer.AnalyzerService/AnalyzeFile"
-	AnalyzerService_GetWordCloud_FullMethodName = "/analyzer.AnalyzerService/GetWordCloud"
+	FileAnalysisService_AnalyzeFile_FullMethodName           = "/analyzer.FileAnalysisService/AnalyzeFile"
+	FileAnalysisService_GetWordCloud_FullMethodName          = "/analyzer.FileAnalysisService/GetWordCloud"
+	FileAnalysisService_GetMatchedPassages_FullMethodName    = "/analyzer.FileAnalysisService/GetMatchedPassages"
+	FileAnalysisService_GetKeywords_FullMethodName           = "/analyzer.FileAnalysisService/GetKeywords"
+	FileAnalysisService_GetWordFrequencies_FullMethodName    = "/analyzer.FileAnalysisService/GetWordFrequencies"
+	FileAnalysisService_SubmitAnalysis_FullMethodName        = "/analyzer.FileAnalysisService/SubmitAnalysis"
+	FileAnalysisService_GetAnalysisJob_FullMethodName        = "/analyzer.FileAnalysisService/GetAnalysisJob"
+	FileAnalysisService_WatchAnalysis_FullMethodName         = "/analyzer.FileAnalysisService/WatchAnalysis"
+	FileAnalysisService_CreateWebhook_FullMethodName         = "/analyzer.FileAnalysisService/CreateWebhook"
+	FileAnalysisService_ListWebhooks_FullMethodName          = "/analyzer.FileAnalysisService/ListWebhooks"
+	FileAnalysisService_DeleteWebhook_FullMethodName         = "/analyzer.FileAnalysisService/DeleteWebhook"
+	FileAnalysisService_ListWebhookDeliveries_FullMethodName = "/analyzer.FileAnalysisService/ListWebhookDeliveries"
 )
 
-// AnalyzerServiceClient is the client API for AnalyzerService service.
+// FileAnalysisServiceClient is the client API for FileAnalysisService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 //
 // Сервис анализа документов
-type AnalyzerServiceClient interface {
+type FileAnalysisServiceClient interface {
 	AnalyzeFile(ctx context.Context, in *AnalyzeFileRequest, opts ...grpc.CallOption) (*AnalyzeFileResponse, error)
 	GetWordCloud(ctx context.Context, in *GetWordCloudRequest, opts ...grpc.CallOption) (*GetWordCloudResponse, error)
+	GetMatchedPassages(ctx context.Context, in *GetMatchedPassagesRequest, opts ...grpc.CallOption) (*GetMatchedPassagesResponse, error)
+	GetKeywords(ctx context.Context, in *GetKeywordsRequest, opts ...grpc.CallOption) (*GetKeywordsResponse, error)
+	GetWordFrequencies(ctx context.Context, in *GetWordFrequenciesRequest, opts ...grpc.CallOption) (*GetWordFrequenciesResponse, error)
+	// Ставит анализ файла в очередь и сразу возвращает задание
+	SubmitAnalysis(ctx context.Context, in *AnalyzeFileRequest, opts ...grpc.CallOption) (*AnalysisJob, error)
+	GetAnalysisJob(ctx context.Context, in *GetAnalysisJobRequest, opts ...grpc.CallOption) (*AnalysisJob, error)
+	// Передаёт этапы анализа файла, пока анализ не завершится
+	WatchAnalysis(ctx context.Context, in *WatchAnalysisRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AnalysisEvent], error)
+	// Вебхуки, уведомляемые о завершении анализа и найденном плагиате
+	CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*Webhook, error)
+	ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error)
+	DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookResponse, error)
+	ListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest, opts ...grpc.CallOption) (*ListWebhookDeliveriesResponse, error)
 }
 
-type analyzerServiceClient struct {
+type fileAnalysisServiceClient struct {
 	cc grpc.ClientConnInterface
 }
 
-func NewAnalyzerServiceClient(cc grpc.ClientConnInterface) AnalyzerServiceClient {
-	return &analyzerServiceClient{cc}
+func NewFileAnalysisServiceClient(cc grpc.ClientConnInterface) FileAnalysisServiceClient {
+	return &fileAnalysisServiceClient{cc}
 }
 
-func (c *analyzerServiceClient) AnalyzeFile(ctx context.Context, in *AnalyzeFileRequest, opts ...grpc.CallOption) (*AnalyzeFileResponse, error) {
+func (c *fileAnalysisServiceClient) AnalyzeFile(ctx context.Context, in *AnalyzeFileRequest, opts ...grpc.CallOption) (*AnalyzeFileResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(AnalyzeFileResponse)
-	err := c.cc.Invoke(ctx, AnalyzerService_AnalyzeFile_FullMethodName, in, out, cOpts...)
+	err := c.cc.Invoke(ctx, FileAnalysisService_AnalyzeFile_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *analyzerServiceClient) GetWordCloud(ctx context.Context, in *GetWordCloudRequest, opts ...grpc.CallOption) (*GetWordCloudResponse, error) {
+func (c *fileAnalysisServiceClient) GetWordCloud(ctx context.Context, in *GetWordCloudRequest, opts ...grpc.CallOption) (*GetWordCloudResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetWordCloudResponse)
-	err := c.cc.Invoke(ctx, AnalyzerService_GetWordCloud_FullMethodName, in, out, cOpts...)
+	err := c.cc.Invoke(ctx, FileAnalysisService_GetWordCloud_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// AnalyzerServiceServer is the server API for AnalyzerService service.
-// All implementations must embed UnimplementedAnalyzerServiceServer
+func (c *fileAnalysisServiceClient) GetMatchedPassages(ctx context.Context, in *GetMatchedPassagesRequest, opts ...grpc.CallOption) (*GetMatchedPassagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMatchedPassagesResponse)
+	err := c.cc.Invoke(ctx, FileAnalysisService_GetMatchedPassages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileAnalysisServiceClient) GetKeywords(ctx context.Context, in *GetKeywordsRequest, opts ...grpc.CallOption) (*GetKeywordsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetKeywordsResponse)
+	err := c.cc.Invoke(ctx, FileAnalysisService_GetKeywords_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileAnalysisServiceClient) GetWordFrequencies(ctx context.Context, in *GetWordFrequenciesRequest, opts ...grpc.CallOption) (*GetWordFrequenciesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetWordFrequenciesResponse)
+	err := c.cc.Invoke(ctx, FileAnalysisService_GetWordFrequencies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileAnalysisServiceClient) SubmitAnalysis(ctx context.Context, in *AnalyzeFileRequest, opts ...grpc.CallOption) (*AnalysisJob, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnalysisJob)
+	err := c.cc.Invoke(ctx, FileAnalysisService_SubmitAnalysis_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileAnalysisServiceClient) GetAnalysisJob(ctx context.Context, in *GetAnalysisJobRequest, opts ...grpc.CallOption) (*AnalysisJob, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnalysisJob)
+	err := c.cc.Invoke(ctx, FileAnalysisService_GetAnalysisJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileAnalysisServiceClient) WatchAnalysis(ctx context.Context, in *WatchAnalysisRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AnalysisEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FileAnalysisService_ServiceDesc.Streams[0], FileAnalysisService_WatchAnalysis_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchAnalysisRequest, AnalysisEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FileAnalysisService_WatchAnalysisClient = grpc.ServerStreamingClient[AnalysisEvent]
+
+func (c *fileAnalysisServiceClient) CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*Webhook, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Webhook)
+	err := c.cc.Invoke(ctx, FileAnalysisService_CreateWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileAnalysisServiceClient) ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWebhooksResponse)
+	err := c.cc.Invoke(ctx, FileAnalysisService_ListWebhooks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileAnalysisServiceClient) DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteWebhookResponse)
+	err := c.cc.Invoke(ctx, FileAnalysisService_DeleteWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileAnalysisServiceClient) ListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest, opts ...grpc.CallOption) (*ListWebhookDeliveriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWebhookDeliveriesResponse)
+	err := c.cc.Invoke(ctx, FileAnalysisService_ListWebhookDeliveries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FileAnalysisServiceServer is the server API for FileAnalysisService service.
+// All implementations must embed UnimplementedFileAnalysisServiceServer
 // for forward compatibility.
 //
 // Сервис анализа документов
-type AnalyzerServiceServer interface {
+type FileAnalysisServiceServer interface {
 	AnalyzeFile(context.Context, *AnalyzeFileRequest) (*AnalyzeFileResponse, error)
 	GetWordCloud(context.Context, *GetWordCloudRequest) (*GetWordCloudResponse, error)
-	mustEmbedUnimplementedAnalyzerServiceServer()
+	GetMatchedPassages(context.Context, *GetMatchedPassagesRequest) (*GetMatchedPassagesResponse, error)
+	GetKeywords(context.Context, *GetKeywordsRequest) (*GetKeywordsResponse, error)
+	GetWordFrequencies(context.Context, *GetWordFrequenciesRequest) (*GetWordFrequenciesResponse, error)
+	// Ставит анализ файла в очередь и сразу возвращает задание
+	SubmitAnalysis(context.Context, *AnalyzeFileRequest) (*AnalysisJob, error)
+	GetAnalysisJob(context.Context, *GetAnalysisJobRequest) (*AnalysisJob, error)
+	// Передаёт этапы анализа файла, пока анализ не завершится
+	WatchAnalysis(*WatchAnalysisRequest, grpc.ServerStreamingServer[AnalysisEvent]) error
+	// Вебхуки, уведомляемые о завершении анализа и найденном плагиате
+	CreateWebhook(context.Context, *CreateWebhookRequest) (*Webhook, error)
+	ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error)
+	DeleteWebhook(context.Context, *DeleteWebhookRequest) (*DeleteWebhookResponse, error)
+	ListWebhookDeliveries(context.Context, *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error)
+	mustEmbedUnimplementedFileAnalysisServiceServer()
 }
 
-// UnimplementedAnalyzerServiceServer must be embedded to have
+// UnimplementedFileAnalysisServiceServer must be embedded to have
 // forward compatible implementations.
 //
 // NOTE: this should be embedded by value instead of pointer to avoid a nil
 // pointer dereference when methods are called.
-type UnimplementedAnalyzerServiceServer struct{}
+type UnimplementedFileAnalysisServiceServer struct{}
 
-func (UnimplementedAnalyzerServiceServer) AnalyzeFile(context.Context, *AnalyzeFileRequest) (*AnalyzeFileResponse, error) {
+func (UnimplementedFileAnalysisServiceServer) AnalyzeFile(context.Context, *AnalyzeFileRequest) (*AnalyzeFileResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AnalyzeFile not implemented")
 }
-func (UnimplementedAnalyzerServiceServer) GetWordCloud(context.Context, *GetWordCloudRequest) (*GetWordCloudResponse, error) {
+func (UnimplementedFileAnalysisServiceServer) GetWordCloud(context.Context, *GetWordCloudRequest) (*GetWordCloudResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetWordCloud not implemented")
 }
-func (UnimplementedAnalyzerServiceServer) mustEmbedUnimplementedAnalyzerServiceServer() {}
-func (UnimplementedAnalyzerServiceServer) testEmbeddedByValue()                         {}
+func (UnimplementedFileAnalysisServiceServer) GetMatchedPassages(context.Context, *GetMatchedPassagesRequest) (*GetMatchedPassagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMatchedPassages not implemented")
+}
+func (UnimplementedFileAnalysisServiceServer) GetKeywords(context.Context, *GetKeywordsRequest) (*GetKeywordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKeywords not implemented")
+}
+func (UnimplementedFileAnalysisServiceServer) GetWordFrequencies(context.Context, *GetWordFrequenciesRequest) (*GetWordFrequenciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWordFrequencies not implemented")
+}
+func (UnimplementedFileAnalysisServiceServer) SubmitAnalysis(context.Context, *AnalyzeFileRequest) (*AnalysisJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitAnalysis not implemented")
+}
+func (UnimplementedFileAnalysisServiceServer) GetAnalysisJob(context.Context, *GetAnalysisJobRequest) (*AnalysisJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAnalysisJob not implemented")
+}
+func (UnimplementedFileAnalysisServiceServer) WatchAnalysis(*WatchAnalysisRequest, grpc.ServerStreamingServer[AnalysisEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchAnalysis not implemented")
+}
+func (UnimplementedFileAnalysisServiceServer) CreateWebhook(context.Context, *CreateWebhookRequest) (*Webhook, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWebhook not implemented")
+}
+func (UnimplementedFileAnalysisServiceServer) ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhooks not implemented")
+}
+func (UnimplementedFileAnalysisServiceServer) DeleteWebhook(context.Context, *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhook not implemented")
+}
+func (UnimplementedFileAnalysisServiceServer) ListWebhookDeliveries(context.Context, *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhookDeliveries not implemented")
+}
+func (UnimplementedFileAnalysisServiceServer) mustEmbedUnimplementedFileAnalysisServiceServer() {}
+func (UnimplementedFileAnalysisServiceServer) testEmbeddedByValue()                             {}
 
-// UnsafeAnalyzerServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to AnalyzerServiceServer will
+// UnsafeFileAnalysisServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FileAnalysisServiceServer will
 // result in compilation errors.
-type UnsafeAnalyzerServiceServer interface {
-	mustEmbedUnimplementedAnalyzerServiceServer()
+type UnsafeFileAnalysisServiceServer interface {
+	mustEmbedUnimplementedFileAnalysisServiceServer()
 }
 
-func RegisterAnalyzerServiceServer(s grpc.ServiceRegistrar, srv AnalyzerServiceServer) {
-	// If the following call pancis, it indicates UnimplementedAnalyzerServiceServer was
+func RegisterFileAnalysisServiceServer(s grpc.ServiceRegistrar, srv FileAnalysisServiceServer) {
+	// If the following call pancis, it indicates UnimplementedFileAnalysisServiceServer was
 	// embedded by pointer and is nil.  This will cause panics if an
 	// unimplemented method is ever invoked, so we test this at initialization
 	// time to prevent it from happening at runtime later due to I/O.
 	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
 		t.testEmbeddedByValue()
 	}
-	s.RegisterService(&AnalyzerService_ServiceDesc, srv)
+	s.RegisterService(&FileAnalysisService_ServiceDesc, srv)
 }
 
-func _AnalyzerService_AnalyzeFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _FileAnalysisService_AnalyzeFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AnalyzeFileRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AnalyzerServiceServer).AnalyzeFile(ctx, in)
+		return srv.(FileAnalysisServiceServer).AnalyzeFile(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AnalyzerService_AnalyzeFile_FullMethodName,
+		FullMethod: FileAnalysisService_AnalyzeFile_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AnalyzerServiceServer).AnalyzeFile(ctx, req.(*AnalyzeFileRequest))
+		return srv.(FileAnalysisServiceServer).AnalyzeFile(ctx, req.(*AnalyzeFileRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _AnalyzerService_GetWordCloud_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _FileAnalysisService_GetWordCloud_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetWordCloudRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AnalyzerServiceServer).GetWordCloud(ctx, in)
+		return srv.(FileAnalysisServiceServer).GetWordCloud(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AnalyzerService_GetWordCloud_FullMethodName,
+		FullMethod: FileAnalysisService_GetWordCloud_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AnalyzerServiceServer).GetWordCloud(ctx, req.(*GetWordCloudRequest))
+		return srv.(FileAnalysisServiceServer).GetWordCloud(ctx, req.(*GetWordCloudRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// AnalyzerService_ServiceDesc is the grpc.ServiceDesc for AnalyzerService service.
+func _FileAnalysisService_GetMatchedPassages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMatchedPassagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileAnalysisServiceServer).GetMatchedPassages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileAnalysisService_GetMatchedPassages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileAnalysisServiceServer).GetMatchedPassages(ctx, req.(*GetMatchedPassagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileAnalysisService_GetKeywords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetKeywordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileAnalysisServiceServer).GetKeywords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileAnalysisService_GetKeywords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileAnalysisServiceServer).GetKeywords(ctx, req.(*GetKeywordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileAnalysisService_GetWordFrequencies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWordFrequenciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileAnalysisServiceServer).GetWordFrequencies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileAnalysisService_GetWordFrequencies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileAnalysisServiceServer).GetWordFrequencies(ctx, req.(*GetWordFrequenciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileAnalysisService_SubmitAnalysis_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyzeFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileAnalysisServiceServer).SubmitAnalysis(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileAnalysisService_SubmitAnalysis_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileAnalysisServiceServer).SubmitAnalysis(ctx, req.(*AnalyzeFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileAnalysisService_GetAnalysisJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAnalysisJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileAnalysisServiceServer).GetAnalysisJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileAnalysisService_GetAnalysisJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileAnalysisServiceServer).GetAnalysisJob(ctx, req.(*GetAnalysisJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileAnalysisService_WatchAnalysis_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchAnalysisRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FileAnalysisServiceServer).WatchAnalysis(m, &grpc.GenericServerStream[WatchAnalysisRequest, AnalysisEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FileAnalysisService_WatchAnalysisServer = grpc.ServerStreamingServer[AnalysisEvent]
+
+func _FileAnalysisService_CreateWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileAnalysisServiceServer).CreateWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileAnalysisService_CreateWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileAnalysisServiceServer).CreateWebhook(ctx, req.(*CreateWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileAnalysisService_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileAnalysisServiceServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileAnalysisService_ListWebhooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileAnalysisServiceServer).ListWebhooks(ctx, req.(*ListWebhooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileAnalysisService_DeleteWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileAnalysisServiceServer).DeleteWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileAnalysisService_DeleteWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileAnalysisServiceServer).DeleteWebhook(ctx, req.(*DeleteWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileAnalysisService_ListWebhookDeliveries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhookDeliveriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileAnalysisServiceServer).ListWebhookDeliveries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileAnalysisService_ListWebhookDeliveries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileAnalysisServiceServer).ListWebhookDeliveries(ctx, req.(*ListWebhookDeliveriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// FileAnalysisService_ServiceDesc is the grpc.ServiceDesc for FileAnalysisService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
-var AnalyzerService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "analyzer.AnalyzerService",
-	HandlerType: (*AnalyzerServiceServer)(nil),
+var FileAnalysisService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "analyzer.FileAnalysisService",
+	HandlerType: (*FileAnalysisServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
 			MethodName: "AnalyzeFile",
-			Handler:    _AnalyzerService_AnalyzeFile_Handler,
+			Handler:    _FileAnalysisService_AnalyzeFile_Handler,
 		},
 		{
 			MethodName: "GetWordCloud",
-			Handler:    _AnalyzerService_GetWordCloud_Handler,
+			Handler:    _FileAnalysisService_GetWordCloud_Handler,
+		},
+		{
+			MethodName: "GetMatchedPassages",
+			Handler:    _FileAnalysisService_GetMatchedPassages_Handler,
+		},
+		{
+			MethodName: "GetKeywords",
+			Handler:    _FileAnalysisService_GetKeywords_Handler,
+		},
+		{
+			MethodName: "GetWordFrequencies",
+			Handler:    _FileAnalysisService_GetWordFrequencies_Handler,
+		},
+		{
+			MethodName: "SubmitAnalysis",
+			Handler:    _FileAnalysisService_SubmitAnalysis_Handler,
+		},
+		{
+			MethodName: "GetAnalysisJob",
+			Handler:    _FileAnalysisService_GetAnalysisJob_Handler,
+		},
+		{
+			MethodName: "CreateWebhook",
+			Handler:    _FileAnalysisService_CreateWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _FileAnalysisService_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "DeleteWebhook",
+			Handler:    _FileAnalysisService_DeleteWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhookDeliveries",
+			Handler:    _FileAnalysisService_ListWebhookDeliveries_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchAnalysis",
+			Handler:       _FileAnalysisService_WatchAnalysis_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/analyzer.proto",
 }
@@ -121,8 +121,10 @@ func (x *UploadFileResponse) GetFileId() string {
 
 // Запрос на получение файла
 type GetFileRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	FileId string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	// Вернуть файл в том виде, в котором он был загружен, а не извлечённый текст
+	Original      bool `protobuf:"varint,2,opt,name=original,proto3" json:"original,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -164,6 +166,13 @@ func (x *GetFileRequest) GetFileId() string {
 	return ""
 }
 
+func (x *GetFileRequest) GetOriginal() bool {
+	if x != nil {
+		return x.Original
+	}
+	return false
+}
+
 // Ответ с файлом
 type GetFileResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -217,6 +226,112 @@ func (x *GetFileResponse) GetContent() []byte {
 	return nil
 }
 
+// Часть загружаемого файла, имя файла передаётся в первом сообщении
+type UploadFileChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Content       []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadFileChunk) Reset() {
+	*x = UploadFileChunk{}
+	mi := &file_proto_storage_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadFileChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadFileChunk) ProtoMessage() {}
+
+func (x *UploadFileChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_storage_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadFileChunk.ProtoReflect.Descriptor instead.
+func (*UploadFileChunk) Descriptor() ([]byte, []int) {
+	return file_proto_storage_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UploadFileChunk) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *UploadFileChunk) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+// Часть получаемого файла, первое сообщение содержит только имя файла
+type FileChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Content       []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileChunk) Reset() {
+	*x = FileChunk{}
+	mi := &file_proto_storage_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileChunk) ProtoMessage() {}
+
+func (x *FileChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_storage_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileChunk.ProtoReflect.Descriptor instead.
+func (*FileChunk) Descriptor() ([]byte, []int) {
+	return file_proto_storage_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *FileChunk) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *FileChunk) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
 var File_proto_storage_proto protoreflect.FileDescriptor
 
 const file_proto_storage_proto_rawDesc = "" +
@@ -226,16 +341,25 @@ const file_proto_storage_proto_rawDesc = "" +
 	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x18\n" +
 	"\acontent\x18\x02 \x01(\fR\acontent\"-\n" +
 	"\x12UploadFileResponse\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\")\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\"E\n" +
 	"\x0eGetFileRequest\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\"H\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x1a\n" +
+	"\boriginal\x18\x02 \x01(\bR\boriginal\"H\n" +
 	"\x0fGetFileResponse\x12\x1b\n" +
 	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x18\n" +
-	"\acontent\x18\x02 \x01(\fR\acontent2\x95\x01\n" +
-	"\x0eStorageService\x12E\n" +
+	"\acontent\x18\x02 \x01(\fR\acontent\"H\n" +
+	"\x0fUploadFileChunk\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\fR\acontent\"B\n" +
+	"\tFileChunk\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\fR\acontent2\xa6\x02\n" +
+	"\x12FileStoringService\x12E\n" +
 	"\n" +
 	"UploadFile\x12\x1a.storage.UploadFileRequest\x1a\x1b.storage.UploadFileResponse\x12<\n" +
-	"\aGetFile\x12\x17.storage.GetFileRequest\x1a\x18.storage.GetFileResponseB7Z5local.dev/doc-analyzer/internal/proto/storage;storageb\x06proto3"
+	"\aGetFile\x12\x17.storage.GetFileRequest\x1a\x18.storage.GetFileResponse\x12K\n" +
+	"\x10UploadFileStream\x12\x18.storage.UploadFileChunk\x1a\x1b.storage.UploadFileResponse(\x01\x12>\n" +
+	"\rGetFileStream\x12\x17.storage.GetFileRequest\x1a\x12.storage.FileChunk0\x01B7Z5local.dev/doc-analyzer/internal/proto/storage;storageb\x06proto3"
 
 var (
 	file_proto_storage_proto_rawDescOnce sync.Once
@@ -249,20 +373,26 @@ func file_proto_storage_proto_rawDescGZIP() []byte {
 	return file_proto_storage_proto_rawDescData
 }
 
-var file_proto_storage_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_storage_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_proto_storage_proto_goTypes = []any{
 	(*UploadFileRequest)(nil),  // 0: storage.UploadFileRequest
 	(*UploadFileResponse)(nil), // 1: storage.UploadFileResponse
 	(*GetFileRequest)(nil),     // 2: storage.GetFileRequest
 	(*GetFileResponse)(nil),    // 3: storage.GetFileResponse
+	(*UploadFileChunk)(nil),    // 4: storage.UploadFileChunk
+	(*FileChunk)(nil),          // 5: storage.FileChunk
 }
 var file_proto_storage_proto_depIdxs = []int32{
-	0, // 0: storage.StorageService.UploadFile:input_type -> storage.UploadFileRequest
-	2, // 1: storage.StorageService.GetFile:input_type -> storage.GetFileRequest
-	1, // 2: storage.StorageService.UploadFile:output_type -> storage.UploadFileResponse
-	3, // 3: storage.StorageService.GetFile:output_type -> storage.GetFileResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
+	0, // 0: storage.FileStoringService.UploadFile:input_type -> storage.UploadFileRequest
+	2, // 1: storage.FileStoringService.GetFile:input_type -> storage.GetFileRequest
+	4, // 2: storage.FileStoringService.UploadFileStream:input_type -> storage.UploadFileChunk
+	2, // 3: storage.FileStoringService.GetFileStream:input_type -> storage.GetFileRequest
+	1, // 4: storage.FileStoringService.UploadFile:output_type -> storage.UploadFileResponse
+	3, // 5: storage.FileStoringService.GetFile:output_type -> storage.GetFileResponse
+	1, // 6: storage.FileStoringService.UploadFileStream:output_type -> storage.UploadFileResponse
+	5, // 7: storage.FileStoringService.GetFileStream:output_type -> storage.FileChunk
+	4, // [4:8] is the sub-list for method output_type
+	0, // [0:4] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
 	0, // [0:0] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
@@ -279,7 +409,7 @@ func file_proto_storage_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_storage_proto_rawDesc), len(file_proto_storage_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,149 +19,228 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	StorageService_UploadFile_FullMethodName = "/storage.StorageService/UploadFile"
-	StorageService_GetFile_FullMethodName    = "/storage.StorageService/GetFile"
+	FileStoringService_UploadFile_FullMethodName       = "/storage.FileStoringService/UploadFile"
+	FileStoringService_GetFile_FullMethodName          = "/storage.FileStoringService/GetFile"
+	FileStoringService_UploadFileStream_FullMethodName = "/storage.FileStoringService/UploadFileStream"
+	FileStoringService_GetFileStream_FullMethodName    = "/storage.FileStoringService/GetFileStream"
 )
 
-// StorageServiceClient is the client API for StorageService service.
+// FileStoringServiceClient is the client API for FileStoringService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 //
 // Сервис хранения файлов
-type StorageServiceClient interface {
-	// UploadFile — загрузка файла, возвращает ID
+type FileStoringServiceClient interface {
+	// UploadFile — загрузка файла (.txt, .md, .docx, .odt, .pdf, .rtf), возвращает ID
+	// Из документа извлекается текст, он хранится рядом с исходным файлом
 	UploadFile(ctx context.Context, in *UploadFileRequest, opts ...grpc.CallOption) (*UploadFileResponse, error)
-	// GetFile — получение файла по его ID
+	// GetFile — получение текста файла по его ID или исходного файла
 	GetFile(ctx context.Context, in *GetFileRequest, opts ...grpc.CallOption) (*GetFileResponse, error)
+	// UploadFileStream — загрузка файла частями, для файлов больше размера сообщения gRPC
+	UploadFileStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadFileChunk, UploadFileResponse], error)
+	// GetFileStream — получение текста файла или исходного файла частями
+	GetFileStream(ctx context.Context, in *GetFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChunk], error)
 }
 
-type storageServiceClient struct {
+type fileStoringServiceClient struct {
 	cc grpc.ClientConnInterface
 }
 
-func NewStorageServiceClient(cc grpc.ClientConnInterface) StorageServiceClient {
-	return &storageServiceClient{cc}
+func NewFileStoringServiceClient(cc grpc.ClientConnInterface) FileStoringServiceClient {
+	return &fileStoringServiceClient{cc}
 }
 
-func (c *storageServiceClient) UploadFile(ctx context.Context, in *UploadFileRequest, opts ...grpc.CallOption) (*UploadFileResponse, error) {
+func (c *fileStoringServiceClient) UploadFile(ctx context.Context, in *UploadFileRequest, opts ...grpc.CallOption) (*UploadFileResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(UploadFileResponse)
-	err := c.cc.Invoke(ctx, StorageService_UploadFile_FullMethodName, in, out, cOpts...)
+	err := c.cc.Invoke(ctx, FileStoringService_UploadFile_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *storageServiceClient) GetFile(ctx context.Context, in *GetFileRequest, opts ...grpc.CallOption) (*GetFileResponse, error) {
+func (c *fileStoringServiceClient) GetFile(ctx context.Context, in *GetFileRequest, opts ...grpc.CallOption) (*GetFileResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetFileResponse)
-	err := c.cc.Invoke(ctx, StorageService_GetFile_FullMethodName, in, out, cOpts...)
+	err := c.cc.Invoke(ctx, FileStoringService_GetFile_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// StorageServiceServer is the server API for StorageService service.
-// All implementations must embed UnimplementedStorageServiceServer
+func (c *fileStoringServiceClient) UploadFileStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadFileChunk, UploadFileResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FileStoringService_ServiceDesc.Streams[0], FileStoringService_UploadFileStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadFileChunk, UploadFileResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FileStoringService_UploadFileStreamClient = grpc.ClientStreamingClient[UploadFileChunk, UploadFileResponse]
+
+func (c *fileStoringServiceClient) GetFileStream(ctx context.Context, in *GetFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FileStoringService_ServiceDesc.Streams[1], FileStoringService_GetFileStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetFileRequest, FileChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FileStoringService_GetFileStreamClient = grpc.ServerStreamingClient[FileChunk]
+
+// FileStoringServiceServer is the server API for FileStoringService service.
+// All implementations must embed UnimplementedFileStoringServiceServer
 // for forward compatibility.
 //
 // Сервис хранения файлов
-type StorageServiceServer interface {
-	// UploadFile — загрузка файла, возвращает ID
+type FileStoringServiceServer interface {
+	// UploadFile — загрузка файла (.txt, .md, .docx, .odt, .pdf, .rtf), возвращает ID
+	// Из документа извлекается текст, он хранится рядом с исходным файлом
 	UploadFile(context.Context, *UploadFileRequest) (*UploadFileResponse, error)
-	// GetFile — получение файла по его ID
+	// GetFile — получение текста файла по его ID или исходного файла
 	GetFile(context.Context, *GetFileRequest) (*GetFileResponse, error)
-	mustEmbedUnimplementedStorageServiceServer()
+	// UploadFileStream — загрузка файла частями, для файлов больше размера сообщения gRPC
+	UploadFileStream(grpc.ClientStreamingServer[UploadFileChunk, UploadFileResponse]) error
+	// GetFileStream — получение текста файла или исходного файла частями
+	GetFileStream(*GetFileRequest, grpc.ServerStreamingServer[FileChunk]) error
+	mustEmbedUnimplementedFileStoringServiceServer()
 }
 
-// UnimplementedStorageServiceServer must be embedded to have
+// UnimplementedFileStoringServiceServer must be embedded to have
 // forward compatible implementations.
 //
 // NOTE: this should be embedded by value instead of pointer to avoid a nil
 // pointer dereference when methods are called.
-type UnimplementedStorageServiceServer struct{}
+type UnimplementedFileStoringServiceServer struct{}
 
-func (UnimplementedStorageServiceServer) UploadFile(context.Context, *UploadFileRequest) (*UploadFileResponse, error) {
+func (UnimplementedFileStoringServiceServer) UploadFile(context.Context, *UploadFileRequest) (*UploadFileResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UploadFile not implemented")
 }
-func (UnimplementedStorageServiceServer) GetFile(context.Context, *GetFileRequest) (*GetFileResponse, error) {
+func (UnimplementedFileStoringServiceServer) GetFile(context.Context, *GetFileRequest) (*GetFileResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetFile not implemented")
 }
-func (UnimplementedStorageServiceServer) mustEmbedUnimplementedStorageServiceServer() {}
-func (UnimplementedStorageServiceServer) testEmbeddedByValue()                        {}
+func (UnimplementedFileStoringServiceServer) UploadFileStream(grpc.ClientStreamingServer[UploadFileChunk, UploadFileResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadFileStream not implemented")
+}
+func (UnimplementedFileStoringServiceServer) GetFileStream(*GetFileRequest, grpc.ServerStreamingServer[FileChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method GetFileStream not implemented")
+}
+func (UnimplementedFileStoringServiceServer) mustEmbedUnimplementedFileStoringServiceServer() {}
+func (UnimplementedFileStoringServiceServer) testEmbeddedByValue()                            {}
 
-// UnsafeStorageServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to StorageServiceServer will
+// UnsafeFileStoringServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FileStoringServiceServer will
 // result in compilation errors.
-type UnsafeStorageServiceServer interface {
-	mustEmbedUnimplementedStorageServiceServer()
+type UnsafeFileStoringServiceServer interface {
+	mustEmbedUnimplementedFileStoringServiceServer()
 }
 
-func RegisterStorageServiceServer(s grpc.ServiceRegistrar, srv StorageServiceServer) {
-	// If the following call pancis, it indicates UnimplementedStorageServiceServer was
+func RegisterFileStoringServiceServer(s grpc.ServiceRegistrar, srv FileStoringServiceServer) {
+	// If the following call pancis, it indicates UnimplementedFileStoringServiceServer was
 	// embedded by pointer and is nil.  This will cause panics if an
 	// unimplemented method is ever invoked, so we test this at initialization
 	// time to prevent it from happening at runtime later due to I/O.
 	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
 		t.testEmbeddedByValue()
 	}
-	s.RegisterService(&StorageService_ServiceDesc, srv)
+	s.RegisterService(&FileStoringService_ServiceDesc, srv)
 }
 
-func _StorageService_UploadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _FileStoringService_UploadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UploadFileRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(StorageServiceServer).UploadFile(ctx, in)
+		return srv.(FileStoringServiceServer).UploadFile(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: StorageService_UploadFile_FullMethodName,
+		FullMethod: FileStoringService_UploadFile_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(StorageServiceServer).UploadFile(ctx, req.(*UploadFileRequest))
+		return srv.(FileStoringServiceServer).UploadFile(ctx, req.(*UploadFileRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _StorageService_GetFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _FileStoringService_GetFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetFileRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(StorageServiceServer).GetFile(ctx, in)
+		return srv.(FileStoringServiceServer).GetFile(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: StorageService_GetFile_FullMethodName,
+		FullMethod: FileStoringService_GetFile_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(StorageServiceServer).GetFile(ctx, req.(*GetFileRequest))
+		return srv.(FileStoringServiceServer).GetFile(ctx, req.(*GetFileRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// StorageService_ServiceDesc is the grpc.ServiceDesc for StorageService service.
+func _FileStoringService_UploadFileStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(FileStoringServiceServer).UploadFileStream(&grpc.GenericServerStream[UploadFileChunk, UploadFileResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FileStoringService_UploadFileStreamServer = grpc.ClientStreamingServer[UploadFileChunk, UploadFileResponse]
+
+func _FileStoringService_GetFileStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FileStoringServiceServer).GetFileStream(m, &grpc.GenericServerStream[GetFileRequest, FileChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FileStoringService_GetFileStreamServer = grpc.ServerStreamingServer[FileChunk]
+
+// FileStoringService_ServiceDesc is the grpc.ServiceDesc for FileStoringService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
-var StorageService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "storage.StorageService",
-	HandlerType: (*StorageServiceServer)(nil),
+var FileStoringService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "storage.FileStoringService",
+	HandlerType: (*FileStoringServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
 			MethodName: "UploadFile",
-			Handler:    _StorageService_UploadFile_Handler,
+			Handler:    _FileStoringService_UploadFile_Handler,
 		},
 		{
 			MethodName: "GetFile",
-			Handler:    _StorageService_GetFile_Handler,
+			Handler:    _FileStoringService_GetFile_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadFileStream",
+			Handler:       _FileStoringService_UploadFileStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "GetFileStream",
+			Handler:       _FileStoringService_GetFileStream_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/storage.proto",
 }
@@ -21,14 +21,64 @@ type MockFileAnalysisClient struct {
 	mock.Mock
 }
 
-func (m *MockFileAnalysisClient) AnalyzeFile(ctx context.Context, fileID string, generateWordCloud bool) (*pb.AnalyzeFileResponse, error) {
-	args := m.Called(ctx, fileID, generateWordCloud)
+func (m *MockFileAnalysisClient) AnalyzeFile(ctx context.Context, fileID string, generateWordCloud bool, wordCloudOptions *pb.WordCloudOptions) (*pb.AnalyzeFileResponse, error) {
+	args := m.Called(ctx, fileID, generateWordCloud, wordCloudOptions)
 	return args.Get(0).(*pb.AnalyzeFileResponse), args.Error(1)
 }
 
-func (m *MockFileAnalysisClient) GetWordCloud(ctx context.Context, location string) ([]byte, error) {
+func (m *MockFileAnalysisClient) GetWordCloud(ctx context.Context, location string) ([]byte, string, error) {
 	args := m.Called(ctx, location)
-	return args.Get(0).([]byte), args.Error(1)
+	return args.Get(0).([]byte), args.String(1), args.Error(2)
+}
+
+func (m *MockFileAnalysisClient) GetMatchedPassages(ctx context.Context, fileID, similarFileID string) ([]*pb.MatchedPassage, error) {
+	args := m.Called(ctx, fileID, similarFileID)
+	return args.Get(0).([]*pb.MatchedPassage), args.Error(1)
+}
+
+func (m *MockFileAnalysisClient) GetKeywords(ctx context.Context, fileID string, limit int32) ([]*pb.Keyword, error) {
+	args := m.Called(ctx, fileID, limit)
+	return args.Get(0).([]*pb.Keyword), args.Error(1)
+}
+
+func (m *MockFileAnalysisClient) GetWordFrequencies(ctx context.Context, request *pb.GetWordFrequenciesRequest) ([]*pb.WordItem, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).([]*pb.WordItem), args.Error(1)
+}
+
+func (m *MockFileAnalysisClient) SubmitAnalysis(ctx context.Context, fileID string, generateWordCloud bool, wordCloudOptions *pb.WordCloudOptions, webhookURL string) (*pb.AnalysisJob, error) {
+	args := m.Called(ctx, fileID, generateWordCloud, wordCloudOptions, webhookURL)
+	return args.Get(0).(*pb.AnalysisJob), args.Error(1)
+}
+
+func (m *MockFileAnalysisClient) GetAnalysisJob(ctx context.Context, jobID string) (*pb.AnalysisJob, error) {
+	args := m.Called(ctx, jobID)
+	return args.Get(0).(*pb.AnalysisJob), args.Error(1)
+}
+
+func (m *MockFileAnalysisClient) WatchAnalysis(ctx context.Context, fileID string, handle func(*pb.AnalysisEvent) error) error {
+	args := m.Called(ctx, fileID, handle)
+	return args.Error(0)
+}
+
+func (m *MockFileAnalysisClient) CreateWebhook(ctx context.Context, url, secret string, events []string) (*pb.Webhook, error) {
+	args := m.Called(ctx, url, secret, events)
+	return args.Get(0).(*pb.Webhook), args.Error(1)
+}
+
+func (m *MockFileAnalysisClient) ListWebhooks(ctx context.Context) (*pb.ListWebhooksResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*pb.ListWebhooksResponse), args.Error(1)
+}
+
+func (m *MockFileAnalysisClient) DeleteWebhook(ctx context.Context, webhookID string) error {
+	args := m.Called(ctx, webhookID)
+	return args.Error(0)
+}
+
+func (m *MockFileAnalysisClient) ListWebhookDeliveries(ctx context.Context, webhookID, fileID string, limit int32) (*pb.ListWebhookDeliveriesResponse, error) {
+	args := m.Called(ctx, webhookID, fileID, limit)
+	return args.Get(0).(*pb.ListWebhookDeliveriesResponse), args.Error(1)
 }
 
 func (m *MockFileAnalysisClient) Close() error {
@@ -49,7 +99,7 @@ func TestAnalyzeFile(t *testing.T) {
 	// Test case: successful analysis
 	t.Run("Successful analysis", func(t *testing.T) {
 		// Mock the client response
-		mockClient.On("AnalyzeFile", mock.Anything, "file123", true).Return(
+		mockClient.On("AnalyzeFile", mock.Anything, "file123", true, &pb.WordCloudOptions{}).Return(
 			&pb.AnalyzeFileResponse{
 				ParagraphCount:    5,
 				WordCount:         100,
@@ -133,7 +183,7 @@ func TestGetWordCloud(t *testing.T) {
 		imageData := []byte("fake-image-data")
 
 		// Mock the client response
-		mockClient.On("GetWordCloud", mock.Anything, "file123.png").Return(imageData, nil)
+		mockClient.On("GetWordCloud", mock.Anything, "file123.png").Return(imageData, "png", nil)
 
 		// Create a test request
 		req, _ := http.NewRequest("GET", "/api/v1/wordcloud/file123.png", nil)
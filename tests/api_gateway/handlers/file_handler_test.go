@@ -3,6 +3,7 @@ package handlers_test
 import (
 	"bytes"
 	"context"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
@@ -25,9 +26,17 @@ func (m *MockFileStoringClient) UploadFile(ctx context.Context, fileName string,
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockFileStoringClient) GetFile(ctx context.Context, fileID string) (string, []byte, error) {
-	args := m.Called(ctx, fileID)
-	return args.String(0), args.Get(1).([]byte), args.Error(2)
+func (m *MockFileStoringClient) UploadFileStream(ctx context.Context, fileName string, content io.Reader) (string, error) {
+	args := m.Called(ctx, fileName, content)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockFileStoringClient) GetFileStream(ctx context.Context, fileID string, original bool) (string, io.ReadCloser, error) {
+	args := m.Called(ctx, fileID, original)
+	if args.Get(1) == nil {
+		return args.String(0), nil, args.Error(2)
+	}
+	return args.String(0), args.Get(1).(io.ReadCloser), args.Error(2)
 }
 
 func (m *MockFileStoringClient) Close() error {
@@ -53,7 +62,7 @@ func TestUploadFile_ValidTxtFile(t *testing.T) {
 	writer.Close()
 
 	// Mock the client response
-	mockClient.On("UploadFile", mock.Anything, "test.txt", mock.Anything).Return("file123", nil)
+	mockClient.On("UploadFileStream", mock.Anything, "test.txt", mock.Anything).Return("file123", nil)
 
 	// Create a test request
 	req, _ := http.NewRequest("POST", "/api/v1/files", body)
@@ -78,10 +87,10 @@ func TestUploadFile_InvalidFileExtension(t *testing.T) {
 	router := gin.Default()
 	router.POST("/api/v1/files", handler.UploadFile)
 
-	// Create a multipart form with a non-.txt file
+	// Create a multipart form with a file of an unsupported extension
 	body := new(bytes.Buffer)
 	writer := multipart.NewWriter(body)
-	part, _ := writer.CreateFormFile("file", "test.pdf")
+	part, _ := writer.CreateFormFile("file", "test.exe")
 	part.Write([]byte("test content"))
 	writer.Close()
 
@@ -96,7 +105,7 @@ func TestUploadFile_InvalidFileExtension(t *testing.T) {
 	// Assert
 	assert.Equal(t, http.StatusBadRequest, resp.Code)
 	// The client should not be called because the file extension is invalid
-	mockClient.AssertNotCalled(t, "UploadFile")
+	mockClient.AssertNotCalled(t, "UploadFileStream")
 }
 
 func TestUploadFile_NoFileProvided(t *testing.T) {
@@ -120,7 +129,7 @@ func TestUploadFile_NoFileProvided(t *testing.T) {
 	// Assert
 	assert.Equal(t, http.StatusBadRequest, resp.Code)
 	// The client should not be called because no file was provided
-	mockClient.AssertNotCalled(t, "UploadFile")
+	mockClient.AssertNotCalled(t, "UploadFileStream")
 }
 
 func TestGetFile(t *testing.T) {
@@ -140,7 +149,7 @@ func TestGetFile(t *testing.T) {
 		fileContent := []byte("test content")
 
 		// Mock the client response
-		mockClient.On("GetFile", mock.Anything, "file123").Return(fileName, fileContent, nil)
+		mockClient.On("GetFileStream", mock.Anything, "file123", true).Return(fileName, io.NopCloser(bytes.NewReader(fileContent)), nil)
 
 		// Create a test request
 		req, _ := http.NewRequest("GET", "/api/v1/files/file123", nil)
@@ -169,6 +178,6 @@ func TestGetFile(t *testing.T) {
 
 		// Assert
 		assert.Equal(t, http.StatusNotFound, resp.Code) // Gin returns 404 for missing path parameters
-		mockClient.AssertNotCalled(t, "GetFile")
+		mockClient.AssertNotCalled(t, "GetFileStream")
 	})
 }